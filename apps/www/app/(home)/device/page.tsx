@@ -0,0 +1,57 @@
+import { redirect } from "next/navigation";
+
+import { lookupDeviceCode } from "@/lib/utils/device-codes";
+
+export const dynamic = "force-dynamic";
+
+type DevicePageProps = {
+  searchParams?: Promise<Record<string, string | string[] | undefined>>;
+};
+
+/**
+ * Where `cmux auth login --device` sends the user: enter the code the CLI
+ * shows, then approve the login on Stack's confirm page.
+ */
+export default async function DevicePage({ searchParams: searchParamsPromise }: DevicePageProps) {
+  const searchParams = await searchParamsPromise;
+  const codeParam = searchParams?.code;
+  const code = Array.isArray(codeParam) ? codeParam[0] : codeParam;
+
+  let error: string | null = null;
+  if (code) {
+    const entry = await lookupDeviceCode(code);
+    if (entry) {
+      redirect(`/handler/cli-auth-confirm?login_code=${encodeURIComponent(entry.loginCode)}`);
+    }
+    error = "That code is invalid or has expired. Run the login command again to get a new one.";
+  }
+
+  return (
+    <div className="flex min-h-dvh items-center justify-center px-4">
+      <form method="get" className="flex w-full max-w-sm flex-col gap-4">
+        <h1 className="text-2xl font-semibold">Log in to the cmux CLI</h1>
+        <p className="text-sm text-neutral-500">
+          Enter the code shown in your terminal.
+        </p>
+        <input
+          name="code"
+          defaultValue={code ?? ""}
+          placeholder="XXXX-XXXX"
+          autoComplete="off"
+          autoCapitalize="characters"
+          spellCheck={false}
+          autoFocus
+          required
+          className="rounded-md border border-neutral-300 bg-transparent px-3 py-2 font-mono text-lg uppercase tracking-widest dark:border-neutral-700"
+        />
+        {error ? <p className="text-sm text-red-500">{error}</p> : null}
+        <button
+          type="submit"
+          className="rounded-md bg-neutral-900 px-3 py-2 text-sm font-medium text-white dark:bg-white dark:text-neutral-900"
+        >
+          Continue
+        </button>
+      </form>
+    </div>
+  );
+}
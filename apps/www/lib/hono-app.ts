@@ -27,11 +27,13 @@ import {
   sandboxesRouter,
   teamsRouter,
   usersRouter,
+  usageRouter,
   iframePreflightRouter,
   workspaceConfigsRouter,
   previewRouter,
 } from "@/lib/routes/index";
 import { authAnonymousRouter } from "@/lib/routes/auth.anonymous.route";
+import { authDeviceRouter } from "@/lib/routes/auth.device.route";
 import { stackServerApp } from "@/lib/utils/stack";
 import { swaggerUI } from "@hono/swagger-ui";
 import { OpenAPIHono } from "@hono/zod-openapi";
@@ -121,6 +123,7 @@ app.get("/user", async (c) => {
 // Routes - Next.js passes the full /api/* path
 app.route("/", healthRouter);
 app.route("/", authAnonymousRouter);
+app.route("/", authDeviceRouter);
 app.route("/", usersRouter);
 app.route("/", booksRouter);
 app.route("/", devServerRouter);
@@ -145,6 +148,7 @@ app.route("/", iframePreflightRouter);
 app.route("/", environmentsRouter);
 app.route("/", sandboxesRouter);
 app.route("/", teamsRouter);
+app.route("/", usageRouter);
 app.route("/", branchRouter);
 app.route("/", codeReviewRouter);
 app.route("/", workspaceConfigsRouter);
@@ -0,0 +1,85 @@
+import { OpenAPIHono, createRoute, z } from "@hono/zod-openapi";
+import { generateUserCode, storeDeviceCode } from "@/lib/utils/device-codes";
+import { env } from "@/lib/utils/www-env";
+
+export const authDeviceRouter = new OpenAPIHono();
+
+// How long a device login stays open; the CLI takes its deadline from the
+// response rather than assuming this
+const DEVICE_CODE_TTL_SECONDS = 10 * 60;
+const POLL_INTERVAL_SECONDS = 5;
+
+const DeviceCodeResponse = z
+  .object({
+    device_code: z.string(),
+    user_code: z.string(),
+    verification_uri: z.string(),
+    verification_uri_complete: z.string(),
+    expires_in: z.number(),
+    interval: z.number(),
+  })
+  .openapi("DeviceCodeResponse");
+
+authDeviceRouter.openapi(
+  createRoute({
+    method: "post",
+    path: "/auth/device/code",
+    tags: ["Auth"],
+    summary: "Start a device login for a CLI without a local browser",
+    responses: {
+      200: {
+        description: "Device login started",
+        content: {
+          "application/json": {
+            schema: DeviceCodeResponse,
+          },
+        },
+      },
+      500: { description: "Server error" },
+    },
+  }),
+  async (c) => {
+    try {
+      const response = await fetch("https://api.stack-auth.com/api/v1/auth/cli", {
+        method: "POST",
+        headers: {
+          "Content-Type": "application/json",
+          "x-stack-project-id": env.NEXT_PUBLIC_STACK_PROJECT_ID,
+          "x-stack-publishable-client-key": env.NEXT_PUBLIC_STACK_PUBLISHABLE_CLIENT_KEY,
+          "x-stack-access-type": "client",
+        },
+        body: JSON.stringify({ expires_in_millis: DEVICE_CODE_TTL_SECONDS * 1000 }),
+      });
+      if (!response.ok) {
+        console.error("[authDevice] Stack API response status:", response.status);
+        return c.text("Failed to start device login", 500);
+      }
+      const data = (await response.json()) as {
+        polling_code: string;
+        login_code: string;
+      };
+
+      const userCode = generateUserCode();
+      await storeDeviceCode(userCode, {
+        loginCode: data.login_code,
+        expiresAt: Date.now() + DEVICE_CODE_TTL_SECONDS * 1000,
+      });
+
+      const verificationUri = `${new URL(c.req.url).origin}/device`;
+      return c.json(
+        {
+          device_code: data.polling_code,
+          user_code: userCode,
+          verification_uri: verificationUri,
+          verification_uri_complete: `${verificationUri}?code=${userCode}`,
+          expires_in: DEVICE_CODE_TTL_SECONDS,
+          interval: POLL_INTERVAL_SECONDS,
+        },
+        200
+      );
+    } catch (error) {
+      console.error("[authDevice] Error starting device login:", error);
+      return c.text("Failed to start device login", 500);
+    }
+  }
+);
@@ -125,13 +125,17 @@ const UpdateEnvironmentBody = z
     description: z.string().optional(),
     maintenanceScript: z.string().optional(),
     devScript: z.string().optional(),
+    selectedRepos: z.array(z.string()).optional(),
+    envVarsContent: z.string().optional(), // Replaces the entire .env file content
   })
   .refine(
     (value) =>
       value.name !== undefined ||
       value.description !== undefined ||
       value.maintenanceScript !== undefined ||
-      value.devScript !== undefined,
+      value.devScript !== undefined ||
+      value.selectedRepos !== undefined ||
+      value.envVarsContent !== undefined,
     "At least one field must be provided",
   )
   .openapi("UpdateEnvironmentBody");
@@ -573,6 +577,7 @@ environmentsRouter.openapi(
         description: body.description,
         maintenanceScript: body.maintenanceScript,
         devScript: body.devScript,
+        selectedRepos: body.selectedRepos,
       });
 
       const updated = await convexClient.query(api.environments.get, {
@@ -584,6 +589,14 @@ environmentsRouter.openapi(
         return c.text("Environment not found", 404);
       }
 
+      if (body.envVarsContent !== undefined) {
+        const store =
+          await stackServerAppJs.getDataVaultStore("cmux-snapshot-envs");
+        await store.setValue(updated.dataVaultKey, body.envVarsContent, {
+          secret: env.STACK_DATA_VAULT_SECRET,
+        });
+      }
+
       return c.json({
         id: updated._id,
         name: updated.name,
@@ -1,8 +1,10 @@
 import { getConvex } from "@/lib/utils/get-convex";
 import { getUserFromRequest } from "@/lib/utils/auth";
-import { stackServerApp } from "@/lib/utils/stack";
+import { stackServerApp, stackServerAppJs } from "@/lib/utils/stack";
+import { verifyTeamAccess } from "@/lib/utils/team-verification";
 import { api } from "@cmux/convex/api";
 import { OpenAPIHono, createRoute, z } from "@hono/zod-openapi";
+import { HTTPException } from "hono/http-exception";
 
 const teamsRouter = new OpenAPIHono();
 
@@ -54,6 +56,9 @@ const CreateTeamResponseSchema = z
   })
   .openapi("CreateTeamResponse");
 
+// Stack's default team permissions
+const TEAM_ADMIN_PERMISSION = "team_admin";
+
 const ErrorResponseSchema = z
   .object({
     code: z.number(),
@@ -61,11 +66,15 @@ const ErrorResponseSchema = z
   })
   .openapi("CreateTeamErrorResponse");
 
+const TeamRoleSchema = z.enum(["admin", "member"]);
+
 const TeamSchema = z
   .object({
     id: z.string().openapi({ description: "Team ID" }),
     displayName: z.string().openapi({ description: "Display name", example: "Frontend Wizards" }),
     slug: z.string().nullable().openapi({ description: "URL slug", example: "frontend-wizards" }),
+    role: TeamRoleSchema.openapi({ description: "The caller's role in the team" }),
+    selected: z.boolean().openapi({ description: "Whether this is the caller's selected team" }),
   })
   .openapi("Team");
 
@@ -75,6 +84,61 @@ const ListTeamsResponseSchema = z
   })
   .openapi("ListTeamsResponse");
 
+const TeamMemberSchema = z
+  .object({
+    userId: z.string().openapi({ description: "Stack user ID" }),
+    displayName: z.string().nullable().openapi({ description: "Name shown in the team" }),
+    email: z.string().nullable().openapi({ description: "Primary email" }),
+    role: TeamRoleSchema,
+  })
+  .openapi("TeamMember");
+
+const ListTeamMembersResponseSchema = z
+  .object({
+    members: z.array(TeamMemberSchema),
+  })
+  .openapi("ListTeamMembersResponse");
+
+const InviteTeamMemberRequestSchema = z
+  .object({
+    email: z.string().trim().email().openapi({ example: "teammate@example.com" }),
+    role: TeamRoleSchema.default("member").openapi({
+      description: "Role granted when the invitation is accepted",
+    }),
+  })
+  .openapi("InviteTeamMemberRequest");
+
+const TeamParamsSchema = z.object({
+  teamSlugOrId: z.string().openapi({ description: "Team slug or ID" }),
+});
+
+
+/**
+ * Resolves the caller and the Stack team behind teamSlugOrId, and checks
+ * that the caller holds permissionId in it.
+ */
+async function getTeamForMemberAdmin(
+  req: Request,
+  teamSlugOrId: string,
+  permissionId: string
+) {
+  const user = await getUserFromRequest(req);
+  if (!user) {
+    throw new HTTPException(401, { message: "Unauthorized" });
+  }
+  const convexTeam = await verifyTeamAccess({ req, teamSlugOrId });
+  const team = await stackServerAppJs.getTeam(convexTeam.uuid);
+  if (!team) {
+    throw new HTTPException(404, { message: "Team not found" });
+  }
+  if (!(await user.hasPermission(team, permissionId))) {
+    throw new HTTPException(403, {
+      message: `Forbidden: requires the ${permissionId} permission`,
+    });
+  }
+  return { user, team };
+}
+
 const SLUG_POLL_INTERVAL_MS = 400;
 const SLUG_POLL_TIMEOUT_MS = 15_000;
 
@@ -139,6 +203,7 @@ teamsRouter.openapi(
 
     const stackTeams = await user.listTeams();
     const convex = getConvex({ accessToken: authJson.accessToken });
+    const selectedTeamId = user.selectedTeam?.id ?? null;
 
     // Fetch slugs from Convex for each team
     const teams = await Promise.all(
@@ -150,10 +215,13 @@ teamsRouter.openapi(
         } catch {
           // Team might not exist in Convex yet
         }
+        const isAdmin = await user.hasPermission(team, TEAM_ADMIN_PERMISSION);
         return {
           id: team.id,
           displayName: team.displayName,
           slug,
+          role: isAdmin ? ("admin" as const) : ("member" as const),
+          selected: team.id === selectedTeamId,
         };
       })
     );
@@ -344,4 +412,157 @@ teamsRouter.openapi(
   }
 );
 
+// GET /teams/{teamSlugOrId}/members - List team members
+teamsRouter.openapi(
+  createRoute({
+    method: "get" as const,
+    path: "/teams/{teamSlugOrId}/members",
+    tags: ["Teams"],
+    summary: "List team members",
+    request: {
+      params: TeamParamsSchema,
+    },
+    responses: {
+      200: {
+        description: "Team members",
+        content: {
+          "application/json": {
+            schema: ListTeamMembersResponseSchema,
+          },
+        },
+      },
+      401: { description: "Unauthorized" },
+      403: { description: "Forbidden" },
+      404: { description: "Team not found" },
+    },
+  }),
+  async (c) => {
+    const { teamSlugOrId } = c.req.valid("param");
+    const { team } = await getTeamForMemberAdmin(c.req.raw, teamSlugOrId, "$read_members");
+
+    const users = await team.listUsers();
+    const members = await Promise.all(
+      users.map(async (member) => ({
+        userId: member.id,
+        displayName: member.teamProfile.displayName ?? member.displayName ?? null,
+        email: member.primaryEmail ?? null,
+        role: (await member.hasPermission(team, TEAM_ADMIN_PERMISSION))
+          ? ("admin" as const)
+          : ("member" as const),
+      }))
+    );
+
+    return c.json({ members }, 200);
+  }
+);
+
+// POST /teams/{teamSlugOrId}/invitations - Invite someone by email
+teamsRouter.openapi(
+  createRoute({
+    method: "post" as const,
+    path: "/teams/{teamSlugOrId}/invitations",
+    tags: ["Teams"],
+    summary: "Invite a member to the team",
+    request: {
+      params: TeamParamsSchema,
+      body: {
+        content: {
+          "application/json": {
+            schema: InviteTeamMemberRequestSchema,
+          },
+        },
+        required: true,
+      },
+    },
+    responses: {
+      201: { description: "Invitation sent" },
+      401: { description: "Unauthorized" },
+      403: { description: "Forbidden" },
+      404: { description: "Team not found" },
+      500: { description: "Failed to send invitation" },
+    },
+  }),
+  async (c) => {
+    const { teamSlugOrId } = c.req.valid("param");
+    const body = c.req.valid("json");
+    const { user, team } = await getTeamForMemberAdmin(c.req.raw, teamSlugOrId, "$invite_members");
+    const email = body.email.toLowerCase();
+
+    // Inviting someone as an admin would let members escalate through an
+    // account they control, so it takes the admin permission itself
+    if (body.role === "admin" && !(await user.hasPermission(team, TEAM_ADMIN_PERMISSION))) {
+      return c.json(
+        { code: 403, message: `Forbidden: inviting an admin requires the ${TEAM_ADMIN_PERMISSION} permission` },
+        403
+      );
+    }
+
+    try {
+      await team.inviteUser({ email });
+
+      // Invitations can't carry permissions; the Stack webhook grants the
+      // role once the invitee joins (see applyPendingInviteRole)
+      if (body.role === "admin") {
+        const metadata =
+          team.serverMetadata && typeof team.serverMetadata === "object"
+            ? (team.serverMetadata as Record<string, unknown>)
+            : {};
+        const pendingRoles =
+          (metadata.pendingInviteRoles as Record<string, string> | undefined) ?? {};
+        await team.update({
+          serverMetadata: {
+            ...metadata,
+            pendingInviteRoles: { ...pendingRoles, [email]: body.role },
+          },
+        });
+      }
+    } catch (error) {
+      console.error("Failed to invite team member", { teamSlugOrId, email, error });
+      return c.json({ code: 500, message: "Failed to send invitation" }, 500);
+    }
+
+    return c.json({ email, role: body.role }, 201);
+  }
+);
+
+// DELETE /teams/{teamSlugOrId}/members/{userId} - Remove a member
+teamsRouter.openapi(
+  createRoute({
+    method: "delete" as const,
+    path: "/teams/{teamSlugOrId}/members/{userId}",
+    tags: ["Teams"],
+    summary: "Remove a member from the team",
+    request: {
+      params: TeamParamsSchema.extend({
+        userId: z.string().openapi({ description: "Stack user ID or primary email" }),
+      }),
+    },
+    responses: {
+      200: { description: "Member removed" },
+      400: { description: "Cannot remove yourself" },
+      401: { description: "Unauthorized" },
+      403: { description: "Forbidden" },
+      404: { description: "Member not found" },
+    },
+  }),
+  async (c) => {
+    const { teamSlugOrId, userId } = c.req.valid("param");
+    const { user, team } = await getTeamForMemberAdmin(c.req.raw, teamSlugOrId, "$remove_members");
+
+    const needle = userId.toLowerCase();
+    const member = (await team.listUsers()).find(
+      (candidate) => candidate.id === userId || candidate.primaryEmail?.toLowerCase() === needle
+    );
+    if (!member) {
+      return c.json({ code: 404, message: "Member not found" }, 404);
+    }
+    if (member.id === user.id) {
+      return c.json({ code: 400, message: "You can't remove yourself from the team" }, 400);
+    }
+
+    await team.removeUser(member.id);
+    return c.json({ userId: member.id, removed: true }, 200);
+  }
+);
+
 export { teamsRouter };
@@ -0,0 +1,107 @@
+import { getAccessTokenFromRequest } from "@/lib/utils/auth";
+import { getConvex } from "@/lib/utils/get-convex";
+import { verifyTeamAccess } from "@/lib/utils/team-verification";
+import { api } from "@cmux/convex/api";
+import { OpenAPIHono, createRoute, z } from "@hono/zod-openapi";
+import { HTTPException } from "hono/http-exception";
+
+export const usageRouter = new OpenAPIHono();
+
+const DEFAULT_WINDOW_MS = 30 * 24 * 60 * 60 * 1000;
+
+const UsageQuery = z
+  .object({
+    teamSlugOrId: z.string(),
+    from: z.coerce.number().int().min(0).optional().openapi({
+      description: "Window start (ms since epoch). Defaults to 30 days before `to`.",
+    }),
+    to: z.coerce.number().int().min(0).optional().openapi({
+      description: "Window end (ms since epoch). Defaults to now.",
+    }),
+  })
+  .openapi("UsageQuery");
+
+const UsageResponse = z
+  .object({
+    from: z.number(),
+    to: z.number(),
+    instances: z.object({
+      count: z.number(),
+      hours: z.number(),
+      byProvider: z.array(
+        z.object({
+          provider: z.string(),
+          count: z.number(),
+          hours: z.number(),
+        })
+      ),
+    }),
+    taskRuns: z.object({
+      total: z.number(),
+      completed: z.number(),
+      failed: z.number(),
+      running: z.number(),
+      hours: z.number(),
+    }),
+    agents: z.array(
+      z.object({
+        agentName: z.string(),
+        runs: z.number(),
+        completed: z.number(),
+        failed: z.number(),
+        hours: z.number(),
+      })
+    ),
+  })
+  .openapi("UsageResponse");
+
+usageRouter.openapi(
+  createRoute({
+    method: "get",
+    path: "/usage",
+    summary: "Get team usage over a time window",
+    description:
+      "Aggregates devbox instance hours by provider, task run counts, and per-agent usage for the team.",
+    tags: ["Usage"],
+    request: {
+      query: UsageQuery,
+    },
+    responses: {
+      200: {
+        description: "Usage summary",
+        content: {
+          "application/json": {
+            schema: UsageResponse,
+          },
+        },
+      },
+      400: { description: "Invalid time window" },
+      401: { description: "Unauthorized" },
+    },
+  }),
+  async (c) => {
+    const accessToken = await getAccessTokenFromRequest(c.req.raw);
+    if (!accessToken) return c.text("Unauthorized", 401);
+
+    const query = c.req.valid("query");
+    const to = query.to ?? Date.now();
+    const from = query.from ?? to - DEFAULT_WINDOW_MS;
+    if (from >= to) {
+      throw new HTTPException(400, { message: "from must be before to" });
+    }
+
+    await verifyTeamAccess({
+      req: c.req.raw,
+      teamSlugOrId: query.teamSlugOrId,
+    });
+
+    const convex = getConvex({ accessToken });
+    const summary = await convex.query(api.usage.summary, {
+      teamSlugOrId: query.teamSlugOrId,
+      from,
+      to,
+    });
+
+    return c.json(summary);
+  }
+);
@@ -0,0 +1,63 @@
+import { randomBytes } from "node:crypto";
+import { stackServerAppJs } from "@/lib/utils/stack";
+import { env } from "@/lib/utils/www-env";
+
+// Consonants only: codes are read off one screen and typed on another, so
+// leave out digits and letters that look alike (0/O, 1/I/L), and vowels so
+// codes never spell words
+const USER_CODE_ALPHABET = "BCDFGHJKMNPQRSTVWXZ";
+const USER_CODE_LENGTH = 8;
+
+const DEVICE_CODE_STORE = "cmux-device-codes";
+
+export interface DeviceCodeEntry {
+  loginCode: string;
+  expiresAt: number;
+}
+
+/** Generates a user code like "BDFG-HJKM". */
+export function generateUserCode(): string {
+  const bytes = randomBytes(USER_CODE_LENGTH);
+  let code = "";
+  for (const byte of bytes) {
+    code += USER_CODE_ALPHABET[byte % USER_CODE_ALPHABET.length];
+  }
+  return `${code.slice(0, 4)}-${code.slice(4)}`;
+}
+
+/** Normalizes a typed user code: case and separators don't matter. */
+export function normalizeUserCode(code: string): string {
+  return code.toUpperCase().replace(/[^A-Z]/g, "");
+}
+
+export async function storeDeviceCode(
+  userCode: string,
+  entry: DeviceCodeEntry
+): Promise<void> {
+  const store = await stackServerAppJs.getDataVaultStore(DEVICE_CODE_STORE);
+  await store.setValue(normalizeUserCode(userCode), JSON.stringify(entry), {
+    secret: env.STACK_DATA_VAULT_SECRET,
+  });
+}
+
+/** Returns the entry for a user code, or null if it's unknown or expired. */
+export async function lookupDeviceCode(
+  userCode: string
+): Promise<DeviceCodeEntry | null> {
+  const key = normalizeUserCode(userCode);
+  if (key.length !== USER_CODE_LENGTH) {
+    return null;
+  }
+  const store = await stackServerAppJs.getDataVaultStore(DEVICE_CODE_STORE);
+  const value = await store.getValue(key, {
+    secret: env.STACK_DATA_VAULT_SECRET,
+  });
+  if (!value) {
+    return null;
+  }
+  const entry = JSON.parse(value) as DeviceCodeEntry;
+  if (Date.now() >= entry.expiresAt) {
+    return null;
+  }
+  return entry;
+}
@@ -352,6 +352,8 @@ func handleAPI(w http.ResponseWriter, r *http.Request) {
 	switch path {
 	case "/exec":
 		handleExec(w, r, body)
+	case "/exec-stream":
+		handleExecStream(w, r, body)
 	case "/read-file":
 		handleReadFile(w, r, body)
 	case "/write-file":
@@ -424,6 +426,89 @@ func handleExec(w http.ResponseWriter, r *http.Request, body map[string]interfac
 	})
 }
 
+// execEvent is one line of the /exec-stream response
+type execEvent struct {
+	Type    string `json:"type"` // stdout, stderr, exit, or error
+	Data    string `json:"data,omitempty"`
+	Code    *int   `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// handleExecStream runs a command like the SSH exec path and streams its
+// output as newline-delimited JSON events, ending with an exit event. The
+// command's process group is killed if the client disconnects.
+func handleExecStream(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
+	command, _ := body["command"].(string)
+	if command == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		sendJSON(w, map[string]string{"error": "command required"})
+		return
+	}
+
+	ctx := r.Context()
+	if t, ok := body["timeout"].(float64); ok && t > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(t)*time.Millisecond)
+		defer cancel()
+	}
+
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var writeMu sync.Mutex
+	encoder := json.NewEncoder(w)
+	send := func(event execEvent) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		encoder.Encode(event)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	c := newUserCommand(ctx, command)
+	setProcessGroup(c)
+	c.Cancel = func() error { return killProcessGroup(c) }
+	stdout, _ := c.StdoutPipe()
+	stderr, _ := c.StderrPipe()
+
+	if err := c.Start(); err != nil {
+		send(execEvent{Type: "error", Message: err.Error()})
+		return
+	}
+
+	var wg sync.WaitGroup
+	pump := func(eventType string, reader io.Reader) {
+		defer wg.Done()
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				send(execEvent{Type: eventType, Data: string(buf[:n])})
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+	wg.Add(2)
+	go pump("stdout", stdout)
+	go pump("stderr", stderr)
+	wg.Wait()
+
+	c.Wait()
+	if ctx.Err() != nil {
+		send(execEvent{Type: "error", Message: "command canceled: " + ctx.Err().Error()})
+	}
+	exitCode := -1
+	if c.ProcessState != nil {
+		exitCode = c.ProcessState.ExitCode()
+	}
+	send(execEvent{Type: "exit", Code: &exitCode})
+}
+
 func handleReadFile(w http.ResponseWriter, r *http.Request, body map[string]interface{}) {
 	path, _ := body["path"].(string)
 	if path == "" {
@@ -976,17 +1061,23 @@ func sendExitStatus(channel cryptossh.Channel, exitCode int) {
 	channel.SendRequest("exit-status", false, payload)
 }
 
-func runSSHExec(channel cryptossh.Channel, cmdStr string) int {
-	hasUser := userExists()
+// newUserCommand runs cmdStr in the workspace as "user" when that account
+// exists, or with bash otherwise
+func newUserCommand(ctx context.Context, cmdStr string) *exec.Cmd {
 	var c *exec.Cmd
-	if hasUser {
-		c = exec.Command("su", "-", "user", "-c", cmdStr)
+	if userExists() {
+		c = exec.CommandContext(ctx, "su", "-", "user", "-c", cmdStr)
 		c.Env = append(os.Environ(), "HOME=/home/user", "USER=user")
 	} else {
-		c = exec.Command("bash", "-c", cmdStr)
+		c = exec.CommandContext(ctx, "bash", "-c", cmdStr)
 		c.Env = append(os.Environ(), "HOME=/home/user")
 	}
 	c.Dir = workspaceDir
+	return c
+}
+
+func runSSHExec(channel cryptossh.Channel, cmdStr string) int {
+	c := newUserCommand(context.Background(), cmdStr)
 
 	stdin, _ := c.StdinPipe()
 	stdout, _ := c.StdoutPipe()
@@ -996,7 +1087,11 @@ func runSSHExec(channel cryptossh.Channel, cmdStr string) int {
 		return 1
 	}
 
-	go io.Copy(stdin, channel)
+	// Close stdin at EOF so commands reading a stream (e.g. tar -x) finish
+	go func() {
+		io.Copy(stdin, channel)
+		stdin.Close()
+	}()
 	go io.Copy(channel, stdout)
 	go io.Copy(channel.Stderr(), stderr)
 
@@ -0,0 +1,15 @@
+//go:build windows
+
+package main
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows (the worker only runs on Linux)
+func setProcessGroup(_ *exec.Cmd) {}
+
+// killProcessGroup kills just the command's process on Windows
+func killProcessGroup(c *exec.Cmd) error {
+	return c.Process.Kill()
+}
@@ -7,13 +7,17 @@ toolchain go1.24.12
 require (
 	github.com/creack/pty v1.1.21
 	github.com/gorilla/websocket v1.5.3
+	github.com/manaflow-ai/fswatch v0.0.0
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/sys v0.40.0
 	golang.org/x/term v0.39.0
 )
 
 require (
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.32.0
-	golang.org/x/sys v0.40.0 // indirect
 )
+
+replace github.com/manaflow-ai/fswatch => ../fswatch
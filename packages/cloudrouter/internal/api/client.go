@@ -3,6 +3,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -68,17 +69,27 @@ func (c *Client) doRequest(method, path string, body interface{}) ([]byte, error
 
 // Instance represents a sandbox instance
 type Instance struct {
-	ID         string `json:"id"`
-	Name       string `json:"name,omitempty"`
-	Status     string `json:"status"`
-	Provider   string `json:"provider,omitempty"`
-	Template   string `json:"templateId,omitempty"`
-	GPU        string `json:"gpu,omitempty"`
-	CreatedAt  int64  `json:"createdAt,omitempty"`
-	JupyterURL string `json:"jupyterUrl,omitempty"`
-	VSCodeURL  string `json:"vscodeUrl,omitempty"`
-	VNCURL     string `json:"vncUrl,omitempty"`
-	WorkerURL  string `json:"workerUrl,omitempty"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name,omitempty"`
+	Status       string            `json:"status"`
+	Provider     string            `json:"provider,omitempty"`
+	Template     string            `json:"templateId,omitempty"`
+	GPU          string            `json:"gpu,omitempty"`
+	CreatedAt    int64             `json:"createdAt,omitempty"`
+	ExpiresAt    int64             `json:"expiresAt,omitempty"`
+	JupyterURL   string            `json:"jupyterUrl,omitempty"`
+	VSCodeURL    string            `json:"vscodeUrl,omitempty"`
+	VNCURL       string            `json:"vncUrl,omitempty"`
+	WorkerURL    string            `json:"workerUrl,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	ExposedPorts []ExposedPort     `json:"exposedPorts,omitempty"`
+}
+
+// ExposedPort is a sandbox port shared at a public URL
+type ExposedPort struct {
+	Port      int    `json:"port"`
+	URL       string `json:"url"`
+	CreatedAt int64  `json:"createdAt,omitempty"`
 }
 
 type CreateInstanceRequest struct {
@@ -91,20 +102,24 @@ type CreateInstanceRequest struct {
 	MemoryMiB    int               `json:"memoryMiB,omitempty"`
 	DiskGB       int               `json:"diskGB,omitempty"`
 	Image        string            `json:"image,omitempty"`
+	SnapshotID   string            `json:"snapshotId,omitempty"`
 	TTLSeconds   int               `json:"ttlSeconds,omitempty"`
 	Envs         map[string]string `json:"envs,omitempty"`
+	Secrets      []string          `json:"secrets,omitempty"`
+	WithSecrets  bool              `json:"withSecrets,omitempty"`
 }
 
 type CreateInstanceResponse struct {
-	DevboxID   string `json:"id"`
-	Provider   string `json:"provider,omitempty"`
-	Status     string `json:"status"`
-	Template   string `json:"templateId,omitempty"`
-	GPU        string `json:"gpu,omitempty"`
-	JupyterURL string `json:"jupyterUrl,omitempty"`
-	VSCodeURL  string `json:"vscodeUrl,omitempty"`
-	WorkerURL  string `json:"workerUrl,omitempty"`
-	VNCURL     string `json:"vncUrl,omitempty"`
+	DevboxID   string   `json:"id"`
+	Provider   string   `json:"provider,omitempty"`
+	Status     string   `json:"status"`
+	Template   string   `json:"templateId,omitempty"`
+	GPU        string   `json:"gpu,omitempty"`
+	Secrets    []string `json:"secrets,omitempty"`
+	JupyterURL string   `json:"jupyterUrl,omitempty"`
+	VSCodeURL  string   `json:"vscodeUrl,omitempty"`
+	WorkerURL  string   `json:"workerUrl,omitempty"`
+	VNCURL     string   `json:"vncUrl,omitempty"`
 }
 
 func (c *Client) CreateInstance(req CreateInstanceRequest) (*CreateInstanceResponse, error) {
@@ -125,11 +140,16 @@ type ListInstancesResponse struct {
 	Instances []Instance `json:"instances"`
 }
 
-func (c *Client) ListInstances(teamSlug, provider string) ([]Instance, error) {
+// ListInstances lists sandboxes, optionally filtered by provider. Stopped
+// sandboxes are only included when includeStopped is set.
+func (c *Client) ListInstances(teamSlug, provider string, includeStopped bool) ([]Instance, error) {
 	path := fmt.Sprintf("/api/v2/devbox/instances?teamSlugOrId=%s", teamSlug)
 	if provider != "" {
 		path += "&provider=" + provider
 	}
+	if includeStopped {
+		path += "&includeStopped=true"
+	}
 	respBody, err := c.doRequest("GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -183,6 +203,42 @@ func (c *Client) DeleteInstance(teamSlug, id string) error {
 	return err
 }
 
+// RenameInstance changes the display name of a sandbox
+func (c *Client) RenameInstance(teamSlug, id, name string) error {
+	path := fmt.Sprintf("/api/v2/devbox/instances/%s/rename", id)
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"name":         name,
+	}
+	_, err := c.doRequest("POST", path, body)
+	return err
+}
+
+type UpdateTagsResponse struct {
+	Tags map[string]string `json:"tags"`
+}
+
+// UpdateTags sets and removes sandbox tags and returns the resulting tags
+func (c *Client) UpdateTags(teamSlug, id string, set map[string]string, remove []string) (map[string]string, error) {
+	path := fmt.Sprintf("/api/v2/devbox/instances/%s/tags", id)
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"set":          set,
+		"remove":       remove,
+	}
+
+	respBody, err := c.doRequest("POST", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp UpdateTagsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tags, nil
+}
+
 // ExtendTimeout extends the sandbox timeout
 func (c *Client) ExtendTimeout(teamSlug, id string, timeoutMs int) error {
 	path := fmt.Sprintf("/api/v2/devbox/instances/%s/extend", id)
@@ -194,6 +250,184 @@ func (c *Client) ExtendTimeout(teamSlug, id string, timeoutMs int) error {
 	return err
 }
 
+// ExposePort shares a sandbox port at a public HTTPS URL and returns it
+func (c *Client) ExposePort(teamSlug, id string, port int) (*ExposedPort, error) {
+	path := fmt.Sprintf("/api/v2/devbox/instances/%s/expose", id)
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"port":         port,
+	}
+
+	respBody, err := c.doRequest("POST", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ExposedPort
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UnexposePort stops sharing a sandbox port
+func (c *Client) UnexposePort(teamSlug, id string, port int) error {
+	path := fmt.Sprintf("/api/v2/devbox/instances/%s/unexpose", id)
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"port":         port,
+	}
+	_, err := c.doRequest("POST", path, body)
+	return err
+}
+
+type Snapshot struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider,omitempty"`
+	SourceID  string `json:"sourceId,omitempty"`
+	GPU       string `json:"gpu,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type ListSnapshotsResponse struct {
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// CreateSnapshot captures the sandbox filesystem as a snapshot shared with the team
+func (c *Client) CreateSnapshot(teamSlug, id, name string) (*Snapshot, error) {
+	path := fmt.Sprintf("/api/v2/devbox/instances/%s/snapshot", id)
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"name":         name,
+	}
+
+	respBody, err := c.doRequest("POST", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Snapshot
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListSnapshots lists the snapshots shared with the team
+func (c *Client) ListSnapshots(teamSlug string) ([]Snapshot, error) {
+	path := fmt.Sprintf("/api/v2/devbox/snapshots?teamSlugOrId=%s", teamSlug)
+	respBody, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListSnapshotsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Snapshots, nil
+}
+
+// Secret is a team secret. Values are write-only and never returned.
+type Secret struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+type ListSecretsResponse struct {
+	Secrets []Secret `json:"secrets"`
+}
+
+// ListSecrets lists the names of the team's secrets
+func (c *Client) ListSecrets(teamSlug string) ([]Secret, error) {
+	path := fmt.Sprintf("/api/v1/cmux/secrets?teamSlugOrId=%s", teamSlug)
+	respBody, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListSecretsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Secrets, nil
+}
+
+// SetSecret creates or updates a team secret
+func (c *Client) SetSecret(teamSlug, name, value, description string) error {
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"name":         name,
+		"value":        value,
+	}
+	if description != "" {
+		body["description"] = description
+	}
+	_, err := c.doRequest("PUT", "/api/v1/cmux/secrets", body)
+	return err
+}
+
+// DeleteSecret deletes a team secret
+func (c *Client) DeleteSecret(teamSlug, name string) error {
+	path := fmt.Sprintf("/api/v1/cmux/secrets/%s?teamSlugOrId=%s", name, teamSlug)
+	_, err := c.doRequest("DELETE", path, nil)
+	return err
+}
+
+// Team is a team the user belongs to
+type Team struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Role        string `json:"role"`     // admin or member
+	Selected    bool   `json:"selected"` // the user's selected team on the server
+}
+
+// SlugOrID returns the team's slug, or its ID if it has none
+func (t Team) SlugOrID() string {
+	if t.Slug != "" {
+		return t.Slug
+	}
+	return t.ID
+}
+
+type ListTeamsResponse struct {
+	Teams []Team `json:"teams"`
+}
+
+// ListTeams lists the teams the user belongs to
+func (c *Client) ListTeams() ([]Team, error) {
+	respBody, err := c.doRequest("GET", "/api/v2/devbox/teams", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ListTeamsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Teams, nil
+}
+
+// SelectTeam makes a team, given its slug or ID, the user's selected team on
+// the server, which commands use when no team is given
+func (c *Client) SelectTeam(teamSlugOrID string) (*Team, error) {
+	body := map[string]string{"teamSlugOrId": teamSlugOrID}
+	respBody, err := c.doRequest("POST", "/api/v2/devbox/teams/select", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var team Team
+	if err := json.Unmarshal(respBody, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
 type ExecRequest struct {
 	TeamSlugOrID string `json:"teamSlugOrId"`
 	Command      string `json:"command"`
@@ -239,6 +473,9 @@ type Template struct {
 	Image          string `json:"image,omitempty"`
 	SupportsDocker bool   `json:"supportsDocker,omitempty"`
 	Gated          bool   `json:"gated,omitempty"`
+	Custom         bool   `json:"custom,omitempty"`
+	Status         string `json:"status,omitempty"`
+	CreatedAt      int64  `json:"createdAt,omitempty"`
 }
 
 type ListTemplatesResponse struct {
@@ -262,6 +499,67 @@ func (c *Client) ListTemplates(teamSlug, provider string) ([]Template, error) {
 	return resp.Templates, nil
 }
 
+type TemplateLogLine struct {
+	Seq  int    `json:"seq"`
+	Text string `json:"text"`
+}
+
+// TemplateBuild is the status of a custom template built from a Dockerfile
+type TemplateBuild struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider,omitempty"`
+	Status    string            `json:"status"`
+	Error     string            `json:"error,omitempty"`
+	Warnings  []string          `json:"warnings,omitempty"`
+	CreatedAt int64             `json:"createdAt"`
+	Logs      []TemplateLogLine `json:"logs,omitempty"`
+}
+
+// BuildTemplate starts building a custom team template from a Dockerfile
+func (c *Client) BuildTemplate(teamSlug, name, dockerfile string) (*TemplateBuild, error) {
+	body := map[string]interface{}{
+		"teamSlugOrId": teamSlug,
+		"name":         name,
+		"dockerfile":   dockerfile,
+	}
+
+	respBody, err := c.doRequest("POST", "/api/v2/devbox/templates", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TemplateBuild
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetTemplateBuild returns a custom template's status and the build log
+// lines after the given sequence number
+func (c *Client) GetTemplateBuild(teamSlug, id string, after int) (*TemplateBuild, error) {
+	path := fmt.Sprintf("/api/v2/devbox/templates/%s?teamSlugOrId=%s&after=%d", id, teamSlug, after)
+	respBody, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TemplateBuild
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteTemplate deletes a custom team template
+func (c *Client) DeleteTemplate(teamSlug, id string) error {
+	path := fmt.Sprintf("/api/v2/devbox/templates/%s/delete", id)
+	body := map[string]string{"teamSlugOrId": teamSlug}
+	_, err := c.doRequest("POST", path, body)
+	return err
+}
+
 type AuthTokenResponse struct {
 	Token string `json:"token"`
 }
@@ -372,3 +670,58 @@ func DoWorkerRequestWithTimeout(workerURL, path, token string, body []byte, time
 
 	return respBody, nil
 }
+
+// ExecEvent is one event from the worker's /exec-stream endpoint
+type ExecEvent struct {
+	Type    string `json:"type"` // stdout, stderr, exit, or error
+	Data    string `json:"data,omitempty"`
+	Code    *int   `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// StreamWorkerExec runs a command through the worker daemon, calling onEvent
+// for each chunk of output as it arrives, and returns the exit code.
+// Canceling ctx closes the stream, which kills the remote command.
+func StreamWorkerExec(ctx context.Context, workerURL, token, command string, onEvent func(ExecEvent)) (int, error) {
+	data, err := json.Marshal(map[string]string{"command": command})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", workerURL+"/exec-stream", bytes.NewReader(data))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	// No client timeout: the stream lasts as long as the command does
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return -1, fmt.Errorf("worker error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event ExecEvent
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return -1, ctx.Err()
+			}
+			if err == io.EOF {
+				return -1, fmt.Errorf("output stream ended without an exit code")
+			}
+			return -1, fmt.Errorf("failed to read output stream: %w", err)
+		}
+		if event.Type == "exit" && event.Code != nil {
+			return *event.Code, nil
+		}
+		onEvent(event)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -137,7 +138,7 @@ func getCredentialsPath() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "credentials.json"), nil
+	return filepath.Join(configDir, "credentials"+profileSuffix()+".json"), nil
 }
 
 func getAccessTokenCachePath() (string, error) {
@@ -146,9 +147,9 @@ func getAccessTokenCachePath() (string, error) {
 		return "", err
 	}
 	cfg := GetConfig()
-	filename := "access_token_cache_prod.json"
+	filename := "access_token_cache_prod" + profileSuffix() + ".json"
 	if cfg.IsDev {
-		filename = "access_token_cache_dev.json"
+		filename = "access_token_cache_dev" + profileSuffix() + ".json"
 	}
 	return filepath.Join(configDir, filename), nil
 }
@@ -157,111 +158,68 @@ type Credentials struct {
 	StackRefreshToken string `json:"stack_refresh_token,omitempty"`
 }
 
-func StoreRefreshToken(token string) error {
-	if runtime.GOOS == "darwin" {
-		return storeInKeychain(token)
-	}
-	return storeInFile(token)
+// refreshTokenAccount names the refresh token entry in the credential store
+func refreshTokenAccount() string {
+	return fmt.Sprintf("STACK_REFRESH_TOKEN_%s%s", GetConfig().ProjectID, profileSuffix())
 }
 
-func GetRefreshToken() (string, error) {
-	if runtime.GOOS == "darwin" {
-		return getFromKeychain()
+func StoreRefreshToken(token string) error {
+	store, err := activeCredentialStore()
+	if err != nil {
+		return err
 	}
-	return getFromFile()
+	return store.set(refreshTokenAccount(), token)
 }
 
-func DeleteRefreshToken() error {
-	if runtime.GOOS == "darwin" {
-		return deleteFromKeychain()
+func GetRefreshToken() (string, error) {
+	store, err := activeCredentialStore()
+	if err != nil {
+		return "", err
 	}
-	return deleteFromFile()
-}
-
-func storeInKeychain(token string) error {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
-	_ = exec.Command("security", "delete-generic-password", "-s", KeychainService, "-a", account).Run()
-	cmd := exec.Command("security", "add-generic-password", "-s", KeychainService, "-a", account, "-w", token)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to store token in keychain: %w", err)
+	token, err := store.get(refreshTokenAccount())
+	if err == nil {
+		return token, nil
 	}
-	return nil
-}
-
-func getFromKeychain() (string, error) {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
-	cmd := exec.Command("security", "find-generic-password", "-s", KeychainService, "-a", account, "-w")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("token not found in keychain")
+	if _, isFile := store.(fileStore); !isFile {
+		if migrated, ok := migrateFileCredentials(store); ok {
+			return migrated, nil
+		}
 	}
-	return strings.TrimSpace(string(output)), nil
-}
-
-func deleteFromKeychain() error {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
-	_ = exec.Command("security", "delete-generic-password", "-s", KeychainService, "-a", account).Run()
-	return nil
+	return "", err
 }
 
-func storeInFile(token string) error {
-	path, err := getCredentialsPath()
+// migrateFileCredentials moves a refresh token left in the plaintext
+// credentials file by an older version into the system store
+func migrateFileCredentials(store credentialStore) (string, bool) {
+	token, err := fileStore{}.get("")
 	if err != nil {
-		return err
-	}
-	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
-		return fmt.Errorf("failed to create config dir: %w", err)
-	}
-	creds := Credentials{}
-	if data, err := os.ReadFile(path); err == nil {
-		_ = json.Unmarshal(data, &creds)
+		return "", false
 	}
-	creds.StackRefreshToken = token
-	data, err := json.MarshalIndent(creds, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal credentials: %w", err)
+	if err := store.set(refreshTokenAccount(), token); err != nil {
+		return "", false
 	}
-	return os.WriteFile(path, data, 0600)
+	_ = fileStore{}.delete("")
+	fmt.Fprintf(os.Stderr, "Moved credentials from %s to %s\n", fileStore{}.location(), store.location())
+	return token, true
 }
 
-func getFromFile() (string, error) {
-	path, err := getCredentialsPath()
+// CredentialStoreLocation describes where the refresh token is stored: the
+// system credential store, or the credentials file with --credential-store
+// file
+func CredentialStoreLocation() string {
+	store, err := activeCredentialStore()
 	if err != nil {
-		return "", err
+		return "no credential store"
 	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", fmt.Errorf("credentials file not found")
-	}
-	var creds Credentials
-	if err := json.Unmarshal(data, &creds); err != nil {
-		return "", fmt.Errorf("failed to parse credentials: %w", err)
-	}
-	if creds.StackRefreshToken == "" {
-		return "", fmt.Errorf("no refresh token stored")
-	}
-	return creds.StackRefreshToken, nil
+	return store.location()
 }
 
-func deleteFromFile() error {
-	path, err := getCredentialsPath()
+func DeleteRefreshToken() error {
+	store, err := activeCredentialStore()
 	if err != nil {
 		return err
 	}
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil
-	}
-	var creds Credentials
-	if err := json.Unmarshal(data, &creds); err != nil {
-		return nil
-	}
-	creds.StackRefreshToken = ""
-	newData, _ := json.MarshalIndent(creds, "", "  ")
-	return os.WriteFile(path, newData, 0600)
+	return store.delete(refreshTokenAccount())
 }
 
 type AccessToken struct {
@@ -298,7 +256,7 @@ func CacheAccessToken(token string, expiresAt int64) error {
 	}
 	cached := AccessToken{Token: token, ExpiresAt: expiresAt}
 	data, _ := json.Marshal(cached)
-	return os.WriteFile(path, data, 0600)
+	return writeFileAtomic(path, data, 0600)
 }
 
 func ClearCachedAccessToken() error {
@@ -332,6 +290,10 @@ type RefreshTokenResponse struct {
 func Login() error {
 	cfg := GetConfig()
 	if IsLoggedIn() {
+		if name := ActiveProfile(); name != DefaultProfile {
+			fmt.Printf("Already logged in to profile %s. Run 'cloudrouter --profile %s logout' first to re-authenticate.\n", name, name)
+			return nil
+		}
 		fmt.Println("Already logged in. Run 'cloudrouter logout' first to re-authenticate.")
 		return nil
 	}
@@ -391,7 +353,11 @@ func Login() error {
 			if err := StoreRefreshToken(pollResp.RefreshToken); err != nil {
 				return fmt.Errorf("failed to store token: %w", err)
 			}
+			_ = recordProfile(true)
 			fmt.Println("\n\n✓ Authentication successful!")
+			if name := ActiveProfile(); name != DefaultProfile {
+				fmt.Printf("  Profile: %s\n", name)
+			}
 			return nil
 		} else if pollResp.Status == "expired" {
 			return fmt.Errorf("authentication expired. Please try again")
@@ -404,15 +370,59 @@ func Login() error {
 func Logout() error {
 	_ = DeleteRefreshToken()
 	_ = ClearCachedAccessToken()
+	_ = recordProfile(false)
 	fmt.Println("✓ Logged out successfully")
 	return nil
 }
 
+// refreshMu serializes refreshes within a process; the lock file does the
+// same across processes
+var refreshMu sync.Mutex
+
+// GetAccessToken returns a valid access token, refreshing if necessary.
+// Concurrent callers, including other cloudrouter processes, share one
+// refresh.
 func GetAccessToken() (string, error) {
 	if token, err := GetCachedAccessToken(60); err == nil {
 		return token, nil
 	}
+	return refreshLocked(func() (string, error) {
+		// Another process may have refreshed while we waited for the lock
+		if token, err := GetCachedAccessToken(60); err == nil {
+			return token, nil
+		}
+		return refreshAccessToken()
+	})
+}
 
+// RefreshAccessToken exchanges the stored refresh token for a new access
+// token, bypassing the cache
+func RefreshAccessToken() (string, error) {
+	return refreshLocked(refreshAccessToken)
+}
+
+// refreshLocked runs refresh while holding the token cache lock
+func refreshLocked(refresh func() (string, error)) (string, error) {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	path, err := getAccessTokenCachePath()
+	if err != nil {
+		return "", err
+	}
+	release, err := lockFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return refresh()
+}
+
+func refreshAccessToken() (string, error) {
+	if err := CheckCredentialStore(); err != nil {
+		return "", err
+	}
 	refreshToken, err := GetRefreshToken()
 	if err != nil {
 		return "", fmt.Errorf("not logged in. Run 'cloudrouter login' first")
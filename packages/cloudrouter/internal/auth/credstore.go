@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Credential store names for --credential-store / CMUX_CREDENTIAL_STORE
+const (
+	CredentialStoreNative = "native"
+	CredentialStoreFile   = "file"
+)
+
+// CredentialStoreEnv selects the credential store when --credential-store
+// isn't given
+const CredentialStoreEnv = "CMUX_CREDENTIAL_STORE"
+
+// credentialStore keeps secrets under an account name: the macOS Keychain,
+// Secret Service on Linux, Windows Credential Manager, or a plaintext file
+type credentialStore interface {
+	set(account, secret string) error
+	get(account string) (string, error)
+	delete(account string) error
+	location() string
+}
+
+// cliCredentialStore is set from the --credential-store flag
+var cliCredentialStore string
+
+// SetCredentialStore selects the credential store for this invocation,
+// overriding CMUX_CREDENTIAL_STORE. Pass "" to leave the selection alone.
+func SetCredentialStore(name string) {
+	cliCredentialStore = name
+}
+
+// credentialStoreName returns the store asked for, or "" if none was
+func credentialStoreName() string {
+	if cliCredentialStore != "" {
+		return cliCredentialStore
+	}
+	return os.Getenv(CredentialStoreEnv)
+}
+
+// fallbackWarned makes sure the file store fallback is only reported once
+var fallbackWarned sync.Once
+
+// activeCredentialStore returns the selected store. By default that is the
+// system store, falling back to the credentials file with a warning when
+// there is none, so headless machines and CI keep working. Asking for
+// native explicitly makes a missing system store an error.
+func activeCredentialStore() (credentialStore, error) {
+	switch name := credentialStoreName(); name {
+	case CredentialStoreFile:
+		return fileStore{}, nil
+	case CredentialStoreNative:
+		store, err := nativeCredentialStore()
+		if err != nil {
+			return nil, fmt.Errorf("no system credential store available: %w. Pass --credential-store file (or set %s=file) to keep credentials in a plaintext file instead", err, CredentialStoreEnv)
+		}
+		return store, nil
+	case "":
+		store, err := nativeCredentialStore()
+		if err != nil {
+			fallbackWarned.Do(func() {
+				fmt.Fprintf(os.Stderr, "Warning: no system credential store available (%v); using %s\n", err, fileStore{}.location())
+			})
+			return fileStore{}, nil
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("invalid credential store %q (use %q or %q)", name, CredentialStoreNative, CredentialStoreFile)
+	}
+}
+
+// CheckCredentialStore returns an error if the selected store can't be used
+func CheckCredentialStore() error {
+	_, err := activeCredentialStore()
+	return err
+}
+
+// FileCredentialStoreRequested reports whether the credentials file was
+// asked for, rather than used because there is no system store
+func FileCredentialStoreRequested() bool {
+	return credentialStoreName() == CredentialStoreFile
+}
+
+// UsingFileCredentialStore reports whether credentials are kept in the
+// plaintext credentials file, whether asked for or as a fallback
+func UsingFileCredentialStore() bool {
+	store, err := activeCredentialStore()
+	if err != nil {
+		return false
+	}
+	_, isFile := store.(fileStore)
+	return isFile
+}
+
+// fileStore keeps the refresh token in the plaintext credentials file. It
+// holds a single secret per profile, so the account is ignored.
+type fileStore struct{}
+
+func (fileStore) set(_, secret string) error {
+	path, err := getCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	creds := Credentials{}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &creds)
+	}
+	creds.StackRefreshToken = secret
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+func (fileStore) get(string) (string, error) {
+	path, err := getCredentialsPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("credentials file not found")
+	}
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if creds.StackRefreshToken == "" {
+		return "", fmt.Errorf("no refresh token stored")
+	}
+	return creds.StackRefreshToken, nil
+}
+
+func (fileStore) delete(string) error {
+	path, err := getCredentialsPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil
+	}
+	creds.StackRefreshToken = ""
+	if creds == (Credentials{}) {
+		return os.Remove(path)
+	}
+	newData, _ := json.MarshalIndent(creds, "", "  ")
+	return writeFileAtomic(path, newData, 0600)
+}
+
+func (fileStore) location() string {
+	path, err := getCredentialsPath()
+	if err != nil {
+		return "credentials file"
+	}
+	return path
+}
@@ -0,0 +1,46 @@
+//go:build darwin
+
+package auth
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainStore keeps secrets in the macOS Keychain via the security tool
+type keychainStore struct{}
+
+func nativeCredentialStore() (credentialStore, error) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil, fmt.Errorf("macOS 'security' tool not found")
+	}
+	return keychainStore{}, nil
+}
+
+func (keychainStore) set(account, secret string) error {
+	_ = exec.Command("security", "delete-generic-password", "-s", KeychainService, "-a", account).Run()
+	cmd := exec.Command("security", "add-generic-password", "-s", KeychainService, "-a", account, "-w", secret)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store token in keychain: %w", err)
+	}
+	return nil
+}
+
+func (keychainStore) get(account string) (string, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", KeychainService, "-a", account, "-w")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token not found in keychain")
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (keychainStore) delete(account string) error {
+	_ = exec.Command("security", "delete-generic-password", "-s", KeychainService, "-a", account).Run()
+	return nil
+}
+
+func (keychainStore) location() string {
+	return fmt.Sprintf("macOS Keychain (service %q)", KeychainService)
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretServiceStore keeps secrets in the Secret Service (GNOME Keyring,
+// KWallet, KeePassXC) via libsecret's secret-tool
+type secretServiceStore struct {
+	path string
+}
+
+func nativeCredentialStore() (credentialStore, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("secret-tool not found (install libsecret-tools)")
+	}
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil, fmt.Errorf("no D-Bus session for the Secret Service")
+	}
+	return secretServiceStore{path: path}, nil
+}
+
+func (s secretServiceStore) set(account, secret string) error {
+	cmd := exec.Command(s.path, "store", "--label", KeychainService+" "+account,
+		"service", KeychainService, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to store token in Secret Service: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (s secretServiceStore) get(account string) (string, error) {
+	output, err := exec.Command(s.path, "lookup", "service", KeychainService, "account", account).Output()
+	token := strings.TrimSpace(string(output))
+	if err != nil || token == "" {
+		return "", fmt.Errorf("token not found in Secret Service")
+	}
+	return token, nil
+}
+
+func (s secretServiceStore) delete(account string) error {
+	_ = exec.Command(s.path, "clear", "service", KeychainService, "account", account).Run()
+	return nil
+}
+
+func (secretServiceStore) location() string {
+	return fmt.Sprintf("Secret Service (service %q)", KeychainService)
+}
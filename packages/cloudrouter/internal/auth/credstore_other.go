@@ -0,0 +1,9 @@
+//go:build !darwin && !linux && !windows
+
+package auth
+
+import "fmt"
+
+func nativeCredentialStore() (credentialStore, error) {
+	return nil, fmt.Errorf("not supported on this platform")
+}
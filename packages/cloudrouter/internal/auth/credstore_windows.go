@@ -0,0 +1,115 @@
+//go:build windows
+
+package auth
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential mirrors the Win32 CREDENTIALW struct
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// wincredStore keeps secrets in Windows Credential Manager
+type wincredStore struct{}
+
+func nativeCredentialStore() (credentialStore, error) {
+	if err := procCredWriteW.Find(); err != nil {
+		return nil, fmt.Errorf("Credential Manager unavailable: %w", err)
+	}
+	return wincredStore{}, nil
+}
+
+func credentialTarget(account string) string {
+	return KeychainService + ":" + account
+}
+
+func (wincredStore) set(account, secret string) error {
+	target, err := syscall.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ret, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return fmt.Errorf("failed to store token in Credential Manager: %w", err)
+	}
+	return nil
+}
+
+func (wincredStore) get(account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if err == errorNotFound {
+			return "", fmt.Errorf("token not found in Credential Manager")
+		}
+		return "", fmt.Errorf("failed to read Credential Manager: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	if cred.CredentialBlobSize == 0 {
+		return "", fmt.Errorf("token not found in Credential Manager")
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (wincredStore) delete(account string) error {
+	target, err := syscall.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return err
+	}
+	if ret, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ret == 0 && err != errorNotFound {
+		return fmt.Errorf("failed to delete token from Credential Manager: %w", err)
+	}
+	return nil
+}
+
+func (wincredStore) location() string {
+	return fmt.Sprintf("Windows Credential Manager (%s:*)", KeychainService)
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultProfile uses the original credential locations, so existing logins
+// keep working
+const DefaultProfile = "default"
+
+// ProfileEnv selects a profile when --profile isn't given
+const ProfileEnv = "CMUX_PROFILE"
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// cliProfile is set from the --profile flag
+var cliProfile string
+
+// SetProfile selects a profile for this invocation, overriding CMUX_PROFILE.
+// Pass "" to leave the selection alone.
+func SetProfile(name string) {
+	cliProfile = name
+}
+
+// ActiveProfile returns the selected profile: --profile, then CMUX_PROFILE,
+// then DefaultProfile
+func ActiveProfile() string {
+	if cliProfile != "" {
+		return cliProfile
+	}
+	if env := os.Getenv(ProfileEnv); env != "" {
+		return env
+	}
+	return DefaultProfile
+}
+
+// ValidateProfileName rejects names that can't be used in file names and
+// Keychain accounts
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, numbers, '-' and '_')", name)
+	}
+	return nil
+}
+
+// profileSuffix keeps each named profile's credentials and token cache
+// apart; the default profile uses the original names
+func profileSuffix() string {
+	name := ActiveProfile()
+	if name == DefaultProfile {
+		return ""
+	}
+	return "_" + name
+}
+
+func getProfilesPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "profiles.json"), nil
+}
+
+// loadProfileNames returns the named profiles that have been logged in to.
+// The Keychain can't be listed, so logins are recorded separately.
+func loadProfileNames() (map[string]bool, error) {
+	path, err := getProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return names, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+	for _, name := range list {
+		names[name] = true
+	}
+	return names, nil
+}
+
+func saveProfileNames(names map[string]bool) error {
+	path, err := getProfilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	list := make([]string, 0, len(names))
+	for name := range names {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// recordProfile adds or removes the active profile from the list of profiles
+func recordProfile(loggedIn bool) error {
+	name := ActiveProfile()
+	if name == DefaultProfile {
+		return nil
+	}
+	names, err := loadProfileNames()
+	if err != nil {
+		return err
+	}
+	if loggedIn {
+		names[name] = true
+	} else {
+		delete(names, name)
+	}
+	return saveProfileNames(names)
+}
+
+// ListProfiles returns the default profile and every named profile that has
+// been logged in to, sorted
+func ListProfiles() ([]string, error) {
+	names, err := loadProfileNames()
+	if err != nil {
+		return nil, err
+	}
+	list := []string{DefaultProfile}
+	for name := range names {
+		if name != DefaultProfile {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list[1:])
+	return list, nil
+}
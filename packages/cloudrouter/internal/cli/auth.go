@@ -1,15 +1,79 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 
+	"github.com/manaflow-ai/cloudrouter/internal/api"
 	"github.com/manaflow-ai/cloudrouter/internal/auth"
 	"github.com/spf13/cobra"
 )
 
+var (
+	whoamiFlagTeams bool
+	whoamiFlagJSON  bool
+)
+
+// whoamiTeam is a team in 'whoami --json' output
+type whoamiTeam struct {
+	api.Team
+	Active bool `json:"active"`
+}
+
+// whoamiResult is the 'whoami --json' output
+type whoamiResult struct {
+	LoggedIn   bool              `json:"loggedIn"`
+	User       *auth.UserProfile `json:"user,omitempty"`
+	ActiveTeam string            `json:"activeTeam,omitempty"`
+	Profile    string            `json:"profile"`
+	Teams      []whoamiTeam      `json:"teams,omitempty"`
+}
+
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Authentication commands",
+	Long: `Authentication commands.
+
+Each profile has its own login and token cache, so you can stay logged in
+to several accounts or organizations at once. Select one with --profile or
+CMUX_PROFILE; without either, the "default" profile is used.
+
+Examples:
+  cloudrouter auth login --profile work
+  cloudrouter --profile work ls
+  CMUX_PROFILE=work cloudrouter ls
+  cloudrouter auth profiles`,
+}
+
+var authProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List profiles and whether they are logged in",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := auth.ListProfiles()
+		if err != nil {
+			return err
+		}
+		active := auth.ActiveProfile()
+		if !slices.Contains(names, active) {
+			names = append(names, active)
+		}
+		defer auth.SetProfile(flagProfile)
+
+		for _, name := range names {
+			auth.SetProfile(name)
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			status := "not logged in"
+			if auth.IsLoggedIn() {
+				status = "logged in"
+			}
+			fmt.Printf("%s %-20s %s\n", marker, name, status)
+		}
+		return nil
+	},
 }
 
 var loginCmd = &cobra.Command{
@@ -31,18 +95,60 @@ var logoutCmd = &cobra.Command{
 var whoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show current user and team",
+	Long: `Show the logged-in user and the team commands use.
+
+With --teams, also list every team you belong to with your role in it. The
+team commands use is marked with '*'. Combine with --json so scripts can
+check the team context before running batch operations.
+
+Examples:
+  cloudrouter whoami --teams
+  cloudrouter whoami --teams --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := auth.CheckCredentialStore(); err != nil {
+			return err
+		}
 		if !auth.IsLoggedIn() {
+			if whoamiFlagJSON {
+				return printWhoamiJSON(whoamiResult{Profile: auth.ActiveProfile()})
+			}
 			fmt.Println("Not logged in. Run 'cloudrouter login' to authenticate.")
 			return nil
 		}
 
 		profile, err := auth.FetchUserProfile()
 		if err != nil {
+			if whoamiFlagJSON {
+				return fmt.Errorf("failed to fetch profile: %w", err)
+			}
 			fmt.Println("Logged in (could not fetch profile)")
 			return nil
 		}
 
+		activeTeam, _ := getTeamSlug()
+		var teams []api.Team
+		if whoamiFlagTeams {
+			if teams, err = api.NewClient().ListTeams(); err != nil {
+				return fmt.Errorf("failed to list teams: %w", err)
+			}
+		}
+
+		if whoamiFlagJSON {
+			result := whoamiResult{
+				LoggedIn:   true,
+				User:       profile,
+				ActiveTeam: activeTeam,
+				Profile:    auth.ActiveProfile(),
+			}
+			if whoamiFlagTeams {
+				result.Teams = make([]whoamiTeam, 0, len(teams))
+				for _, team := range teams {
+					result.Teams = append(result.Teams, whoamiTeam{Team: team, Active: teamMatches(team, activeTeam)})
+				}
+			}
+			return printWhoamiJSON(result)
+		}
+
 		if profile.Email != "" {
 			fmt.Printf("User: %s\n", profile.Email)
 		} else if profile.Name != "" {
@@ -53,10 +159,35 @@ var whoamiCmd = &cobra.Command{
 		} else if profile.TeamSlug != "" {
 			fmt.Printf("Team: %s\n", profile.TeamSlug)
 		}
+		if flagTeam != "" {
+			fmt.Printf("Using team: %s (from -t)\n", flagTeam)
+		}
+		if name := auth.ActiveProfile(); name != auth.DefaultProfile {
+			fmt.Printf("Profile: %s\n", name)
+		}
+		if whoamiFlagTeams {
+			fmt.Println("Teams:")
+			for _, team := range teams {
+				marker := " "
+				if teamMatches(team, activeTeam) {
+					marker = "*"
+				}
+				fmt.Printf("  %s %-24s %-24s %s\n", marker, team.SlugOrID(), orDash(team.DisplayName), team.Role)
+			}
+		}
 		return nil
 	},
 }
 
+func printWhoamiJSON(result whoamiResult) error {
+	out, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
 func init() {
 	authCmd.AddCommand(&cobra.Command{
 		Use:   "login",
@@ -68,9 +199,16 @@ func init() {
 		Short: "Logout",
 		RunE:  logoutCmd.RunE,
 	})
-	authCmd.AddCommand(&cobra.Command{
+	authWhoamiCmd := &cobra.Command{
 		Use:   "whoami",
 		Short: "Show current user",
+		Long:  whoamiCmd.Long,
 		RunE:  whoamiCmd.RunE,
-	})
+	}
+	for _, cmd := range []*cobra.Command{whoamiCmd, authWhoamiCmd} {
+		cmd.Flags().BoolVar(&whoamiFlagTeams, "teams", false, "Also list your teams and your role in each")
+		cmd.Flags().BoolVar(&whoamiFlagJSON, "json", false, "Output as JSON")
+	}
+	authCmd.AddCommand(authWhoamiCmd)
+	authCmd.AddCommand(authProfilesCmd)
 }
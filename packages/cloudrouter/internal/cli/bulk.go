@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+)
+
+// bulkConcurrency caps how many sandbox operations run at once.
+const bulkConcurrency = 8
+
+// selectSandboxes resolves the sandboxes a bulk command acts on: the IDs given
+// as arguments, every sandbox with --all, or those whose name matches a glob.
+// Exactly one of the three may be used. keep, if non-nil, filters the
+// sandboxes selected by --all or the glob.
+func selectSandboxes(client *api.Client, teamSlug string, ids []string, all bool, nameGlob string, includeStopped bool, keep func(api.Instance) bool) ([]string, error) {
+	modes := 0
+	if len(ids) > 0 {
+		modes++
+	}
+	if all {
+		modes++
+	}
+	if nameGlob != "" {
+		modes++
+	}
+	if modes != 1 {
+		return nil, fmt.Errorf("specify sandbox IDs, --all, or --name-glob (exactly one)")
+	}
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	if nameGlob != "" {
+		if _, err := path.Match(nameGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid --name-glob %q: %w", nameGlob, err)
+		}
+	}
+
+	instances, err := client.ListInstances(teamSlug, "", includeStopped)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []string
+	for _, inst := range instances {
+		if nameGlob != "" {
+			if ok, _ := path.Match(nameGlob, inst.Name); !ok {
+				continue
+			}
+		}
+		if keep != nil && !keep(inst) {
+			continue
+		}
+		selected = append(selected, inst.ID)
+	}
+	return selected, nil
+}
+
+// runBulk runs op for every ID concurrently and reports each result as it
+// completes, e.g. "Deleted: cr_abc123". Returns an error if any op failed.
+func runBulk(ids []string, doneLabel string, op func(id string) error) error {
+	if len(ids) == 1 {
+		if err := op(ids[0]); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", doneLabel, ids[0])
+		return nil
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+		wg     sync.WaitGroup
+	)
+	sem := make(chan struct{}, bulkConcurrency)
+
+	for _, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := op(id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "Failed: %s: %v\n", id, err)
+				return
+			}
+			fmt.Printf("%s: %s\n", doneLabel, id)
+		}(id)
+	}
+	wg.Wait()
+
+	fmt.Printf("\n%s %d of %d sandbox(es)\n", doneLabel, len(ids)-failed, len(ids))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d operation(s) failed", failed, len(ids))
+	}
+	return nil
+}
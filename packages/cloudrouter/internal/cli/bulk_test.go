@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRunBulk(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen = map[string]bool{}
+	)
+	ids := []string{"cr_1", "cr_2", "cr_3", "cr_4"}
+	err := runBulk(ids, "Deleted", func(id string) error {
+		mu.Lock()
+		seen[id] = true
+		mu.Unlock()
+		if id == "cr_3" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("runBulk() expected error when an operation fails")
+	}
+	if len(seen) != len(ids) {
+		t.Errorf("runBulk() ran %d operations, want %d", len(seen), len(ids))
+	}
+
+	if err := runBulk([]string{"cr_1"}, "Deleted", func(string) error { return nil }); err != nil {
+		t.Errorf("runBulk(single) error = %v", err)
+	}
+}
+
+func TestSelectSandboxesRequiresOneMode(t *testing.T) {
+	cases := []struct {
+		ids      []string
+		all      bool
+		nameGlob string
+	}{
+		{nil, false, ""},
+		{[]string{"cr_1"}, true, ""},
+		{nil, true, "e2e-*"},
+	}
+	for _, c := range cases {
+		if _, err := selectSandboxes(nil, "team", c.ids, c.all, c.nameGlob, false, nil); err == nil {
+			t.Errorf("selectSandboxes(%v, %v, %q) expected error", c.ids, c.all, c.nameGlob)
+		}
+	}
+
+	ids, err := selectSandboxes(nil, "team", []string{"cr_1", "cr_2"}, false, "", false, nil)
+	if err != nil || len(ids) != 2 {
+		t.Errorf("selectSandboxes(ids) = %v, %v", ids, err)
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
+	"github.com/spf13/cobra"
+)
+
+const cloneWorkspacePath = "/home/user/workspace"
+
+var (
+	cloneFlagName    string
+	cloneFlagTimeout int
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <id>",
+	Short: "Duplicate a sandbox's workspace into a new sandbox",
+	Long: `Create a new sandbox of the same type as an existing one and copy the
+existing sandbox's workspace (/home/user/workspace) into it.
+
+The source sandbox keeps running untouched, so this is useful for trying an
+experiment without disturbing work in progress. Packages installed outside
+the workspace are not copied; use 'cloudrouter snapshot' for that.
+
+Examples:
+  cloudrouter clone cr_abc123                    # Clone into a new sandbox
+  cloudrouter clone cr_abc123 --name experiment  # Clone with a name`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		src, err := client.GetInstance(teamSlug, args[0])
+		if err != nil {
+			return fmt.Errorf("sandbox not found: %w", err)
+		}
+		if src.WorkerURL == "" {
+			return fmt.Errorf("worker URL not available")
+		}
+		srcToken, err := client.GetAuthToken(teamSlug, src.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get auth token: %w", err)
+		}
+
+		name := cloneFlagName
+		if name == "" {
+			base := src.Name
+			if base == "" {
+				base = src.ID
+			}
+			name = base + "-clone"
+		}
+
+		createReq := api.CreateInstanceRequest{
+			TeamSlugOrID: teamSlug,
+			Name:         name,
+			GPU:          src.GPU,
+			TTLSeconds:   cloneFlagTimeout,
+		}
+		if src.Provider == "e2b" || src.Provider == "modal" {
+			createReq.Provider = src.Provider
+		}
+
+		resp, err := client.CreateInstance(createReq)
+		if err != nil {
+			return err
+		}
+
+		token := waitForAuthToken(client, teamSlug, resp.DevboxID)
+		if token == "" {
+			return fmt.Errorf("sandbox %s did not become ready; workspace was not copied", resp.DevboxID)
+		}
+
+		workerURL := resp.WorkerURL
+		if workerURL == "" {
+			inst, err := client.GetInstance(teamSlug, resp.DevboxID)
+			if err != nil || inst.WorkerURL == "" {
+				return fmt.Errorf("worker URL not available for %s; workspace was not copied", resp.DevboxID)
+			}
+			workerURL = inst.WorkerURL
+		}
+
+		output.Infof("Copying workspace from %s...\n", src.ID)
+		if err := copyWorkspace(src.WorkerURL, srcToken, workerURL, token); err != nil {
+			return fmt.Errorf("sandbox %s created, but copying the workspace failed: %w", resp.DevboxID, err)
+		}
+		output.Infoln("✓ Workspace copied")
+
+		fmt.Printf("Cloned %s to %s\n", src.ID, resp.DevboxID)
+		return nil
+	},
+}
+
+// copyWorkspace streams a tar of the source sandbox's workspace into the
+// destination sandbox over two SSH sessions, without staging it locally.
+func copyWorkspace(srcWorkerURL, srcToken, dstWorkerURL, dstToken string) error {
+	srcArgs, err := buildWorkerSSHArgs(srcWorkerURL, srcToken)
+	if err != nil {
+		return err
+	}
+	srcArgs = append(srcArgs, fmt.Sprintf("tar -C %s -cf - .", cloneWorkspacePath))
+
+	dstArgs, err := buildWorkerSSHArgs(dstWorkerURL, dstToken)
+	if err != nil {
+		return err
+	}
+	dstArgs = append(dstArgs, fmt.Sprintf("mkdir -p %s && tar -C %s -xf -", cloneWorkspacePath, cloneWorkspacePath))
+
+	srcCmd, srcCleanup, err := buildSSHCmd(srcArgs)
+	if err != nil {
+		return err
+	}
+	if srcCleanup != nil {
+		defer srcCleanup()
+	}
+	dstCmd, dstCleanup, err := buildSSHCmd(dstArgs)
+	if err != nil {
+		return err
+	}
+	if dstCleanup != nil {
+		defer dstCleanup()
+	}
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	var srcStderr, dstStderr bytes.Buffer
+	srcCmd.Stdout = pw
+	srcCmd.Stderr = &srcStderr
+	dstCmd.Stdin = pr
+	dstCmd.Stderr = &dstStderr
+
+	if err := srcCmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		return fmt.Errorf("ssh failed: %w", err)
+	}
+	if err := dstCmd.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		srcCmd.Process.Kill()
+		srcCmd.Wait()
+		return fmt.Errorf("ssh failed: %w", err)
+	}
+	// The child processes hold their own copies of the pipe ends.
+	pr.Close()
+	pw.Close()
+
+	srcErr := srcCmd.Wait()
+	dstErr := dstCmd.Wait()
+	if srcErr != nil {
+		return fmt.Errorf("reading workspace failed: %s", sshErrorDetail(srcErr, &srcStderr))
+	}
+	if dstErr != nil {
+		return fmt.Errorf("writing workspace failed: %s", sshErrorDetail(dstErr, &dstStderr))
+	}
+	return nil
+}
+
+// sshErrorDetail describes a failed SSH command, preferring its stderr.
+func sshErrorDetail(err error, stderr *bytes.Buffer) string {
+	if msg := strings.TrimSpace(filterSSHWarnings(stderr.String())); msg != "" {
+		return msg
+	}
+	return err.Error()
+}
+
+func init() {
+	cloneCmd.Flags().StringVarP(&cloneFlagName, "name", "n", "", "Name for the new sandbox (default: <source>-clone)")
+	cloneCmd.Flags().IntVar(&cloneFlagTimeout, "timeout", 600, "Sandbox timeout in seconds (default: 10 minutes)")
+}
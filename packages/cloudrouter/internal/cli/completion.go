@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/auth"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionCacheTTL keeps repeated <TAB> presses from hitting the API
+	completionCacheTTL = 30 * time.Second
+	// completionTimeout bounds how long a <TAB> press waits for the API
+	completionTimeout = 3 * time.Second
+)
+
+// completionEntry is a completable value with a human-readable description
+type completionEntry struct {
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	// Provider lets cached entries be filtered by --provider
+	Provider string `json:"provider,omitempty"`
+}
+
+type completionCache struct {
+	FetchedAt int64             `json:"fetchedAt"`
+	Entries   []completionEntry `json:"entries"`
+}
+
+func completionCachePath(kind, teamSlug string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	mode := "prod"
+	if auth.GetConfig().IsDev {
+		mode = "dev"
+	}
+	return filepath.Join(cacheDir, auth.ConfigDirName, fmt.Sprintf("completion_%s_%s_%s.json", kind, mode, teamSlug)), nil
+}
+
+// cachedCompletions returns entries from the cache if fresh, otherwise
+// fetches and caches them. Errors are swallowed: completion must never fail
+// loudly or hang the shell.
+func cachedCompletions(kind string, fetch func(client *api.Client, teamSlug string) ([]completionEntry, error)) []completionEntry {
+	teamSlug, err := getTeamSlug()
+	if err != nil {
+		return nil
+	}
+
+	path, pathErr := completionCachePath(kind, teamSlug)
+	if pathErr == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			var cache completionCache
+			if json.Unmarshal(data, &cache) == nil && time.Since(time.UnixMilli(cache.FetchedAt)) < completionCacheTTL {
+				return cache.Entries
+			}
+		}
+	}
+
+	// The API client has no per-request deadline, so give up on slow
+	// responses instead of blocking the shell
+	type result struct {
+		entries []completionEntry
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		entries, err := fetch(api.NewClient(), teamSlug)
+		done <- result{entries, err}
+	}()
+
+	var entries []completionEntry
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil
+		}
+		entries = res.entries
+	case <-time.After(completionTimeout):
+		return nil
+	}
+
+	if pathErr == nil {
+		data, _ := json.Marshal(completionCache{FetchedAt: time.Now().UnixMilli(), Entries: entries})
+		if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			_ = os.WriteFile(path, data, 0600)
+		}
+	}
+	return entries
+}
+
+// formatCompletions filters entries by prefix and skips values already on
+// the command line
+func formatCompletions(entries []completionEntry, toComplete string, exclude []string) []string {
+	var completions []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Value, toComplete) || containsString(exclude, entry.Value) {
+			continue
+		}
+		if entry.Description == "" {
+			completions = append(completions, entry.Value)
+		} else {
+			completions = append(completions, entry.Value+"\t"+entry.Description)
+		}
+	}
+	return completions
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxCompletions lists the team's sandboxes, described by name and status
+func sandboxCompletions() []completionEntry {
+	return cachedCompletions("instances", func(client *api.Client, teamSlug string) ([]completionEntry, error) {
+		instances, err := client.ListInstances(teamSlug, "", false)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(instances))
+		for _, inst := range instances {
+			description := inst.Status
+			if inst.Name != "" {
+				description = fmt.Sprintf("%s (%s)", inst.Name, inst.Status)
+			}
+			entries = append(entries, completionEntry{Value: inst.ID, Description: description})
+		}
+		return entries, nil
+	})
+}
+
+// completeSandboxIDs completes sandbox IDs for commands whose usage starts
+// with <id>. Commands taking <id>... complete an ID at every position;
+// others complete files for later path arguments and nothing otherwise.
+func completeSandboxIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	usage := strings.Fields(cmd.Use)
+	if len(args) > 0 && !(len(usage) > 1 && usage[1] == "<id>...") {
+		if len(usage) > len(args)+1 && strings.Contains(usage[len(args)+1], "path") {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return formatCompletions(sandboxCompletions(), toComplete, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateIDs completes template IDs, described by name and type,
+// limited to the --provider given on the command line
+func completeTemplateIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	provider := ""
+	if flag := cmd.Flags().Lookup("provider"); flag != nil {
+		provider = flag.Value.String()
+	}
+
+	entries := cachedCompletions("templates", func(client *api.Client, teamSlug string) ([]completionEntry, error) {
+		templates, err := client.ListTemplates(teamSlug, "")
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(templates))
+		for _, t := range templates {
+			description := t.Name
+			switch {
+			case t.Custom:
+				description += fmt.Sprintf(" (custom, %s)", t.Status)
+			case t.GPU != "":
+				description += fmt.Sprintf(" (%s, %s)", t.Provider, t.GPU)
+			case t.Provider != "":
+				description += fmt.Sprintf(" (%s)", t.Provider)
+			}
+			entries = append(entries, completionEntry{Value: t.ID, Description: description, Provider: t.Provider})
+		}
+		return entries, nil
+	})
+
+	if provider != "" {
+		filtered := entries[:0:0]
+		for _, entry := range entries {
+			if entry.Provider == provider {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+	return formatCompletions(entries, toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSnapshotIDs completes team snapshot IDs, described by name
+func completeSnapshotIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	entries := cachedCompletions("snapshots", func(client *api.Client, teamSlug string) ([]completionEntry, error) {
+		snapshots, err := client.ListSnapshots(teamSlug)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(snapshots))
+		for _, s := range snapshots {
+			entries = append(entries, completionEntry{Value: s.ID, Description: s.Name})
+		}
+		return entries, nil
+	})
+	return formatCompletions(entries, toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerSandboxCompletions gives every command whose usage starts with
+// <id> sandbox ID completion, so new commands get it without registering
+func registerSandboxCompletions(cmd *cobra.Command) {
+	usage := strings.Fields(cmd.Use)
+	if cmd.ValidArgsFunction == nil && len(usage) > 1 && strings.HasPrefix(usage[1], "<id>") {
+		cmd.ValidArgsFunction = completeSandboxIDs
+	}
+	for _, child := range cmd.Commands() {
+		registerSandboxCompletions(child)
+	}
+}
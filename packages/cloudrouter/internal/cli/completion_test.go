@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFormatCompletions(t *testing.T) {
+	entries := []completionEntry{
+		{Value: "cr_abc123", Description: "api (running)"},
+		{Value: "cr_abd456"},
+		{Value: "cr_xyz789", Description: "paused"},
+	}
+
+	got := formatCompletions(entries, "cr_ab", []string{"cr_abd456"})
+	want := []string{"cr_abc123\tapi (running)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formatCompletions() = %v, want %v", got, want)
+	}
+}
+
+func TestCompleteSandboxIDsLaterArgs(t *testing.T) {
+	tests := []struct {
+		use      string
+		args     []string
+		expected cobra.ShellCompDirective
+	}{
+		{"upload <id> [local-path]", []string{"cr_abc123"}, cobra.ShellCompDirectiveDefault},
+		{"rename <id> <name>", []string{"cr_abc123"}, cobra.ShellCompDirectiveNoFileComp},
+		{"code <id>", []string{"cr_abc123"}, cobra.ShellCompDirectiveNoFileComp},
+	}
+
+	for _, tt := range tests {
+		completions, directive := completeSandboxIDs(&cobra.Command{Use: tt.use}, tt.args, "")
+		if completions != nil || directive != tt.expected {
+			t.Errorf("completeSandboxIDs(%q, %v) = %v, %v; want nil, %v", tt.use, tt.args, completions, directive, tt.expected)
+		}
+	}
+}
+
+func TestRegisterSandboxCompletions(t *testing.T) {
+	root := &cobra.Command{Use: "cloudrouter"}
+	code := &cobra.Command{Use: "code <id>"}
+	stop := &cobra.Command{Use: "stop <id>... | --all"}
+	list := &cobra.Command{Use: "list"}
+	root.AddCommand(code, stop, list)
+
+	registerSandboxCompletions(root)
+
+	if code.ValidArgsFunction == nil || stop.ValidArgsFunction == nil {
+		t.Error("expected <id> commands to get sandbox ID completion")
+	}
+	if list.ValidArgsFunction != nil || root.ValidArgsFunction != nil {
+		t.Error("expected commands without <id> to be left alone")
+	}
+}
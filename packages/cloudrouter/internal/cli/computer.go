@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -42,23 +43,11 @@ func shellQuote(s string) string {
 // password prompts on Linux where SSH opens /dev/tty directly.
 // Returns stdout, stderr, and exit code.
 func runSSHCommand(workerURL, token, command string) (string, string, int, error) {
-	wsURL := toWebSocketURL(workerURL, token)
-
-	selfPath, err := getSelfPath()
+	sshArgs, err := buildWorkerSSHArgs(workerURL, token)
 	if err != nil {
 		return "", "", -1, err
 	}
-
-	proxyCmd := fmt.Sprintf("%s __ssh-proxy '%s'", selfPath, wsURL)
-	sshArgs := []string{
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "LogLevel=ERROR",
-		"-o", "PubkeyAuthentication=no",
-		"-o", fmt.Sprintf("ProxyCommand=%s", proxyCmd),
-		fmt.Sprintf("%s@e2b-sandbox", token),
-		command,
-	}
+	sshArgs = append(sshArgs, command)
 
 	cmd, cleanup, buildErr := buildSSHCmd(sshArgs)
 	if buildErr != nil {
@@ -86,6 +75,57 @@ func runSSHCommand(workerURL, token, command string) (string, string, int, error
 	return stdout.String(), stderrStr, exitCode, nil
 }
 
+// runSSHStream runs a command inside the sandbox like runSSHCommand, but
+// streams its output to stdout and stderr as it arrives. Returns the exit
+// code.
+func runSSHStream(workerURL, token, command string, stdout, stderr io.Writer) (int, error) {
+	sshArgs, err := buildWorkerSSHArgs(workerURL, token)
+	if err != nil {
+		return -1, err
+	}
+	sshArgs = append(sshArgs, command)
+
+	cmd, cleanup, err := buildSSHCmd(sshArgs)
+	if err != nil {
+		return -1, err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return ee.ExitCode(), nil
+		}
+		return -1, fmt.Errorf("ssh failed: %w", err)
+	}
+	return 0, nil
+}
+
+// buildWorkerSSHArgs returns the SSH options and destination for reaching a
+// sandbox through the worker's WebSocket tunnel. Callers append the remote
+// command, if any.
+func buildWorkerSSHArgs(workerURL, token string) ([]string, error) {
+	wsURL := toWebSocketURL(workerURL, token)
+
+	selfPath, err := getSelfPath()
+	if err != nil {
+		return nil, err
+	}
+
+	proxyCmd := fmt.Sprintf("%s __ssh-proxy '%s'", selfPath, wsURL)
+	return []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "LogLevel=ERROR",
+		"-o", "PubkeyAuthentication=no",
+		"-o", fmt.Sprintf("ProxyCommand=%s", proxyCmd),
+		fmt.Sprintf("%s@e2b-sandbox", token),
+	}, nil
+}
+
 // buildSSHCmd wraps SSH args with non-interactive password authentication.
 // Uses sshpass when available; otherwise sets up SSH_ASKPASS with a temp
 // script so SSH doesn't open /dev/tty for password prompts on Linux.
@@ -0,0 +1,499 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
+)
+
+// devcontainerPaths are the locations a devcontainer.json is looked up at,
+// relative to the workspace root, in order of preference.
+var devcontainerPaths = []string{
+	".devcontainer/devcontainer.json",
+	".devcontainer.json",
+}
+
+// providedFeatures are devcontainer features whose tools are already in the
+// cloudrouter base images, keyed by feature name without registry or version.
+var providedFeatures = map[string]bool{
+	"common-utils":             true,
+	"docker-in-docker":         true,
+	"docker-outside-of-docker": true,
+	"git":                      true,
+	"github-cli":               true,
+	"node":                     true,
+	"python":                   true,
+	"sshd":                     true,
+	"nvidia-cuda":              true, // Selects a GPU sandbox, see wantsGPU
+}
+
+// devcontainerConfig holds the parts of devcontainer.json that map onto a
+// cloudrouter sandbox.
+type devcontainerConfig struct {
+	Name              string                        `json:"name"`
+	Image             string                        `json:"image"`
+	Build             *devcontainerBuild            `json:"build"`
+	DockerFile        string                        `json:"dockerFile"` // Legacy spelling of build.dockerfile
+	DockerComposeFile json.RawMessage               `json:"dockerComposeFile"`
+	Features          map[string]json.RawMessage    `json:"features"`
+	OnCreateCommand   json.RawMessage               `json:"onCreateCommand"`
+	PostCreateCommand json.RawMessage               `json:"postCreateCommand"`
+	ForwardPorts      []json.RawMessage             `json:"forwardPorts"`
+	HostRequirements  *devcontainerHostRequirements `json:"hostRequirements"`
+}
+
+type devcontainerBuild struct {
+	Dockerfile string `json:"dockerfile"`
+}
+
+type devcontainerHostRequirements struct {
+	CPUs   int             `json:"cpus"`
+	Memory string          `json:"memory"`
+	GPU    json.RawMessage `json:"gpu"`
+}
+
+// stripJSONC turns devcontainer.json's JSON-with-comments into plain JSON by
+// dropping comments and trailing commas.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+		if inString {
+			out = append(out, ch)
+			if ch == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if ch == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case ch == '"':
+			inString = true
+			out = append(out, ch)
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case ch == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		case ch == '}' || ch == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+func parseDevcontainer(data []byte) (*devcontainerConfig, error) {
+	var dc devcontainerConfig
+	if err := json.Unmarshal(stripJSONC(data), &dc); err != nil {
+		return nil, fmt.Errorf("invalid devcontainer.json: %w", err)
+	}
+	return &dc, nil
+}
+
+// findDevcontainer returns the path of the devcontainer.json in dir, if any.
+func findDevcontainer(dir string) (string, bool) {
+	for _, rel := range devcontainerPaths {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// githubRawURLs returns the raw.githubusercontent.com URLs a GitHub repo's
+// devcontainer.json could be fetched from before the repo is cloned.
+func githubRawURLs(gitURL, branch string) []string {
+	repo := strings.TrimSuffix(gitURL, ".git")
+	switch {
+	case strings.HasPrefix(repo, "https://github.com/"):
+		repo = strings.TrimPrefix(repo, "https://github.com/")
+	case strings.HasPrefix(repo, "git@github.com:"):
+		repo = strings.TrimPrefix(repo, "git@github.com:")
+	default:
+		return nil
+	}
+	if strings.Count(repo, "/") != 1 {
+		return nil
+	}
+	ref := branch
+	if ref == "" {
+		ref = "HEAD"
+	}
+	urls := make([]string, 0, len(devcontainerPaths))
+	for _, rel := range devcontainerPaths {
+		urls = append(urls, fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repo, ref, rel))
+	}
+	return urls
+}
+
+// fetchGitHubDevcontainer fetches a public GitHub repo's devcontainer.json so
+// it can shape the sandbox before it's created. Returns nil if the repo isn't
+// on GitHub, is private, or has no devcontainer.json.
+func fetchGitHubDevcontainer(gitURL, branch string) *devcontainerConfig {
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	for _, url := range githubRawURLs(gitURL, branch) {
+		resp, err := httpClient.Get(url)
+		if err != nil {
+			return nil
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			continue
+		}
+		dc, err := parseDevcontainer(data)
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			return nil
+		}
+		return dc
+	}
+	return nil
+}
+
+// devcontainerCommands expands a lifecycle command (string, argv array, or
+// object of named commands) into shell commands.
+func devcontainerCommands(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var command string
+	if err := json.Unmarshal(raw, &command); err == nil {
+		if command == "" {
+			return nil, nil
+		}
+		return []string{command}, nil
+	}
+
+	var argv []string
+	if err := json.Unmarshal(raw, &argv); err == nil {
+		if len(argv) == 0 {
+			return nil, nil
+		}
+		quoted := make([]string, len(argv))
+		for i, arg := range argv {
+			quoted[i] = shellQuote(arg)
+		}
+		return []string{strings.Join(quoted, " ")}, nil
+	}
+
+	// Named commands run in parallel in devcontainers; run them one at a
+	// time in name order here.
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, fmt.Errorf("unsupported command format: %s", string(raw))
+	}
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var commands []string
+	for _, name := range names {
+		cmds, err := devcontainerCommands(named[name])
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmds...)
+	}
+	return commands, nil
+}
+
+// lifecycleCommands returns onCreateCommand then postCreateCommand.
+func (dc *devcontainerConfig) lifecycleCommands() ([]string, error) {
+	onCreate, err := devcontainerCommands(dc.OnCreateCommand)
+	if err != nil {
+		return nil, fmt.Errorf("onCreateCommand: %w", err)
+	}
+	postCreate, err := devcontainerCommands(dc.PostCreateCommand)
+	if err != nil {
+		return nil, fmt.Errorf("postCreateCommand: %w", err)
+	}
+	return append(onCreate, postCreate...), nil
+}
+
+// ports returns the forwardPorts entries that are plain ports. Entries like
+// "db:5432" point at other compose services and are returned as skipped.
+func (dc *devcontainerConfig) ports() (ports []int, skipped []string) {
+	for _, raw := range dc.ForwardPorts {
+		var port int
+		if err := json.Unmarshal(raw, &port); err == nil && port >= 1 && port <= 65535 {
+			ports = append(ports, port)
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			if p, err := strconv.Atoi(s); err == nil && p >= 1 && p <= 65535 {
+				ports = append(ports, p)
+				continue
+			}
+			skipped = append(skipped, s)
+			continue
+		}
+		skipped = append(skipped, string(raw))
+	}
+	return ports, skipped
+}
+
+// featureName strips the registry path and version from a feature ID, e.g.
+// "ghcr.io/devcontainers/features/node:1" -> "node".
+func featureName(id string) string {
+	name := id[strings.LastIndex(id, "/")+1:]
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// unsupportedFeatures returns the features cloudrouter can't provide, sorted.
+func (dc *devcontainerConfig) unsupportedFeatures() []string {
+	var unsupported []string
+	for id := range dc.Features {
+		if !providedFeatures[featureName(id)] {
+			unsupported = append(unsupported, id)
+		}
+	}
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// wantsGPU reports whether the devcontainer asks for a GPU, via
+// hostRequirements.gpu, the nvidia-cuda feature, or a CUDA image.
+func (dc *devcontainerConfig) wantsGPU() bool {
+	if dc.HostRequirements != nil && len(dc.HostRequirements.GPU) > 0 {
+		var required bool
+		if err := json.Unmarshal(dc.HostRequirements.GPU, &required); err == nil {
+			if required {
+				return true
+			}
+		} else if string(dc.HostRequirements.GPU) != `"optional"` {
+			return true // {"cores": ..., "memory": ...}
+		}
+	}
+	for id := range dc.Features {
+		if featureName(id) == "nvidia-cuda" {
+			return true
+		}
+	}
+	image := strings.ToLower(dc.Image)
+	return strings.Contains(image, "cuda") || strings.Contains(image, "nvidia")
+}
+
+// memoryMiB parses hostRequirements.memory ("8gb", "512mb") into MiB.
+func (dc *devcontainerConfig) memoryMiB() int {
+	if dc.HostRequirements == nil || dc.HostRequirements.Memory == "" {
+		return 0
+	}
+	s := strings.ToLower(strings.TrimSpace(dc.HostRequirements.Memory))
+	units := []struct {
+		suffix string
+		mib    float64
+	}{
+		{"tb", 1024 * 1024},
+		{"gb", 1024},
+		{"mb", 1},
+		{"kb", 1.0 / 1024},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return int(n * u.mib)
+		}
+	}
+	bytes, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(bytes / (1024 * 1024))
+}
+
+// dockerfile returns the Dockerfile path relative to devcontainer.json.
+func (dc *devcontainerConfig) dockerfile() string {
+	if dc.Build != nil && dc.Build.Dockerfile != "" {
+		return dc.Build.Dockerfile
+	}
+	return dc.DockerFile
+}
+
+// applyDevcontainerToStart maps devcontainer.json settings onto the start
+// flags that haven't been set explicitly. dcPath is the local
+// devcontainer.json, or empty when it was fetched remotely (in which case a
+// Dockerfile can't be built). project names templates built for it.
+func applyDevcontainerToStart(client *api.Client, teamSlug string, dc *devcontainerConfig, dcPath, project string) {
+	if len(dc.DockerComposeFile) > 0 {
+		fmt.Println("Warning: devcontainer dockerComposeFile isn't supported; only commands and ports are applied")
+	}
+
+	if dc.wantsGPU() && startFlagProvider == "" && startFlagGPU == "" {
+		output.Infoln("devcontainer: requests a GPU, using a GPU sandbox")
+		startFlagProvider = "modal"
+	}
+
+	if dc.HostRequirements != nil && startFlagSize == "" {
+		if startFlagCPU <= 0 && dc.HostRequirements.CPUs > 0 {
+			startFlagCPU = float64(dc.HostRequirements.CPUs)
+		}
+		if startFlagMemory <= 0 {
+			startFlagMemory = dc.memoryMiB()
+		}
+	}
+
+	if unsupported := dc.unsupportedFeatures(); len(unsupported) > 0 {
+		fmt.Printf("Warning: devcontainer features aren't installed: %s (install them in postCreateCommand)\n", strings.Join(unsupported, ", "))
+	}
+
+	dockerfile := dc.dockerfile()
+	switch {
+	case dockerfile != "" && (startFlagTemplate != "" || startFlagSnapshot != ""):
+		// An explicit template or snapshot wins over the devcontainer's
+	case dockerfile != "" && dcPath == "":
+		fmt.Println("Warning: devcontainer Dockerfile can only be built from a local directory; using the default template")
+	case dockerfile != "" && startFlagProvider != "" && startFlagProvider != "modal":
+		fmt.Println("Warning: devcontainer Dockerfiles are built as GPU templates; ignoring it for the e2b provider")
+	case dockerfile != "":
+		path := filepath.Join(filepath.Dir(dcPath), filepath.FromSlash(dockerfile))
+		templateID, err := ensureDevcontainerTemplate(client, teamSlug, project, path)
+		if err != nil {
+			fmt.Printf("Warning: failed to build devcontainer Dockerfile, using the default template: %v\n", err)
+		} else {
+			startFlagTemplate = templateID
+		}
+	case dc.Image != "" && !dc.wantsGPU():
+		output.Infof("devcontainer: image %s isn't used; sandboxes run the cloudrouter base image\n", dc.Image)
+	}
+}
+
+// ensureDevcontainerTemplate builds a custom template from a devcontainer
+// Dockerfile, reusing a ready template built from the same contents.
+func ensureDevcontainerTemplate(client *api.Client, teamSlug, project, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	name := fmt.Sprintf("devcontainer-%s-%s", project, hex.EncodeToString(sum[:])[:8])
+
+	templates, err := client.ListTemplates(teamSlug, "modal")
+	if err == nil {
+		for _, t := range templates {
+			if t.Custom && t.Name == name && t.Status == "ready" {
+				output.Infof("devcontainer: using template %s (%s)\n", t.ID, name)
+				return t.ID, nil
+			}
+		}
+	}
+
+	output.Infof("devcontainer: building template %s from %s...\n", name, path)
+	build, err := client.BuildTemplate(teamSlug, name, string(data))
+	if err != nil {
+		return "", err
+	}
+	final, err := streamTemplateBuild(client, teamSlug, build.ID)
+	if err != nil {
+		return "", err
+	}
+	if final.Status != "ready" {
+		return "", fmt.Errorf("template build failed: %s", final.Error)
+	}
+	return final.ID, nil
+}
+
+// readSandboxDevcontainer reads devcontainer.json from a sandbox workspace,
+// e.g. after cloning a repo that couldn't be inspected beforehand.
+func readSandboxDevcontainer(client *api.Client, teamSlug, id string) *devcontainerConfig {
+	for _, rel := range devcontainerPaths {
+		resp, err := client.Exec(teamSlug, id, "cat /home/user/workspace/"+rel, 30)
+		if err != nil || resp.ExitCode != 0 {
+			continue
+		}
+		dc, err := parseDevcontainer([]byte(resp.Stdout))
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+			return nil
+		}
+		return dc
+	}
+	return nil
+}
+
+// runDevcontainerSetup runs the devcontainer lifecycle commands in the
+// workspace and forwards the declared ports. Failures are reported as
+// warnings so the sandbox stays usable.
+func runDevcontainerSetup(client *api.Client, teamSlug, id string, dc *devcontainerConfig) {
+	commands, err := dc.lifecycleCommands()
+	if err != nil {
+		fmt.Printf("Warning: devcontainer %v\n", err)
+	}
+	succeeded := true
+	for _, command := range commands {
+		output.Infof("Running %s...\n", command)
+		resp, err := client.Exec(teamSlug, id, "cd /home/user/workspace && "+command, 600)
+		if err != nil {
+			fmt.Printf("Warning: devcontainer command failed: %v\n", err)
+			succeeded = false
+			break
+		}
+		if resp.ExitCode != 0 {
+			fmt.Printf("Warning: devcontainer command exited with code %d\n", resp.ExitCode)
+			if resp.Stderr != "" {
+				fmt.Print(resp.Stderr)
+			}
+			succeeded = false
+			break
+		}
+	}
+	if len(commands) > 0 && succeeded {
+		output.Infoln("✓ devcontainer commands finished")
+	}
+
+	ports, skipped := dc.ports()
+	for _, port := range ports {
+		exposed, err := client.ExposePort(teamSlug, id, port)
+		if err != nil {
+			fmt.Printf("Warning: failed to forward port %d: %v\n", port, err)
+			continue
+		}
+		fmt.Printf("  Port %d: %s\n", port, exposed.URL)
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Warning: skipped devcontainer forwardPorts: %s\n", strings.Join(skipped, ", "))
+	}
+}
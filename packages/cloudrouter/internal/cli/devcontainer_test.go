@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDevcontainer(t *testing.T) {
+	data := []byte(`{
+	// Node + Postgres project
+	"name": "web", /* inline */
+	"image": "mcr.microsoft.com/devcontainers/typescript-node:20",
+	"features": {
+		"ghcr.io/devcontainers/features/node:1": {},
+		"ghcr.io/devcontainers-contrib/features/pnpm:2": {},
+	},
+	"onCreateCommand": ["npm", "install", "it's"],
+	"postCreateCommand": {
+		"server": "npm run build",
+		"db": "createdb app // not a comment",
+	},
+	"forwardPorts": [3000, "5173", "db:5432",],
+	"hostRequirements": {"cpus": 4, "memory": "8gb"},
+}`)
+
+	dc, err := parseDevcontainer(data)
+	if err != nil {
+		t.Fatalf("parseDevcontainer() error = %v", err)
+	}
+	if dc.Name != "web" {
+		t.Errorf("Name = %q, want %q", dc.Name, "web")
+	}
+
+	commands, err := dc.lifecycleCommands()
+	if err != nil {
+		t.Fatalf("lifecycleCommands() error = %v", err)
+	}
+	wantCommands := []string{
+		`'npm' 'install' 'it'\''s'`,
+		"createdb app // not a comment",
+		"npm run build",
+	}
+	if !reflect.DeepEqual(commands, wantCommands) {
+		t.Errorf("lifecycleCommands() = %q, want %q", commands, wantCommands)
+	}
+
+	ports, skipped := dc.ports()
+	if !reflect.DeepEqual(ports, []int{3000, 5173}) || !reflect.DeepEqual(skipped, []string{"db:5432"}) {
+		t.Errorf("ports() = %v, %v", ports, skipped)
+	}
+
+	if got := dc.unsupportedFeatures(); !reflect.DeepEqual(got, []string{"ghcr.io/devcontainers-contrib/features/pnpm:2"}) {
+		t.Errorf("unsupportedFeatures() = %v", got)
+	}
+	if dc.wantsGPU() {
+		t.Error("wantsGPU() = true, want false")
+	}
+	if got := dc.memoryMiB(); got != 8192 {
+		t.Errorf("memoryMiB() = %d, want 8192", got)
+	}
+}
+
+func TestDevcontainerWantsGPU(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected bool
+	}{
+		{`{"hostRequirements": {"gpu": true}}`, true},
+		{`{"hostRequirements": {"gpu": "optional"}}`, false},
+		{`{"hostRequirements": {"gpu": {"cores": 1}}}`, true},
+		{`{"features": {"ghcr.io/devcontainers/features/nvidia-cuda:1": {}}}`, true},
+		{`{"image": "nvidia/cuda:12.2.0-devel-ubuntu22.04"}`, true},
+		{`{"image": "mcr.microsoft.com/devcontainers/base:ubuntu"}`, false},
+	}
+
+	for _, tt := range tests {
+		dc, err := parseDevcontainer([]byte(tt.json))
+		if err != nil {
+			t.Fatalf("parseDevcontainer(%s) error = %v", tt.json, err)
+		}
+		if got := dc.wantsGPU(); got != tt.expected {
+			t.Errorf("wantsGPU(%s) = %v, want %v", tt.json, got, tt.expected)
+		}
+	}
+}
+
+func TestGithubRawURLs(t *testing.T) {
+	got := githubRawURLs("https://github.com/acme/app.git", "dev")
+	want := []string{
+		"https://raw.githubusercontent.com/acme/app/dev/.devcontainer/devcontainer.json",
+		"https://raw.githubusercontent.com/acme/app/dev/.devcontainer.json",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("githubRawURLs() = %v, want %v", got, want)
+	}
+
+	if got := githubRawURLs("git@github.com:acme/app.git", ""); len(got) != 2 || got[1] != "https://raw.githubusercontent.com/acme/app/HEAD/.devcontainer.json" {
+		t.Errorf("githubRawURLs(ssh) = %v", got)
+	}
+	if got := githubRawURLs("https://gitlab.com/acme/app", ""); got != nil {
+		t.Errorf("githubRawURLs(gitlab) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/auth"
+	"github.com/manaflow-ai/cloudrouter/internal/version"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// Provider APIs that sandboxes are created on
+const (
+	e2bAPIURL   = "https://api.e2b.dev/health"
+	modalAPIURL = "https://api.modal.com"
+)
+
+var doctorFlagJSON bool
+
+// doctorCheck is the result of a single diagnostic
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// checkVersion reports the build and whether a newer release is available
+func checkVersion() doctorCheck {
+	check := doctorCheck{
+		Name:   "Version",
+		Status: checkOK,
+		Detail: fmt.Sprintf("cloudrouter %s (%s, %s build), %s/%s", versionStr, commitStr, buildMode, runtime.GOOS, runtime.GOARCH),
+	}
+
+	result := version.CheckForUpdates()
+	switch {
+	case result.CurrentVersion == "" || result.CurrentVersion == "dev":
+		check.Detail += "; update check skipped for dev builds"
+	case result.Error != nil:
+		check.Status = checkWarn
+		check.Detail += "; couldn't check for updates: " + result.Error.Error()
+	case result.IsOutdated:
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("cloudrouter %s is out of date (latest %s)", result.CurrentVersion, result.LatestVersion)
+		check.Fix = "Run 'npm i -g @manaflow-ai/cloudrouter', then 'cloudrouter skills update'"
+	default:
+		check.Detail += "; up to date"
+	}
+	return check
+}
+
+// checkCredentialStore verifies the refresh token can be read from the
+// system credential store or credentials file
+func checkCredentialStore() doctorCheck {
+	check := doctorCheck{Name: "Credential store"}
+
+	if err := auth.CheckCredentialStore(); err != nil {
+		check.Status = checkFail
+		check.Detail = err.Error()
+		check.Fix = credentialStoreHint()
+		return check
+	}
+	location := auth.CredentialStoreLocation()
+
+	if auth.UsingFileCredentialStore() {
+		if info, err := os.Stat(location); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+			check.Status = checkWarn
+			check.Detail = fmt.Sprintf("%s is readable by other users (mode %s)", location, info.Mode().Perm())
+			check.Fix = fmt.Sprintf("Run 'chmod 600 %s'", location)
+			return check
+		}
+	}
+
+	if _, err := auth.GetRefreshToken(); err != nil {
+		check.Status = checkFail
+		check.Detail = "no refresh token in " + location
+		check.Fix = "Run 'cloudrouter login'"
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = "refresh token found in " + location
+	if auth.UsingFileCredentialStore() {
+		check.Status = checkWarn
+		check.Detail += " (plaintext)"
+		if auth.FileCredentialStoreRequested() {
+			check.Fix = "Drop --credential-store file to use the system credential store"
+		} else {
+			check.Detail += "; no system credential store available"
+			check.Fix = credentialStoreHint()
+		}
+	}
+	return check
+}
+
+func credentialStoreHint() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "Make sure /usr/bin is on your PATH"
+	case "linux":
+		return "Install libsecret-tools and a Secret Service provider such as GNOME Keyring, or pass --credential-store file"
+	default:
+		return "Pass --credential-store file to keep credentials in a plaintext file"
+	}
+}
+
+// checkAuthentication forces a token refresh and looks up the team
+func checkAuthentication() doctorCheck {
+	check := doctorCheck{Name: "Authentication"}
+
+	if _, err := auth.RefreshAccessToken(); err != nil {
+		check.Status = checkFail
+		check.Detail = "token refresh failed: " + err.Error()
+		check.Fix = "Run 'cloudrouter login' to sign in again"
+		return check
+	}
+
+	teamSlug, err := getTeamSlug()
+	if err != nil {
+		check.Status = checkWarn
+		check.Detail = "token refresh works, but no team was found: " + err.Error()
+		check.Fix = "Select a team in the web app, or pass --team"
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = "token refresh works, team " + teamSlug
+	return check
+}
+
+// checkReachable treats any HTTP response as reachable; only network errors
+// fail
+func checkReachable(ctx context.Context, name, rawURL, fix string) doctorCheck {
+	check := doctorCheck{Name: name}
+	if rawURL == "" {
+		check.Status = checkFail
+		check.Detail = "URL not configured"
+		check.Fix = fix
+		return check
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("invalid URL %s: %v", rawURL, err)
+		check.Fix = fix
+		return check
+	}
+
+	start := time.Now()
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("%s unreachable: %v", rawURL, err)
+		check.Fix = strings.TrimSpace("Check your network, proxy, or VPN settings. " + fix)
+		return check
+	}
+	resp.Body.Close()
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s (%d in %s)", rawURL, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	if resp.StatusCode >= 500 {
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s returned %d", rawURL, resp.StatusCode)
+		check.Fix = "The service may be having problems; try again in a few minutes"
+	}
+	return check
+}
+
+// checkSandboxWorker calls the worker health endpoint of a running sandbox,
+// which is what ssh, upload, download, and browser commands talk to
+func checkSandboxWorker(ctx context.Context) doctorCheck {
+	check := doctorCheck{Name: "Sandbox worker"}
+
+	teamSlug, err := getTeamSlug()
+	if err != nil {
+		check.Status = checkWarn
+		check.Detail = "skipped: not logged in"
+		return check
+	}
+	instances, err := api.NewClient().ListInstances(teamSlug, "", false)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = "failed to list sandboxes: " + err.Error()
+		check.Fix = "Check the Convex check above, then retry"
+		return check
+	}
+
+	var sample *api.Instance
+	for i := range instances {
+		if instances[i].Status == "running" && instances[i].WorkerURL != "" {
+			sample = &instances[i]
+			break
+		}
+	}
+	if sample == nil {
+		check.Status = checkOK
+		check.Detail = "skipped: no running sandboxes to test"
+		return check
+	}
+
+	check = checkReachable(ctx, "Sandbox worker", strings.TrimRight(sample.WorkerURL, "/")+"/health",
+		fmt.Sprintf("Run 'cloudrouter logs %s --service worker'", sample.ID))
+	if check.Status == checkOK {
+		check.Detail = fmt.Sprintf("%s: %s", sample.ID, check.Detail)
+	}
+	return check
+}
+
+// checkTool looks for an external program and reports the first line of its
+// version output
+func checkTool(ctx context.Context, name, neededFor string, versionArgs ...string) doctorCheck {
+	check := doctorCheck{Name: name}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("not found on PATH (needed for %s)", neededFor)
+		check.Fix = toolInstallHint(name)
+		return check
+	}
+
+	// ssh -V prints to stderr
+	out, _ := exec.CommandContext(ctx, path, versionArgs...).CombinedOutput()
+	versionLine := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if versionLine == "" {
+		versionLine = "version unknown"
+	}
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s (%s)", versionLine, path)
+	return check
+}
+
+func toolInstallHint(name string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return fmt.Sprintf("Run 'brew install %s'", name)
+	case "windows":
+		if name == "ssh" {
+			return "Install the OpenSSH client from Settings > Optional features"
+		}
+		return fmt.Sprintf("Install %s via WSL, MSYS2, or cwRsync", name)
+	default:
+		if name == "ssh" {
+			return "Install the OpenSSH client (e.g. 'sudo apt install openssh-client')"
+		}
+		return fmt.Sprintf("Install %s with your package manager (e.g. 'sudo apt install %s')", name, name)
+	}
+}
+
+func printDoctorCheck(check doctorCheck) {
+	symbol := "✓"
+	switch check.Status {
+	case checkWarn:
+		symbol = "!"
+	case checkFail:
+		symbol = "✗"
+	}
+	fmt.Printf("%s %-18s %s\n", symbol, check.Name, check.Detail)
+	if check.Fix != "" {
+		fmt.Printf("  %-18s → %s\n", "", check.Fix)
+	}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose connectivity and configuration problems",
+	Long: `Check that cloudrouter can authenticate, reach its services and sandbox
+providers, and find the tools it depends on, with a suggested fix for each
+problem found.
+
+Checks:
+  - Version, and whether a newer release is available
+  - Credential store (Keychain, Secret Service, Credential Manager, or file)
+  - Authentication (forces a token refresh)
+  - Reachability of the cloudrouter API, Stack Auth, E2B, and Modal
+  - The worker of a running sandbox, if there is one
+  - ssh and rsync availability
+
+Include the output when reporting a problem.
+
+Examples:
+  cloudrouter doctor
+  cloudrouter doctor --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		cfg := auth.GetConfig()
+		var checks []doctorCheck
+		run := func(check doctorCheck) {
+			checks = append(checks, check)
+			if !doctorFlagJSON {
+				printDoctorCheck(check)
+			}
+		}
+
+		run(checkVersion())
+		run(checkReachable(ctx, "cloudrouter API", cfg.ConvexSiteURL, "Override with CONVEX_SITE_URL"))
+		run(checkReachable(ctx, "Stack Auth", cfg.StackAuthURL, "Override with AUTH_API_URL"))
+		run(checkReachable(ctx, "E2B", e2bAPIURL, ""))
+		run(checkReachable(ctx, "Modal", modalAPIURL, ""))
+		credentials := checkCredentialStore()
+		run(credentials)
+		if credentials.Status != checkFail {
+			authCheck := checkAuthentication()
+			run(authCheck)
+			if authCheck.Status == checkOK {
+				run(checkSandboxWorker(ctx))
+			}
+		}
+		run(checkTool(ctx, "ssh", "cloudrouter ssh", "-V"))
+		run(checkTool(ctx, "rsync", "cloudrouter upload, download, and watch", "--version"))
+
+		failed, warned := 0, 0
+		for _, check := range checks {
+			switch check.Status {
+			case checkFail:
+				failed++
+			case checkWarn:
+				warned++
+			}
+		}
+
+		if doctorFlagJSON {
+			data, err := json.MarshalIndent(checks, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		} else {
+			fmt.Println()
+			if failed == 0 && warned == 0 {
+				fmt.Println("✓ No problems found")
+			} else {
+				fmt.Printf("%d problem(s), %d warning(s)\n", failed, warned)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFlagJSON, "json", false, "Output the checks as JSON")
+}
@@ -2,17 +2,302 @@ package cli
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
 	downloadFlagRemotePath string
+	downloadFlagParallel   int
+	downloadFlagRetries    int
+	downloadFlagNoProgress bool
 )
 
+// downloadPartialDir holds partially transferred files between attempts, so
+// an interrupted download resumes where it stopped instead of starting over.
+// It's relative to each destination directory.
+const downloadPartialDir = ".cloudrouter-partial"
+
+// remoteTree summarizes what a download will transfer
+type remoteTree struct {
+	files   int64
+	bytes   int64
+	entries []string // top-level entries, minus default excludes
+}
+
+// scanRemoteTree lists the remote directory's top-level entries and totals
+// its file count and size, skipping the same paths the download excludes
+func scanRemoteTree(client *api.Client, teamSlug, id, remotePath string) (*remoteTree, error) {
+	var prune []string
+	for _, ex := range defaultExcludes {
+		if !strings.Contains(ex, "/") {
+			prune = append(prune, "-name "+shellQuote(ex))
+		}
+	}
+	command := fmt.Sprintf(
+		"cd %s && ls -A && echo __CLOUDROUTER_TOTALS__ && "+
+			"find . \\( %s \\) -prune -o -type f -printf '%%s\\n' | awk '{n++; s+=$1} END {print n+0, s+0}'",
+		shellQuote(remotePath), strings.Join(prune, " -o "),
+	)
+	resp, err := client.Exec(teamSlug, id, command, 120)
+	if err != nil {
+		return nil, err
+	}
+	if resp.ExitCode != 0 {
+		return nil, fmt.Errorf("%s", strings.TrimSpace(resp.Stderr))
+	}
+
+	listing, totals, ok := strings.Cut(resp.Stdout, "__CLOUDROUTER_TOTALS__\n")
+	if !ok {
+		return nil, fmt.Errorf("unexpected output: %q", resp.Stdout)
+	}
+	tree := &remoteTree{}
+	if _, err := fmt.Sscan(totals, &tree.files, &tree.bytes); err != nil {
+		return nil, fmt.Errorf("unexpected totals %q", strings.TrimSpace(totals))
+	}
+	for _, name := range strings.Split(listing, "\n") {
+		if name != "" && !shouldExcludeEntry(name) {
+			tree.entries = append(tree.entries, name)
+		}
+	}
+	return tree, nil
+}
+
+// localTreeSize totals the files under dir, including partial files
+func localTreeSize(dir string) (files, bytes int64) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			bytes += info.Size()
+			if filepath.Base(filepath.Dir(path)) != downloadPartialDir {
+				files++
+			}
+		}
+		return nil
+	})
+	return files, bytes
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// downloadProgress redraws a one-line progress bar on stderr by polling the
+// size of the local destination, which works with any rsync version and any
+// number of parallel streams
+type downloadProgress struct {
+	localPath  string
+	total      *remoteTree
+	startBytes int64
+	started    time.Time
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func startDownloadProgress(localPath string, total *remoteTree) *downloadProgress {
+	p := &downloadProgress{
+		localPath: localPath,
+		total:     total,
+		started:   time.Now(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	_, p.startBytes = localTreeSize(localPath)
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+				p.render()
+			}
+		}
+	}()
+	return p
+}
+
+func (p *downloadProgress) render() {
+	files, bytes := localTreeSize(p.localPath)
+	elapsed := time.Since(p.started).Seconds()
+	rate := float64(bytes-p.startBytes) / elapsed
+
+	line := formatBytes(bytes)
+	if p.total != nil && p.total.bytes > 0 {
+		// Local files that already existed can push this past the total
+		done := min(bytes, p.total.bytes)
+		pct := float64(done) / float64(p.total.bytes)
+		const width = 24
+		filled := int(pct * width)
+		line = fmt.Sprintf("[%s%s] %3.0f%% %s/%s  %d/%d files",
+			strings.Repeat("=", filled), strings.Repeat(" ", width-filled), pct*100,
+			formatBytes(done), formatBytes(p.total.bytes), min(files, p.total.files), p.total.files)
+		if rate > 0 {
+			eta := time.Duration(float64(p.total.bytes-done)/rate) * time.Second
+			line += fmt.Sprintf("  %s/s  ETA %s", formatBytes(int64(rate)), eta.Round(time.Second))
+		}
+	} else if rate > 0 {
+		line += fmt.Sprintf("  %s/s", formatBytes(int64(rate)))
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K  %s", line)
+}
+
+func (p *downloadProgress) Stop() {
+	close(p.stop)
+	<-p.done
+}
+
+// buildRsyncDownloadChunkArgs builds rsync arguments for one download stream.
+// items limits the stream to those top-level entries; nil downloads all.
+func buildRsyncDownloadChunkArgs(items []string) []string {
+	args := append(buildRsyncDownloadArgs(), "--partial-dir="+downloadPartialDir)
+	for _, item := range items {
+		// Entries may be files or directories
+		args = append(args, "--include", "/"+item, "--include", "/"+item+"/***")
+	}
+	if items != nil {
+		args = append(args, "--exclude", "*")
+	}
+	return args
+}
+
+// runDownloadChunk runs one rsync stream, retrying with backoff when it
+// fails; partial files carry over between attempts
+func runDownloadChunk(workerURL, token, remotePath, localPath string, items []string, retries int) (*rsyncStats, error) {
+	wsURL := toWebSocketURL(workerURL, token)
+	remoteSpec := fmt.Sprintf("%s@e2b-sandbox:%s/", token, strings.TrimSuffix(remotePath, "/"))
+	localDest := strings.TrimSuffix(localPath, "/") + "/"
+
+	backoff := 2 * time.Second
+	for attempt := 0; ; attempt++ {
+		sshCmd, cleanup, err := buildSSHProxyCommand(wsURL)
+		if err != nil {
+			return nil, err
+		}
+		rsyncArgs := append(buildRsyncDownloadChunkArgs(items), "-e", sshCmd, remoteSpec, localDest)
+		stats, err := execRsync(rsyncArgs)
+		cleanup()
+		if err == nil || attempt >= retries {
+			return stats, err
+		}
+		clearLine := ""
+		if output.Color(os.Stderr) {
+			clearLine = "\r\033[K"
+		}
+		fmt.Fprintf(os.Stderr, "%sTransfer interrupted (%v), resuming in %s...\n", clearLine, strings.TrimSpace(err.Error()), backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// runDownload downloads remotePath into localPath with parallel rsync
+// streams, resumable partial files, and a progress bar on terminals
+func runDownload(client *api.Client, teamSlug, sandboxID, workerURL, token, remotePath, localPath string) error {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return fmt.Errorf("rsync not found. Install with: brew install rsync (macOS) or apt install rsync (Linux)")
+	}
+
+	tree, err := scanRemoteTree(client, teamSlug, sandboxID, remotePath)
+	if err != nil {
+		// Still downloadable, just without totals or parallel streams
+		fmt.Fprintf(os.Stderr, "Warning: could not scan %s: %v\n", remotePath, err)
+	} else if len(tree.entries) == 0 {
+		fmt.Println("No files to download")
+		return nil
+	}
+
+	parallelism := 1
+	if tree != nil {
+		parallelism = downloadFlagParallel
+		if parallelism <= 0 {
+			parallelism = autoParallelism(int(tree.files))
+		}
+		parallelism = max(1, min(parallelism, len(tree.entries), maxParallelism))
+		output.Infof("Found %d files (%s)\n", tree.files, formatBytes(tree.bytes))
+	}
+
+	var progress *downloadProgress
+	// The progress bar redraws its line with escape sequences, so it needs a
+	// terminal that allows them
+	if !downloadFlagNoProgress && !output.Quiet() && output.Color(os.Stderr) {
+		progress = startDownloadProgress(localPath, tree)
+	}
+
+	chunks := [][]string{nil}
+	if parallelism > 1 {
+		chunks = splitEntries(tree.entries, parallelism)
+	}
+
+	startTime := time.Now()
+	var syncedFiles, totalBytes atomic.Int64
+	var wg sync.WaitGroup
+	errs := make([]error, len(chunks))
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, items []string) {
+			defer wg.Done()
+			stats, err := runDownloadChunk(workerURL, token, remotePath, localPath, items, downloadFlagRetries)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if stats != nil {
+				syncedFiles.Add(stats.files)
+				totalBytes.Add(stats.bytes)
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+	if progress != nil {
+		progress.Stop()
+	}
+
+	var failed int
+	for _, err := range errs {
+		if err != nil {
+			failed++
+			fmt.Printf("  Error: %v\n", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d download stream(s) failed; rerun the same command to resume", failed, len(chunks))
+	}
+
+	elapsed := time.Since(startTime)
+	if totalBytes.Load() > 0 {
+		speedMBps := float64(totalBytes.Load()) / elapsed.Seconds() / 1024 / 1024
+		fmt.Printf("✓ Downloaded %d files (%.1f MB) in %.1fs (%.1f MB/s)\n",
+			syncedFiles.Load(), float64(totalBytes.Load())/1024/1024, elapsed.Seconds(), speedMBps)
+	} else {
+		fmt.Println("✓ Download complete")
+	}
+	return nil
+}
+
 var downloadCmd = &cobra.Command{
 	Use:   "download <id> [local-path]",
 	Short: "Download files from sandbox",
@@ -21,10 +306,16 @@ var downloadCmd = &cobra.Command{
 The remote path defaults to /home/user/workspace if not specified.
 The local path defaults to the current directory if not specified.
 
+Large trees are split across parallel rsync streams (--parallel), and a
+progress bar shows files, bytes, and ETA. Interrupted transfers keep their
+partial files: dropped connections are retried automatically (--retries),
+and rerunning the same command resumes where it stopped.
+
 Examples:
   cloudrouter download cr_abc123                          # Download workspace to current dir
   cloudrouter download cr_abc123 ./output                 # Download workspace to ./output
-  cloudrouter download cr_abc123 . -r /home/user/app      # Download specific remote path`,
+  cloudrouter download cr_abc123 . -r /home/user/app      # Download specific remote path
+  cloudrouter download cr_abc123 ./dist -r /home/user/app/dist -p 8  # 8 parallel streams`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sandboxID := args[0]
@@ -33,6 +324,12 @@ Examples:
 			localPath = args[1]
 		}
 		remotePath := downloadFlagRemotePath
+		if downloadFlagParallel < 0 || downloadFlagParallel > maxParallelism {
+			return fmt.Errorf("--parallel must be between 1 and %d", maxParallelism)
+		}
+		if downloadFlagRetries < 0 {
+			return fmt.Errorf("--retries must not be negative")
+		}
 
 		// Get absolute path for local destination
 		absPath, err := filepath.Abs(localPath)
@@ -74,11 +371,14 @@ Examples:
 		rsyncFlagVerbose = false
 		rsyncFlagExclude = nil
 
-		fmt.Printf("Downloading %s:%s to %s...\n", sandboxID, remotePath, absPath)
-		return runRsyncDownload(inst.WorkerURL, token, remotePath, absPath)
+		output.Infof("Downloading %s:%s to %s...\n", sandboxID, remotePath, absPath)
+		return runDownload(client, teamSlug, sandboxID, inst.WorkerURL, token, remotePath, absPath)
 	},
 }
 
 func init() {
 	downloadCmd.Flags().StringVarP(&downloadFlagRemotePath, "remote-path", "r", "/home/user/workspace", "Remote path to download")
+	downloadCmd.Flags().IntVarP(&downloadFlagParallel, "parallel", "p", 0, fmt.Sprintf("Parallel rsync streams, 1-%d (default: based on file count)", maxParallelism))
+	downloadCmd.Flags().IntVar(&downloadFlagRetries, "retries", 3, "Times to resume a stream after a dropped connection")
+	downloadCmd.Flags().BoolVar(&downloadFlagNoProgress, "no-progress", false, "Hide the progress bar")
 }
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildRsyncDownloadChunkArgs(t *testing.T) {
+	base := append(buildRsyncDownloadArgs(), "--partial-dir="+downloadPartialDir)
+
+	if got := buildRsyncDownloadChunkArgs(nil); !reflect.DeepEqual(got, base) {
+		t.Errorf("buildRsyncDownloadChunkArgs(nil) = %v, want %v", got, base)
+	}
+
+	got := buildRsyncDownloadChunkArgs([]string{"dist", "README.md"})
+	want := append(base,
+		"--include", "/dist", "--include", "/dist/***",
+		"--include", "/README.md", "--include", "/README.md/***",
+		"--exclude", "*",
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildRsyncDownloadChunkArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n        int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+		{3 * 1024 * 1024 * 1024 / 2, "1.5 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.expected {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestAutoParallelism(t *testing.T) {
+	tests := []struct {
+		files    int
+		expected int
+	}{
+		{10, 1},
+		{100, 2},
+		{1000, 4},
+		{3000, 6},
+		{100000, maxParallelism},
+	}
+
+	for _, tt := range tests {
+		if got := autoParallelism(tt.files); got != tt.expected {
+			t.Errorf("autoParallelism(%d) = %d, want %d", tt.files, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+)
+
+// sandboxEnvFile holds variables passed to 'start --env/--env-file'. It's
+// sourced from the user's shell profile so SSH, PTY, and exec sessions see
+// the variables.
+const sandboxEnvFile = "/home/user/.cloudrouter/env.sh"
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseEnvFile parses a dotenv file: KEY=value lines with optional "export"
+// prefixes, # comments, and single- or double-quoted values.
+func parseEnvFile(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNum)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNum, key)
+		}
+		value, err := parseEnvValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// parseEnvValue unquotes a dotenv value. Single quotes are literal, double
+// quotes support \n, \t, \" and \\ escapes, and unquoted values end at " #".
+func parseEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '\'':
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			ch := raw[i]
+			if ch == '"' {
+				return b.String(), nil
+			}
+			if ch == '\\' && i+1 < len(raw) {
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+				continue
+			}
+			b.WriteByte(ch)
+		}
+		return "", fmt.Errorf("unterminated double quote")
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// collectStartEnv merges --env-file and --env values; --env wins. "--env KEY"
+// without a value copies KEY from the local environment.
+func collectStartEnv(envFile string, envFlags []string) (map[string]string, error) {
+	vars := map[string]string{}
+	if envFile != "" {
+		data, err := os.ReadFile(envFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file: %w", err)
+		}
+		fileVars, err := parseEnvFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid env file %s: %w", envFile, err)
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+	for _, entry := range envFlags {
+		key, value, hasValue := strings.Cut(entry, "=")
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid --env %q: expected KEY=VALUE", entry)
+		}
+		if !hasValue {
+			local, ok := os.LookupEnv(key)
+			if !ok {
+				return nil, fmt.Errorf("--env %s: not set in the local environment", key)
+			}
+			value = local
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
+
+// envScript renders variables as sorted, single-quoted export lines.
+func envScript(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("# Written by cloudrouter start --env/--env-file\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(vars[k]))
+	}
+	return b.String()
+}
+
+// writeSandboxEnv writes the variables to sandboxEnvFile and sources it from
+// .bashrc and .profile, so every later shell in the sandbox sees them. Exec
+// may run as root, so the file is handed back to the sandbox user.
+func writeSandboxEnv(client *api.Client, teamSlug, id string, vars map[string]string) error {
+	source := fmt.Sprintf("[ -f %[1]s ] && . %[1]s", sandboxEnvFile)
+	command := fmt.Sprintf(
+		"mkdir -p /home/user/.cloudrouter && printf '%%s' %s > %s && chmod 600 %s && "+
+			"{ chown -R user:user /home/user/.cloudrouter 2>/dev/null || true; } && "+
+			"for rc in /home/user/.bashrc /home/user/.profile; do "+
+			"grep -qsF %s \"$rc\" || echo %s >> \"$rc\"; done",
+		shellQuote(envScript(vars)), sandboxEnvFile, sandboxEnvFile,
+		shellQuote(sandboxEnvFile), shellQuote(source),
+	)
+	resp, err := client.Exec(teamSlug, id, command, 30)
+	if err != nil {
+		return err
+	}
+	if resp.ExitCode != 0 {
+		return fmt.Errorf("%s", strings.TrimSpace(resp.Stderr))
+	}
+	return nil
+}
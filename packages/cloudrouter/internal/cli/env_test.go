@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	data := []byte(`# database
+DATABASE_URL=postgres://localhost/app
+export DEBUG=1
+GREETING="hello \"world\"\nbye"
+LITERAL='$HOME stays'
+TRAILING=value # comment
+EMPTY=
+`)
+
+	got, err := parseEnvFile(data)
+	if err != nil {
+		t.Fatalf("parseEnvFile() error = %v", err)
+	}
+	want := map[string]string{
+		"DATABASE_URL": "postgres://localhost/app",
+		"DEBUG":        "1",
+		"GREETING":     "hello \"world\"\nbye",
+		"LITERAL":      "$HOME stays",
+		"TRAILING":     "value",
+		"EMPTY":        "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEnvFile() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"NOVALUE", "1BAD=x", "QUOTE=\"open"} {
+		if _, err := parseEnvFile([]byte(bad)); err == nil {
+			t.Errorf("parseEnvFile(%q) expected error", bad)
+		}
+	}
+}
+
+func TestCollectStartEnv(t *testing.T) {
+	t.Setenv("CLOUDROUTER_TEST_TOKEN", "local-secret")
+
+	got, err := collectStartEnv("", []string{"A=1", "A=2", "B=x=y", "CLOUDROUTER_TEST_TOKEN"})
+	if err != nil {
+		t.Fatalf("collectStartEnv() error = %v", err)
+	}
+	want := map[string]string{"A": "2", "B": "x=y", "CLOUDROUTER_TEST_TOKEN": "local-secret"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectStartEnv() = %v, want %v", got, want)
+	}
+
+	if _, err := collectStartEnv("", []string{"CLOUDROUTER_TEST_UNSET_VAR"}); err == nil {
+		t.Error("expected error for unset local variable")
+	}
+	if _, err := collectStartEnv("", []string{"=x"}); err == nil {
+		t.Error("expected error for empty key")
+	}
+}
+
+func TestEnvScript(t *testing.T) {
+	got := envScript(map[string]string{"B": "it's", "A": "$x"})
+	want := "# Written by cloudrouter start --env/--env-file\n" +
+		"export A='$x'\n" +
+		"export B='it'\\''s'\n"
+	if got != want {
+		t.Errorf("envScript() = %q, want %q", got, want)
+	}
+}
@@ -1,24 +1,43 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"strings"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+var execFlagStream bool
+
 func init() {
 	// Stop parsing flags after the first positional arg (the sandbox ID).
 	// This ensures "ssh <id> ls -la" works without quoting.
 	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().BoolVarP(&execFlagStream, "stream", "s", false, "Print output as it arrives (Ctrl+C stops the remote command)")
 }
 
 var execCmd = &cobra.Command{
-	Use:   "ssh <id> <command...>",
-	Short: "Run a command in a sandbox via SSH",
-	Args:  cobra.MinimumNArgs(2),
+	Use:   "ssh <id> [command...]",
+	Short: "Open a shell or run a command in a sandbox via SSH",
+	Long: `Open an interactive shell in a sandbox, or run a single command.
+
+Without a command, this starts a login shell over the worker's SSH tunnel and
+exits with the shell's exit code. With a command, it runs the command and
+prints its output.
+
+Examples:
+  cloudrouter ssh cr_abc123                  # Interactive shell
+  cloudrouter ssh cr_abc123 ls -la           # Run a command
+  cloudrouter ssh cr_abc123 "npm test"
+  cloudrouter ssh --stream cr_abc123 npm run build`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		teamSlug, err := getTeamSlug()
 		if err != nil {
@@ -26,7 +45,6 @@ var execCmd = &cobra.Command{
 		}
 
 		id := args[0]
-		command := strings.Join(args[1:], " ")
 
 		client := api.NewClient()
 		inst, err := client.GetInstance(teamSlug, id)
@@ -43,10 +61,26 @@ var execCmd = &cobra.Command{
 			return fmt.Errorf("failed to get auth token: %w", err)
 		}
 
+		if len(args) == 1 {
+			exitCode, err := runInteractiveSSH(inst.WorkerURL, token)
+			if err != nil {
+				return err
+			}
+			if exitCode != 0 {
+				return &ExitError{Code: exitCode}
+			}
+			return nil
+		}
+
+		command := strings.Join(args[1:], " ")
 		if flagVerbose {
 			fmt.Fprintf(os.Stderr, "[debug] SSH command: %s\n", command)
 		}
 
+		if execFlagStream {
+			return runStreamingExec(inst.WorkerURL, token, command)
+		}
+
 		stdout, stderr, exitCode, err := runSSHCommand(inst.WorkerURL, token, command)
 		if err != nil {
 			return err
@@ -64,3 +98,85 @@ var execCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// runInteractiveSSH attaches the local terminal to a login shell in the
+// sandbox and returns the shell's exit code. When stdin is a terminal it is
+// put in raw mode and a remote PTY is requested; ssh forwards window size
+// changes to it (sshpass relays them too). Otherwise the shell reads
+// commands from stdin.
+func runInteractiveSSH(workerURL, token string) (int, error) {
+	sshArgs, err := buildWorkerSSHArgs(workerURL, token)
+	if err != nil {
+		return -1, err
+	}
+
+	stdinFd := int(os.Stdin.Fd())
+	interactive := term.IsTerminal(stdinFd)
+	if interactive {
+		sshArgs = append([]string{"-tt"}, sshArgs...)
+	} else {
+		sshArgs = append([]string{"-T"}, sshArgs...)
+	}
+
+	cmd, cleanup, err := buildSSHCmd(sshArgs)
+	if err != nil {
+		return -1, err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if interactive {
+		oldState, err := term.MakeRaw(stdinFd)
+		if err != nil {
+			return -1, fmt.Errorf("failed to set raw mode: %w", err)
+		}
+		defer term.Restore(stdinFd, oldState)
+	}
+
+	// Leave Ctrl+C to the remote shell. Catching (not ignoring) the signal
+	// keeps the default behavior for ssh itself.
+	interruptCh := make(chan os.Signal, 1)
+	signal.Notify(interruptCh, os.Interrupt)
+	defer signal.Stop(interruptCh)
+
+	if err := cmd.Run(); err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return ee.ExitCode(), nil
+		}
+		return -1, fmt.Errorf("ssh failed: %w", err)
+	}
+	return 0, nil
+}
+
+// runStreamingExec runs command through the worker's streaming exec endpoint,
+// printing output as it arrives. Ctrl+C closes the stream, which kills the
+// remote command.
+func runStreamingExec(workerURL, token, command string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	exitCode, err := api.StreamWorkerExec(ctx, workerURL, token, command, func(event api.ExecEvent) {
+		switch event.Type {
+		case "stdout":
+			fmt.Fprint(os.Stdout, event.Data)
+		case "stderr":
+			fmt.Fprint(os.Stderr, event.Data)
+		case "error":
+			fmt.Fprintf(os.Stderr, "Error: %s\n", event.Message)
+		}
+	})
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("interrupted; remote command stopped")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run command: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("exit code: %d", exitCode)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exposeFlagList   bool
+	exposeFlagRemove bool
+)
+
+var exposeCmd = &cobra.Command{
+	Use:   "expose <id> [port]",
+	Short: "Share a sandbox port at a public URL",
+	Long: `Share a port in a sandbox at a public HTTPS URL, e.g. to send a web app
+running in the sandbox to someone for review. Anyone with the URL can reach
+the port while something listens on it.
+
+GPU sandboxes can only share ports set up when they were created.
+
+Examples:
+  cloudrouter expose cr_abc123 3000          # Print the public URL for port 3000
+  cloudrouter expose cr_abc123 --list        # List exposed ports
+  cloudrouter expose cr_abc123 3000 --rm     # Stop listing port 3000`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exposeFlagList && exposeFlagRemove {
+			return fmt.Errorf("--list and --rm can't be used together")
+		}
+		if !exposeFlagList && len(args) < 2 {
+			return fmt.Errorf("port is required (or pass --list)")
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		id := args[0]
+		client := api.NewClient()
+
+		if exposeFlagList {
+			inst, err := client.GetInstance(teamSlug, id)
+			if err != nil {
+				return fmt.Errorf("sandbox not found: %w", err)
+			}
+			if len(inst.ExposedPorts) == 0 {
+				fmt.Println("No exposed ports")
+				return nil
+			}
+			fmt.Printf("%-7s %s\n", "PORT", "URL")
+			for _, p := range inst.ExposedPorts {
+				fmt.Printf("%-7d %s\n", p.Port, p.URL)
+			}
+			return nil
+		}
+
+		port, err := strconv.Atoi(args[1])
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %q (must be 1-65535)", args[1])
+		}
+
+		if exposeFlagRemove {
+			if err := client.UnexposePort(teamSlug, id, port); err != nil {
+				return err
+			}
+			fmt.Printf("Removed: port %d\n", port)
+			return nil
+		}
+
+		exposed, err := client.ExposePort(teamSlug, id, port)
+		if err != nil {
+			return err
+		}
+		fmt.Println(exposed.URL)
+		return nil
+	},
+}
+
+func init() {
+	exposeCmd.Flags().BoolVarP(&exposeFlagList, "list", "l", false, "List exposed ports")
+	exposeCmd.Flags().BoolVar(&exposeFlagRemove, "rm", false, "Remove the port from the exposed list")
+}
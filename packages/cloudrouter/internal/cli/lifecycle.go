@@ -2,16 +2,31 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
 	"github.com/spf13/cobra"
 )
 
+var (
+	stopFlagAll        bool
+	stopFlagNameGlob   string
+	deleteFlagAll      bool
+	deleteFlagNameGlob string
+	deleteFlagYes      bool
+)
+
 var stopCmd = &cobra.Command{
-	Use:   "stop <id>",
-	Short: "Pause a sandbox (preserves state)",
-	Long:  "Pause a sandbox. The sandbox state is preserved and can be resumed later with 'resume'.",
-	Args:  cobra.ExactArgs(1),
+	Use:   "stop <id>... | --all | --name-glob <pattern>",
+	Short: "Pause sandboxes (preserves state)",
+	Long: `Pause one or more sandboxes. The sandbox state is preserved and can be
+resumed later with 'resume'. Multiple sandboxes are paused concurrently.
+
+Examples:
+  cloudrouter stop cr_abc123                # Pause one sandbox
+  cloudrouter stop cr_abc123 cr_def456      # Pause several sandboxes
+  cloudrouter stop --name-glob 'e2e-*'      # Pause running sandboxes named e2e-*
+  cloudrouter stop --all                    # Pause all running sandboxes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		teamSlug, err := getTeamSlug()
 		if err != nil {
@@ -19,11 +34,19 @@ var stopCmd = &cobra.Command{
 		}
 
 		client := api.NewClient()
-		if err := client.PauseInstance(teamSlug, args[0]); err != nil {
+		isRunning := func(inst api.Instance) bool { return inst.Status == "running" }
+		ids, err := selectSandboxes(client, teamSlug, args, stopFlagAll, stopFlagNameGlob, false, isRunning)
+		if err != nil {
 			return err
 		}
-		fmt.Printf("Paused: %s\n", args[0])
-		return nil
+		if len(ids) == 0 {
+			fmt.Println("No matching sandboxes")
+			return nil
+		}
+
+		return runBulk(ids, "Paused", func(id string) error {
+			return client.PauseInstance(teamSlug, id)
+		})
 	},
 }
 
@@ -68,11 +91,19 @@ var resumeCmd = &cobra.Command{
 }
 
 var deleteCmd = &cobra.Command{
-	Use:     "delete <id>",
+	Use:     "delete <id>... | --all | --name-glob <pattern>",
 	Aliases: []string{"rm", "kill"},
-	Short:   "Delete a sandbox (terminates and removes)",
-	Long:    "Permanently delete a sandbox. This terminates the sandbox and removes all records.",
-	Args:    cobra.ExactArgs(1),
+	Short:   "Delete sandboxes (terminates and removes)",
+	Long: `Permanently delete one or more sandboxes. This terminates the sandboxes
+and removes all records. Multiple sandboxes are deleted concurrently.
+
+--all and --name-glob ask for confirmation unless --yes is given.
+
+Examples:
+  cloudrouter delete cr_abc123                    # Delete one sandbox
+  cloudrouter delete cr_abc123 cr_def456          # Delete several sandboxes
+  cloudrouter delete --name-glob 'e2e-*' --yes    # Delete sandboxes named e2e-* (CI)
+  cloudrouter delete --all                        # Delete every sandbox`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		teamSlug, err := getTeamSlug()
 		if err != nil {
@@ -80,11 +111,30 @@ var deleteCmd = &cobra.Command{
 		}
 
 		client := api.NewClient()
-		if err := client.DeleteInstance(teamSlug, args[0]); err != nil {
+		ids, err := selectSandboxes(client, teamSlug, args, deleteFlagAll, deleteFlagNameGlob, true, nil)
+		if err != nil {
 			return err
 		}
-		fmt.Printf("Deleted: %s\n", args[0])
-		return nil
+		if len(ids) == 0 {
+			fmt.Println("No matching sandboxes")
+			return nil
+		}
+
+		if len(args) == 0 && !deleteFlagYes {
+			fmt.Println(strings.Join(ids, "\n"))
+			ok, err := confirm(fmt.Sprintf("\nDelete %d sandbox(es)?", len(ids)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Aborted")
+				return nil
+			}
+		}
+
+		return runBulk(ids, "Deleted", func(id string) error {
+			return client.DeleteInstance(teamSlug, id)
+		})
 	},
 }
 
@@ -112,5 +162,12 @@ var extendCmd = &cobra.Command{
 }
 
 func init() {
+	stopCmd.Flags().BoolVar(&stopFlagAll, "all", false, "Pause all running sandboxes")
+	stopCmd.Flags().StringVar(&stopFlagNameGlob, "name-glob", "", "Pause running sandboxes whose name matches a glob (e.g. 'e2e-*')")
+
+	deleteCmd.Flags().BoolVar(&deleteFlagAll, "all", false, "Delete all sandboxes")
+	deleteCmd.Flags().StringVar(&deleteFlagNameGlob, "name-glob", "", "Delete sandboxes whose name matches a glob (e.g. 'e2e-*')")
+	deleteCmd.Flags().BoolVarP(&deleteFlagYes, "yes", "y", false, "Skip the confirmation prompt for --all and --name-glob")
+
 	extendCmd.Flags().IntVar(&extendFlagTimeout, "seconds", 3600, "Timeout in seconds (default: 1 hour)")
 }
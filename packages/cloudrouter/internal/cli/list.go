@@ -1,7 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
 	"github.com/spf13/cobra"
@@ -9,56 +15,219 @@ import (
 
 var (
 	listFlagProvider      string
+	listFlagTags          []string
+	listFlagStatus        string
+	listFlagName          string
+	listFlagSort          string
+	listFlagColumns       string
+	listFlagJSON          bool
 	templatesFlagProvider string
 )
 
+const defaultListColumns = "id,name,status,provider,type,ttl,tags"
+
+// listColumns maps column names accepted by --columns to their header and
+// how to render them for an instance.
+var listColumns = map[string]struct {
+	header string
+	value  func(inst api.Instance, now time.Time) string
+}{
+	"id":       {"ID", func(inst api.Instance, _ time.Time) string { return inst.ID }},
+	"name":     {"NAME", func(inst api.Instance, _ time.Time) string { return orDash(inst.Name) }},
+	"status":   {"STATUS", func(inst api.Instance, _ time.Time) string { return inst.Status }},
+	"provider": {"PROVIDER", func(inst api.Instance, _ time.Time) string { return orDash(inst.Provider) }},
+	"type":     {"TYPE", func(inst api.Instance, _ time.Time) string { return instanceTypeLabel(inst) }},
+	"gpu":      {"GPU", func(inst api.Instance, _ time.Time) string { return orDash(inst.GPU) }},
+	"ttl":      {"TTL", formatTTL},
+	"created":  {"CREATED", formatCreated},
+	"tags":     {"TAGS", func(inst api.Instance, _ time.Time) string { return orDash(formatTags(inst.Tags)) }},
+}
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List sandboxes",
-	Long: `List sandboxes. Optionally filter by type.
+	Long: `List sandboxes, with optional filters, sorting, and column selection.
+
+Stopped sandboxes are only listed with --status stopped.
+
+Columns (--columns): id, name, status, provider, type, gpu, ttl, created, tags
+TTL is the time left before the sandbox stops on its own.
 
 Examples:
-  cloudrouter list                        # List all sandboxes
-  cloudrouter list --provider e2b         # List only Docker sandboxes
-  cloudrouter list --provider modal       # List only GPU sandboxes`,
+  cloudrouter list                             # List all sandboxes
+  cloudrouter list --provider e2b              # List only Docker sandboxes
+  cloudrouter list --provider modal            # List only GPU sandboxes
+  cloudrouter list --tag project=api           # List sandboxes tagged project=api
+  cloudrouter list --status paused             # List paused sandboxes
+  cloudrouter list --name api --sort name      # Filter by name, sort by name
+  cloudrouter list --columns id,status,ttl     # Choose columns
+  cloudrouter list --json | jq '.[].id'        # Script against the full records`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		tagFilter, err := parseTags(listFlagTags)
+		if err != nil {
+			return err
+		}
+		switch listFlagStatus {
+		case "", "running", "paused", "stopped":
+		default:
+			return fmt.Errorf("invalid status %q, valid statuses: running, paused, stopped", listFlagStatus)
+		}
+		columns, err := parseListColumns(listFlagColumns)
+		if err != nil {
+			return err
+		}
+
 		teamSlug, err := getTeamSlug()
 		if err != nil {
 			return fmt.Errorf("failed to get team: %w", err)
 		}
 
 		client := api.NewClient()
-		instances, err := client.ListInstances(teamSlug, listFlagProvider)
+		instances, err := client.ListInstances(teamSlug, listFlagProvider, listFlagStatus == "stopped")
 		if err != nil {
 			return err
 		}
 
+		instances = filterInstances(instances, listFlagStatus, listFlagName, tagFilter)
+		if err := sortInstances(instances, listFlagSort); err != nil {
+			return err
+		}
+
+		if listFlagJSON {
+			if instances == nil {
+				instances = []api.Instance{}
+			}
+			out, err := json.MarshalIndent(instances, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
 		if len(instances) == 0 {
 			fmt.Println("No sandboxes found")
 			return nil
 		}
 
-		fmt.Println("Sandboxes:")
+		now := time.Now()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		headers := make([]string, len(columns))
+		for i, col := range columns {
+			headers[i] = listColumns[col].header
+		}
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
 		for _, inst := range instances {
-			name := inst.Name
-			if name == "" {
-				name = "(unnamed)"
+			values := make([]string, len(columns))
+			for i, col := range columns {
+				values[i] = listColumns[col].value(inst, now)
 			}
-			typeLabel := "Docker"
-			if inst.Provider == "modal" {
-				if inst.GPU != "" {
-					typeLabel = fmt.Sprintf("GPU (%s)", inst.GPU)
-				} else {
-					typeLabel = "GPU"
-				}
-			}
-			fmt.Printf("  %s - %s (%s) [%s]\n", inst.ID, inst.Status, name, typeLabel)
+			fmt.Fprintln(w, strings.Join(values, "\t"))
 		}
-		return nil
+		return w.Flush()
 	},
 }
 
+// parseListColumns parses a comma-separated --columns value.
+func parseListColumns(spec string) ([]string, error) {
+	var columns []string
+	for _, col := range strings.Split(spec, ",") {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if col == "" {
+			continue
+		}
+		if _, ok := listColumns[col]; !ok {
+			return nil, fmt.Errorf("unknown column %q, valid columns: id, name, status, provider, type, gpu, ttl, created, tags", col)
+		}
+		columns = append(columns, col)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("--columns must name at least one column")
+	}
+	return columns, nil
+}
+
+// filterInstances keeps instances matching the status, name substring
+// (case-insensitive), and tags. Empty filters match everything.
+func filterInstances(instances []api.Instance, status, name string, tags map[string]string) []api.Instance {
+	name = strings.ToLower(name)
+	filtered := instances[:0]
+	for _, inst := range instances {
+		if status != "" && inst.Status != status {
+			continue
+		}
+		if name != "" && !strings.Contains(strings.ToLower(inst.Name), name) {
+			continue
+		}
+		if !matchesTags(inst.Tags, tags) {
+			continue
+		}
+		filtered = append(filtered, inst)
+	}
+	return filtered
+}
+
+// sortInstances sorts by creation time (newest first), name, or status.
+func sortInstances(instances []api.Instance, by string) error {
+	var less func(a, b api.Instance) bool
+	switch by {
+	case "", "created":
+		less = func(a, b api.Instance) bool { return a.CreatedAt > b.CreatedAt }
+	case "name":
+		less = func(a, b api.Instance) bool { return a.Name < b.Name }
+	case "status":
+		less = func(a, b api.Instance) bool { return a.Status < b.Status }
+	default:
+		return fmt.Errorf("invalid sort %q, valid sorts: created, name, status", by)
+	}
+	sort.SliceStable(instances, func(i, j int) bool { return less(instances[i], instances[j]) })
+	return nil
+}
+
+func instanceTypeLabel(inst api.Instance) string {
+	if inst.Provider != "modal" {
+		return "Docker"
+	}
+	if inst.GPU != "" {
+		return fmt.Sprintf("GPU (%s)", inst.GPU)
+	}
+	return "GPU"
+}
+
+// formatTTL renders the time left before a running sandbox stops on its own.
+func formatTTL(inst api.Instance, now time.Time) string {
+	if inst.ExpiresAt == 0 || inst.Status != "running" {
+		return "-"
+	}
+	left := time.UnixMilli(inst.ExpiresAt).Sub(now)
+	if left <= 0 {
+		return "expired"
+	}
+	left = left.Round(time.Minute)
+	if left < time.Minute {
+		return "<1m"
+	}
+	if left < time.Hour {
+		return fmt.Sprintf("%dm", int(left.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(left.Hours()), int(left.Minutes())%60)
+}
+
+func formatCreated(inst api.Instance, _ time.Time) string {
+	if inst.CreatedAt == 0 {
+		return "-"
+	}
+	return time.UnixMilli(inst.CreatedAt).Format("2006-01-02 15:04")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 var templatesCmd = &cobra.Command{
 	Use:   "templates",
 	Short: "List available templates",
@@ -88,7 +257,9 @@ Examples:
 		fmt.Println("Templates:")
 		for _, t := range templates {
 			typeLabel := "Docker"
-			if t.Provider == "modal" {
+			if t.Custom {
+				typeLabel = fmt.Sprintf("Custom, %s", t.Status)
+			} else if t.Provider == "modal" {
 				if t.GPU != "" {
 					typeLabel = fmt.Sprintf("GPU (%s)", t.GPU)
 				} else {
@@ -103,5 +274,11 @@ Examples:
 
 func init() {
 	listCmd.Flags().StringVarP(&listFlagProvider, "provider", "p", "", "Filter by provider: e2b, modal")
+	listCmd.Flags().StringArrayVar(&listFlagTags, "tag", nil, "Filter by tag key=value (can be repeated)")
+	listCmd.Flags().StringVar(&listFlagStatus, "status", "", "Filter by status: running, paused, stopped")
+	listCmd.Flags().StringVar(&listFlagName, "name", "", "Filter by name (case-insensitive substring)")
+	listCmd.Flags().StringVar(&listFlagSort, "sort", "created", "Sort by: created, name, status")
+	listCmd.Flags().StringVar(&listFlagColumns, "columns", defaultListColumns, "Comma-separated columns to show")
+	listCmd.Flags().BoolVar(&listFlagJSON, "json", false, "Output the full sandbox records as JSON")
 	templatesCmd.Flags().StringVarP(&templatesFlagProvider, "provider", "p", "", "Filter by provider: e2b, modal")
 }
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+)
+
+func TestFormatTTL(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	at := func(d time.Duration) int64 { return now.Add(d).UnixMilli() }
+
+	tests := []struct {
+		inst     api.Instance
+		expected string
+	}{
+		{api.Instance{Status: "running"}, "-"},
+		{api.Instance{Status: "paused", ExpiresAt: at(time.Hour)}, "-"},
+		{api.Instance{Status: "running", ExpiresAt: at(-time.Second)}, "expired"},
+		{api.Instance{Status: "running", ExpiresAt: at(20 * time.Second)}, "<1m"},
+		{api.Instance{Status: "running", ExpiresAt: at(9*time.Minute + 50*time.Second)}, "10m"},
+		{api.Instance{Status: "running", ExpiresAt: at(2*time.Hour + 5*time.Minute)}, "2h05m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTTL(tt.inst, now); got != tt.expected {
+			t.Errorf("formatTTL(%+v) = %q, want %q", tt.inst, got, tt.expected)
+		}
+	}
+}
+
+func TestFilterAndSortInstances(t *testing.T) {
+	instances := []api.Instance{
+		{ID: "cr_1", Name: "api-main", Status: "running", CreatedAt: 1, Tags: map[string]string{"team": "core"}},
+		{ID: "cr_2", Name: "web", Status: "paused", CreatedAt: 3},
+		{ID: "cr_3", Name: "API-experiment", Status: "running", CreatedAt: 2},
+	}
+
+	got := filterInstances(append([]api.Instance(nil), instances...), "running", "api", nil)
+	if err := sortInstances(got, "created"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "cr_3" || got[1].ID != "cr_1" {
+		t.Errorf("filter running+api sorted by created = %v", got)
+	}
+
+	got = filterInstances(append([]api.Instance(nil), instances...), "", "", map[string]string{"team": "core"})
+	if len(got) != 1 || got[0].ID != "cr_1" {
+		t.Errorf("filter by tag = %v", got)
+	}
+
+	got = append([]api.Instance(nil), instances...)
+	if err := sortInstances(got, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if got[0].ID != "cr_3" || got[2].ID != "cr_2" {
+		t.Errorf("sort by name = %v", got)
+	}
+
+	if err := sortInstances(got, "size"); err == nil {
+		t.Error("sortInstances(size) expected error")
+	}
+}
+
+func TestParseListColumns(t *testing.T) {
+	columns, err := parseListColumns("id, TTL,,provider")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(columns) != 3 || columns[0] != "id" || columns[1] != "ttl" || columns[2] != "provider" {
+		t.Errorf("parseListColumns() = %v", columns)
+	}
+
+	for _, spec := range []string{"", "id,size"} {
+		if _, err := parseListColumns(spec); err == nil {
+			t.Errorf("parseListColumns(%q) expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// userLogDir is where processes started in a sandbox can write logs for
+// `logs --service user`
+const userLogDir = "/home/user/logs"
+
+// serviceLogs maps each --service name to its log files in the sandbox. The
+// start scripts write service output to these paths.
+var serviceLogs = map[string][]string{
+	"startup": {"/tmp/start-services.log"},
+	"vscode":  {"/tmp/cmux-code.log"},
+	"worker":  {"/tmp/worker-daemon.log"},
+	"jupyter": {"/tmp/jupyter.log"},
+	"vnc":     {"/tmp/tigervnc.log"},
+	"chrome":  {"/tmp/chrome.log"},
+	"docker":  {"/tmp/dockerd.log"},
+	"user":    {userLogDir + "/*.log"},
+}
+
+var (
+	logsFlagFollow  bool
+	logsFlagService string
+	logsFlagLines   int
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <id>",
+	Short: "Show service logs from a sandbox",
+	Long: `Show the logs of services running in a sandbox, e.g. to debug a sandbox
+whose VS Code or worker won't come up.
+
+Without --service, all service logs are shown. The user service shows
+*.log files in /home/user/logs, so redirect background processes there to
+follow them too:
+
+  npm run dev > /home/user/logs/dev.log 2>&1 &
+
+Services: startup, vscode, worker, jupyter, vnc, chrome, docker, user
+
+Examples:
+  cloudrouter logs cr_abc123                     # Last 100 lines of every service
+  cloudrouter logs cr_abc123 -s worker -f        # Follow the worker daemon
+  cloudrouter logs cr_abc123 -s user -n 500      # Your own process logs`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if logsFlagLines < 0 {
+			return fmt.Errorf("--lines must not be negative")
+		}
+
+		var files []string
+		if logsFlagService == "" {
+			for _, name := range serviceNames() {
+				files = append(files, serviceLogs[name]...)
+			}
+		} else {
+			var ok bool
+			files, ok = serviceLogs[logsFlagService]
+			if !ok {
+				return fmt.Errorf("unknown service %q (valid: %s)", logsFlagService, strings.Join(serviceNames(), ", "))
+			}
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		id := args[0]
+		client := api.NewClient()
+		inst, err := client.GetInstance(teamSlug, id)
+		if err != nil {
+			return fmt.Errorf("sandbox not found: %w", err)
+		}
+		if inst.WorkerURL == "" {
+			return fmt.Errorf("worker URL not available — sandbox may not be running")
+		}
+
+		token, err := client.GetAuthToken(teamSlug, id)
+		if err != nil {
+			return fmt.Errorf("failed to get auth token: %w", err)
+		}
+
+		command := buildTailCommand(files, logsFlagLines, logsFlagFollow)
+		if flagVerbose {
+			fmt.Fprintf(os.Stderr, "[debug] SSH command: %s\n", command)
+		}
+
+		exitCode, err := runSSHStream(inst.WorkerURL, token, command, os.Stdout, os.Stderr)
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			return &ExitError{Code: exitCode}
+		}
+		return nil
+	},
+}
+
+// serviceNames returns the --service names in sorted order
+func serviceNames() []string {
+	names := make([]string, 0, len(serviceLogs))
+	for name := range serviceLogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildTailCommand returns a shell command that tails the files that exist
+// among paths. Paths may contain globs; they are fixed strings, not user
+// input, so they are left unquoted for the shell to expand.
+func buildTailCommand(paths []string, lines int, follow bool) string {
+	tailArgs := fmt.Sprintf("-n %d", lines)
+	if follow {
+		tailArgs += " -F"
+	}
+	return fmt.Sprintf(`files=""; for f in %s; do [ -f "$f" ] && files="$files $f"; done; `+
+		`[ -n "$files" ] || { echo "no log files found" >&2; exit 1; }; exec tail %s $files`,
+		strings.Join(paths, " "), tailArgs)
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFlagFollow, "follow", "f", false, "Keep streaming new log lines")
+	logsCmd.Flags().StringVarP(&logsFlagService, "service", "s", "", "Only show this service's logs")
+	logsCmd.Flags().IntVarP(&logsFlagLines, "lines", "n", 100, "Number of lines to show from the end of each log")
+}
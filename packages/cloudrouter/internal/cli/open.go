@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -86,7 +87,7 @@ Examples:
 			return err
 		}
 
-		fmt.Println("Opening VS Code...")
+		output.Infoln("Opening VS Code...")
 		return openBrowser(authURL)
 	},
 }
@@ -126,7 +127,7 @@ Examples:
 			return err
 		}
 
-		fmt.Println("Opening VNC...")
+		output.Infoln("Opening VNC...")
 		return openBrowser(authURL)
 	},
 }
@@ -170,7 +171,7 @@ Examples:
 		query.Set("token", token)
 		parsed.RawQuery = query.Encode()
 
-		fmt.Println("Opening Jupyter Lab...")
+		output.Infoln("Opening Jupyter Lab...")
 		return openBrowser(parsed.String())
 	},
 }
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	pruneFlagOlderThan   time.Duration
+	pruneFlagStoppedOnly bool
+	pruneFlagDryRun      bool
+	pruneFlagYes         bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete stale sandboxes",
+	Long: `Delete sandboxes created longer ago than --older-than (default 48h), so
+forgotten sandboxes stop costing money.
+
+The sandboxes to delete are listed first, and you are asked to confirm
+unless --yes is given. Use --dry-run to only list them.
+
+Examples:
+  cloudrouter prune --dry-run                 # Show what would be deleted
+  cloudrouter prune                           # Delete sandboxes older than 48h
+  cloudrouter prune --older-than 12h --yes    # No confirmation prompt
+  cloudrouter prune --stopped-only            # Only delete stopped sandboxes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneFlagOlderThan < 0 {
+			return fmt.Errorf("--older-than can't be negative")
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		instances, err := client.ListInstances(teamSlug, "", true)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		candidates := pruneCandidates(instances, now, pruneFlagOlderThan, pruneFlagStoppedOnly)
+		if len(candidates) == 0 {
+			fmt.Println("No sandboxes to prune")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tAGE")
+		for _, inst := range candidates {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", inst.ID, orDash(inst.Name), inst.Status, formatAge(now.Sub(time.UnixMilli(inst.CreatedAt))))
+		}
+		w.Flush()
+
+		if pruneFlagDryRun {
+			fmt.Printf("\n%d sandbox(es) would be deleted (dry run)\n", len(candidates))
+			return nil
+		}
+
+		if !pruneFlagYes {
+			ok, err := confirm(fmt.Sprintf("\nDelete %d sandbox(es)?", len(candidates)))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Aborted")
+				return nil
+			}
+		}
+
+		ids := make([]string, len(candidates))
+		for i, inst := range candidates {
+			ids[i] = inst.ID
+		}
+		fmt.Println()
+		return runBulk(ids, "Deleted", func(id string) error {
+			return client.DeleteInstance(teamSlug, id)
+		})
+	},
+}
+
+// pruneCandidates returns the sandboxes created more than olderThan before
+// now, optionally only the stopped ones.
+func pruneCandidates(instances []api.Instance, now time.Time, olderThan time.Duration, stoppedOnly bool) []api.Instance {
+	var candidates []api.Instance
+	for _, inst := range instances {
+		if stoppedOnly && inst.Status != "stopped" {
+			continue
+		}
+		if inst.CreatedAt == 0 || now.Sub(time.UnixMilli(inst.CreatedAt)) < olderThan {
+			continue
+		}
+		candidates = append(candidates, inst)
+	}
+	return candidates
+}
+
+// formatAge renders a duration coarsely, e.g. "3d4h", "5h12m", or "40m".
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// confirm asks a yes/no question on stdin. It refuses to guess when stdin
+// isn't a terminal, so scripts must pass --yes explicitly.
+func confirm(prompt string) (bool, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false, fmt.Errorf("refusing to continue without confirmation; pass --yes to skip the prompt")
+	}
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, nil
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func init() {
+	pruneCmd.Flags().DurationVar(&pruneFlagOlderThan, "older-than", 48*time.Hour, "Only delete sandboxes created longer ago than this")
+	pruneCmd.Flags().BoolVar(&pruneFlagStoppedOnly, "stopped-only", false, "Only delete stopped sandboxes")
+	pruneCmd.Flags().BoolVar(&pruneFlagDryRun, "dry-run", false, "List the sandboxes that would be deleted without deleting them")
+	pruneCmd.Flags().BoolVarP(&pruneFlagYes, "yes", "y", false, "Skip the confirmation prompt")
+}
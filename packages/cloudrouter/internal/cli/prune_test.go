@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+)
+
+func TestPruneCandidates(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	created := func(ago time.Duration) int64 { return now.Add(-ago).UnixMilli() }
+
+	instances := []api.Instance{
+		{ID: "cr_old_running", Status: "running", CreatedAt: created(72 * time.Hour)},
+		{ID: "cr_old_stopped", Status: "stopped", CreatedAt: created(50 * time.Hour)},
+		{ID: "cr_new_stopped", Status: "stopped", CreatedAt: created(time.Hour)},
+		{ID: "cr_unknown_age", Status: "stopped"},
+	}
+
+	ids := func(list []api.Instance) []string {
+		var out []string
+		for _, inst := range list {
+			out = append(out, inst.ID)
+		}
+		return out
+	}
+
+	got := ids(pruneCandidates(instances, now, 48*time.Hour, false))
+	if len(got) != 2 || got[0] != "cr_old_running" || got[1] != "cr_old_stopped" {
+		t.Errorf("pruneCandidates(48h) = %v", got)
+	}
+
+	got = ids(pruneCandidates(instances, now, 0, true))
+	if len(got) != 2 || got[0] != "cr_old_stopped" || got[1] != "cr_new_stopped" {
+		t.Errorf("pruneCandidates(stopped-only) = %v", got)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{40 * time.Minute, "40m"},
+		{5*time.Hour + 12*time.Minute, "5h12m"},
+		{76 * time.Hour, "3d4h"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.expected {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.expected)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/manaflow-ai/cloudrouter/internal/auth"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 	"github.com/manaflow-ai/cloudrouter/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -11,6 +13,12 @@ import (
 var (
 	flagVerbose bool
 	flagTeam    string
+	flagProfile string
+
+	flagCredentialStore string
+
+	flagQuiet   bool
+	flagNoColor bool
 )
 
 // versionCheckDone signals when version check is complete
@@ -26,23 +34,37 @@ var rootCmd = &cobra.Command{
 
 Quick start:
   cloudrouter login                      # Authenticate
+  cloudrouter --profile work login       # Log in to a second account
   cloudrouter start                      # Create a sandbox
   cloudrouter start --size small         # Create a smaller sandbox (2 vCPU, 8 GB)
   cloudrouter start --gpu B200           # Create a sandbox with GPU
   cloudrouter start ./my-project         # Create sandbox + upload directory
+  cloudrouter start --snapshot <snap>    # Create sandbox from a team snapshot
+  cloudrouter start -T <tpl>             # Create sandbox from a custom template
+  cloudrouter team switch <slug>         # Use another team without passing -t
+  cloudrouter secrets set <NAME>         # Store a team secret (prompts for value)
+  cloudrouter start --with-secrets       # Create sandbox with team secrets as env vars
+  cloudrouter clone <id>                 # Copy a sandbox's workspace into a new one
   cloudrouter code <id>                  # Open VS Code
   cloudrouter jupyter <id>               # Open Jupyter Lab
+  cloudrouter expose <id> 3000           # Share port 3000 at a public URL
   cloudrouter vnc <id>                   # Open VNC desktop
   cloudrouter pty <id>                   # Open terminal session
+  cloudrouter ssh <id>                   # Open an interactive shell
   cloudrouter ssh <id> "ls -la"          # Run a command via SSH
+  cloudrouter logs <id> -s worker -f     # Follow a service's logs
   cloudrouter upload <id> ./my-dir       # Upload files to sandbox
   cloudrouter download <id> ./output     # Download files from sandbox
+  cloudrouter watch <id> ./my-dir        # Sync local changes continuously
   cloudrouter browser snapshot <id>      # Get browser accessibility tree
   cloudrouter browser open <id> <url>    # Navigate browser to URL
   cloudrouter stop <id>                  # Pause sandbox
   cloudrouter resume <id>                # Resume paused sandbox
   cloudrouter delete <id>                # Delete sandbox permanently
+  cloudrouter prune --dry-run            # Show sandboxes older than 48h to delete
   cloudrouter ls                         # List all sandboxes
+  cloudrouter tag <id> project=api       # Tag a sandbox (filter with ls --tag)
+  cloudrouter doctor                     # Diagnose setup and connectivity problems
 
 Size presets (--size):
   small       2 vCPU,  8 GB RAM,  20 GB disk
@@ -64,8 +86,15 @@ GPU options (--gpu, auto-selects Modal provider):
   B200        192GB VRAM - latest gen, frontier models`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		auth.SetConfigOverrides("", "", "", "")
+		auth.SetProfile(flagProfile)
+		auth.SetCredentialStore(flagCredentialStore)
+		output.SetQuiet(flagQuiet)
+		output.SetNoColor(flagNoColor)
+		if err := auth.ValidateProfileName(auth.ActiveProfile()); err != nil {
+			return err
+		}
 
 		// Start version check in background for long-running commands
 		cmdName := cmd.Name()
@@ -76,11 +105,12 @@ GPU options (--gpu, auto-selects Modal provider):
 				versionCheckResult = version.CheckForUpdates()
 			}()
 		}
+		return nil
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		// Show version update warning after long-running commands complete
 		cmdName := cmd.Name()
-		if version.IsLongRunningCommand(cmdName) && versionCheckDone != nil {
+		if version.IsLongRunningCommand(cmdName) && versionCheckDone != nil && !output.Quiet() {
 			// Wait for version check to complete (with timeout)
 			select {
 			case <-versionCheckDone:
@@ -103,6 +133,10 @@ GPU options (--gpu, auto-selects Modal provider):
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
 	rootCmd.PersistentFlags().StringVarP(&flagTeam, "team", "t", "", "Team slug (overrides default)")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Credential profile to use (or set CMUX_PROFILE)")
+	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Print only results, without progress or informational messages")
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colors and other terminal escape sequences (or set NO_COLOR)")
+	rootCmd.PersistentFlags().StringVar(&flagCredentialStore, "credential-store", "", "Where to keep credentials: native or file, a plaintext last resort (default: native, falling back to file; or set CMUX_CREDENTIAL_STORE)")
 
 	// Version command
 	rootCmd.AddCommand(versionCmd)
@@ -115,8 +149,11 @@ func init() {
 
 	// Instance management
 	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(cloneCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(tagCmd)
 
 	// Open commands
 	rootCmd.AddCommand(codeCmd)
@@ -129,13 +166,19 @@ func init() {
 	rootCmd.AddCommand(extendCmd)
 	rootCmd.AddCommand(pauseCmd)
 	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(pruneCmd)
 
 	// SSH command (run commands in sandbox via SSH)
 	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(logsCmd)
+
+	// Port sharing
+	rootCmd.AddCommand(exposeCmd)
 
 	// File transfer commands
 	rootCmd.AddCommand(uploadCmd)
 	rootCmd.AddCommand(downloadCmd)
+	rootCmd.AddCommand(watchCmd)
 
 	// PTY commands (terminal session)
 	rootCmd.AddCommand(ptyCmd)
@@ -144,14 +187,24 @@ func init() {
 	// Browser commands (browser automation)
 	rootCmd.AddCommand(browserCmd)
 
-	// Templates
+	// Templates and snapshots
 	rootCmd.AddCommand(templatesCmd)
+	rootCmd.AddCommand(templateCmd)
+	rootCmd.AddCommand(snapshotCmd)
+
+	// Teams and team secrets
+	rootCmd.AddCommand(teamCmd)
+	rootCmd.AddCommand(secretsCmd)
 
 	// Skills management
 	rootCmd.AddCommand(skillsCmd)
+
+	// Diagnostics
+	rootCmd.AddCommand(doctorCmd)
 }
 
 func Execute() error {
+	registerSandboxCompletions(rootCmd)
 	return rootCmd.Execute()
 }
 
@@ -174,6 +227,16 @@ func SetBuildMode(mode string) {
 	buildMode = mode
 }
 
+// ExitError makes the CLI exit with Code without printing an error, e.g. to
+// pass on the exit code of an interactive shell
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit code: %d", e.Code)
+}
+
 func getTeamSlug() (string, error) {
 	if flagTeam != "" {
 		return flagTeam, nil
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 )
 
 // Rsync flags - set by sync.go before calling runRsyncOverWebSocket
@@ -139,18 +141,7 @@ func runRsyncOverWebSocket(workerURL, token, localPath, remotePath string) error
 	// More files = more parallel streams (up to max)
 	parallelism := rsyncFlagParallel
 	if parallelism <= 0 {
-		// Auto-determine based on file count
-		if totalFiles < minFilesForParallel {
-			parallelism = 1
-		} else if totalFiles < 500 {
-			parallelism = 2
-		} else if totalFiles < 2000 {
-			parallelism = 4
-		} else if totalFiles < 5000 {
-			parallelism = 6
-		} else {
-			parallelism = maxParallelism
-		}
+		parallelism = autoParallelism(totalFiles)
 	}
 
 	// Can't have more parallel streams than top-level entries
@@ -179,7 +170,7 @@ func runRsyncOverWebSocket(workerURL, token, localPath, remotePath string) error
 	// Split entries into chunks for parallel processing
 	chunks := splitEntries(syncEntries, parallelism)
 
-	fmt.Printf("Syncing %d files...\n", totalFiles)
+	output.Infof("Syncing %d files...\n", totalFiles)
 
 	// Run parallel rsync processes
 	var wg sync.WaitGroup
@@ -239,6 +230,23 @@ func runRsyncOverWebSocket(workerURL, token, localPath, remotePath string) error
 	return nil
 }
 
+// autoParallelism picks the number of parallel rsync streams for a transfer
+// of totalFiles files: more files = more streams (up to max)
+func autoParallelism(totalFiles int) int {
+	switch {
+	case totalFiles < minFilesForParallel:
+		return 1
+	case totalFiles < 500:
+		return 2
+	case totalFiles < 2000:
+		return 4
+	case totalFiles < 5000:
+		return 6
+	default:
+		return maxParallelism
+	}
+}
+
 // runRsyncSingleFile syncs a single file using rsync over WebSocket SSH
 func runRsyncSingleFile(workerURL, token, localFile, remotePath string) error {
 	if _, err := exec.LookPath("rsync"); err != nil {
@@ -277,48 +285,6 @@ func runRsyncSingleFile(workerURL, token, localFile, remotePath string) error {
 	return nil
 }
 
-// runRsyncDownload downloads files from remote sandbox to local using rsync over WebSocket SSH
-func runRsyncDownload(workerURL, token, remotePath, localPath string) error {
-	if _, err := exec.LookPath("rsync"); err != nil {
-		return fmt.Errorf("rsync not found. Install with: brew install rsync (macOS) or apt install rsync (Linux)")
-	}
-
-	wsURL := toWebSocketURL(workerURL, token)
-	startTime := time.Now()
-
-	rsyncArgs := buildRsyncDownloadArgs()
-
-	sshCmd, cleanup, err := buildSSHProxyCommand(wsURL)
-	if err != nil {
-		return err
-	}
-	defer cleanup()
-	rsyncArgs = append(rsyncArgs, "-e", sshCmd)
-
-	remoteSpec := fmt.Sprintf("%s@e2b-sandbox:%s/", token, remotePath)
-	localDest := localPath
-	if !strings.HasSuffix(localDest, "/") {
-		localDest += "/"
-	}
-	rsyncArgs = append(rsyncArgs, remoteSpec, localDest)
-
-	stats, err := execRsync(rsyncArgs)
-	if err != nil {
-		return err
-	}
-
-	elapsed := time.Since(startTime)
-	if stats != nil && stats.bytes > 0 {
-		speedMBps := float64(stats.bytes) / elapsed.Seconds() / 1024 / 1024
-		fmt.Printf("✓ Downloaded %d files (%.1f MB) in %.1fs (%.1f MB/s)\n",
-			stats.files, float64(stats.bytes)/1024/1024, elapsed.Seconds(), speedMBps)
-	} else {
-		fmt.Println("✓ Download complete")
-	}
-
-	return nil
-}
-
 // buildRsyncDownloadArgs builds rsync arguments for download (minimal excludes)
 func buildRsyncDownloadArgs() []string {
 	args := []string{
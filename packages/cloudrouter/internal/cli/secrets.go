@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	secretsSetFlagDescription string
+	secretsListFlagJSON       bool
+)
+
+// readSecretValue prompts for a value without echo on a terminal, or reads
+// all of stdin when piped, so values never end up in shell history.
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value: %w", err)
+		}
+		return string(value), nil
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value from stdin: %w", err)
+	}
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
+
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	Aliases: []string{"secret"},
+	Short:   "Manage team secrets injected into sandboxes",
+	Long: `Manage secrets that are injected into new sandboxes as environment
+variables with 'cloudrouter start --secret NAME' or '--with-secrets'.
+
+Secrets are stored server-side and are write-only: they can be set and
+deleted, but never read back, and their values are never written to disk
+locally. Secrets are shared with the whole team; only a secret's creator or
+a team admin can change or delete it.
+
+Examples:
+  cloudrouter secrets set OPENAI_API_KEY                # Prompt for the value
+  cat key.txt | cloudrouter secrets set OPENAI_API_KEY  # Read the value from stdin
+  cloudrouter secrets list                              # List secret names
+  cloudrouter secrets rm OPENAI_API_KEY                 # Delete a secret
+  cloudrouter start --secret OPENAI_API_KEY             # Inject a secret as an env var
+  cloudrouter start --with-secrets                      # Inject all team secrets`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create or update a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if !envKeyPattern.MatchString(name) {
+			return fmt.Errorf("invalid secret name %q (must be a valid environment variable name)", name)
+		}
+
+		value, err := readSecretValue(name)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("secret value is empty")
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		if err := client.SetSecret(teamSlug, name, value, secretsSetFlagDescription); err != nil {
+			return fmt.Errorf("failed to set secret: %w", err)
+		}
+
+		fmt.Printf("✓ Secret %s saved\n", name)
+		return nil
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List secret names",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		secrets, err := client.ListSecrets(teamSlug)
+		if err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		if secretsListFlagJSON {
+			if secrets == nil {
+				secrets = []api.Secret{}
+			}
+			out, err := json.MarshalIndent(secrets, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(secrets) == 0 {
+			fmt.Println("No secrets found. Run 'cloudrouter secrets set <name>' to add one.")
+			return nil
+		}
+
+		fmt.Printf("%-32s %-8s %s\n", "NAME", "UPDATED", "DESCRIPTION")
+		now := time.Now()
+		for _, secret := range secrets {
+			age := formatAge(now.Sub(time.UnixMilli(secret.UpdatedAt)))
+			fmt.Printf("%-32s %-8s %s\n", secret.Name, age, secret.Description)
+		}
+		return nil
+	},
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:     "rm <name>...",
+	Aliases: []string{"delete"},
+	Short:   "Delete secrets",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		for _, name := range args {
+			if err := client.DeleteSecret(teamSlug, name); err != nil {
+				return fmt.Errorf("failed to delete secret %s: %w", name, err)
+			}
+			fmt.Printf("✓ Secret %s deleted\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	secretsSetCmd.Flags().StringVar(&secretsSetFlagDescription, "description", "", "Description shown in 'cloudrouter secrets list'")
+	secretsListCmd.Flags().BoolVar(&secretsListFlagJSON, "json", false, "Output secret names as JSON")
+
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+}
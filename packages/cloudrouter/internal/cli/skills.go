@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -18,7 +20,8 @@ const (
 var skillsCmd = &cobra.Command{
 	Use:   "skills",
 	Short: "Manage Claude Code skills for cloudrouter",
-	Long:  `Manage Claude Code skills that help AI assistants use cloudrouter effectively.`,
+	Long: `Manage Claude Code skills that help AI assistants use cloudrouter effectively,
+and install community skills from the skills registry.`,
 }
 
 var skillsUpdateCmd = &cobra.Command{
@@ -37,30 +40,234 @@ documentation on how to use cloudrouter commands effectively.`,
 }
 
 var skillsInstallCmd = &cobra.Command{
-	Use:   "install",
-	Short: "Install cloudrouter skills locally",
-	Long: `Install the cloudrouter SKILL.md file to the Claude Code skills directory.
+	Use:   "install [name[@version]]",
+	Short: "Install the cloudrouter skill or a skill from the registry",
+	Long: `Install a skill to the Claude Code skills directory (~/.claude/skills/<name>).
 
-This is equivalent to 'skills update' but with a clearer intent for first-time setup.`,
+Without a name, installs the bundled cloudrouter skill, like 'skills update'.
+With a name, installs that skill from the skills registry: the latest
+version, or the version after @. Every file is checked against the SHA-256
+in the registry index before anything is written, and a failed install
+leaves the previously installed version in place.
+
+Registry installs are pinned in the nearest skills.lock (created in the
+current directory if there is none), with the exact version and file
+hashes. Commit it and run 'cloudrouter skills sync' to install the same
+skills elsewhere. Pinned skills are not auto-updated.
+
+Set CLOUDROUTER_SKILLS_REGISTRY to use a different registry index.
+
+Examples:
+  cloudrouter skills install                    # Bundled cloudrouter skill
+  cloudrouter skills install playwright-testing
+  cloudrouter skills install pytorch-train@1.2.0
+  cloudrouter skills install qa --no-lock        # Don't record in skills.lock`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return updateSkills()
+		if len(args) == 0 {
+			return updateSkills()
+		}
+		return installSkill(args[0], skillsInstallFlagNoLock)
+	},
+}
+
+var skillsInstallFlagNoLock bool
+
+var skillsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install the skills pinned in skills.lock",
+	Long: `Install every skill pinned in the nearest skills.lock at its exact version,
+verifying each file's SHA-256. Skills whose installed files already match
+the lock are left alone.
+
+Examples:
+  cloudrouter skills sync`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		lockPath, ok := findSkillsLock(cwd)
+		if !ok {
+			return fmt.Errorf("no %s found in this directory or its parents. Run 'cloudrouter skills install <name>' to create one", skillsLockFile)
+		}
+		return syncSkills(lockPath)
+	},
+}
+
+var skillsSearchFlagJSON bool
+
+var skillsSearchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the skills registry",
+	Long: `Search the skills registry by name, description, and tags. Without a
+query, lists every skill.
+
+Examples:
+  cloudrouter skills search
+  cloudrouter skills search gpu
+  cloudrouter skills search browser --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		index, err := fetchSkillsIndex(skillsRegistryIndexURL())
+		if err != nil {
+			return err
+		}
+		matches := searchSkills(index, query)
+
+		if skillsSearchFlagJSON {
+			if matches == nil {
+				matches = []registrySkill{}
+			}
+			data, err := json.MarshalIndent(matches, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(matches) == 0 {
+			fmt.Printf("No skills match %q\n", query)
+			return nil
+		}
+		fmt.Printf("%-28s %-10s %s\n", "NAME", "LATEST", "DESCRIPTION")
+		for _, skill := range matches {
+			fmt.Printf("%-28s %-10s %s\n", skill.Name, skill.Latest, skill.Description)
+		}
+		fmt.Println("\nInstall with: cloudrouter skills install <name>[@version]")
+		return nil
 	},
 }
 
 func init() {
+	skillsSearchCmd.Flags().BoolVar(&skillsSearchFlagJSON, "json", false, "Output matching skills as JSON")
+	skillsInstallCmd.Flags().BoolVar(&skillsInstallFlagNoLock, "no-lock", false, "Don't pin the installed version in skills.lock")
+
 	skillsCmd.AddCommand(skillsUpdateCmd)
 	skillsCmd.AddCommand(skillsInstallCmd)
+	skillsCmd.AddCommand(skillsSearchCmd)
+	skillsCmd.AddCommand(skillsSyncCmd)
 }
 
-func getSkillsDir() (string, error) {
+// getSkillsRoot returns the Claude Code skills directory
+func getSkillsRoot() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, ".claude", "skills", "cloudrouter"), nil
+	return filepath.Join(home, ".claude", "skills"), nil
+}
+
+func getSkillsDir() (string, error) {
+	root, err := getSkillsRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, "cloudrouter"), nil
+}
+
+// installSkill installs name[@version] from the skills registry and pins it
+// in skills.lock unless noLock is set
+func installSkill(ref string, noLock bool) error {
+	name, version, err := parseSkillRef(ref)
+	if err != nil {
+		return err
+	}
+
+	indexURL := skillsRegistryIndexURL()
+	index, err := fetchSkillsIndex(indexURL)
+	if err != nil {
+		return err
+	}
+	_, version, files, err := resolveSkill(index, name, version)
+	if err != nil {
+		return err
+	}
+
+	root, err := getSkillsRoot()
+	if err != nil {
+		return err
+	}
+	destDir := filepath.Join(root, name)
+	fmt.Printf("Installing %s@%s (%d file(s))...\n", name, version, len(files.Files))
+	if err := installRegistrySkill(indexURL, files, destDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Skill installed: %s@%s → %s\n", name, version, destDir)
+
+	if noLock {
+		return nil
+	}
+	lockPath, _, err := currentSkillsLockPath()
+	if err != nil {
+		return err
+	}
+	if err := recordSkillPin(lockPath, indexURL, name, version, files.Files); err != nil {
+		return fmt.Errorf("installed, but failed to update %s: %w", lockPath, err)
+	}
+	fmt.Printf("  Pinned in %s\n", lockPath)
+	return nil
+}
+
+// syncSkills installs every skill pinned in the lock at lockPath
+func syncSkills(lockPath string) error {
+	lock, err := readSkillsLock(lockPath)
+	if err != nil {
+		return err
+	}
+	if len(lock.Skills) == 0 {
+		fmt.Printf("No skills pinned in %s\n", lockPath)
+		return nil
+	}
+	root, err := getSkillsRoot()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(lock.Skills))
+	for name := range lock.Skills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	installed := 0
+	for _, name := range names {
+		skill := lock.Skills[name]
+		if !validSkillName(name) {
+			return fmt.Errorf("%s pins an invalid skill name %q", lockPath, name)
+		}
+		destDir := filepath.Join(root, name)
+		if skillMatchesLock(destDir, skill) {
+			fmt.Printf("  %s@%s is up to date\n", name, skill.Version)
+			continue
+		}
+		fmt.Printf("Installing %s@%s...\n", name, skill.Version)
+		// Locked URLs are absolute, so the registry URL is only a base
+		if err := installRegistrySkill(lock.Registry, &skillVersion{Files: skill.Files}, destDir); err != nil {
+			return fmt.Errorf("failed to install %s@%s: %w", name, skill.Version, err)
+		}
+		installed++
+	}
+
+	fmt.Printf("✓ %d skill(s) in sync with %s (%d installed)\n", len(names), lockPath, installed)
+	return nil
 }
 
 func updateSkills() error {
+	if version, ok := pinnedSkillVersion("cloudrouter"); ok {
+		fmt.Printf("The cloudrouter skill is pinned to %s in %s; not updating.\n", version, skillsLockFile)
+		fmt.Println("Run 'cloudrouter skills install cloudrouter@<version>' to change the pin.")
+		return nil
+	}
+
 	skillsDir, err := getSkillsDir()
 	if err != nil {
 		return err
@@ -127,6 +334,11 @@ func AutoUpdateSkillsIfNeeded() error {
 		return nil
 	}
 
+	// A project that pins the skill in skills.lock keeps that version
+	if _, pinned := pinnedSkillVersion("cloudrouter"); pinned {
+		return nil
+	}
+
 	// Silently update skills
 	return updateSkillsSilent()
 }
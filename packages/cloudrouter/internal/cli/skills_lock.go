@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	skillsLockFile    = "skills.lock"
+	skillsLockVersion = 1
+)
+
+// skillsLock pins the skills a project uses to exact versions and file
+// hashes, so 'skills sync' installs the same files everywhere
+type skillsLock struct {
+	Version  int                    `json:"version"`
+	Registry string                 `json:"registry,omitempty"`
+	Skills   map[string]lockedSkill `json:"skills"`
+}
+
+// lockedSkill is one pinned skill. File URLs are absolute so the lock still
+// works after the registry index changes.
+type lockedSkill struct {
+	Version string      `json:"version"`
+	Files   []skillFile `json:"files"`
+}
+
+// findSkillsLock returns the nearest skills.lock in dir or its parents
+func findSkillsLock(dir string) (string, bool) {
+	for {
+		candidate := filepath.Join(dir, skillsLockFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// currentSkillsLockPath returns the nearest skills.lock, or where a new one
+// goes (the current directory) when there is none
+func currentSkillsLockPath() (string, bool, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false, err
+	}
+	if path, ok := findSkillsLock(cwd); ok {
+		return path, true, nil
+	}
+	return filepath.Join(cwd, skillsLockFile), false, nil
+}
+
+func readSkillsLock(path string) (*skillsLock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lock skillsLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if lock.Version > skillsLockVersion {
+		return nil, fmt.Errorf("%s was written by a newer cloudrouter (lock version %d); update cloudrouter", path, lock.Version)
+	}
+	if lock.Skills == nil {
+		lock.Skills = map[string]lockedSkill{}
+	}
+	return &lock, nil
+}
+
+func writeSkillsLock(path string, lock *skillsLock) error {
+	lock.Version = skillsLockVersion
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// recordSkillPin writes name@version with its resolved file URLs and hashes
+// to the lock at path, creating it if needed
+func recordSkillPin(path, indexURL, name, version string, files []skillFile) error {
+	lock, err := readSkillsLock(path)
+	if errors.Is(err, os.ErrNotExist) {
+		lock = &skillsLock{Skills: map[string]lockedSkill{}}
+	} else if err != nil {
+		return err
+	}
+
+	pinned := make([]skillFile, 0, len(files))
+	for _, file := range files {
+		fileURL, err := resolveSkillFileURL(indexURL, file.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL for %s: %w", file.Path, err)
+		}
+		pinned = append(pinned, skillFile{Path: file.Path, URL: fileURL, SHA256: strings.ToLower(file.SHA256)})
+	}
+	lock.Registry = indexURL
+	lock.Skills[name] = lockedSkill{Version: version, Files: pinned}
+	return writeSkillsLock(path, lock)
+}
+
+// skillMatchesLock reports whether every pinned file is installed in dir
+// with the pinned hash
+func skillMatchesLock(dir string, skill lockedSkill) bool {
+	for _, file := range skill.Files {
+		if !validSkillFilePath(file.Path) {
+			return false
+		}
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return false
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), file.SHA256) {
+			return false
+		}
+	}
+	return len(skill.Files) > 0
+}
+
+// pinnedSkillVersion returns the version of name pinned by the skills.lock
+// nearest the current directory, if any
+func pinnedSkillVersion(name string) (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	path, ok := findSkillsLock(cwd)
+	if !ok {
+		return "", false
+	}
+	lock, err := readSkillsLock(path)
+	if err != nil {
+		return "", false
+	}
+	skill, ok := lock.Skills[name]
+	return skill.Version, ok
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindSkillsLock(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := findSkillsLock(nested); ok {
+		t.Fatal("found a lock before one was written")
+	}
+
+	lockPath := filepath.Join(root, skillsLockFile)
+	if err := os.WriteFile(lockPath, []byte(`{"version":1,"skills":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := findSkillsLock(nested); !ok || got != lockPath {
+		t.Errorf("findSkillsLock() = %q, %v; want %q", got, ok, lockPath)
+	}
+}
+
+func TestRecordSkillPin(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), skillsLockFile)
+	files := []skillFile{{Path: "SKILL.md", URL: "qa/1.0.0/SKILL.md", SHA256: "ABC"}}
+
+	if err := recordSkillPin(lockPath, "https://example.com/skills/index.json", "qa", "1.0.0", files); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordSkillPin(lockPath, "https://example.com/skills/index.json", "gpu", "2.0.0", files); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := readSkillsLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Skills) != 2 || lock.Version != skillsLockVersion {
+		t.Fatalf("lock = %+v", lock)
+	}
+	qa := lock.Skills["qa"]
+	if qa.Version != "1.0.0" || qa.Files[0].URL != "https://example.com/skills/qa/1.0.0/SKILL.md" || qa.Files[0].SHA256 != "abc" {
+		t.Errorf("qa pin = %+v", qa)
+	}
+}
+
+func TestReadSkillsLockRejectsNewerVersion(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), skillsLockFile)
+	if err := os.WriteFile(lockPath, []byte(`{"version":99,"skills":{}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSkillsLock(lockPath); err == nil {
+		t.Error("expected an error for a lock from a newer version")
+	}
+}
+
+func TestSyncSkills(t *testing.T) {
+	content := "# QA\n"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	lockPath := filepath.Join(t.TempDir(), skillsLockFile)
+	files := []skillFile{{Path: "SKILL.md", URL: server.URL + "/qa/SKILL.md", SHA256: sha256Hex(content)}}
+	if err := recordSkillPin(lockPath, server.URL+"/index.json", "qa", "1.0.0", files); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := syncSkills(lockPath); err != nil {
+		t.Fatalf("syncSkills() error = %v", err)
+	}
+	installed := filepath.Join(home, ".claude", "skills", "qa", "SKILL.md")
+	if data, err := os.ReadFile(installed); err != nil || string(data) != content {
+		t.Fatalf("installed skill = %q, %v", data, err)
+	}
+
+	// A second sync finds the files matching and downloads nothing
+	if err := syncSkills(lockPath); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 download, got %d", requests)
+	}
+}
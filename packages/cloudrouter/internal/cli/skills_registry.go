@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// skillsRegistryURL is the index of installable skills. Relative file
+	// URLs in it are resolved against the index URL.
+	skillsRegistryURL = skillsBaseURL + "/index.json"
+	// skillsRegistryEnv overrides the registry index URL, e.g. for a
+	// company-internal registry
+	skillsRegistryEnv = "CLOUDROUTER_SKILLS_REGISTRY"
+	// maxSkillFileSize guards against a bad index pointing at huge files
+	maxSkillFileSize = 10 << 20
+)
+
+// skillsIndex is the registry index document
+type skillsIndex struct {
+	Skills []registrySkill `json:"skills"`
+}
+
+// registrySkill is one skill in the registry, with every published version
+type registrySkill struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Author      string                  `json:"author,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Latest      string                  `json:"latest"`
+	Versions    map[string]skillVersion `json:"versions"`
+}
+
+// skillVersion lists the files of one published version
+type skillVersion struct {
+	Files []skillFile `json:"files"`
+}
+
+// skillFile is a file of a skill, relative to the skill directory, and the
+// SHA-256 its contents must match
+type skillFile struct {
+	Path   string `json:"path"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+func skillsRegistryIndexURL() string {
+	if u := os.Getenv(skillsRegistryEnv); u != "" {
+		return u
+	}
+	return skillsRegistryURL
+}
+
+// fetchSkillsIndex downloads and parses the registry index
+func fetchSkillsIndex(indexURL string) (*skillsIndex, error) {
+	data, err := httpGetLimited(indexURL, maxSkillFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch skills registry: %w", err)
+	}
+	var index skillsIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid skills registry %s: %w", indexURL, err)
+	}
+	return &index, nil
+}
+
+func httpGetLimited(rawURL string, limit int64) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", rawURL, limit)
+	}
+	return data, nil
+}
+
+// searchSkills returns the skills whose name, description, or tags contain
+// query (case-insensitive), sorted by name. An empty query matches all.
+func searchSkills(index *skillsIndex, query string) []registrySkill {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var matches []registrySkill
+	for _, skill := range index.Skills {
+		haystack := strings.ToLower(skill.Name + " " + skill.Description + " " + strings.Join(skill.Tags, " "))
+		if query == "" || strings.Contains(haystack, query) {
+			matches = append(matches, skill)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
+
+// parseSkillRef splits "name[@version]"
+func parseSkillRef(ref string) (name, version string, err error) {
+	name, version, _ = strings.Cut(ref, "@")
+	if !validSkillName(name) {
+		return "", "", fmt.Errorf("invalid skill name %q", name)
+	}
+	return name, version, nil
+}
+
+// validSkillName allows the names skill directories can safely use
+func validSkillName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return name != "." && name != ".."
+}
+
+// resolveSkill finds name in the index and picks version, or the latest
+// version when version is empty
+func resolveSkill(index *skillsIndex, name, version string) (*registrySkill, string, *skillVersion, error) {
+	for i := range index.Skills {
+		skill := &index.Skills[i]
+		if skill.Name != name {
+			continue
+		}
+		if version == "" {
+			version = skill.Latest
+		}
+		v, ok := skill.Versions[version]
+		if !ok {
+			available := make([]string, 0, len(skill.Versions))
+			for name := range skill.Versions {
+				available = append(available, name)
+			}
+			sort.Strings(available)
+			return nil, "", nil, fmt.Errorf("skill %s has no version %q (available: %s)", name, version, strings.Join(available, ", "))
+		}
+		if len(v.Files) == 0 {
+			return nil, "", nil, fmt.Errorf("skill %s@%s has no files", name, version)
+		}
+		return skill, version, &v, nil
+	}
+	return nil, "", nil, fmt.Errorf("skill %q not found in the registry. Run 'cloudrouter skills search' to list skills", name)
+}
+
+// validSkillFilePath rejects absolute paths and paths escaping the skill
+// directory
+func validSkillFilePath(p string) bool {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, "\\") {
+		return false
+	}
+	clean := path.Clean(p)
+	return clean == p && clean != "." && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// resolveSkillFileURL resolves a file URL relative to the index URL
+func resolveSkillFileURL(indexURL, fileURL string) (string, error) {
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// installRegistrySkill downloads every file of version, verifies its
+// checksum, and only then replaces destDir, so a failed install leaves the
+// previous version in place
+func installRegistrySkill(indexURL string, version *skillVersion, destDir string) error {
+	if err := os.MkdirAll(filepath.Dir(destDir), 0755); err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	stagingDir, err := os.MkdirTemp(filepath.Dir(destDir), "."+filepath.Base(destDir)+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create skills directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	for _, file := range version.Files {
+		if !validSkillFilePath(file.Path) {
+			return fmt.Errorf("registry lists an unsafe file path %q", file.Path)
+		}
+		fileURL, err := resolveSkillFileURL(indexURL, file.URL)
+		if err != nil {
+			return fmt.Errorf("invalid URL for %s: %w", file.Path, err)
+		}
+		data, err := httpGetLimited(fileURL, maxSkillFileSize)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", file.Path, err)
+		}
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, file.SHA256) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", file.Path, file.SHA256, got)
+		}
+
+		target := filepath.Join(stagingDir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	if err := os.Chmod(stagingDir, 0755); err != nil {
+		return err
+	}
+	backupDir := stagingDir + ".old"
+	if _, err := os.Stat(destDir); err == nil {
+		if err := os.Rename(destDir, backupDir); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", destDir, err)
+		}
+	}
+	if err := os.Rename(stagingDir, destDir); err != nil {
+		os.Rename(backupDir, destDir)
+		return fmt.Errorf("failed to install to %s: %w", destDir, err)
+	}
+	os.RemoveAll(backupDir)
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseSkillRef(t *testing.T) {
+	tests := []struct {
+		ref         string
+		name        string
+		version     string
+		expectError bool
+	}{
+		{"playwright", "playwright", "", false},
+		{"pytorch-train@1.2.0", "pytorch-train", "1.2.0", false},
+		{"../etc", "", "", true},
+		{"Bad Name", "", "", true},
+		{"@1.0.0", "", "", true},
+	}
+
+	for _, tt := range tests {
+		name, version, err := parseSkillRef(tt.ref)
+		if (err != nil) != tt.expectError || name != tt.name || version != tt.version {
+			t.Errorf("parseSkillRef(%q) = %q, %q, %v", tt.ref, name, version, err)
+		}
+	}
+}
+
+func TestSearchSkills(t *testing.T) {
+	index := &skillsIndex{Skills: []registrySkill{
+		{Name: "pytorch-train", Description: "Train models on GPU sandboxes", Tags: []string{"ml"}},
+		{Name: "browser-qa", Description: "Test web apps", Tags: []string{"playwright"}},
+	}}
+
+	if got := searchSkills(index, "GPU"); len(got) != 1 || got[0].Name != "pytorch-train" {
+		t.Errorf("search by description = %v", got)
+	}
+	if got := searchSkills(index, "playwright"); len(got) != 1 || got[0].Name != "browser-qa" {
+		t.Errorf("search by tag = %v", got)
+	}
+	if got := searchSkills(index, ""); len(got) != 2 || got[0].Name != "browser-qa" {
+		t.Errorf("empty search = %v, want all sorted by name", got)
+	}
+}
+
+func TestResolveSkill(t *testing.T) {
+	index := &skillsIndex{Skills: []registrySkill{{
+		Name:   "qa",
+		Latest: "1.1.0",
+		Versions: map[string]skillVersion{
+			"1.0.0": {Files: []skillFile{{Path: "SKILL.md"}}},
+			"1.1.0": {Files: []skillFile{{Path: "SKILL.md"}}},
+		},
+	}}}
+
+	if _, version, _, err := resolveSkill(index, "qa", ""); err != nil || version != "1.1.0" {
+		t.Errorf("latest = %q, %v", version, err)
+	}
+	if _, version, _, err := resolveSkill(index, "qa", "1.0.0"); err != nil || version != "1.0.0" {
+		t.Errorf("pinned = %q, %v", version, err)
+	}
+	if _, _, _, err := resolveSkill(index, "qa", "2.0.0"); err == nil || !strings.Contains(err.Error(), "1.0.0, 1.1.0") {
+		t.Errorf("missing version error = %v", err)
+	}
+	if _, _, _, err := resolveSkill(index, "nope", ""); err == nil {
+		t.Error("expected an error for an unknown skill")
+	}
+}
+
+func TestValidSkillFilePath(t *testing.T) {
+	for _, p := range []string{"SKILL.md", "scripts/run.sh"} {
+		if !validSkillFilePath(p) {
+			t.Errorf("validSkillFilePath(%q) = false, want true", p)
+		}
+	}
+	for _, p := range []string{"", "/etc/passwd", "../x", "a/../../x", "a/./b", `a\b`} {
+		if validSkillFilePath(p) {
+			t.Errorf("validSkillFilePath(%q) = true, want false", p)
+		}
+	}
+}
+
+func TestInstallRegistrySkill(t *testing.T) {
+	skill := "# QA skill\n"
+	script := "echo ok\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/skills/qa/SKILL.md":
+			w.Write([]byte(skill))
+		case "/skills/qa/run.sh":
+			w.Write([]byte(script))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	indexURL := server.URL + "/skills/index.json"
+
+	destDir := filepath.Join(t.TempDir(), "qa")
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "SKILL.md"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := &skillVersion{Files: []skillFile{{Path: "SKILL.md", URL: "qa/SKILL.md", SHA256: sha256Hex("tampered")}}}
+	if err := installRegistrySkill(indexURL, bad, destDir); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(destDir, "SKILL.md")); string(data) != "old" {
+		t.Errorf("failed install changed the existing skill to %q", data)
+	}
+
+	good := &skillVersion{Files: []skillFile{
+		{Path: "SKILL.md", URL: "qa/SKILL.md", SHA256: sha256Hex(skill)},
+		{Path: "scripts/run.sh", URL: server.URL + "/skills/qa/run.sh", SHA256: sha256Hex(script)},
+	}}
+	if err := installRegistrySkill(indexURL, good, destDir); err != nil {
+		t.Fatalf("installRegistrySkill() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(destDir, "SKILL.md")); string(data) != skill {
+		t.Errorf("SKILL.md = %q, want %q", data, skill)
+	}
+	if data, _ := os.ReadFile(filepath.Join(destDir, "scripts", "run.sh")); string(data) != script {
+		t.Errorf("scripts/run.sh = %q, want %q", data, script)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(destDir))
+	if len(entries) != 1 {
+		t.Errorf("expected only the skill directory to remain, found %d entries", len(entries))
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCreateFlagName string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture and list team sandbox snapshots",
+	Long: `Capture a configured sandbox (dependencies installed, files in place) as a
+snapshot shared with your team, then start new sandboxes from it.
+
+Snapshots capture the filesystem; services are restarted when a sandbox
+starts from a snapshot. Only GPU sandboxes can be snapshotted.
+
+Examples:
+  cloudrouter snapshot create cr_abc123 --name ml-base  # Capture a snapshot
+  cloudrouter snapshot list                              # List team snapshots
+  cloudrouter start --snapshot snap_abc123               # Start from a snapshot`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create <id>",
+	Short: "Capture a sandbox as a team snapshot",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if snapshotCreateFlagName == "" {
+			return fmt.Errorf("--name is required")
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		output.Infof("Snapshotting %s (this can take a few minutes)...\n", args[0])
+		snap, err := client.CreateSnapshot(teamSlug, args[0], snapshotCreateFlagName)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created snapshot: %s (%s)\n", snap.ID, snap.Name)
+		fmt.Printf("Start from it with: cloudrouter start --snapshot %s\n", snap.ID)
+		return nil
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List team snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		snapshots, err := client.ListSnapshots(teamSlug)
+		if err != nil {
+			return err
+		}
+
+		if len(snapshots) == 0 {
+			fmt.Println("No snapshots found")
+			return nil
+		}
+
+		fmt.Println("Snapshots:")
+		for _, snap := range snapshots {
+			created := time.UnixMilli(snap.CreatedAt).Format("2006-01-02 15:04")
+			fmt.Printf("  %s - %s (from %s, %s)\n", snap.ID, snap.Name, snap.SourceID, created)
+		}
+		return nil
+	},
+}
+
+func init() {
+	snapshotCreateCmd.Flags().StringVarP(&snapshotCreateFlagName, "name", "n", "", "Name for the snapshot (required)")
+
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+}
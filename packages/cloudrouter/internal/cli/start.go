@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -35,7 +36,14 @@ var (
 	startFlagDisk     int
 	startFlagSize     string
 	startFlagImage    string
+	startFlagSnapshot string
 	startFlagTimeout  int
+
+	startFlagNoDevcontainer bool
+	startFlagEnv            []string
+	startFlagEnvFile        string
+	startFlagSecrets        []string
+	startFlagWithSecrets    bool
 )
 
 // sizePreset defines a machine size preset (cpu, memory, disk).
@@ -62,6 +70,22 @@ func isGitURL(s string) bool {
 		strings.HasSuffix(s, ".git")
 }
 
+// waitForAuthToken fetches the auth token of a new sandbox, retrying while it
+// boots. Returns an empty string if the sandbox isn't ready in time.
+func waitForAuthToken(client *api.Client, teamSlug, id string) string {
+	output.Infof("Waiting for sandbox to initialize")
+	defer output.Infoln()
+	for i := 0; i < 10; i++ {
+		time.Sleep(2 * time.Second)
+		output.Infof(".")
+		token, err := client.GetAuthToken(teamSlug, id)
+		if err == nil && token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
 var startCmd = &cobra.Command{
 	Use:     "start [path-or-git-url]",
 	Aliases: []string{"create", "new"},
@@ -89,6 +113,11 @@ GPU options (--gpu):
 
 Individual resource flags (--cpu, --memory, --disk) override --size values.
 
+If the path or cloned repo contains .devcontainer/devcontainer.json, its
+hostRequirements pick the sandbox size and GPU, a build.dockerfile is built
+as a custom template (local paths only), onCreateCommand/postCreateCommand
+run in the workspace, and forwardPorts are exposed at public URLs.
+
 Examples:
   cloudrouter start                          # Create a sandbox (8 vCPU, 32 GB RAM)
   cloudrouter start --size small             # Smaller sandbox (2 vCPU, 8 GB RAM)
@@ -97,7 +126,13 @@ Examples:
   cloudrouter start --gpu B200               # Sandbox with B200 GPU
   cloudrouter start --gpu A100               # Sandbox with A100 GPU
   cloudrouter start --gpu H100:2             # Sandbox with 2x H100 GPUs
+  cloudrouter start --snapshot snap_abc123   # Start from a team snapshot
   cloudrouter start .                        # Sync current directory
+  cloudrouter start . --no-devcontainer      # Sync without applying devcontainer.json
+  cloudrouter start . --env-file .env        # Sync and set variables from .env
+  cloudrouter start -e DEBUG=1 -e API_KEY    # Set variables (API_KEY copied from local env)
+  cloudrouter start --secret NPM_TOKEN      # Inject a team secret as an env var
+  cloudrouter start --with-secrets           # Inject all team secrets as env vars
   cloudrouter start https://github.com/u/r   # Clone git repo`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -178,7 +213,39 @@ Examples:
 			}
 		}
 
+		// Read --env/--env-file up front so mistakes fail before creating
+		envVars, err := collectStartEnv(startFlagEnvFile, startFlagEnv)
+		if err != nil {
+			return err
+		}
+
 		client := api.NewClient()
+
+		// Apply .devcontainer/devcontainer.json from the start path, or from
+		// a GitHub repo before it's cloned
+		var devcontainer *devcontainerConfig
+		if !startFlagNoDevcontainer {
+			var dcPath string
+			if syncPath != "" {
+				if path, ok := findDevcontainer(syncPath); ok {
+					data, err := os.ReadFile(path)
+					if err != nil {
+						return fmt.Errorf("failed to read devcontainer.json: %w", err)
+					}
+					if devcontainer, err = parseDevcontainer(data); err != nil {
+						return err
+					}
+					dcPath = path
+				}
+			} else if gitURL != "" {
+				devcontainer = fetchGitHubDevcontainer(gitURL, startFlagBranch)
+			}
+			if devcontainer != nil {
+				output.Infoln("Using devcontainer.json")
+				applyDevcontainerToStart(client, teamSlug, devcontainer, dcPath, name)
+			}
+		}
+
 		provider := startFlagProvider
 
 		// If --gpu is specified without --provider, default to modal
@@ -186,6 +253,22 @@ Examples:
 			provider = "modal"
 		}
 
+		// Snapshots are Modal images, so --snapshot implies modal
+		if startFlagSnapshot != "" {
+			if provider != "" && provider != "modal" {
+				return fmt.Errorf("--snapshot requires the modal provider, got %q", provider)
+			}
+			provider = "modal"
+		}
+
+		// Custom templates (tpl_...) are built as Modal images
+		if strings.HasPrefix(startFlagTemplate, "tpl_") {
+			if provider != "" && provider != "modal" {
+				return fmt.Errorf("custom templates require the modal provider, got %q", provider)
+			}
+			provider = "modal"
+		}
+
 		// Apply --size preset (individual flags override preset values)
 		if startFlagSize != "" {
 			preset, ok := sizePresets[strings.ToLower(startFlagSize)]
@@ -231,7 +314,7 @@ Examples:
 					// Still nothing? Use first modal template
 					if templateID == "" {
 						for _, t := range templates {
-							if t.Provider == "modal" {
+							if t.Provider == "modal" && !t.Custom {
 								templateID = t.ID
 								break
 							}
@@ -280,28 +363,41 @@ Examples:
 		if startFlagImage != "" {
 			createReq.Image = startFlagImage
 		}
+		if startFlagSnapshot != "" {
+			createReq.SnapshotID = startFlagSnapshot
+		}
+		if len(envVars) > 0 {
+			createReq.Envs = envVars
+		}
+		// Secret values are resolved server-side and never pass through here
+		for _, name := range startFlagSecrets {
+			if !envKeyPattern.MatchString(name) {
+				return fmt.Errorf("invalid secret name %q (must be a valid environment variable name)", name)
+			}
+		}
+		createReq.Secrets = startFlagSecrets
+		createReq.WithSecrets = startFlagWithSecrets
 
 		resp, err := client.CreateInstance(createReq)
 		if err != nil {
 			return err
 		}
 
-		// Try to fetch auth token (may need a few retries as sandbox boots)
-		var token string
-		fmt.Print("Waiting for sandbox to initialize")
-		for i := 0; i < 10; i++ {
-			time.Sleep(2 * time.Second)
-			fmt.Print(".")
-			token, err = client.GetAuthToken(teamSlug, resp.DevboxID)
-			if err == nil && token != "" {
-				break
+		token := waitForAuthToken(client, teamSlug, resp.DevboxID)
+
+		// Write env vars to the shell profile before cloning/syncing so setup
+		// commands and later sessions see them
+		if len(envVars) > 0 && token != "" {
+			if err := writeSandboxEnv(client, teamSlug, resp.DevboxID, envVars); err != nil {
+				fmt.Printf("Warning: failed to write environment variables: %v\n", err)
+			} else {
+				output.Infof("✓ Set %d environment variable(s)\n", len(envVars))
 			}
 		}
-		fmt.Println()
 
 		// Clone git repo if specified (fast!)
 		if gitURL != "" && token != "" {
-			fmt.Printf("Cloning %s...\n", gitURL)
+			output.Infof("Cloning %s...\n", gitURL)
 			cloneCmd := fmt.Sprintf("cd /home/user/workspace && git clone %s .", gitURL)
 			if startFlagBranch != "" {
 				cloneCmd = fmt.Sprintf("cd /home/user/workspace && git clone -b %s %s .", startFlagBranch, gitURL)
@@ -312,7 +408,7 @@ Examples:
 			} else if execResp.ExitCode != 0 {
 				fmt.Printf("Warning: git clone failed: %s\n", execResp.Stderr)
 			} else {
-				fmt.Println("✓ Repository cloned")
+				output.Infoln("✓ Repository cloned")
 			}
 		}
 
@@ -320,15 +416,26 @@ Examples:
 		if syncPath != "" && token != "" {
 			inst, err := client.GetInstance(teamSlug, resp.DevboxID)
 			if err == nil && inst.WorkerURL != "" {
-				fmt.Printf("Syncing %s to sandbox...\n", syncPath)
+				output.Infof("Syncing %s to sandbox...\n", syncPath)
 				if err := runRsyncOverWebSocket(inst.WorkerURL, token, syncPath, "/home/user/workspace"); err != nil {
 					fmt.Printf("Warning: failed to sync files: %v\n", err)
 				} else {
-					fmt.Println("✓ Files synced")
+					output.Infoln("✓ Files synced")
 				}
 			}
 		}
 
+		// Run devcontainer commands once the workspace is in place. Repos that
+		// couldn't be inspected before cloning only get commands and ports.
+		if token != "" && !startFlagNoDevcontainer {
+			if devcontainer == nil && gitURL != "" {
+				devcontainer = readSandboxDevcontainer(client, teamSlug, resp.DevboxID)
+			}
+			if devcontainer != nil {
+				runDevcontainerSetup(client, teamSlug, resp.DevboxID, devcontainer)
+			}
+		}
+
 		// Build authenticated URLs
 		var vscodeAuthURL, vncAuthURL, jupyterAuthURL string
 		if token != "" {
@@ -360,6 +467,9 @@ Examples:
 		fmt.Printf("Created sandbox: %s\n", resp.DevboxID)
 		fmt.Printf("  Type:   %s\n", typeLabel)
 		fmt.Printf("  Status: %s\n", resp.Status)
+		if len(resp.Secrets) > 0 {
+			fmt.Printf("  Secrets: %s\n", strings.Join(resp.Secrets, ", "))
+		}
 		if vscodeAuthURL != "" {
 			fmt.Printf("  VSCode:  %s\n", vscodeAuthURL)
 		} else if resp.VSCodeURL != "" {
@@ -381,9 +491,9 @@ Examples:
 		}
 		if startFlagOpen && openableURL != "" {
 			if resp.Provider == "modal" && jupyterAuthURL != "" {
-				fmt.Println("\nOpening Jupyter Lab...")
+				output.Infoln("\nOpening Jupyter Lab...")
 			} else {
-				fmt.Println("\nOpening VSCode...")
+				output.Infoln("\nOpening VSCode...")
 			}
 			openURL(openableURL)
 		}
@@ -395,6 +505,7 @@ Examples:
 func init() {
 	startCmd.Flags().StringVarP(&startFlagName, "name", "n", "", "Name for the sandbox")
 	startCmd.Flags().StringVarP(&startFlagTemplate, "template", "T", "", "Template ID")
+	startCmd.RegisterFlagCompletionFunc("template", completeTemplateIDs)
 	startCmd.Flags().BoolVarP(&startFlagOpen, "open", "o", false, "Open VSCode after creation")
 	startCmd.Flags().StringVar(&startFlagGit, "git", "", "Git repository URL to clone (or user/repo shorthand)")
 	startCmd.Flags().StringVarP(&startFlagBranch, "branch", "b", "", "Git branch to clone")
@@ -409,5 +520,13 @@ func init() {
 	startCmd.Flags().IntVar(&startFlagMemory, "memory", 0, "Memory in MiB (overrides --size)")
 	startCmd.Flags().IntVar(&startFlagDisk, "disk", 0, "Disk size in GB (overrides --size)")
 	startCmd.Flags().StringVar(&startFlagImage, "image", "", "Container image (e.g., ubuntu:22.04)")
+	startCmd.Flags().StringVar(&startFlagSnapshot, "snapshot", "", "Start from a team snapshot (see 'cloudrouter snapshot list')")
+	startCmd.RegisterFlagCompletionFunc("snapshot", completeSnapshotIDs)
 	startCmd.Flags().IntVar(&startFlagTimeout, "timeout", 600, "Sandbox timeout in seconds (default: 10 minutes)")
+	startCmd.Flags().StringArrayVarP(&startFlagEnv, "env", "e", nil, "Set an environment variable KEY=VALUE (can be repeated; KEY alone copies the local value)")
+	startCmd.Flags().StringVar(&startFlagEnvFile, "env-file", "", "Read environment variables from a .env file")
+	startCmd.Flags().StringArrayVar(&startFlagSecrets, "secret", nil, "Inject a team secret as an environment variable (can be repeated; see 'cloudrouter secrets')")
+	startCmd.Flags().BoolVar(&startFlagWithSecrets, "with-secrets", false, "Inject all of your team's secrets as environment variables")
+	startCmd.MarkFlagsMutuallyExclusive("secret", "with-secrets")
+	startCmd.Flags().BoolVar(&startFlagNoDevcontainer, "no-devcontainer", false, "Ignore .devcontainer/devcontainer.json in the path or repo")
 }
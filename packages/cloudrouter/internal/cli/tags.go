@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var tagFlagRemove []string
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <id> <name>",
+	Short: "Rename a sandbox",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimSpace(args[1])
+		if name == "" {
+			return fmt.Errorf("name can't be empty")
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		if err := client.RenameInstance(teamSlug, args[0], name); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed: %s -> %s\n", args[0], name)
+		return nil
+	},
+}
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <id> [key=value...]",
+	Short: "Set, remove, or show sandbox tags",
+	Long: `Label a sandbox with key=value tags. Tags are shown by 'cloudrouter ls'
+and can be used to filter it with --tag.
+
+With no key=value arguments and no --rm, prints the sandbox's tags.
+
+Examples:
+  cloudrouter tag cr_abc123 project=api owner=alice   # Set tags
+  cloudrouter tag cr_abc123 --rm owner                # Remove a tag
+  cloudrouter tag cr_abc123                           # Show tags
+  cloudrouter ls --tag project=api                    # List sandboxes by tag`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		set, err := parseTags(args[1:])
+		if err != nil {
+			return err
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+
+		var tags map[string]string
+		if len(set) == 0 && len(tagFlagRemove) == 0 {
+			inst, err := client.GetInstance(teamSlug, args[0])
+			if err != nil {
+				return fmt.Errorf("sandbox not found: %w", err)
+			}
+			tags = inst.Tags
+		} else {
+			tags, err = client.UpdateTags(teamSlug, args[0], set, tagFlagRemove)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(tags) == 0 {
+			fmt.Println("No tags")
+			return nil
+		}
+		for _, key := range sortedTagKeys(tags) {
+			fmt.Printf("%s=%s\n", key, tags[key])
+		}
+		return nil
+	},
+}
+
+// parseTags parses key=value arguments into a map.
+func parseTags(args []string) (map[string]string, error) {
+	tags := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", arg)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
+
+// matchesTags reports whether tags contains every key=value in filter.
+func matchesTags(tags, filter map[string]string) bool {
+	for key, value := range filter {
+		if got, ok := tags[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatTags renders tags as a stable, comma-separated key=value list.
+func formatTags(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for _, key := range sortedTagKeys(tags) {
+		parts = append(parts, key+"="+tags[key])
+	}
+	return strings.Join(parts, ",")
+}
+
+func init() {
+	tagCmd.Flags().StringArrayVar(&tagFlagRemove, "rm", nil, "Tag key to remove (can be repeated)")
+}
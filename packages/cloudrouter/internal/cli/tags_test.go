@@ -0,0 +1,46 @@
+package cli
+
+import "testing"
+
+func TestParseTags(t *testing.T) {
+	tags, err := parseTags([]string{"project=api", "owner=", "note=a=b"})
+	if err != nil {
+		t.Fatalf("parseTags() error = %v", err)
+	}
+	want := map[string]string{"project": "api", "owner": "", "note": "a=b"}
+	if len(tags) != len(want) {
+		t.Fatalf("parseTags() = %v, want %v", tags, want)
+	}
+	for key, value := range want {
+		if tags[key] != value {
+			t.Errorf("tags[%q] = %q, want %q", key, tags[key], value)
+		}
+	}
+
+	for _, arg := range []string{"project", "=api"} {
+		if _, err := parseTags([]string{arg}); err == nil {
+			t.Errorf("parseTags(%q) expected error", arg)
+		}
+	}
+}
+
+func TestMatchesTags(t *testing.T) {
+	tags := map[string]string{"project": "api", "owner": "alice"}
+
+	tests := []struct {
+		filter   map[string]string
+		expected bool
+	}{
+		{nil, true},
+		{map[string]string{"project": "api"}, true},
+		{map[string]string{"project": "api", "owner": "alice"}, true},
+		{map[string]string{"project": "web"}, false},
+		{map[string]string{"env": "prod"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesTags(tags, tt.filter); got != tt.expected {
+			t.Errorf("matchesTags(%v) = %v, want %v", tt.filter, got, tt.expected)
+		}
+	}
+}
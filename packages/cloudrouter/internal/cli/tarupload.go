@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+)
+
+// Upload modes for directories: a single tar stream is much faster for fresh
+// uploads of many small files, while rsync only sends what changed when the
+// target already has a copy.
+const (
+	uploadModeAuto  = "auto"
+	uploadModeTar   = "tar"
+	uploadModeRsync = "rsync"
+)
+
+// Compression for tar stream uploads
+const (
+	compressNone = "none"
+	compressGzip = "gzip"
+	compressZstd = "zstd"
+)
+
+// tarStats counts what a tar stream upload sent
+type tarStats struct {
+	files int64
+	bytes int64
+}
+
+// remoteDirHasFiles reports whether remotePath exists and is not empty
+func remoteDirHasFiles(client *api.Client, teamSlug, id, remotePath string) (bool, error) {
+	command := fmt.Sprintf(`[ -n "$(ls -A %s 2>/dev/null)" ] && echo yes || echo no`, shellQuote(remotePath))
+	resp, err := client.Exec(teamSlug, id, command, 30)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(resp.Stdout) == "yes", nil
+}
+
+// chooseUploadMode resolves --mode auto: rsync when the target already has
+// files (delta transfer) or when rsync-only flags are set, tar otherwise
+func chooseUploadMode(mode string, needsRsync bool, targetHasFiles func() (bool, error)) (string, error) {
+	switch mode {
+	case uploadModeRsync:
+		return mode, nil
+	case uploadModeTar:
+		if needsRsync {
+			return "", fmt.Errorf("--delete and --dry-run require --mode rsync")
+		}
+		return mode, nil
+	case uploadModeAuto, "":
+		if needsRsync {
+			return uploadModeRsync, nil
+		}
+		hasFiles, err := targetHasFiles()
+		if err != nil || hasFiles {
+			return uploadModeRsync, nil
+		}
+		return uploadModeTar, nil
+	}
+	return "", fmt.Errorf("invalid --mode %q (must be auto, tar, or rsync)", mode)
+}
+
+// remoteExtractCommand builds the sandbox command that unpacks the stream
+func remoteExtractCommand(remotePath, compress string) string {
+	dest := shellQuote(remotePath)
+	switch compress {
+	case compressGzip:
+		return fmt.Sprintf("mkdir -p %s && tar --no-same-owner -xzf - -C %s", dest, dest)
+	case compressZstd:
+		return fmt.Sprintf("mkdir -p %s && zstd -d -q -c | tar --no-same-owner -xf - -C %s", dest, dest)
+	default:
+		return fmt.Sprintf("mkdir -p %s && tar --no-same-owner -xf - -C %s", dest, dest)
+	}
+}
+
+// writeTarStream writes localPath's contents to w as a tar archive, skipping
+// the same entries rsync uploads exclude
+func writeTarStream(w io.Writer, localPath string, stats *tarStats) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(localPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == localPath {
+			return nil
+		}
+		if shouldExcludeEntry(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		} else if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil // sockets, devices, and pipes can't be uploaded
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localPath, path)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		// Ownership comes from the sandbox user, not this machine
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if runtime.GOOS == "windows" && !info.IsDir() && link == "" {
+			hdr.Mode = 0644
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(tw, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		stats.files++
+		stats.bytes += n
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// runTarUpload streams localPath to remotePath as a single tar archive over
+// the WebSocket SSH tunnel, extracted in the sandbox as it arrives
+func runTarUpload(workerURL, token, localPath, remotePath, compress string) error {
+	if compress == compressZstd {
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return fmt.Errorf("zstd not found locally. Install it or use --compress gzip")
+		}
+	}
+
+	sshCmd, cleanup, err := buildSSHProxyCommand(toWebSocketURL(workerURL, token))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	sshExec := exec.Command(sshCmd, token+"@e2b-sandbox", remoteExtractCommand(remotePath, compress))
+	var stderr bytes.Buffer
+	sshExec.Stderr = &stderr
+	sshIn, err := sshExec.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	// tar -> [compressor] -> ssh stdin
+	var compressor *exec.Cmd
+	var archiveOut io.WriteCloser = sshIn
+	if compress == compressZstd {
+		compressor = exec.Command("zstd", "-q", "-c", "-T0")
+		compressor.Stdout = sshIn
+		compressor.Stderr = &stderr
+		if archiveOut, err = compressor.StdinPipe(); err != nil {
+			return err
+		}
+	}
+
+	startTime := time.Now()
+	if err := sshExec.Start(); err != nil {
+		return fmt.Errorf("failed to start ssh: %w", err)
+	}
+	if compressor != nil {
+		if err := compressor.Start(); err != nil {
+			sshIn.Close()
+			sshExec.Wait()
+			return fmt.Errorf("failed to start zstd: %w", err)
+		}
+	}
+
+	var stats tarStats
+	writeErr := func() error {
+		if compress == compressGzip {
+			gz := gzip.NewWriter(archiveOut)
+			if err := writeTarStream(gz, localPath, &stats); err != nil {
+				return err
+			}
+			if err := gz.Close(); err != nil {
+				return err
+			}
+		} else if err := writeTarStream(archiveOut, localPath, &stats); err != nil {
+			return err
+		}
+		return archiveOut.Close()
+	}()
+	if writeErr != nil {
+		archiveOut.Close()
+	}
+	if compressor != nil {
+		if err := compressor.Wait(); err != nil && writeErr == nil {
+			writeErr = fmt.Errorf("zstd failed: %w", err)
+		}
+		sshIn.Close()
+	}
+	sshErr := sshExec.Wait()
+
+	if sshErr != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("upload failed: %s", msg)
+		}
+		return fmt.Errorf("upload failed: %w", sshErr)
+	}
+	if writeErr != nil {
+		return fmt.Errorf("upload failed: %w", writeErr)
+	}
+
+	elapsed := time.Since(startTime)
+	speedMBps := float64(stats.bytes) / elapsed.Seconds() / 1024 / 1024
+	fmt.Printf("✓ Uploaded %d files (%.1f MB) in %.1fs (%.1f MB/s)\n",
+		stats.files, float64(stats.bytes)/1024/1024, elapsed.Seconds(), speedMBps)
+	return nil
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWriteTarStream(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":             "package main\n",
+		"src/app/index.ts":    "export {}\n",
+		"node_modules/x/a.js": "skipped\n",
+		"debug.log":           "skipped\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	var stats tarStats
+	if err := writeTarStream(&buf, dir, &stats); err != nil {
+		t.Fatalf("writeTarStream() error = %v", err)
+	}
+
+	var names []string
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading archive: %v", err)
+		}
+		if hdr.Uid != 0 || hdr.Uname != "" {
+			t.Errorf("%s: ownership not cleared", hdr.Name)
+		}
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+
+	want := []string{"main.go", "src/", "src/app/", "src/app/index.ts"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("archive entries = %v, want %v", names, want)
+	}
+	if stats.files != 2 || stats.bytes != int64(len("package main\n")+len("export {}\n")) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestChooseUploadMode(t *testing.T) {
+	empty := func() (bool, error) { return false, nil }
+	populated := func() (bool, error) { return true, nil }
+	failing := func() (bool, error) { return false, errors.New("exec failed") }
+
+	tests := []struct {
+		mode       string
+		needsRsync bool
+		target     func() (bool, error)
+		expected   string
+		wantErr    bool
+	}{
+		{uploadModeAuto, false, empty, uploadModeTar, false},
+		{uploadModeAuto, false, populated, uploadModeRsync, false},
+		{uploadModeAuto, false, failing, uploadModeRsync, false},
+		{uploadModeAuto, true, empty, uploadModeRsync, false},
+		{uploadModeTar, false, populated, uploadModeTar, false},
+		{uploadModeTar, true, empty, "", true},
+		{uploadModeRsync, false, empty, uploadModeRsync, false},
+		{"scp", false, empty, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := chooseUploadMode(tt.mode, tt.needsRsync, tt.target)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("chooseUploadMode(%q, %v) = %q, %v; want %q", tt.mode, tt.needsRsync, got, err, tt.expected)
+		}
+	}
+}
+
+func TestRemoteExtractCommand(t *testing.T) {
+	if got, want := remoteExtractCommand("/home/user/my app", compressNone),
+		"mkdir -p '/home/user/my app' && tar --no-same-owner -xf - -C '/home/user/my app'"; got != want {
+		t.Errorf("remoteExtractCommand(none) = %q, want %q", got, want)
+	}
+	if got, want := remoteExtractCommand("/w", compressZstd),
+		"mkdir -p '/w' && zstd -d -q -c | tar --no-same-owner -xf - -C '/w'"; got != want {
+		t.Errorf("remoteExtractCommand(zstd) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/spf13/cobra"
+)
+
+var teamListFlagJSON bool
+
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "List your teams and switch between them",
+	Long: `List the teams you belong to and switch the team commands use, instead
+of passing -t on every invocation.
+
+Examples:
+  cloudrouter team list
+  cloudrouter team switch acme`,
+}
+
+var teamListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List your teams",
+	Long: `List the teams you belong to with your role in each. The team commands
+use is marked with '*'.
+
+Examples:
+  cloudrouter team list
+  cloudrouter team list --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := api.NewClient()
+		teams, err := client.ListTeams()
+		if err != nil {
+			return fmt.Errorf("failed to list teams: %w", err)
+		}
+
+		if teamListFlagJSON {
+			if teams == nil {
+				teams = []api.Team{}
+			}
+			out, err := json.MarshalIndent(teams, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		activeTeam, _ := getTeamSlug()
+		fmt.Printf("  %-24s %-24s %s\n", "SLUG", "NAME", "ROLE")
+		for _, team := range teams {
+			marker := " "
+			if teamMatches(team, activeTeam) {
+				marker = "*"
+			}
+			fmt.Printf("%s %-24s %-24s %s\n", marker, team.SlugOrID(), orDash(team.DisplayName), team.Role)
+		}
+		return nil
+	},
+}
+
+var teamSwitchCmd = &cobra.Command{
+	Use:   "switch <slug>",
+	Short: "Switch the team commands use",
+	Long: `Make a team your selected team, so commands use it without -t. The
+selection is saved on the server, so it also applies to the web app and
+your other machines.
+
+Examples:
+  cloudrouter team switch acme
+  cloudrouter --profile work team switch platform`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := api.NewClient()
+		team, err := client.SelectTeam(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to switch to team %s: %w", args[0], err)
+		}
+
+		fmt.Printf("✓ Switched to team %s\n", team.SlugOrID())
+		return nil
+	},
+}
+
+// teamMatches reports whether team is the one named by slugOrID
+func teamMatches(team api.Team, slugOrID string) bool {
+	return slugOrID != "" && (team.Slug == slugOrID || team.ID == slugOrID)
+}
+
+func init() {
+	teamListCmd.Flags().BoolVar(&teamListFlagJSON, "json", false, "Output teams as JSON")
+
+	teamCmd.AddCommand(teamListCmd)
+	teamCmd.AddCommand(teamSwitchCmd)
+}
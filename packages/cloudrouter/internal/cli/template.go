@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// How often to poll for new build log lines
+	templateBuildPollInterval = 2 * time.Second
+	// Builds run inside a single backend action, which is capped at 10 minutes
+	templateBuildTimeout = 12 * time.Minute
+)
+
+var (
+	templateBuildFlagName string
+	templateListFlagJSON  bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Build and manage custom team templates",
+	Long: `Bake your team's toolchain into a custom template built from a Dockerfile,
+then start sandboxes from it with 'cloudrouter start -T <template-id>'.
+
+Templates are built on top of the cloudrouter base image by replaying the
+Dockerfile's RUN, ENV, ARG, and WORKDIR instructions, so FROM is ignored and
+COPY/ADD aren't supported (fetch files with curl or git in a RUN step).
+ENV values apply to login shells in sandboxes started from the template.
+Builds must finish within 10 minutes.
+
+Examples:
+  cloudrouter template build ./Dockerfile --name my-stack  # Build a template
+  cloudrouter template list                                # List team templates
+  cloudrouter template delete tpl_abc123                   # Delete a template
+  cloudrouter start -T tpl_abc123                          # Start from a template`,
+}
+
+var templateBuildCmd = &cobra.Command{
+	Use:   "build <Dockerfile>",
+	Short: "Build a custom template from a Dockerfile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if templateBuildFlagName == "" {
+			return fmt.Errorf("--name is required")
+		}
+
+		dockerfile, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read Dockerfile: %w", err)
+		}
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		build, err := client.BuildTemplate(teamSlug, templateBuildFlagName, string(dockerfile))
+		if err != nil {
+			return err
+		}
+
+		for _, warning := range build.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		output.Infof("Building template %s (%s)...\n", build.ID, build.Name)
+
+		final, err := streamTemplateBuild(client, teamSlug, build.ID)
+		if err != nil {
+			return err
+		}
+		if final.Status != "ready" {
+			if final.Error != "" {
+				return fmt.Errorf("template build failed: %s", final.Error)
+			}
+			return fmt.Errorf("template build failed")
+		}
+
+		fmt.Printf("Template ready: %s (%s)\n", final.ID, final.Name)
+		fmt.Printf("Start from it with: cloudrouter start -T %s\n", final.ID)
+		return nil
+	},
+}
+
+// streamTemplateBuild prints build log lines as they arrive and returns the
+// template once the build has finished.
+func streamTemplateBuild(client *api.Client, teamSlug, id string) (*api.TemplateBuild, error) {
+	deadline := time.Now().Add(templateBuildTimeout)
+	after := 0
+	for {
+		build, err := client.GetTemplateBuild(teamSlug, id, after)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range build.Logs {
+			output.Infoln(line.Text)
+			after = line.Seq
+		}
+		// Keep reading until the log is drained; the final lines may arrive
+		// in the same poll that reports the build as finished.
+		if build.Status != "building" && len(build.Logs) == 0 {
+			return build, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for template %s to build; check 'cloudrouter template list' later", id)
+		}
+		if len(build.Logs) == 0 {
+			time.Sleep(templateBuildPollInterval)
+		}
+	}
+}
+
+var templateListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List custom team templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		templates, err := client.ListTemplates(teamSlug, "modal")
+		if err != nil {
+			return err
+		}
+
+		custom := []api.Template{}
+		for _, t := range templates {
+			if t.Custom {
+				custom = append(custom, t)
+			}
+		}
+
+		if templateListFlagJSON {
+			out, err := json.MarshalIndent(custom, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
+		if len(custom) == 0 {
+			fmt.Println("No custom templates found")
+			return nil
+		}
+
+		fmt.Println("Templates:")
+		for _, t := range custom {
+			created := time.UnixMilli(t.CreatedAt).Format("2006-01-02 15:04")
+			fmt.Printf("  %s - %s [%s, %s]\n", t.ID, t.Name, t.Status, created)
+		}
+		return nil
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:   "delete <template-id>",
+	Short: "Delete a custom team template",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		if err := client.DeleteTemplate(teamSlug, args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted template: %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	templateBuildCmd.Flags().StringVarP(&templateBuildFlagName, "name", "n", "", "Name for the template (required)")
+	templateListCmd.Flags().BoolVar(&templateListFlagJSON, "json", false, "Output templates as JSON")
+
+	templateCmd.AddCommand(templateBuildCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateDeleteCmd)
+}
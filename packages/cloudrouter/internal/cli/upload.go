@@ -5,9 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +17,8 @@ var (
 	uploadFlagDelete     bool
 	uploadFlagExclude    []string
 	uploadFlagDryRun     bool
+	uploadFlagMode       string
+	uploadFlagCompress   string
 )
 
 var uploadCmd = &cobra.Command{
@@ -27,13 +29,20 @@ var uploadCmd = &cobra.Command{
 The local path defaults to the current directory if not specified.
 The remote path defaults to /home/user/workspace if not specified.
 
+Directories are sent as a single tar stream, extracted in the sandbox as it
+arrives, which is much faster than per-file transfer for large trees. When
+the remote directory already has files, rsync is used instead so only the
+changes are sent. Use --mode to force either, and --compress to compress the
+tar stream (zstd needs the zstd binary locally).
+
 Examples:
   cloudrouter upload cr_abc123                           # Upload current dir to workspace
   cloudrouter upload cr_abc123 ./my-project              # Upload specific directory
   cloudrouter upload cr_abc123 ./config.json             # Upload single file
   cloudrouter upload cr_abc123 . -r /home/user/app       # Upload to specific remote path
   cloudrouter upload cr_abc123 . --watch                 # Watch and upload on changes
-  cloudrouter upload cr_abc123 . --delete                # Delete remote files not present locally`,
+  cloudrouter upload cr_abc123 . --delete                # Delete remote files not present locally
+  cloudrouter upload cr_abc123 . --mode tar --compress zstd  # Force a compressed tar stream`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sandboxID := args[0]
@@ -82,9 +91,23 @@ Examples:
 
 		if info.IsDir() {
 			if uploadFlagWatch {
-				return watchAndUpload(inst.WorkerURL, token, absPath, remotePath, sandboxID)
+				return runWatchLoop(inst.WorkerURL, token, absPath, remotePath, defaultWatchDebounce)
+			}
+			switch uploadFlagCompress {
+			case compressNone, compressGzip, compressZstd:
+			default:
+				return fmt.Errorf("invalid --compress %q (must be none, gzip, or zstd)", uploadFlagCompress)
+			}
+			mode, err := chooseUploadMode(uploadFlagMode, uploadFlagDelete || uploadFlagDryRun, func() (bool, error) {
+				return remoteDirHasFiles(client, teamSlug, sandboxID, remotePath)
+			})
+			if err != nil {
+				return err
+			}
+			output.Infof("Uploading %s to %s:%s (%s)...\n", absPath, sandboxID, remotePath, mode)
+			if mode == uploadModeTar {
+				return runTarUpload(inst.WorkerURL, token, absPath, remotePath, uploadFlagCompress)
 			}
-			fmt.Printf("Uploading %s to %s:%s...\n", absPath, sandboxID, remotePath)
 			return runRsyncOverWebSocket(inst.WorkerURL, token, absPath, remotePath)
 		}
 
@@ -99,36 +122,17 @@ Examples:
 			fileRemotePath += "/"
 		}
 
-		fmt.Printf("Uploading %s to %s:%s...\n", filepath.Base(absPath), sandboxID, fileRemotePath)
+		output.Infof("Uploading %s to %s:%s...\n", filepath.Base(absPath), sandboxID, fileRemotePath)
 		return runRsyncSingleFile(inst.WorkerURL, token, absPath, fileRemotePath)
 	},
 }
 
-func watchAndUpload(workerURL, token, localPath, remotePath, sandboxID string) error {
-	fmt.Printf("Watching %s for changes (Ctrl+C to stop)...\n", localPath)
-
-	// Initial upload
-	fmt.Println("Initial upload...")
-	if err := runRsyncOverWebSocket(workerURL, token, localPath, remotePath); err != nil {
-		fmt.Printf("Initial upload error: %v\n", err)
-	}
-
-	fmt.Println("Polling for changes every 2 seconds...")
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		if err := runRsyncOverWebSocket(workerURL, token, localPath, remotePath); err != nil {
-			fmt.Printf("Upload error: %v\n", err)
-		}
-	}
-	return nil
-}
-
 func init() {
 	uploadCmd.Flags().StringVarP(&uploadFlagRemotePath, "remote-path", "r", "/home/user/workspace", "Remote path to upload to")
 	uploadCmd.Flags().BoolVarP(&uploadFlagWatch, "watch", "w", false, "Watch for changes and upload continuously")
 	uploadCmd.Flags().BoolVar(&uploadFlagDelete, "delete", false, "Delete remote files not present locally")
 	uploadCmd.Flags().StringSliceVarP(&uploadFlagExclude, "exclude", "e", nil, "Patterns to exclude")
 	uploadCmd.Flags().BoolVarP(&uploadFlagDryRun, "dry-run", "n", false, "Perform a trial run with no changes made")
+	uploadCmd.Flags().StringVar(&uploadFlagMode, "mode", uploadModeAuto, "Directory transfer: auto, tar (single stream), or rsync (delta)")
+	uploadCmd.Flags().StringVar(&uploadFlagCompress, "compress", compressNone, "Compress tar stream uploads: none, gzip, or zstd")
 }
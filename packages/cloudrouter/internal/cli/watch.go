@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/manaflow-ai/cloudrouter/internal/api"
+	"github.com/manaflow-ai/cloudrouter/internal/output"
+	"github.com/manaflow-ai/fswatch"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultWatchDebounce   = 300 * time.Millisecond
+	defaultWatchIgnoreFile = ".cloudrouterignore"
+)
+
+var (
+	watchFlagRemotePath  string
+	watchFlagDelete      bool
+	watchFlagExclude     []string
+	watchFlagExcludeFile string
+	watchFlagDebounce    time.Duration
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <id> [local-path]",
+	Short: "Continuously sync local changes to a sandbox",
+	Long: `Upload a directory to a sandbox, then keep pushing local changes as you
+edit. Changes are batched until the directory has been quiet for the
+debounce interval, and only the top-level entries that changed are synced.
+
+Patterns in .cloudrouterignore (one per line, "#" for comments) are
+excluded along with --exclude and the default excludes.
+
+Examples:
+  cloudrouter watch cr_abc123                  # Watch the current directory
+  cloudrouter watch cr_abc123 ./my-project     # Watch a specific directory
+  cloudrouter watch cr_abc123 . --delete       # Also remove deleted files remotely
+  cloudrouter watch cr_abc123 . -e "*.csv"     # Exclude more patterns`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sandboxID := args[0]
+		localPath := "."
+		if len(args) > 1 {
+			localPath = args[1]
+		}
+
+		absPath, err := filepath.Abs(localPath)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("path not found: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", localPath)
+		}
+
+		excludes := append([]string{}, watchFlagExclude...)
+		ignorePath := watchFlagExcludeFile
+		if !filepath.IsAbs(ignorePath) {
+			ignorePath = filepath.Join(absPath, ignorePath)
+		}
+		filePatterns, err := readExcludeFile(ignorePath)
+		if err != nil {
+			return err
+		}
+		excludes = append(excludes, filePatterns...)
+
+		teamSlug, err := getTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client := api.NewClient()
+		inst, err := client.GetInstance(teamSlug, sandboxID)
+		if err != nil {
+			return fmt.Errorf("sandbox not found: %w", err)
+		}
+		if inst.WorkerURL == "" {
+			return fmt.Errorf("worker URL not available")
+		}
+
+		token, err := client.GetAuthToken(teamSlug, sandboxID)
+		if err != nil {
+			return fmt.Errorf("failed to get auth token: %w", err)
+		}
+
+		// Set rsync flags
+		rsyncFlagDelete = watchFlagDelete
+		rsyncFlagDryRun = false
+		rsyncFlagVerbose = false
+		rsyncFlagExclude = excludes
+
+		return runWatchLoop(inst.WorkerURL, token, absPath, watchFlagRemotePath, watchFlagDebounce)
+	},
+}
+
+// readExcludeFile returns the patterns in an exclude file, skipping blank
+// lines and "#" comments. A missing file has no patterns.
+func readExcludeFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exclude file: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(line, "/"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read exclude file: %w", err)
+	}
+	return patterns, nil
+}
+
+// changedEntries returns the sorted top-level entries of root that contain
+// the changed paths, and whether the whole tree must be synced: a removed
+// top-level entry can't be named as a source, and fswatch.All means changes
+// may have been missed
+func changedEntries(root string, changed map[string]bool) (entries []string, whole bool) {
+	tops := make(map[string]bool)
+	for rel := range changed {
+		if rel == fswatch.All {
+			whole = true
+			continue
+		}
+		top, _, _ := strings.Cut(rel, "/")
+		tops[top] = true
+	}
+	for top := range tops {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(top))); err != nil {
+			whole = true
+		}
+		entries = append(entries, top)
+	}
+	sort.Strings(entries)
+	return entries, whole
+}
+
+// runWatchLoop syncs localPath once, then pushes each batch of changes once
+// the tree has been quiet for debounce. It runs until interrupted.
+func runWatchLoop(workerURL, token, localPath, remotePath string, debounce time.Duration) error {
+	// Watch before the initial sync, so edits made during it are synced
+	watcher, err := fswatch.New(localPath, fswatch.Options{
+		Skip: func(name string, _ bool) bool { return shouldExcludeEntry(name) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", localPath, err)
+	}
+	defer watcher.Close()
+
+	output.Infof("Syncing %s to %s...\n", localPath, remotePath)
+	if err := runRsyncOverWebSocket(workerURL, token, localPath, remotePath); err != nil {
+		fmt.Printf("Initial sync error: %v\n", err)
+	}
+
+	output.Infof("Watching %s for changes (Ctrl+C to stop)...\n", localPath)
+
+	interruptCh := make(chan os.Signal, 1)
+	signal.Notify(interruptCh, os.Interrupt)
+	defer signal.Stop(interruptCh)
+
+	pending := make(map[string]bool)
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-interruptCh:
+			fmt.Println("\nStopped watching")
+			return nil
+		case err := <-watcher.Errors():
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		case rel := <-watcher.Changes():
+			pending[rel] = true
+			settled = time.After(debounce)
+			continue
+		case <-settled:
+			settled = nil
+		}
+
+		entries, whole := changedEntries(localPath, pending)
+		items := entries
+		if whole {
+			items = nil
+		}
+
+		start := time.Now()
+		stats, err := runSingleRsync(workerURL, token, localPath, remotePath, items)
+		if err != nil {
+			fmt.Printf("Sync error: %v\n", err)
+			// Retry after another debounce interval
+			settled = time.After(debounce)
+			continue
+		}
+		clear(pending)
+
+		label := strings.Join(entries, ", ")
+		if len(entries) > 3 {
+			label = fmt.Sprintf("%s, ... (%d entries)", strings.Join(entries[:3], ", "), len(entries))
+		}
+		if label == "" {
+			label = "all files"
+		}
+		if stats != nil && stats.files > 0 {
+			fmt.Printf("[%s] ✓ Synced %d files (%.1f KB) in %.1fs: %s\n",
+				time.Now().Format("15:04:05"), stats.files, float64(stats.bytes)/1024,
+				time.Since(start).Seconds(), label)
+		} else {
+			fmt.Printf("[%s] ✓ Synced: %s\n", time.Now().Format("15:04:05"), label)
+		}
+	}
+}
+
+func init() {
+	watchCmd.Flags().StringVarP(&watchFlagRemotePath, "remote-path", "r", "/home/user/workspace", "Remote path to sync to")
+	watchCmd.Flags().BoolVar(&watchFlagDelete, "delete", false, "Delete remote files not present locally")
+	watchCmd.Flags().StringSliceVarP(&watchFlagExclude, "exclude", "e", nil, "Patterns to exclude")
+	watchCmd.Flags().StringVar(&watchFlagExcludeFile, "exclude-file", defaultWatchIgnoreFile, "File of patterns to exclude, relative to the watched directory")
+	watchCmd.Flags().DurationVar(&watchFlagDebounce, "debounce", defaultWatchDebounce, "Wait this long after the last change before syncing")
+}
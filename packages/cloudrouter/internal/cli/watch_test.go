@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/manaflow-ai/fswatch"
+)
+
+func TestChangedEntries(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src", "old"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("hi"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		changed     []string
+		wantEntries []string
+		wantWhole   bool
+	}{
+		{
+			name: "nothing changed",
+		},
+		{
+			name:        "nested files map to their top-level entry",
+			changed:     []string{"src/main.go", "src/pkg/util.go", "src"},
+			wantEntries: []string{"src"},
+		},
+		{
+			name:        "nested file removed keeps its directory",
+			changed:     []string{"old/a.txt", "README.md"},
+			wantEntries: []string{"README.md", "old"},
+		},
+		{
+			name:        "top-level entry removed",
+			changed:     []string{"gone.txt", "src/main.go"},
+			wantEntries: []string{"gone.txt", "src"},
+			wantWhole:   true,
+		},
+		{
+			name:      "missed changes",
+			changed:   []string{fswatch.All},
+			wantWhole: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := make(map[string]bool)
+			for _, rel := range tt.changed {
+				changed[rel] = true
+			}
+			entries, whole := changedEntries(root, changed)
+			if !reflect.DeepEqual(entries, tt.wantEntries) {
+				t.Errorf("entries = %v, want %v", entries, tt.wantEntries)
+			}
+			if whole != tt.wantWhole {
+				t.Errorf("whole = %v, want %v", whole, tt.wantWhole)
+			}
+		})
+	}
+}
@@ -222,6 +222,8 @@ func IsLongRunningCommand(cmdName string) bool {
 		"pty":   true,
 		"sync":  true,
 		"start": true,
+		"clone": true,
+		"watch": true,
 	}
 	return longRunningCmds[cmdName]
 }
@@ -95,6 +95,8 @@ func TestIsLongRunningCommand(t *testing.T) {
 		{"pty", true},
 		{"sync", true},
 		{"start", true},
+		{"watch", true},
+		{"clone", true},
 		{"ls", false},
 		{"exec", false},
 		{"version", false},
@@ -2,6 +2,11 @@
 # Start all services for the cmux E2B sandbox (Docker-enabled version)
 # Services: Docker, cmux-code (VSCode), Chrome CDP, VNC, noVNC, worker daemon (Go)
 
+# Service output goes to /tmp/<service>.log (same files as Modal sandboxes)
+# so `cloudrouter logs` can tail it; this script's own output goes to
+# /tmp/start-services.log
+exec > >(tee -a /tmp/start-services.log) 2>&1
+
 echo "[cmux-e2b] Starting services (Docker-enabled)..."
 
 # Always generate a fresh auth token on startup (security: each instance gets unique token)
@@ -38,7 +43,7 @@ echo "[cmux-e2b] VNC auth handled by token proxy (no VNC password needed)"
 
 # Start Docker daemon
 echo "[cmux-e2b] Starting Docker daemon..."
-sudo dockerd --host=unix:///var/run/docker.sock --host=tcp://0.0.0.0:2375 &
+sudo dockerd --host=unix:///var/run/docker.sock --host=tcp://0.0.0.0:2375 > /tmp/dockerd.log 2>&1 &
 # Wait for Docker to be ready
 for i in {1..30}; do
     if docker info >/dev/null 2>&1; then
@@ -56,7 +61,7 @@ sudo dbus-daemon --system --fork 2>/dev/null || true
 # Start VNC server on display :1 (port 5901) - localhost only, auth handled by proxy on 39380
 echo "[cmux-e2b] Starting VNC server on display :1 (localhost only - auth via proxy)..."
 rm -f /tmp/.X1-lock /tmp/.X11-unix/X1 2>/dev/null || true
-vncserver :1 -geometry 1920x1080 -depth 24 -SecurityTypes None -localhost yes 2>/dev/null &
+vncserver :1 -geometry 1920x1080 -depth 24 -SecurityTypes None -localhost yes > /tmp/tigervnc.log 2>&1 &
 sleep 3
 
 # VNC auth proxy on port 39380 is now part of the Go worker daemon
@@ -70,7 +75,7 @@ echo "[cmux-e2b] Starting cmux-code on port 39378 (token-protected)..."
     --connection-token-file "$VSCODE_TOKEN_FILE" \
     --disable-workspace-trust \
     --disable-telemetry \
-    /home/user/workspace 2>/dev/null &
+    /home/user/workspace > /tmp/cmux-code.log 2>&1 &
 
 # Chrome with CDP is started by VNC xstartup (visible browser)
 # CDP will be available on port 9222 once VNC desktop is up
@@ -93,11 +98,11 @@ echo "[cmux-e2b] Starting JupyterLab on port 8888..."
 jupyter lab --ip=0.0.0.0 --port=8888 --no-browser \
     --ServerApp.token="$AUTH_TOKEN" \
     --ServerApp.root_dir=/home/user/workspace \
-    --allow-root 2>/dev/null &
+    --allow-root > /tmp/jupyter.log 2>&1 &
 
 # Start worker daemon on port 39377 (Go binary)
 echo "[cmux-e2b] Starting worker daemon on port 39377..."
-/usr/local/bin/worker-daemon &
+/usr/local/bin/worker-daemon > /tmp/worker-daemon.log 2>&1 &
 
 echo "[cmux-e2b] All services started!"
 echo "[cmux-e2b] Services:"
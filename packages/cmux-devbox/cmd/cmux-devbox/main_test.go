@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+)
+
+// buildCLI builds the cmux-devbox binary into a temp directory
+func buildCLI(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "cmux-devbox")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", bin, ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// runCLI runs the binary with an empty home directory and returns its
+// stderr and exit code
+func runCLI(t *testing.T, bin string, args ...string) ([]byte, int) {
+	t.Helper()
+	home := t.TempDir()
+	cmd := exec.Command(bin, args...)
+	cmd.Env = append(os.Environ(), "HOME="+home, "USERPROFILE="+home, "XDG_CONFIG_HOME="+home)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stderr.Bytes(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("run %v: %v", args, err)
+	}
+	return stderr.Bytes(), 0
+}
+
+func TestUsageErrorsExitWithUsageCode(t *testing.T) {
+	bin := buildCLI(t)
+
+	tests := [][]string{
+		{"--no-such-flag"},
+		{"no-such-command"},
+		{"autopause"},
+	}
+	for _, args := range tests {
+		if _, code := runCLI(t, bin, args...); code != output.ExitUsage {
+			t.Errorf("%v: exit code = %d, want %d", args, code, output.ExitUsage)
+		}
+	}
+}
+
+func TestUsageErrorIsStructuredWithJSON(t *testing.T) {
+	bin := buildCLI(t)
+
+	stderr, code := runCLI(t, bin, "--json", "autopause")
+	if code != output.ExitUsage {
+		t.Fatalf("exit code = %d, want %d", code, output.ExitUsage)
+	}
+
+	var got struct {
+		Error struct {
+			Code     output.Code `json:"code"`
+			Message  string      `json:"message"`
+			ExitCode int         `json:"exitCode"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(stderr, &got); err != nil {
+		t.Fatalf("stderr is not JSON: %v\n%s", err, stderr)
+	}
+	if got.Error.Code != output.CodeUsage || got.Error.ExitCode != output.ExitUsage || got.Error.Message == "" {
+		t.Errorf("error = %+v, want code %s and exit code %d", got.Error, output.CodeUsage, output.ExitUsage)
+	}
+}
@@ -4,19 +4,25 @@ go 1.24.0
 
 toolchain go1.24.12
 
-require github.com/spf13/cobra v1.8.0
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/manaflow-ai/fswatch v0.0.0
+	github.com/spf13/cobra v1.8.0
+	golang.org/x/sys v0.40.0
+	golang.org/x/term v0.39.0
+)
 
 require (
 	github.com/chromedp/cdproto v0.0.0-20250803210736-d308e07a266d // indirect
 	github.com/chromedp/chromedp v0.14.2 // indirect
 	github.com/chromedp/sysutil v1.1.0 // indirect
+	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/go-json-experiment/json v0.0.0-20250725192818-e39067aee2d2 // indirect
 	github.com/gobwas/httphead v0.1.0 // indirect
 	github.com/gobwas/pool v0.2.1 // indirect
 	github.com/gobwas/ws v1.4.0 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.40.0 // indirect
-	golang.org/x/term v0.39.0 // indirect
 )
+
+replace github.com/manaflow-ai/fswatch => ../fswatch
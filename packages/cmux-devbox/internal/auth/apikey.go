@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// APIKeyEnv holds a long-lived API key for non-interactive use (CI). It takes
+// priority over a key or login stored with 'cmux auth login'.
+const APIKeyEnv = "CMUX_API_KEY"
+
+// ErrInvalidAPIKey is returned when the API key is rejected by the server
+var ErrInvalidAPIKey = errors.New("API key is invalid or revoked")
+
+// apiKeyExchangeResponse is the response from /api/v1/cmux/auth/api-key
+type apiKeyExchangeResponse struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresAt   int64  `json:"expiresAt"`
+}
+
+// apiKeyAccount is the Keychain account holding a stored API key
+func apiKeyAccount() string {
+	return fmt.Sprintf("CMUX_API_KEY_%s%s", GetConfig().ProjectID, ProfileSuffix())
+}
+
+// StoreAPIKey stores an API key for the active profile
+func StoreAPIKey(key string) error {
+	if runtime.GOOS == "darwin" {
+		return keychainSet(apiKeyAccount(), key)
+	}
+	return updateCredentials(func(creds *Credentials) {
+		creds.APIKey = key
+	})
+}
+
+// getStoredAPIKey returns the API key stored for the active profile
+func getStoredAPIKey() (string, error) {
+	if runtime.GOOS == "darwin" {
+		return keychainGet(apiKeyAccount())
+	}
+	creds, err := readCredentials()
+	if err != nil {
+		return "", err
+	}
+	if creds.APIKey == "" {
+		return "", fmt.Errorf("no API key stored")
+	}
+	return creds.APIKey, nil
+}
+
+// DeleteAPIKey removes the stored API key
+func DeleteAPIKey() error {
+	if runtime.GOOS == "darwin" {
+		keychainDelete(apiKeyAccount())
+		return nil
+	}
+	if _, err := readCredentials(); err != nil {
+		return nil
+	}
+	return updateCredentials(func(creds *Credentials) {
+		creds.APIKey = ""
+	})
+}
+
+// APIKey returns the API key in use, from CMUX_API_KEY or the credential
+// store, and where it came from. ok is false when signing in via browser.
+func APIKey() (key, source string, ok bool) {
+	if key := strings.TrimSpace(os.Getenv(APIKeyEnv)); key != "" {
+		return key, APIKeyEnv, true
+	}
+	if key, err := getStoredAPIKey(); err == nil && key != "" {
+		return key, CredentialStoreLocation(), true
+	}
+	return "", "", false
+}
+
+// ExchangeAPIKey trades an API key for a short-lived access token
+func ExchangeAPIKey(key string) (string, int64, error) {
+	cfg := GetConfig()
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	body, err := json.Marshal(map[string]string{"apiKey": key})
+	if err != nil {
+		return "", 0, err
+	}
+	exchangeURL := fmt.Sprintf("%s/api/v1/cmux/auth/api-key", cfg.ConvexSiteURL)
+	req, err := http.NewRequest("POST", exchangeURL, strings.NewReader(string(body)))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to exchange API key: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", 0, ErrInvalidAPIKey
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", 0, fmt.Errorf("failed to exchange API key: status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var exchangeResp apiKeyExchangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&exchangeResp); err != nil {
+		return "", 0, fmt.Errorf("failed to decode API key exchange response: %w", err)
+	}
+	if exchangeResp.AccessToken == "" {
+		return "", 0, fmt.Errorf("API key exchange returned no access token")
+	}
+	if exchangeResp.ExpiresAt == 0 {
+		exchangeResp.ExpiresAt = time.Now().Add(1 * time.Hour).Unix()
+	}
+	return exchangeResp.AccessToken, exchangeResp.ExpiresAt, nil
+}
+
+// LoginWithAPIKey verifies key and stores it for the active profile, so
+// later commands authenticate without a browser
+func LoginWithAPIKey(key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return fmt.Errorf("API key is empty")
+	}
+
+	token, expiresAt, err := ExchangeAPIKey(key)
+	if err != nil {
+		return err
+	}
+	if err := StoreAPIKey(key); err != nil {
+		return fmt.Errorf("failed to store API key: %w", err)
+	}
+	_ = ClearCachedUserProfile()
+	_ = CacheAccessToken(token, expiresAt)
+
+	fmt.Println("✓ API key verified and stored.")
+	if profile, err := FetchUserProfile(); err == nil {
+		if profile.Email != "" {
+			fmt.Printf("  Logged in as: %s\n", profile.Email)
+		} else if profile.Name != "" {
+			fmt.Printf("  Logged in as: %s\n", profile.Name)
+		}
+		if profile.TeamDisplayName != "" {
+			fmt.Printf("  Team: %s\n", profile.TeamDisplayName)
+		} else if profile.TeamSlug != "" {
+			fmt.Printf("  Team: %s\n", profile.TeamSlug)
+		}
+	}
+	return nil
+}
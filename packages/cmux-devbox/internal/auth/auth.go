@@ -4,7 +4,10 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +16,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -132,36 +136,39 @@ func (c Config) Validate() error {
 // GetConfig returns auth configuration using the following priority (highest to lowest):
 // 1. CLI flags (set via SetConfigOverrides)
 // 2. Environment variables
-// 3. Build-time values (set via -ldflags)
-// 4. Mode-specific defaults (dev defaults for Mode=dev, prod defaults for Mode=prod)
+// 3. The active profile (see ActiveProfile)
+// 4. Build-time values (set via -ldflags)
+// 5. Mode-specific defaults (dev defaults for Mode=dev, prod defaults for Mode=prod)
 func GetConfig() Config {
 	// Get mode-specific defaults
 	defaultProjectID, defaultPublishableKey, defaultCmuxURL, defaultConvexSiteURL := getDefaultsForMode()
 
-	// Helper to resolve value with priority: CLI > env > build-time > default
-	resolve := func(cliVal, envKey, buildVal, defaultVal string) string {
+	profile := activeProfileSettings()
+
+	// Helper to resolve value with priority: CLI > env > profile > build-time > default
+	resolve := func(cliVal, envKey, profileVal, buildVal, defaultVal string) string {
 		if cliVal != "" {
 			return cliVal
 		}
 		if envVal := os.Getenv(envKey); envVal != "" {
 			return envVal
 		}
+		if profileVal != "" {
+			return profileVal
+		}
 		if buildVal != "" {
 			return buildVal
 		}
 		return defaultVal
 	}
 
-	projectID := resolve(cliProjectID, "STACK_PROJECT_ID", ProjectID, defaultProjectID)
-	publishableKey := resolve(cliPublishableKey, "STACK_PUBLISHABLE_CLIENT_KEY", PublishableKey, defaultPublishableKey)
-	cmuxURL := resolve(cliCmuxURL, "CMUX_API_URL", CmuxURL, defaultCmuxURL)
-	convexSiteURL := resolve(cliConvexSiteURL, "CONVEX_SITE_URL", ConvexSiteURL, defaultConvexSiteURL)
+	projectID := resolve(cliProjectID, "STACK_PROJECT_ID", profile.ProjectID, ProjectID, defaultProjectID)
+	publishableKey := resolve(cliPublishableKey, "STACK_PUBLISHABLE_CLIENT_KEY", profile.PublishableKey, PublishableKey, defaultPublishableKey)
+	cmuxURL := resolve(cliCmuxURL, "CMUX_API_URL", profile.CmuxURL, CmuxURL, defaultCmuxURL)
+	convexSiteURL := resolve(cliConvexSiteURL, "CONVEX_SITE_URL", profile.ConvexSiteURL, ConvexSiteURL, defaultConvexSiteURL)
 
-	// Stack Auth URL only has env override and hardcoded default
-	stackAuthURL := os.Getenv("AUTH_API_URL")
-	if stackAuthURL == "" {
-		stackAuthURL = StackAuthAPIURL
-	}
+	// Stack Auth URL only has env and profile overrides and a hardcoded default
+	stackAuthURL := resolve("", "AUTH_API_URL", profile.StackAuthURL, "", StackAuthAPIURL)
 
 	// Dev mode is determined by CMUX_DEVBOX_DEV env var (set by main.go based on build mode)
 	isDev := os.Getenv("CMUX_DEVBOX_DEV") == "1" || os.Getenv("CMUX_DEVBOX_DEV") == "true"
@@ -191,7 +198,7 @@ func getCredentialsPath() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "credentials.json"), nil
+	return filepath.Join(configDir, "credentials"+ProfileSuffix()+".json"), nil
 }
 
 // getAccessTokenCachePath returns the path to the access token cache file
@@ -202,9 +209,15 @@ func getAccessTokenCachePath() (string, error) {
 	}
 
 	cfg := GetConfig()
-	filename := "access_token_cache_prod.json"
+	suffix := ProfileSuffix()
+	// Tokens from an API key are cached per key, apart from the browser login
+	if key, _, ok := APIKey(); ok {
+		sum := sha256.Sum256([]byte(key))
+		suffix += "_apikey_" + hex.EncodeToString(sum[:4])
+	}
+	filename := "access_token_cache_prod" + suffix + ".json"
 	if cfg.IsDev {
-		filename = "access_token_cache_dev.json"
+		filename = "access_token_cache_dev" + suffix + ".json"
 	}
 
 	return filepath.Join(configDir, filename), nil
@@ -214,6 +227,7 @@ func getAccessTokenCachePath() (string, error) {
 type Credentials struct {
 	StackRefreshToken string `json:"stack_refresh_token,omitempty"`
 	MorphAPIKey       string `json:"morph_api_key,omitempty"`
+	APIKey            string `json:"api_key,omitempty"`
 }
 
 // StoreRefreshToken stores the Stack Auth refresh token
@@ -240,16 +254,44 @@ func DeleteRefreshToken() error {
 	return deleteFromFile()
 }
 
-// macOS Keychain operations
+// CredentialStoreLocation describes where the refresh token is stored
+func CredentialStoreLocation() string {
+	if runtime.GOOS == "darwin" {
+		return fmt.Sprintf("macOS Keychain (service %q)", KeychainService)
+	}
+	path, err := getCredentialsPath()
+	if err != nil {
+		return "credentials file"
+	}
+	return path
+}
+
+// refreshTokenAccount is the Keychain account holding the refresh token
+func refreshTokenAccount() string {
+	return fmt.Sprintf("STACK_REFRESH_TOKEN_%s%s", GetConfig().ProjectID, ProfileSuffix())
+}
+
 func storeInKeychain(token string) error {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
+	return keychainSet(refreshTokenAccount(), token)
+}
+
+func getFromKeychain() (string, error) {
+	token, err := keychainGet(refreshTokenAccount())
+	if err != nil {
+		return "", fmt.Errorf("token not found in keychain")
+	}
+	return token, nil
+}
 
+func deleteFromKeychain() error {
+	keychainDelete(refreshTokenAccount())
+	return nil
+}
+
+// macOS Keychain operations
+func keychainSet(account, secret string) error {
 	// Delete existing entry (ignore errors)
-	_ = exec.Command("security", "delete-generic-password",
-		"-s", KeychainService,
-		"-a", account,
-	).Run()
+	keychainDelete(account)
 
 	// Add new entry
 	// Note: We use -T "" to allow only this app to access the item (not -A which allows any app)
@@ -257,7 +299,7 @@ func storeInKeychain(token string) error {
 	cmd := exec.Command("security", "add-generic-password",
 		"-s", KeychainService,
 		"-a", account,
-		"-w", token,
+		"-w", secret,
 	)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to store token in keychain: %w", err)
@@ -265,10 +307,7 @@ func storeInKeychain(token string) error {
 	return nil
 }
 
-func getFromKeychain() (string, error) {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
-
+func keychainGet(account string) (string, error) {
 	cmd := exec.Command("security", "find-generic-password",
 		"-s", KeychainService,
 		"-a", account,
@@ -276,25 +315,41 @@ func getFromKeychain() (string, error) {
 	)
 	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("token not found in keychain")
+		return "", err
 	}
 	return strings.TrimSpace(string(output)), nil
 }
 
-func deleteFromKeychain() error {
-	cfg := GetConfig()
-	account := fmt.Sprintf("STACK_REFRESH_TOKEN_%s", cfg.ProjectID)
-
+func keychainDelete(account string) {
 	cmd := exec.Command("security", "delete-generic-password",
 		"-s", KeychainService,
 		"-a", account,
 	)
 	_ = cmd.Run() // Ignore errors (may not exist)
-	return nil
 }
 
 // File-based storage for Linux
-func storeInFile(token string) error {
+func readCredentials() (Credentials, error) {
+	var creds Credentials
+	path, err := getCredentialsPath()
+	if err != nil {
+		return creds, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return creds, fmt.Errorf("credentials file not found")
+	}
+
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return creds, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// updateCredentials applies update to the stored credentials, keeping the
+// fields it doesn't touch
+func updateCredentials(update func(*Credentials)) error {
 	path, err := getCredentialsPath()
 	if err != nil {
 		return err
@@ -310,36 +365,32 @@ func storeInFile(token string) error {
 		_ = json.Unmarshal(data, &creds)
 	}
 
-	creds.StackRefreshToken = token
+	update(&creds)
 
 	data, err := json.MarshalIndent(creds, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal credentials: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	if err := writeFileAtomic(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write credentials: %w", err)
 	}
 
 	return nil
 }
 
+func storeInFile(token string) error {
+	return updateCredentials(func(creds *Credentials) {
+		creds.StackRefreshToken = token
+	})
+}
+
 func getFromFile() (string, error) {
-	path, err := getCredentialsPath()
+	creds, err := readCredentials()
 	if err != nil {
 		return "", err
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", fmt.Errorf("credentials file not found")
-	}
-
-	var creds Credentials
-	if err := json.Unmarshal(data, &creds); err != nil {
-		return "", fmt.Errorf("failed to parse credentials: %w", err)
-	}
-
 	if creds.StackRefreshToken == "" {
 		return "", fmt.Errorf("no refresh token stored")
 	}
@@ -348,29 +399,12 @@ func getFromFile() (string, error) {
 }
 
 func deleteFromFile() error {
-	path, err := getCredentialsPath()
-	if err != nil {
-		return err
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
+	if _, err := readCredentials(); err != nil {
 		return nil // File doesn't exist, nothing to delete
 	}
-
-	var creds Credentials
-	if err := json.Unmarshal(data, &creds); err != nil {
-		return nil
-	}
-
-	creds.StackRefreshToken = ""
-
-	newData, err := json.MarshalIndent(creds, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, newData, 0600)
+	return updateCredentials(func(creds *Credentials) {
+		creds.StackRefreshToken = ""
+	})
 }
 
 // AccessToken represents a cached access token
@@ -425,7 +459,7 @@ func CacheAccessToken(token string, expiresAt int64) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0600)
+	return writeFileAtomic(path, data, 0600)
 }
 
 // ClearCachedAccessToken removes the cached access token
@@ -438,8 +472,11 @@ func ClearCachedAccessToken() error {
 	return nil
 }
 
-// IsLoggedIn checks if the user has stored credentials
+// IsLoggedIn checks if the user has an API key or stored credentials
 func IsLoggedIn() bool {
+	if _, _, ok := APIKey(); ok {
+		return true
+	}
 	_, err := GetRefreshToken()
 	return err == nil
 }
@@ -450,6 +487,16 @@ type CliAuthInitResponse struct {
 	LoginCode   string `json:"login_code"`
 }
 
+// DeviceCodeResponse is the response from cmux's /api/auth/device/code
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
 // CliAuthPollResponse is the response from /api/v1/auth/cli/poll
 type CliAuthPollResponse struct {
 	Status       string `json:"status"`
@@ -468,12 +515,28 @@ type UserInfo struct {
 	DisplayName  string `json:"display_name,omitempty"`
 }
 
+// LoginOptions controls the browser login flow
+type LoginOptions struct {
+	// Device logs in with a server-issued code entered on another device
+	// instead of opening a local browser
+	Device bool
+}
+
+// headless reports whether there's no local browser to open, e.g. over SSH
+// or in a container without a display
+func headless() bool {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return true
+	}
+	return runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
 // Login performs the browser-based Stack Auth login flow
-func Login() error {
+func Login(opts LoginOptions) error {
 	cfg := GetConfig()
 
 	// Check if already logged in
-	if IsLoggedIn() {
+	if _, err := GetRefreshToken(); err == nil {
 		fmt.Println("Already logged in. Run 'cmux auth logout' first to re-authenticate.")
 		return nil
 	}
@@ -482,57 +545,57 @@ func Login() error {
 
 	client := &http.Client{Timeout: 30 * time.Second}
 
-	// Step 1: Initiate CLI auth flow
-	initURL := fmt.Sprintf("%s/api/v1/auth/cli", cfg.StackAuthURL)
-	initBody := strings.NewReader(`{"expires_in_millis": 600000}`)
-
-	req, err := http.NewRequest("POST", initURL, initBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("x-stack-project-id", cfg.ProjectID)
-	req.Header.Set("x-stack-publishable-client-key", cfg.PublishableKey)
-	req.Header.Set("x-stack-access-type", "client")
-	req.Header.Set("Content-Type", "application/json")
+	// Step 1: Start the login, and have the user approve it in a browser
+	var pollingCode string
+	interval := 5 * time.Second
+	deadline := time.Now().Add(10 * time.Minute)
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to initiate auth: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to initiate auth: status %d", resp.StatusCode)
-	}
+	if opts.Device || headless() {
+		device, err := startDeviceLogin(client, cfg)
+		if err != nil {
+			return err
+		}
+		pollingCode = device.DeviceCode
+		if device.Interval > 0 {
+			interval = time.Duration(device.Interval) * time.Second
+		}
+		expiresIn := time.Duration(device.ExpiresIn) * time.Second
+		deadline = time.Now().Add(expiresIn)
 
-	var initResp CliAuthInitResponse
-	if err := json.NewDecoder(resp.Body).Decode(&initResp); err != nil {
-		return fmt.Errorf("failed to decode init response: %w", err)
-	}
+		fmt.Printf("\nTo log in, open %s on any device and enter this code:\n", device.VerificationURI)
+		fmt.Printf("\n  %s\n\n", device.UserCode)
+		if device.VerificationURIComplete != "" {
+			fmt.Printf("Or open this link to skip typing the code:\n  %s\n\n", device.VerificationURIComplete)
+		}
+		fmt.Printf("The code expires in %s.\n", formatExpiry(expiresIn))
+	} else {
+		initResp, err := startCLILogin(client, cfg)
+		if err != nil {
+			return err
+		}
+		pollingCode = initResp.PollingCode
 
-	// Step 2: Open browser
-	authURL := fmt.Sprintf("%s/handler/cli-auth-confirm?login_code=%s",
-		cfg.CmuxURL, initResp.LoginCode)
+		authURL := fmt.Sprintf("%s/handler/cli-auth-confirm?login_code=%s",
+			cfg.CmuxURL, initResp.LoginCode)
 
-	fmt.Println("\nOpening browser to complete authentication...")
-	fmt.Printf("If browser doesn't open, visit:\n  %s\n\n", authURL)
+		fmt.Println("\nOpening browser to complete authentication...")
+		fmt.Printf("If browser doesn't open, visit:\n  %s\n\n", authURL)
 
-	if err := openBrowser(authURL); err != nil {
-		fmt.Printf("Failed to open browser: %v\n", err)
-		fmt.Println("Please open the URL manually.")
+		if err := openBrowser(authURL); err != nil {
+			fmt.Printf("Failed to open browser: %v\n", err)
+			fmt.Println("Please open the URL manually.")
+		}
 	}
 
-	// Step 3: Poll for completion
+	// Step 2: Poll for completion
 	fmt.Println("Waiting for authentication... (press Ctrl+C to cancel)")
 
 	pollURL := fmt.Sprintf("%s/api/v1/auth/cli/poll", cfg.StackAuthURL)
-	maxAttempts := 120 // 10 minutes at 5 second intervals
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		time.Sleep(5 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
 
-		pollBody := fmt.Sprintf(`{"polling_code": "%s"}`, initResp.PollingCode)
+		pollBody := fmt.Sprintf(`{"polling_code": "%s"}`, pollingCode)
 		req, err := http.NewRequest("POST", pollURL, strings.NewReader(pollBody))
 		if err != nil {
 			continue
@@ -572,6 +635,9 @@ func Login() error {
 			if err := StoreRefreshToken(pollResp.RefreshToken); err != nil {
 				return fmt.Errorf("failed to store token: %w", err)
 			}
+			// A stored API key would otherwise keep taking priority
+			_ = DeleteAPIKey()
+			_ = ClearCachedUserProfile()
 
 			fmt.Println("\n\n✓ Authentication successful!")
 			fmt.Println("  Refresh token stored securely.")
@@ -612,6 +678,79 @@ func Login() error {
 	return fmt.Errorf("authentication timed out")
 }
 
+// startCLILogin starts a Stack Auth CLI login, to be approved on the
+// confirm page for the returned login code
+func startCLILogin(client *http.Client, cfg Config) (*CliAuthInitResponse, error) {
+	initURL := fmt.Sprintf("%s/api/v1/auth/cli", cfg.StackAuthURL)
+	initBody := strings.NewReader(`{"expires_in_millis": 600000}`)
+
+	req, err := http.NewRequest("POST", initURL, initBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("x-stack-project-id", cfg.ProjectID)
+	req.Header.Set("x-stack-publishable-client-key", cfg.PublishableKey)
+	req.Header.Set("x-stack-access-type", "client")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate auth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to initiate auth: status %d", resp.StatusCode)
+	}
+
+	var initResp CliAuthInitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&initResp); err != nil {
+		return nil, fmt.Errorf("failed to decode init response: %w", err)
+	}
+	return &initResp, nil
+}
+
+// startDeviceLogin asks cmux for a device code. The server starts the Stack
+// Auth login and hands back a short user code to enter on another device.
+func startDeviceLogin(client *http.Client, cfg Config) (*DeviceCodeResponse, error) {
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/auth/device/code", cfg.CmuxURL), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate device login: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to initiate device login: status %d", resp.StatusCode)
+	}
+
+	var device DeviceCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&device); err != nil {
+		return nil, fmt.Errorf("failed to decode device code response: %w", err)
+	}
+	if device.DeviceCode == "" || device.UserCode == "" || device.ExpiresIn <= 0 {
+		return nil, fmt.Errorf("invalid device code response from server")
+	}
+	return &device, nil
+}
+
+// formatExpiry renders a device code lifetime as "10 minutes" or "45 seconds"
+func formatExpiry(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%d seconds", int(d/time.Second))
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
+
 // Logout clears stored credentials
 func Logout() error {
 	if err := DeleteRefreshToken(); err != nil {
@@ -620,6 +759,13 @@ func Logout() error {
 	if err := ClearCachedAccessToken(); err != nil {
 		return err
 	}
+	if err := DeleteAPIKey(); err != nil {
+		return err
+	}
+	// The cache path depends on the API key, so clear the browser login's too
+	if err := ClearCachedAccessToken(); err != nil {
+		return err
+	}
 	if err := ClearCachedUserProfile(); err != nil {
 		return err
 	}
@@ -627,17 +773,70 @@ func Logout() error {
 	return nil
 }
 
-// GetAccessToken returns a valid access token, refreshing if necessary
+// ErrNotLoggedIn is returned when there are no stored credentials
+var ErrNotLoggedIn = errors.New("not logged in. Run 'cmux auth login' first")
+
+// ErrSessionExpired is returned when the stored refresh token is rejected
+var ErrSessionExpired = errors.New("session expired. Run 'cmux auth login' to sign in again")
+
+// refreshMu serializes refreshes within a process; the lock file does the
+// same across processes
+var refreshMu sync.Mutex
+
+// GetAccessToken returns a valid access token, refreshing if necessary.
+// Concurrent callers, including other cmux processes, share one refresh.
 func GetAccessToken() (string, error) {
 	// Try cached token first (with 60 second buffer)
 	if token, err := GetCachedAccessToken(60); err == nil {
 		return token, nil
 	}
 
-	// Need to refresh
+	return refreshLocked(func() (string, error) {
+		// Another process may have refreshed while we waited for the lock
+		if token, err := GetCachedAccessToken(60); err == nil {
+			return token, nil
+		}
+		return refreshAccessToken()
+	})
+}
+
+// RefreshAccessToken exchanges the API key or stored refresh token for a new
+// access token, bypassing the cache
+func RefreshAccessToken() (string, error) {
+	return refreshLocked(refreshAccessToken)
+}
+
+// refreshLocked runs refresh while holding the token cache lock
+func refreshLocked(refresh func() (string, error)) (string, error) {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	path, err := getAccessTokenCachePath()
+	if err != nil {
+		return "", err
+	}
+	release, err := lockFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	return refresh()
+}
+
+func refreshAccessToken() (string, error) {
+	if key, _, ok := APIKey(); ok {
+		token, expiresAt, err := ExchangeAPIKey(key)
+		if err != nil {
+			return "", err
+		}
+		_ = CacheAccessToken(token, expiresAt)
+		return token, nil
+	}
+
 	refreshToken, err := GetRefreshToken()
 	if err != nil {
-		return "", fmt.Errorf("not logged in. Run 'cmux auth login' first")
+		return "", ErrNotLoggedIn
 	}
 
 	cfg := GetConfig()
@@ -661,6 +860,9 @@ func GetAccessToken() (string, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", ErrSessionExpired
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("failed to refresh token: status %d. Try 'cmux auth login' to re-authenticate", resp.StatusCode)
 	}
@@ -761,9 +963,9 @@ func getUserProfileCachePath() (string, error) {
 	}
 
 	cfg := GetConfig()
-	filename := "user_profile_prod.json"
+	filename := "user_profile_prod" + ProfileSuffix() + ".json"
 	if cfg.IsDev {
-		filename = "user_profile_dev.json"
+		filename = "user_profile_dev" + ProfileSuffix() + ".json"
 	}
 
 	return filepath.Join(configDir, filename), nil
@@ -812,7 +1014,7 @@ func CacheUserProfile(profile *UserProfile) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0600)
+	return writeFileAtomic(path, data, 0600)
 }
 
 // ClearCachedUserProfile removes the cached user profile
@@ -880,8 +1082,13 @@ func GetUserProfile() (*UserProfile, error) {
 	return FetchUserProfile()
 }
 
-// GetTeamSlug returns the user's team slug/ID, fetching if necessary
+// GetTeamSlug returns the active profile's team, or else the user's team
+// slug/ID, fetching if necessary
 func GetTeamSlug() (string, error) {
+	if teamSlug := activeProfileSettings().TeamSlug; teamSlug != "" {
+		return teamSlug, nil
+	}
+
 	profile, err := GetUserProfile()
 	if err != nil {
 		return "", err
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultProfile is the built-in profile. It uses the original credential
+// locations, so it stays compatible with the cmux Rust CLI.
+const DefaultProfile = "default"
+
+// Profile bundles a team and endpoint overrides under a name, with its own
+// stored credentials. Empty fields fall back to the usual configuration.
+type Profile struct {
+	TeamSlug       string `json:"teamSlug,omitempty"`
+	CmuxURL        string `json:"cmuxUrl,omitempty"`
+	ConvexSiteURL  string `json:"convexSiteUrl,omitempty"`
+	StackAuthURL   string `json:"stackAuthUrl,omitempty"`
+	ProjectID      string `json:"projectId,omitempty"`
+	PublishableKey string `json:"publishableKey,omitempty"`
+}
+
+// profilesFile is the on-disk list of profiles
+type profilesFile struct {
+	Current  string             `json:"current,omitempty"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// cliProfile is set from the --profile flag (highest priority)
+var cliProfile string
+
+// SetProfile selects a profile for this invocation, overriding CMUX_PROFILE
+// and the saved current profile. Pass "" to leave the selection alone.
+func SetProfile(name string) {
+	cliProfile = name
+}
+
+func getProfilesPath() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "profiles.json"), nil
+}
+
+func loadProfiles() (*profilesFile, error) {
+	path, err := getProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &profilesFile{Profiles: map[string]Profile{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return file, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles: %w", err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = map[string]Profile{}
+	}
+	return file, nil
+}
+
+func saveProfiles(file *profilesFile) error {
+	path, err := getProfilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profiles: %w", err)
+	}
+	return writeFileAtomic(path, data, 0600)
+}
+
+// ActiveProfile returns the selected profile name. Priority: --profile flag,
+// CMUX_PROFILE, the saved current profile, then DefaultProfile.
+func ActiveProfile() string {
+	if cliProfile != "" {
+		return cliProfile
+	}
+	if env := os.Getenv("CMUX_PROFILE"); env != "" {
+		return env
+	}
+	if file, err := loadProfiles(); err == nil && file.Current != "" {
+		return file.Current
+	}
+	return DefaultProfile
+}
+
+// activeProfileSettings returns the active profile's settings, or an empty
+// Profile for the default profile or an unknown name
+func activeProfileSettings() Profile {
+	name := ActiveProfile()
+	if name == DefaultProfile {
+		return Profile{}
+	}
+	file, err := loadProfiles()
+	if err != nil {
+		return Profile{}
+	}
+	return file.Profiles[name]
+}
+
+// ProfileSuffix keeps each named profile's credentials and caches apart;
+// the default profile uses the original names
+func ProfileSuffix() string {
+	name := ActiveProfile()
+	if name == DefaultProfile {
+		return ""
+	}
+	return "_" + name
+}
+
+// ValidateActiveProfile returns an error if the selected profile doesn't exist
+func ValidateActiveProfile() error {
+	name := ActiveProfile()
+	if name == DefaultProfile {
+		return nil
+	}
+	file, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found. Run 'cmux profile list' to see profiles", name)
+	}
+	return nil
+}
+
+// ListProfiles returns all profile names (including the default profile),
+// sorted, along with their settings
+func ListProfiles() ([]string, map[string]Profile, error) {
+	file, err := loadProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	names := []string{DefaultProfile}
+	for name := range file.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names, file.Profiles, nil
+}
+
+// SaveProfile creates or replaces a named profile
+func SaveProfile(name string, profile Profile) error {
+	if name == DefaultProfile {
+		return fmt.Errorf("the %q profile is built in and can't be changed", DefaultProfile)
+	}
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use letters, numbers, '-' and '_')", name)
+	}
+	file, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	file.Profiles[name] = profile
+	return saveProfiles(file)
+}
+
+// UseProfile makes a profile the current one for future invocations
+func UseProfile(name string) error {
+	file, err := loadProfiles()
+	if err != nil {
+		return err
+	}
+	if name == DefaultProfile {
+		file.Current = ""
+		return saveProfiles(file)
+	}
+	if _, ok := file.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	file.Current = name
+	return saveProfiles(file)
+}
@@ -2,12 +2,18 @@
 package cli
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
 )
 
 var authCmd = &cobra.Command{
@@ -16,6 +22,20 @@ var authCmd = &cobra.Command{
 	Long:  `Login, logout, and check authentication status.`,
 }
 
+// readAPIKeyFlag returns the --api-key value, reading it from stdin when
+// it's "-" so the key stays out of shell history and process listings
+func readAPIKeyFlag(cmd *cobra.Command) (string, error) {
+	key, _ := cmd.Flags().GetString("api-key")
+	if key != "-" {
+		return key, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read API key from stdin: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login via browser",
@@ -23,19 +43,44 @@ var authLoginCmd = &cobra.Command{
 
 This opens your default browser to complete the authentication flow.
 Once authenticated, your credentials are stored securely and shared
-with the cmux CLI.`,
+with the cmux CLI.
+
+On machines without a browser (SSH sessions, containers), use --device
+to log in from another device instead: the CLI prints a short code and a
+URL, and you enter the code there. This is the default when no display
+is available.
+
+For CI and other non-interactive use, authenticate with an API key
+instead: set CMUX_API_KEY, or store a key with --api-key. Pass '-' to
+read the key from stdin.
+
+Examples:
+  cmux auth login
+  cmux auth login --device
+  cmux auth login --api-key - < key.txt
+  CMUX_API_KEY=... cmux ls`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if err := auth.Login(); err != nil {
-			return err
+		if cmd.Flags().Changed("api-key") {
+			key, err := readAPIKeyFlag(cmd)
+			if err != nil {
+				return err
+			}
+			if err := auth.LoginWithAPIKey(key); err != nil {
+				return err
+			}
+		} else {
+			device, _ := cmd.Flags().GetBool("device")
+			if err := auth.Login(auth.LoginOptions{Device: device}); err != nil {
+				return err
+			}
 		}
 
-		if flagJSON {
-			output := map[string]interface{}{
+		if output.Structured() {
+			result := map[string]interface{}{
 				"success": true,
 				"message": "Authentication successful",
 			}
-			data, _ := json.MarshalIndent(output, "", "  ")
-			fmt.Println(string(data))
+			output.Print(result)
 		}
 
 		return nil
@@ -51,13 +96,12 @@ var authLogoutCmd = &cobra.Command{
 			return err
 		}
 
-		if flagJSON {
-			output := map[string]interface{}{
+		if output.Structured() {
+			result := map[string]interface{}{
 				"success": true,
 				"message": "Logged out successfully",
 			}
-			data, _ := json.MarshalIndent(output, "", "  ")
-			fmt.Println(string(data))
+			output.Print(result)
 		}
 
 		return nil
@@ -70,12 +114,11 @@ var authStatusCmd = &cobra.Command{
 	Long:  `Check if you are logged in and show user information.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !auth.IsLoggedIn() {
-			if flagJSON {
-				output := map[string]interface{}{
+			if output.Structured() {
+				result := map[string]interface{}{
 					"logged_in": false,
 				}
-				data, _ := json.MarshalIndent(output, "", "  ")
-				fmt.Println(string(data))
+				output.Print(result)
 			} else {
 				fmt.Println("Not logged in. Run 'cmux auth login' to authenticate.")
 			}
@@ -85,22 +128,44 @@ var authStatusCmd = &cobra.Command{
 		// Try to get user profile (includes team info)
 		profile, err := auth.GetUserProfile()
 		if err != nil {
-			if flagJSON {
-				output := map[string]interface{}{
+			if output.Structured() {
+				result := map[string]interface{}{
 					"logged_in": true,
 					"error":     err.Error(),
 				}
-				data, _ := json.MarshalIndent(output, "", "  ")
-				fmt.Println(string(data))
+				output.Print(result)
 			} else {
 				fmt.Printf("Logged in (unable to fetch user info: %v)\n", err)
 			}
 			return nil
 		}
 
-		if flagJSON {
-			output := map[string]interface{}{
-				"logged_in": true,
+		method := "browser"
+		if _, _, ok := auth.APIKey(); ok {
+			method = "api_key"
+		}
+
+		// The team commands use: a profile's pinned team, or the selected one
+		activeTeam, _ := auth.GetTeamSlug()
+
+		var teams []vm.Team
+		listTeams, _ := cmd.Flags().GetBool("teams")
+		if listTeams {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			client, err := vm.NewClient()
+			if err != nil {
+				return fmt.Errorf("failed to create client: %w", err)
+			}
+			if teams, err = client.ListTeams(ctx); err != nil {
+				return fmt.Errorf("failed to list teams: %w", err)
+			}
+		}
+
+		if output.Structured() {
+			result := map[string]interface{}{
+				"logged_in":   true,
+				"auth_method": method,
 				"user": map[string]interface{}{
 					"id":    profile.UserID,
 					"email": profile.Email,
@@ -111,11 +176,29 @@ var authStatusCmd = &cobra.Command{
 					"slug":         profile.TeamSlug,
 					"display_name": profile.TeamDisplayName,
 				},
+				"active_team": activeTeam,
+				"profile":     auth.ActiveProfile(),
+			}
+			if listTeams {
+				teamList := make([]map[string]interface{}, 0, len(teams))
+				for _, team := range teams {
+					teamList = append(teamList, map[string]interface{}{
+						"id":           team.ID,
+						"slug":         team.Slug,
+						"display_name": team.DisplayName,
+						"role":         team.Role,
+						"selected":     team.Selected,
+						"active":       teamMatches(team, activeTeam),
+					})
+				}
+				result["teams"] = teamList
 			}
-			data, _ := json.MarshalIndent(output, "", "  ")
-			fmt.Println(string(data))
+			output.Print(result)
 		} else {
 			fmt.Println("✓ Logged in")
+			if _, source, ok := auth.APIKey(); ok {
+				fmt.Printf("  Using API key from %s\n", source)
+			}
 			if profile.Email != "" {
 				fmt.Printf("  Email: %s\n", profile.Email)
 			}
@@ -127,17 +210,43 @@ var authStatusCmd = &cobra.Command{
 			} else if profile.TeamSlug != "" {
 				fmt.Printf("  Team: %s\n", profile.TeamSlug)
 			}
+			if activeTeam != "" && activeTeam != profile.TeamSlug && activeTeam != profile.TeamID {
+				fmt.Printf("  Using team: %s (profile %s)\n", activeTeam, auth.ActiveProfile())
+			}
+			if listTeams {
+				fmt.Println("  Teams:")
+				for _, team := range teams {
+					marker := " "
+					if teamMatches(team, activeTeam) {
+						marker = "*"
+					}
+					fmt.Printf("  %s %s %s %s\n", marker, fit(team.SlugOrID(), 24), fit(orDash(team.DisplayName), 24), team.Role)
+				}
+			}
 		}
 
 		return nil
 	},
 }
 
+// teamMatches reports whether team is the one named by slugOrID
+func teamMatches(team vm.Team, slugOrID string) bool {
+	return slugOrID != "" && (team.Slug == slugOrID || team.ID == slugOrID)
+}
+
 var authWhoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show current user",
-	Long:  `Display the currently authenticated user.`,
-	RunE:  authStatusCmd.RunE, // Alias for status
+	Long: `Display the currently authenticated user.
+
+With --teams, also list every team you belong to with your role in it. The
+team commands use is marked with '*'. Combine with --json so scripts can
+check the team context before running batch operations.
+
+Examples:
+  cmux auth whoami --teams
+  cmux auth whoami --teams --json`,
+	RunE: authStatusCmd.RunE, // Alias for status
 }
 
 // Root-level shorthand commands (aliases for auth subcommands)
@@ -150,6 +259,9 @@ This opens your default browser to complete the authentication flow.
 Once authenticated, your credentials are stored securely and shared
 with the cmux CLI.
 
+On headless machines, use --device to log in from another device. For CI,
+use CMUX_API_KEY or --api-key instead (see 'cmux auth login --help').
+
 This is a shorthand for 'cmux auth login'.`,
 	RunE: authLoginCmd.RunE,
 }
@@ -166,13 +278,22 @@ This is a shorthand for 'cmux auth logout'.`,
 var whoamiCmd = &cobra.Command{
 	Use:   "whoami",
 	Short: "Show current user (shorthand for 'cmux auth whoami')",
-	Long: `Display the currently authenticated user.
+	Long: `Display the currently authenticated user. Use --teams to also list
+your teams and roles.
 
 This is a shorthand for 'cmux auth whoami'.`,
 	RunE: authStatusCmd.RunE,
 }
 
 func init() {
+	for _, cmd := range []*cobra.Command{authLoginCmd, loginCmd} {
+		cmd.Flags().String("api-key", "", "Store an API key for non-interactive use instead of logging in via browser ('-' reads it from stdin)")
+		cmd.Flags().Bool("device", false, "Log in on another device with a short code instead of opening a local browser")
+	}
+	for _, cmd := range []*cobra.Command{authStatusCmd, authWhoamiCmd, whoamiCmd} {
+		cmd.Flags().Bool("teams", false, "Also list all your teams with your role in each")
+	}
+
 	authCmd.AddCommand(authLoginCmd)
 	authCmd.AddCommand(authLogoutCmd)
 	authCmd.AddCommand(authStatusCmd)
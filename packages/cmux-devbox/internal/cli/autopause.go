@@ -0,0 +1,196 @@
+// internal/cli/autopause.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// autopauseBusyLoad is the 1-minute load average at or above which a VM
+// counts as busy, e.g. while an agent or build runs with nobody attached
+const autopauseBusyLoad = 0.5
+
+// checkVMActivity reports why a VM is active, or "" if it looks idle
+func checkVMActivity(ctx context.Context, client *vm.Client, instanceID string) (string, error) {
+	sessions, err := client.ListPtySessions(ctx, instanceID)
+	if err != nil {
+		return "", err
+	}
+	clients := 0
+	for _, session := range sessions {
+		clients += session.ClientCount
+	}
+	if clients > 0 {
+		return fmt.Sprintf("%d terminal client(s) attached", clients), nil
+	}
+
+	stdout, _, exitCode, err := client.ExecCommand(ctx, instanceID, "cat /proc/loadavg")
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(stdout)
+	if exitCode != 0 || len(fields) == 0 {
+		return "", fmt.Errorf("failed to read load average")
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse load average %q", fields[0])
+	}
+	if load >= autopauseBusyLoad {
+		return fmt.Sprintf("load average %.2f", load), nil
+	}
+	return "", nil
+}
+
+// autoResume resumes the instance a command targets if 'cmux autopause'
+// paused it, so commands work on it without a manual resume. Commands whose
+// instance argument is optional resolve it like their RunE does, through the
+// directory binding or the last instance.
+func autoResume(cmd *cobra.Command, args []string) error {
+	var instanceID string
+	var err error
+	switch cmd {
+	case pauseCmd, resumeCmd, deleteCmd, autopauseCmd:
+		return nil
+	case codeCmd, vncCmd, sshCmd, execCmd, syncCmd, watchCmd:
+		instanceID, _, err = splitInstanceArg(args)
+	default:
+		if len(args) == 0 {
+			return nil
+		}
+		instanceID, err = resolveInstanceID(args[0])
+	}
+	if err != nil || !state.IsAutoPaused(instanceID) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, err := newTeamClient()
+	if err != nil {
+		return err
+	}
+
+	if !output.Quiet() {
+		fmt.Fprintf(os.Stderr, "Resuming VM %s (auto-paused while idle)...\n", instanceID)
+	}
+	if err := client.ResumeInstance(ctx, instanceID); err != nil {
+		return fmt.Errorf("failed to resume VM: %w", err)
+	}
+	if _, err := client.WaitForReady(ctx, instanceID, 2*time.Minute); err != nil {
+		return fmt.Errorf("VM failed to resume: %w", err)
+	}
+	state.ClearAutoPaused(instanceID)
+	return nil
+}
+
+var autopauseCmd = &cobra.Command{
+	Use:   "autopause <id|last>",
+	Short: "Pause a VM once it has been idle for a while",
+	Long: `Watch a VM and pause it once it has been idle for --after, so forgotten
+VMs don't run overnight. The next cmux command that uses the VM resumes it
+automatically.
+
+A VM counts as active while a terminal client is attached to one of its
+PTY sessions or its 1-minute load average is at least 0.5 (an agent or build
+is running). If a check fails, the VM is assumed active.
+
+The command runs in the foreground until it pauses the VM, the VM stops, or
+you press Ctrl-C.
+
+Examples:
+  cmux autopause cmux_abc123 --after 30m
+  cmux autopause last --after 2h --interval 5m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		after, _ := cmd.Flags().GetDuration("after")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if after < time.Minute {
+			return fmt.Errorf("--after must be at least 1m")
+		}
+		if interval < 10*time.Second {
+			return fmt.Errorf("--interval must be at least 10s")
+		}
+
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		output.Infof("Watching %s; pausing after %s idle (Ctrl-C to stop)\n", instanceID, after)
+		lastActive := time.Now()
+		for {
+			checkCtx, cancel := context.WithTimeout(ctx, 90*time.Second)
+			instance, err := client.GetInstance(checkCtx, instanceID)
+			if err == nil && instance.Status != "running" {
+				cancel()
+				fmt.Printf("VM is %s; stopped watching\n", instance.Status)
+				return nil
+			}
+			var reason string
+			if err == nil {
+				reason, err = checkVMActivity(checkCtx, client, instanceID)
+			}
+			cancel()
+
+			switch {
+			case ctx.Err() != nil:
+				output.Infoln("\nStopped watching")
+				return nil
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "Warning: failed to check activity: %v\n", err)
+				lastActive = time.Now()
+			case reason != "":
+				if flagVerbose {
+					fmt.Printf("Active: %s\n", reason)
+				}
+				lastActive = time.Now()
+			}
+
+			if idle := time.Since(lastActive); idle >= after {
+				pauseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				err := client.PauseInstance(pauseCtx, instanceID)
+				cancel()
+				if err != nil {
+					return fmt.Errorf("failed to pause VM: %w", err)
+				}
+				state.SetAutoPaused(instanceID, time.Now().UnixMilli())
+				fmt.Printf("✓ VM %s paused after %s idle\n", instanceID, idle.Round(time.Second))
+				fmt.Println("  It resumes automatically the next time a cmux command uses it")
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				output.Infoln("\nStopped watching")
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+func init() {
+	autopauseCmd.Flags().Duration("after", 30*time.Minute, "Pause after the VM has been idle this long")
+	autopauseCmd.Flags().Duration("interval", time.Minute, "How often to check activity")
+	rootCmd.AddCommand(autopauseCmd)
+}
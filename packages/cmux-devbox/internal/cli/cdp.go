@@ -0,0 +1,224 @@
+// internal/cli/cdp.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// cdpProxyPort is the cdp-proxy in front of the VM's Chrome, which
+	// survives browser restarts
+	cdpProxyPort = 39381
+	// chromeDebugPort is Chrome's own DevTools port, used when the VM has
+	// no cdp-proxy
+	chromeDebugPort = 9222
+)
+
+// cdpEndpoint describes a local DevTools endpoint tunneled to a VM
+type cdpEndpoint struct {
+	Instance             string `json:"instance"`
+	LocalPort            int    `json:"localPort"`
+	RemotePort           int    `json:"remotePort"`
+	HTTPURL              string `json:"httpUrl"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	Browser              string `json:"browser,omitempty"`
+}
+
+// detectCDPPort picks the cdp-proxy when it answers in the VM, and Chrome's
+// DevTools port otherwise
+func detectCDPPort(ctx context.Context, client *vm.Client, instanceID string) (int, error) {
+	command := fmt.Sprintf(
+		"curl -sf -m 3 http://127.0.0.1:%d/json/version >/dev/null && echo %d || echo %d",
+		cdpProxyPort, cdpProxyPort, chromeDebugPort,
+	)
+	stdout, stderr, exitCode, err := client.ExecCommand(ctx, instanceID, command)
+	if err != nil {
+		return 0, err
+	}
+	port, convErr := strconv.Atoi(strings.TrimSpace(stdout))
+	if exitCode != 0 || convErr != nil {
+		return 0, fmt.Errorf("failed to detect DevTools port: %s", strings.TrimSpace(stderr))
+	}
+	return port, nil
+}
+
+// freeLocalPort asks the OS for an unused port on bindAddress
+func freeLocalPort(bindAddress string) (int, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(bindAddress, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForCDPEndpoint polls /json/version through the tunnel until the
+// browser answers, and rewrites the WebSocket URL to the local address
+func waitForCDPEndpoint(ctx context.Context, httpURL string, timeout time.Duration) (string, string, error) {
+	deadline := time.Now().Add(timeout)
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	var lastErr error
+	for {
+		req, err := http.NewRequestWithContext(ctx, "GET", httpURL+"/json/version", nil)
+		if err != nil {
+			return "", "", err
+		}
+		resp, err := httpClient.Do(req)
+		if err == nil {
+			var version struct {
+				Browser              string `json:"Browser"`
+				WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+			}
+			decodeErr := json.NewDecoder(resp.Body).Decode(&version)
+			resp.Body.Close()
+			if decodeErr == nil && version.WebSocketDebuggerURL != "" {
+				wsURL, err := url.Parse(version.WebSocketDebuggerURL)
+				if err != nil {
+					return "", "", fmt.Errorf("invalid webSocketDebuggerUrl %q", version.WebSocketDebuggerURL)
+				}
+				local, _ := url.Parse(httpURL)
+				wsURL.Host = local.Host
+				return wsURL.String(), version.Browser, nil
+			}
+			lastErr = fmt.Errorf("unexpected response from %s/json/version (%d)", httpURL, resp.StatusCode)
+		} else {
+			lastErr = err
+		}
+
+		if time.Now().After(deadline) {
+			return "", "", fmt.Errorf("browser did not respond through the tunnel: %w", lastErr)
+		}
+		select {
+		case <-ctx.Done():
+			return "", "", ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+var cdpCmd = &cobra.Command{
+	Use:   "cdp <id|last>",
+	Short: "Expose the VM browser's DevTools endpoint locally",
+	Long: `Tunnel the VM browser's Chrome DevTools Protocol endpoint to a local port,
+so Puppeteer, Playwright, or chrome://inspect on this machine can drive the
+browser in the VM directly.
+
+The tunnel runs over SSH, authenticated with your cmux login, and goes to the
+VM's cdp-proxy (or Chrome's DevTools port when there is no proxy). The local
+webSocketDebuggerUrl is printed once the browser answers. Press Ctrl-C to
+stop.
+
+Examples:
+  cmux cdp cmux_abc123                  # Pick a free local port
+  cmux cdp last --port 9222             # Use a fixed local port
+  cmux cdp cmux_abc123 --json           # Print the endpoint as JSON
+
+Then, from Node:
+  puppeteer.connect({ browserWSEndpoint: "<webSocketDebuggerUrl>" })
+  chromium.connectOverCDP("http://127.0.0.1:<port>")   // Playwright`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+		bindAddress, _ := cmd.Flags().GetString("bind")
+		localPort, _ := cmd.Flags().GetInt("port")
+		remotePort, _ := cmd.Flags().GetInt("remote-port")
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := vm.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client.SetTeamSlug(teamSlug)
+
+		sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if remotePort == 0 {
+			detectCtx, cancel := context.WithTimeout(sigCtx, 60*time.Second)
+			remotePort, err = detectCDPPort(detectCtx, client, instanceID)
+			cancel()
+			if err != nil {
+				return err
+			}
+		}
+		if localPort == 0 {
+			if localPort, err = freeLocalPort(bindAddress); err != nil {
+				return fmt.Errorf("failed to find a free local port: %w", err)
+			}
+		}
+
+		ctx, cancel := context.WithCancel(sigCtx)
+		defer cancel()
+		go keepInstanceAlive(ctx, client, instanceID)
+
+		forwards := []portForward{{localPort: localPort, remoteHost: "127.0.0.1", remotePort: remotePort}}
+		done := make(chan error, 1)
+		go func() {
+			done <- runForwardLoop(ctx, client, instanceID, forwards, bindAddress)
+		}()
+
+		httpURL := "http://" + net.JoinHostPort(bindAddress, strconv.Itoa(localPort))
+		wsURL, browser, err := waitForCDPEndpoint(ctx, httpURL, 60*time.Second)
+		if err != nil {
+			cancel()
+			<-done
+			if sigCtx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		endpoint := cdpEndpoint{
+			Instance:             instanceID,
+			LocalPort:            localPort,
+			RemotePort:           remotePort,
+			HTTPURL:              httpURL,
+			WebSocketDebuggerURL: wsURL,
+			Browser:              browser,
+		}
+		if output.Structured() {
+			if err := output.Print(endpoint); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("✓ DevTools for %s is available locally\n", instanceID)
+			if browser != "" {
+				fmt.Printf("  Browser:   %s\n", browser)
+			}
+			fmt.Printf("  HTTP:      %s\n", httpURL)
+			fmt.Printf("  WebSocket: %s\n", wsURL)
+			output.Infoln("Press Ctrl-C to stop")
+		}
+
+		return <-done
+	},
+}
+
+func init() {
+	cdpCmd.Flags().Int("port", 0, "Local port to listen on (default: a free port)")
+	cdpCmd.Flags().String("bind", "127.0.0.1", "Local address to listen on")
+	cdpCmd.Flags().Int("remote-port", 0, fmt.Sprintf("DevTools port in the VM (default: %d if the cdp-proxy runs, else %d)", cdpProxyPort, chromeDebugPort))
+	rootCmd.AddCommand(cdpCmd)
+}
@@ -0,0 +1,90 @@
+// internal/cli/clipboard.go
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// clipboardImage saves the image on the system clipboard to a temporary PNG
+// and returns its path. The caller removes the file.
+func clipboardImage() (string, error) {
+	file, err := os.CreateTemp("", "cmux-clipboard-*.png")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := file.Name()
+	file.Close()
+
+	if err := saveClipboardImage(path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.HasPrefix(data, pngSignature) {
+		os.Remove(path)
+		return "", fmt.Errorf("clipboard doesn't contain an image")
+	}
+	return path, nil
+}
+
+func saveClipboardImage(path string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		// pbpaste only handles text; pngpaste is faster when installed
+		if _, err := exec.LookPath("pngpaste"); err == nil {
+			return runClipboardCommand(exec.Command("pngpaste", path))
+		}
+		script := fmt.Sprintf(`set f to open for access POSIX file %q with write permission
+write (the clipboard as «class PNGf») to f
+close access f`, path)
+		return runClipboardCommand(exec.Command("osascript", "-e", script))
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$img = [System.Windows.Forms.Clipboard]::GetImage()
+if ($img -eq $null) { exit 1 }
+$img.Save('%s', [System.Drawing.Imaging.ImageFormat]::Png)`, strings.ReplaceAll(path, "'", "''"))
+		return runClipboardCommand(exec.Command("powershell", "-NoProfile", "-STA", "-Command", script))
+	default:
+		var cmd *exec.Cmd
+		switch {
+		case os.Getenv("WAYLAND_DISPLAY") != "":
+			if _, err := exec.LookPath("wl-paste"); err != nil {
+				return fmt.Errorf("reading the clipboard on Wayland requires wl-paste (install wl-clipboard)")
+			}
+			cmd = exec.Command("wl-paste", "--no-newline", "--type", "image/png")
+		default:
+			if _, err := exec.LookPath("xclip"); err != nil {
+				return fmt.Errorf("reading the clipboard requires xclip")
+			}
+			cmd = exec.Command("xclip", "-selection", "clipboard", "-target", "image/png", "-out")
+		}
+
+		out, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		defer out.Close()
+		cmd.Stdout = out
+		return runClipboardCommand(cmd)
+	}
+}
+
+func runClipboardCommand(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("clipboard doesn't contain an image (%s)", msg)
+		}
+		return fmt.Errorf("clipboard doesn't contain an image")
+	}
+	return nil
+}
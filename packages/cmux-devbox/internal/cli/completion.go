@@ -0,0 +1,225 @@
+// internal/cli/completion.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// completionCacheTTL keeps repeated <TAB> presses from hitting the API
+	completionCacheTTL = 30 * time.Second
+	completionTimeout  = 3 * time.Second
+)
+
+// completionEntry is a completable ID with a human-readable description
+type completionEntry struct {
+	ID          string `json:"id"`
+	Description string `json:"description,omitempty"`
+}
+
+type completionCache struct {
+	FetchedAt int64             `json:"fetchedAt"`
+	Entries   []completionEntry `json:"entries"`
+}
+
+func completionCachePath(kind, teamSlug string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	mode := "prod"
+	if auth.GetConfig().IsDev {
+		mode = "dev"
+	}
+	return filepath.Join(cacheDir, "cmux", fmt.Sprintf("completion_%s_%s%s_%s.json", kind, mode, auth.ProfileSuffix(), teamSlug)), nil
+}
+
+// cachedCompletions returns entries from the cache if fresh, otherwise
+// fetches and caches them. Errors are swallowed: completion must never fail
+// loudly.
+func cachedCompletions(kind string, fetch func(ctx context.Context, client *vm.Client) ([]completionEntry, error)) []completionEntry {
+	teamSlug, err := auth.GetTeamSlug()
+	if err != nil {
+		return nil
+	}
+
+	path, pathErr := completionCachePath(kind, teamSlug)
+	if pathErr == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			var cache completionCache
+			if json.Unmarshal(data, &cache) == nil && time.Since(time.UnixMilli(cache.FetchedAt)) < completionCacheTTL {
+				return cache.Entries
+			}
+		}
+	}
+
+	client, err := vm.NewClient()
+	if err != nil {
+		return nil
+	}
+	client.SetTeamSlug(teamSlug)
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	entries, err := fetch(ctx, client)
+	if err != nil {
+		return nil
+	}
+
+	if pathErr == nil {
+		data, _ := json.Marshal(completionCache{FetchedAt: time.Now().UnixMilli(), Entries: entries})
+		if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+			_ = os.WriteFile(path, data, 0600)
+		}
+	}
+	return entries
+}
+
+func formatCompletions(entries []completionEntry, toComplete string) []string {
+	var completions []string
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.ID, toComplete) {
+			continue
+		}
+		if entry.Description == "" {
+			completions = append(completions, entry.ID)
+		} else {
+			completions = append(completions, entry.ID+"\t"+entry.Description)
+		}
+	}
+	return completions
+}
+
+// completeInstanceIDs completes the first argument with the team's instance
+// IDs, described by name and status
+func completeInstanceIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	entries := cachedCompletions("instances", func(ctx context.Context, client *vm.Client) ([]completionEntry, error) {
+		instances, err := client.ListInstances(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(instances))
+		for _, inst := range instances {
+			description := inst.Status
+			if inst.Name != "" {
+				description = fmt.Sprintf("%s (%s)", inst.Name, inst.Status)
+			}
+			entries = append(entries, completionEntry{ID: inst.ID, Description: description})
+		}
+		return entries, nil
+	})
+
+	return formatCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInstanceIDsOrLast is completeInstanceIDs plus "last", for commands
+// that resolve it to the last used instance
+func completeInstanceIDsOrLast(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions, directive := completeInstanceIDs(cmd, args, toComplete)
+	if len(args) == 0 && strings.HasPrefix("last", toComplete) {
+		completions = append(completions, "last\tLast used instance")
+	}
+	return completions, directive
+}
+
+// completeTaskIDs completes the first argument with recent task IDs,
+// described by their prompt
+func completeTaskIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	entries := cachedCompletions("tasks", func(ctx context.Context, client *vm.Client) ([]completionEntry, error) {
+		tasks, err := client.ListTasks(ctx, false, 50)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(tasks))
+		for _, task := range tasks {
+			entries = append(entries, completionEntry{ID: task.ID, Description: strings.TrimSpace(fit(task.Text, 60))})
+		}
+		return entries, nil
+	})
+
+	return formatCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSecretNames completes team secret names, for any argument position
+func completeSecretNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	entries := cachedCompletions("secrets", func(ctx context.Context, client *vm.Client) ([]completionEntry, error) {
+		secrets, err := client.ListSecrets(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entries := make([]completionEntry, 0, len(secrets))
+		for _, secret := range secrets {
+			entries = append(entries, completionEntry{ID: secret.Name, Description: secret.Description})
+		}
+		return entries, nil
+	})
+
+	return formatCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateNames completes local template names. Only rm takes more
+// than one.
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 && cmd != templateRmCmd {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	templates, err := state.ListTemplates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries := make([]completionEntry, 0, len(templates))
+	for _, template := range templates {
+		entries = append(entries, completionEntry{ID: template.Name, Description: strings.TrimSpace(fit(template.Prompt, 60))})
+	}
+	return formatCompletions(entries, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	instanceCommands := []*cobra.Command{
+		deleteCmd, pauseCmd, statusCmd,
+		execCmd, syncCmd, ptyCmd, ptyListCmd,
+		computerSnapshotCmd, computerOpenCmd, computerClickCmd, computerTypeCmd,
+		computerFillCmd, computerPressCmd, computerScrollCmd, computerScreenshotCmd,
+		computerBackCmd, computerForwardCmd, computerReloadCmd, computerURLCmd,
+		computerTitleCmd, computerWaitCmd, computerHoverCmd, computerDblclickCmd,
+		computerEvalCmd,
+	}
+	for _, cmd := range instanceCommands {
+		cmd.ValidArgsFunction = completeInstanceIDs
+	}
+
+	for _, cmd := range []*cobra.Command{sshCmd, sshConfigCmd, forwardCmd, watchCmd, resumeCmd, autopauseCmd, extendCmd, useCmd, pullCmd, codeCmd, vncCmd} {
+		cmd.ValidArgsFunction = completeInstanceIDsOrLast
+	}
+
+	for _, cmd := range []*cobra.Command{taskOpenCmd, taskDiffCmd, taskPRsCmd, notifyCmd} {
+		cmd.ValidArgsFunction = completeTaskIDs
+	}
+
+	secretsRmCmd.ValidArgsFunction = completeSecretNames
+
+	for _, cmd := range []*cobra.Command{templateShowCmd, templateRenderCmd, templateRmCmd} {
+		cmd.ValidArgsFunction = completeTemplateNames
+	}
+}
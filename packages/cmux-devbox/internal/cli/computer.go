@@ -10,9 +10,12 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
@@ -26,9 +29,13 @@ These commands allow you to automate the Chrome browser running in the VNC deskt
 
 Examples:
   cmux computer snapshot cmux_abc123              # Get accessibility tree
-  cmux computer open cmux_abc123 https://example.com  # Navigate to URL
+  cmux computer navigate cmux_abc123 https://example.com  # Navigate to URL
   cmux computer click cmux_abc123 @e1             # Click element by ref
+  cmux computer click cmux_abc123 640 360         # Click at coordinates
   cmux computer type cmux_abc123 "hello world"   # Type text
+  cmux computer key cmux_abc123 ctrl+s           # Press a key combination
+  cmux computer scroll cmux_abc123 down 500      # Scroll 500 pixels
+  cmux computer wait-for cmux_abc123 "#content"  # Wait for an element
   cmux computer screenshot cmux_abc123           # Take screenshot`,
 }
 
@@ -101,7 +108,7 @@ func callWorkerAPI(ctx context.Context, workerURL, token, endpoint string, body
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+		return nil, &vm.APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	var result map[string]interface{}
@@ -162,9 +169,8 @@ Examples:
 			return err
 		}
 
-		if flagJSON {
-			output, _ := json.Marshal(result)
-			fmt.Println(string(output))
+		if output.Structured() {
+			return output.Print(result)
 		} else {
 			// Parse and display in readable format
 			if data, ok := result["data"].(map[string]interface{}); ok {
@@ -182,12 +188,14 @@ Examples:
 
 // Open command
 var computerOpenCmd = &cobra.Command{
-	Use:   "open <id> <url>",
-	Short: "Navigate browser to URL",
+	Use:     "open <id> <url>",
+	Aliases: []string{"navigate", "goto"},
+	Short:   "Navigate browser to URL",
 	Long: `Navigate the browser to the specified URL.
 
 Example:
-  cmux computer open cmux_abc123 https://google.com`,
+  cmux computer open cmux_abc123 https://google.com
+  cmux computer navigate cmux_abc123 https://google.com`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -207,26 +215,49 @@ Example:
 
 // Click command
 var computerClickCmd = &cobra.Command{
-	Use:   "click <id> <selector>",
-	Short: "Click an element",
-	Long: `Click an element by selector (ref like @e1 or CSS selector).
+	Use:   "click <id> <selector> | click <id> <x> <y>",
+	Short: "Click an element or a position",
+	Long: `Click an element by selector (ref like @e1 or CSS selector), or click at
+x/y coordinates in the browser viewport (e.g. on a canvas).
 
 Examples:
-  cmux computer click cmux_abc123 @e1          # Click by ref
-  cmux computer click cmux_abc123 "#submit"    # Click by CSS selector`,
-	Args: cobra.ExactArgs(2),
+  cmux computer click cmux_abc123 @e1                  # Click by ref
+  cmux computer click cmux_abc123 "#submit"            # Click by CSS selector
+  cmux computer click cmux_abc123 640 360              # Click at coordinates
+  cmux computer click cmux_abc123 640 360 --button right`,
+	Args: cobra.RangeArgs(2, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		button, _ := cmd.Flags().GetString("button")
+		switch button {
+		case "left", "right", "middle":
+		default:
+			return fmt.Errorf("invalid --button %q (must be left, right, or middle)", button)
+		}
+
+		body := map[string]interface{}{}
+		target := args[1]
+		if len(args) == 3 {
+			x, errX := strconv.Atoi(args[1])
+			y, errY := strconv.Atoi(args[2])
+			if errX != nil || errY != nil || x < 0 || y < 0 {
+				return fmt.Errorf("invalid coordinates %q %q (must be non-negative integers)", args[1], args[2])
+			}
+			body["x"] = x
+			body["y"] = y
+			body["button"] = button
+			target = fmt.Sprintf("(%d, %d)", x, y)
+		} else {
+			body["selector"] = args[1]
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
 
-		_, err := execAgentBrowser(ctx, args[0], "/click", map[string]interface{}{
-			"selector": args[1],
-		})
-		if err != nil {
+		if _, err := execAgentBrowser(ctx, args[0], "/click", body); err != nil {
 			return err
 		}
 
-		fmt.Printf("Clicked: %s\n", args[1])
+		fmt.Printf("Clicked: %s\n", target)
 		return nil
 	},
 }
@@ -311,30 +342,131 @@ Example:
 	},
 }
 
+// keyAliases maps common shorthand to the key names the browser expects
+var keyAliases = map[string]string{
+	"ctrl":      "Control",
+	"control":   "Control",
+	"cmd":       "Meta",
+	"command":   "Meta",
+	"meta":      "Meta",
+	"super":     "Meta",
+	"alt":       "Alt",
+	"option":    "Alt",
+	"opt":       "Alt",
+	"shift":     "Shift",
+	"enter":     "Enter",
+	"return":    "Enter",
+	"esc":       "Escape",
+	"escape":    "Escape",
+	"tab":       "Tab",
+	"space":     "Space",
+	"bksp":      "Backspace",
+	"backspace": "Backspace",
+	"del":       "Delete",
+	"delete":    "Delete",
+	"up":        "ArrowUp",
+	"down":      "ArrowDown",
+	"left":      "ArrowLeft",
+	"right":     "ArrowRight",
+	"pgup":      "PageUp",
+	"pgdn":      "PageDown",
+	"home":      "Home",
+	"end":       "End",
+}
+
+// normalizeKeyCombo turns "ctrl+shift+p" into "Control+Shift+p"
+func normalizeKeyCombo(combo string) (string, error) {
+	parts := strings.Split(combo, "+")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("invalid key combination %q", combo)
+		}
+		if alias, ok := keyAliases[strings.ToLower(part)]; ok {
+			part = alias
+		} else if len(part) > 1 && part[0] == 'f' {
+			if _, err := strconv.Atoi(part[1:]); err == nil {
+				part = "F" + part[1:] // f5 -> F5
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "+"), nil
+}
+
+// Key command
+var computerKeyCmd = &cobra.Command{
+	Use:   "key <id> <combo>",
+	Short: "Press a key combination",
+	Long: `Press a key or key combination. Modifiers are joined with +.
+
+Modifiers: ctrl, shift, alt (option), cmd (meta)
+
+Examples:
+  cmux computer key cmux_abc123 ctrl+s
+  cmux computer key cmux_abc123 ctrl+shift+p
+  cmux computer key cmux_abc123 esc`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := normalizeKeyCombo(args[1])
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		if _, err := execAgentBrowser(ctx, args[0], "/press", map[string]interface{}{
+			"key": key,
+		}); err != nil {
+			return err
+		}
+
+		fmt.Printf("Pressed: %s\n", key)
+		return nil
+	},
+}
+
 // Scroll command
 var computerScrollCmd = &cobra.Command{
-	Use:   "scroll <id> <direction>",
+	Use:   "scroll <id> <direction> [pixels]",
 	Short: "Scroll the page",
-	Long: `Scroll the page in the specified direction.
+	Long: `Scroll the page in the specified direction, optionally by a number of pixels.
 
-Directions: up, down
+Directions: up, down, left, right
 
 Example:
   cmux computer scroll cmux_abc123 down
+  cmux computer scroll cmux_abc123 down 1200
   cmux computer scroll cmux_abc123 up`,
-	Args: cobra.ExactArgs(2),
+	Args: cobra.RangeArgs(2, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		direction := strings.ToLower(args[1])
+		switch direction {
+		case "up", "down", "left", "right":
+		default:
+			return fmt.Errorf("invalid direction %q (must be up, down, left, or right)", args[1])
+		}
+
+		body := map[string]interface{}{
+			"direction": direction,
+		}
+		if len(args) == 3 {
+			pixels, err := strconv.Atoi(args[2])
+			if err != nil || pixels <= 0 {
+				return fmt.Errorf("invalid pixels %q (must be a positive integer)", args[2])
+			}
+			body["amount"] = pixels
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
 
-		_, err := execAgentBrowser(ctx, args[0], "/scroll", map[string]interface{}{
-			"direction": args[1],
-		})
-		if err != nil {
+		if _, err := execAgentBrowser(ctx, args[0], "/scroll", body); err != nil {
 			return err
 		}
 
-		fmt.Printf("Scrolled %s\n", args[1])
+		fmt.Printf("Scrolled %s\n", direction)
 		return nil
 	},
 }
@@ -509,28 +641,39 @@ var computerTitleCmd = &cobra.Command{
 
 // Wait command
 var computerWaitCmd = &cobra.Command{
-	Use:   "wait <id> <selector>",
-	Short: "Wait for an element",
-	Long: `Wait for an element to be visible.
+	Use:     "wait <id> <selector>",
+	Aliases: []string{"wait-for"},
+	Short:   "Wait for an element",
+	Long: `Wait for an element to reach a state (visible by default).
+
+States: visible, hidden, attached, detached. Refs (@e5) support visible only.
 
 Example:
   cmux computer wait cmux_abc123 "#content"
+  cmux computer wait-for cmux_abc123 "#loading" --state hidden
+  cmux computer wait cmux_abc123 ".modal" --timeout 10000
   cmux computer wait cmux_abc123 "@e5"`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		timeout, _ := cmd.Flags().GetInt("timeout")
+		state, _ := cmd.Flags().GetString("state")
+		if timeout <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout+10000)*time.Millisecond)
 		defer cancel()
 
 		_, err := execAgentBrowser(ctx, args[0], "/wait", map[string]interface{}{
 			"selector": args[1],
+			"state":    state,
+			"timeout":  timeout,
 		})
 		if err != nil {
 			return err
 		}
 
-		fmt.Printf("Element %s found\n", args[1])
+		fmt.Printf("Element %s is %s\n", args[1], state)
 		return nil
 	},
 }
@@ -626,6 +769,8 @@ func init() {
 	computerSnapshotCmd.Flags().BoolP("interactive", "i", false, "Show only interactive elements")
 	computerSnapshotCmd.Flags().BoolP("compact", "c", false, "Compact output")
 	computerWaitCmd.Flags().Int("timeout", 30000, "Timeout in milliseconds")
+	computerWaitCmd.Flags().String("state", "visible", "State to wait for: visible, hidden, attached, detached")
+	computerClickCmd.Flags().String("button", "left", "Mouse button for coordinate clicks: left, right, middle")
 
 	// Add subcommands
 	computerCmd.AddCommand(computerSnapshotCmd)
@@ -635,6 +780,7 @@ func init() {
 	computerCmd.AddCommand(computerTypeCmd)
 	computerCmd.AddCommand(computerFillCmd)
 	computerCmd.AddCommand(computerPressCmd)
+	computerCmd.AddCommand(computerKeyCmd)
 	computerCmd.AddCommand(computerScrollCmd)
 	computerCmd.AddCommand(computerScreenshotCmd)
 	computerCmd.AddCommand(computerBackCmd)
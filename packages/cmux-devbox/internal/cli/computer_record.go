@@ -0,0 +1,252 @@
+// internal/cli/computer_record.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// recordFormat derives the video format from the output file's extension
+func recordFormat(outputPath string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(outputPath)); ext {
+	case ".webm":
+		return "webm", nil
+	case ".mp4":
+		return "mp4", nil
+	default:
+		return "", fmt.Errorf("unsupported output format %q (use .webm or .mp4)", ext)
+	}
+}
+
+// defaultRecordOutput names a recording after the instance and the time
+func defaultRecordOutput(instanceID, ext string) string {
+	return fmt.Sprintf("%s-%s%s", instanceID, time.Now().Format("20060102-150405"), ext)
+}
+
+// startRecording asks the worker to start recording the VNC display
+func startRecording(ctx context.Context, instanceID, format string, fps int, maxDuration time.Duration) error {
+	_, err := execAgentBrowser(ctx, instanceID, "/record/start", map[string]interface{}{
+		"format":     format,
+		"fps":        fps,
+		"maxSeconds": int(maxDuration.Seconds()),
+	})
+	return err
+}
+
+// stopAndDownloadRecording stops the active recording and downloads the
+// file, which is deleted from the VM once it has been transferred. An empty
+// outputPath picks a name with the recording's extension.
+func stopAndDownloadRecording(ctx context.Context, instanceID, outputPath string) (map[string]interface{}, error) {
+	workerURL, token, err := getWorkerClient(ctx, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := callWorkerAPI(ctx, workerURL, token, "/record/stop", nil)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := result["data"].(map[string]interface{})
+	file, _ := data["file"].(string)
+	if file == "" {
+		return nil, fmt.Errorf("recording failed: no file in response")
+	}
+
+	if outputPath == "" {
+		outputPath = defaultRecordOutput(instanceID, filepath.Ext(file))
+	} else if !strings.EqualFold(filepath.Ext(outputPath), filepath.Ext(file)) {
+		fmt.Fprintf(os.Stderr, "Warning: recording is %s but output file is %s\n", filepath.Ext(file), outputPath)
+	}
+
+	query := url.Values{"file": {file}, "cleanup": {"1"}}
+	if err := downloadWorkerFile(ctx, workerURL, token, "/record/download?"+query.Encode(), outputPath); err != nil {
+		return nil, err
+	}
+	data["output"] = outputPath
+	return data, nil
+}
+
+// downloadWorkerFile streams a file from the worker daemon to a local path
+func downloadWorkerFile(ctx context.Context, workerURL, token, endpoint, outputPath string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", workerURL+endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &vm.APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(outputPath)
+		return fmt.Errorf("download failed: %w", err)
+	}
+	return f.Close()
+}
+
+// printRecording reports a downloaded recording
+func printRecording(data map[string]interface{}) error {
+	if output.Structured() {
+		return output.Print(data)
+	}
+	bytes, _ := data["bytes"].(float64)
+	durationMs, _ := data["durationMs"].(float64)
+	fmt.Printf("✓ Recording saved to %s (%s, %.1f MB)\n", data["output"],
+		(time.Duration(durationMs) * time.Millisecond).Round(time.Second), bytes/(1024*1024))
+	return nil
+}
+
+var computerRecordCmd = &cobra.Command{
+	Use:   "record <id> [output-file]",
+	Short: "Record a video of the VM display",
+	Long: `Record a video of the VM's VNC display (browser included) and download it.
+
+Records for --duration, or until Ctrl+C, then saves the video locally. The
+format follows the output file's extension: .webm (default) or .mp4.
+
+Use 'record start' and 'record stop' to record around other commands, e.g.
+while an agent runs a flow. Requires ffmpeg in the VM.
+
+Examples:
+  cmux computer record cmux_abc123 run.webm --duration 30s
+  cmux computer record cmux_abc123 bug.mp4            # Until Ctrl+C
+  cmux computer record start cmux_abc123
+  cmux computer record stop cmux_abc123 run.webm`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		duration, _ := cmd.Flags().GetDuration("duration")
+		fps, _ := cmd.Flags().GetInt("fps")
+		outputPath := defaultRecordOutput(args[0], ".webm")
+		if len(args) > 1 {
+			outputPath = args[1]
+		}
+		format, err := recordFormat(outputPath)
+		if err != nil {
+			return err
+		}
+		if duration < 0 {
+			return fmt.Errorf("--duration must be positive")
+		}
+
+		// Cap the recording in the VM too, so it ends even if we're killed
+		maxDuration := duration + 10*time.Second
+		if duration == 0 {
+			maxDuration = time.Hour
+		}
+
+		startCtx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		err = startRecording(startCtx, args[0], format, fps, maxDuration)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		if duration > 0 {
+			if !output.Quiet() {
+				fmt.Fprintf(os.Stderr, "Recording for %s (Ctrl+C to stop early)...\n", duration)
+			}
+			select {
+			case <-time.After(duration):
+			case <-sigCtx.Done():
+			}
+		} else {
+			if !output.Quiet() {
+				fmt.Fprintln(os.Stderr, "Recording... press Ctrl+C to stop")
+			}
+			<-sigCtx.Done()
+		}
+		stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		data, err := stopAndDownloadRecording(ctx, args[0], outputPath)
+		if err != nil {
+			return err
+		}
+		return printRecording(data)
+	},
+}
+
+var computerRecordStartCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Start recording the VM display",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		fps, _ := cmd.Flags().GetInt("fps")
+		maxDuration, _ := cmd.Flags().GetDuration("max-duration")
+		if format != "webm" && format != "mp4" {
+			return fmt.Errorf("invalid --format %q (must be webm or mp4)", format)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		if err := startRecording(ctx, args[0], format, fps, maxDuration); err != nil {
+			return err
+		}
+
+		fmt.Printf("Recording started. Run 'cmux computer record stop %s <file>' to save it.\n", args[0])
+		return nil
+	},
+}
+
+var computerRecordStopCmd = &cobra.Command{
+	Use:   "stop <id> [output-file]",
+	Short: "Stop recording and download the video",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var outputPath string
+		if len(args) > 1 {
+			outputPath = args[1]
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		data, err := stopAndDownloadRecording(ctx, args[0], outputPath)
+		if err != nil {
+			return err
+		}
+		return printRecording(data)
+	},
+}
+
+func init() {
+	computerRecordCmd.Flags().Duration("duration", 0, "How long to record (default: until Ctrl+C)")
+	computerRecordCmd.Flags().Int("fps", 15, "Frames per second")
+
+	computerRecordStartCmd.Flags().String("format", "webm", "Video format: webm or mp4")
+	computerRecordStartCmd.Flags().Int("fps", 15, "Frames per second")
+	computerRecordStartCmd.Flags().Duration("max-duration", time.Hour, "Stop recording automatically after this long")
+
+	computerRecordCmd.AddCommand(computerRecordStartCmd)
+	computerRecordCmd.AddCommand(computerRecordStopCmd)
+	computerCmd.AddCommand(computerRecordCmd)
+}
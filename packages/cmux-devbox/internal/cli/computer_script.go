@@ -0,0 +1,632 @@
+// internal/cli/computer_script.go
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// scriptStep is one action of a browser automation script. A step is written
+// either as "action: value" or as "action:" with a mapping of options.
+type scriptStep struct {
+	Action string
+	Name   string
+	Value  string
+	Opts   map[string]string
+}
+
+// scriptVarPattern matches ${NAME} references to local environment variables
+var scriptVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// scriptActions lists the supported actions and what their scalar value is
+var scriptActions = map[string]string{
+	"open":       "url",
+	"navigate":   "url",
+	"click":      "selector",
+	"dblclick":   "selector",
+	"type":       "text",
+	"fill":       "selector",
+	"press":      "key",
+	"key":        "key",
+	"hover":      "selector",
+	"scroll":     "direction",
+	"wait":       "selector",
+	"sleep":      "duration",
+	"eval":       "script",
+	"screenshot": "path",
+	"assert":     "selector",
+}
+
+// parseScript reads a script file: either a list of steps, or a mapping
+// with "steps" and an optional "name". JSON files are accepted too.
+func parseScript(path string) (string, []scriptStep, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	var doc interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return "", nil, fmt.Errorf("invalid script %s: %w", path, err)
+		}
+	} else if doc, err = parseYAMLSubset(data); err != nil {
+		return "", nil, fmt.Errorf("invalid script %s: %w", path, err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	rawSteps, ok := doc.([]interface{})
+	if m, isMap := doc.(map[string]interface{}); isMap {
+		if n, ok := m["name"].(string); ok && n != "" {
+			name = n
+		}
+		rawSteps, ok = m["steps"].([]interface{})
+	}
+	if !ok || len(rawSteps) == 0 {
+		return "", nil, fmt.Errorf("invalid script %s: expected a list of steps", path)
+	}
+
+	steps := make([]scriptStep, 0, len(rawSteps))
+	for i, raw := range rawSteps {
+		step, err := parseScriptStep(raw)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid script %s: step %d: %w", path, i+1, err)
+		}
+		steps = append(steps, step)
+	}
+	return name, steps, nil
+}
+
+func parseScriptStep(raw interface{}) (scriptStep, error) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return scriptStep{}, fmt.Errorf("expected \"action: value\"")
+	}
+
+	var step scriptStep
+	for key, value := range m {
+		if key == "name" {
+			step.Name = scriptScalar(value)
+			continue
+		}
+		if _, known := scriptActions[key]; !known {
+			return scriptStep{}, fmt.Errorf("unknown action %q", key)
+		}
+		if step.Action != "" {
+			return scriptStep{}, fmt.Errorf("multiple actions (%s, %s); use one per step", step.Action, key)
+		}
+		step.Action = key
+		if opts, isMap := value.(map[string]interface{}); isMap {
+			step.Opts = map[string]string{}
+			for k, v := range opts {
+				step.Opts[k] = scriptScalar(v)
+			}
+		} else {
+			step.Value = scriptScalar(value)
+		}
+	}
+	if step.Action == "" {
+		return scriptStep{}, fmt.Errorf("missing action")
+	}
+
+	// A scalar value is shorthand for the action's main option
+	if step.Opts == nil {
+		step.Opts = map[string]string{scriptActions[step.Action]: step.Value}
+	}
+	for k, v := range step.Opts {
+		expanded, err := expandScriptVars(v)
+		if err != nil {
+			return scriptStep{}, err
+		}
+		step.Opts[k] = expanded
+	}
+	return step, nil
+}
+
+// scriptScalar renders a scalar from YAML (always a string) or JSON
+func scriptScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// expandScriptVars replaces ${NAME} with the local environment variable, so
+// scripts can reference credentials without containing them
+func expandScriptVars(s string) (string, error) {
+	var missing []string
+	expanded := scriptVarPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := scriptVarPattern.FindStringSubmatch(ref)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// scriptDuration parses a duration like "500ms" or "2s"; bare numbers are
+// milliseconds
+func scriptDuration(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if ms, err := strconv.Atoi(s); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// describe summarizes a step for progress output
+func (s scriptStep) describe() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	keys := make([]string, 0, len(s.Opts))
+	for k := range s.Opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		v := s.Opts[k]
+		if (s.Action == "type" || s.Action == "fill") && (k == "text" || k == "value") {
+			v = strings.Repeat("*", min(len(v), 8)) // may hold secrets
+		}
+		if k == scriptActions[s.Action] {
+			parts = append([]string{v}, parts...)
+		} else {
+			parts = append(parts, k+"="+v)
+		}
+	}
+	return strings.TrimSpace(s.Action + " " + strings.Join(parts, " "))
+}
+
+// workerRequest maps steps that are a single worker call to their endpoint
+// and request body
+func (s scriptStep) workerRequest() (string, map[string]interface{}, error) {
+	o := s.Opts
+	require := func(keys ...string) error {
+		for _, k := range keys {
+			if o[k] == "" {
+				return fmt.Errorf("%s: %s is required", s.Action, k)
+			}
+		}
+		return nil
+	}
+
+	switch s.Action {
+	case "open", "navigate":
+		return "/open", map[string]interface{}{"url": o["url"]}, require("url")
+	case "click":
+		if o["x"] != "" || o["y"] != "" {
+			x, errX := strconv.Atoi(o["x"])
+			y, errY := strconv.Atoi(o["y"])
+			if errX != nil || errY != nil {
+				return "", nil, fmt.Errorf("click: x and y must be integers")
+			}
+			button := o["button"]
+			if button == "" {
+				button = "left"
+			}
+			return "/click", map[string]interface{}{"x": x, "y": y, "button": button}, nil
+		}
+		return "/click", map[string]interface{}{"selector": o["selector"]}, require("selector")
+	case "dblclick", "hover":
+		return "/" + s.Action, map[string]interface{}{"selector": o["selector"]}, require("selector")
+	case "type":
+		return "/type", map[string]interface{}{"selector": o["selector"], "text": o["text"]}, require("text")
+	case "fill":
+		return "/fill", map[string]interface{}{"selector": o["selector"], "value": o["value"]}, require("selector")
+	case "press", "key":
+		if err := require("key"); err != nil {
+			return "", nil, err
+		}
+		key, err := normalizeKeyCombo(o["key"])
+		return "/press", map[string]interface{}{"key": key}, err
+	case "scroll":
+		// "scroll: down 500" is shorthand for direction and pixels
+		direction, pixels, _ := strings.Cut(o["direction"], " ")
+		if p := o["pixels"]; p != "" {
+			pixels = p
+		}
+		body := map[string]interface{}{"direction": direction}
+		if pixels != "" {
+			n, err := strconv.Atoi(strings.TrimSpace(pixels))
+			if err != nil || n <= 0 {
+				return "", nil, fmt.Errorf("scroll: pixels must be a positive integer")
+			}
+			body["amount"] = n
+		}
+		return "/scroll", body, require("direction")
+	case "wait":
+		timeout, err := scriptDuration(o["timeout"], 30*time.Second)
+		if err != nil {
+			return "", nil, err
+		}
+		state := o["state"]
+		if state == "" {
+			state = "visible"
+		}
+		return "/wait", map[string]interface{}{
+			"selector": o["selector"],
+			"state":    state,
+			"timeout":  timeout.Milliseconds(),
+		}, require("selector")
+	case "eval":
+		return "/eval", map[string]interface{}{"script": o["script"]}, require("script")
+	}
+	return "", nil, nil
+}
+
+// validate checks a step's options before anything runs
+func (s scriptStep) validate() error {
+	if _, _, err := s.workerRequest(); err != nil {
+		return err
+	}
+	switch s.Action {
+	case "sleep":
+		_, err := scriptDuration(s.Opts["duration"], 0)
+		return err
+	case "assert":
+		o := s.Opts
+		if o["selector"] == "" && o["url"] == "" && o["title"] == "" && o["eval"] == "" {
+			return fmt.Errorf("assert: needs selector, url, title, or eval")
+		}
+		if o["text"] != "" && strings.HasPrefix(o["selector"], "@") {
+			return fmt.Errorf("assert: text requires a CSS selector, not a ref")
+		}
+		if o["text"] != "" && o["selector"] == "" {
+			return fmt.Errorf("assert: text requires a selector")
+		}
+		_, err := scriptDuration(o["timeout"], 0)
+		return err
+	}
+	return nil
+}
+
+// scriptRunner executes steps against one VM's worker, saving screenshots
+// to an artifacts directory
+type scriptRunner struct {
+	workerURL    string
+	token        string
+	artifactsDir string
+	screenshots  int
+}
+
+func (r *scriptRunner) call(endpoint string, body map[string]interface{}, timeout time.Duration) (map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return callWorkerAPI(ctx, r.workerURL, r.token, endpoint, body)
+}
+
+// resultData returns data[key] from a worker response as a string
+func resultData(result map[string]interface{}, key string) string {
+	if data, ok := result["data"].(map[string]interface{}); ok {
+		if v, ok := data[key]; ok && v != nil {
+			if s, ok := v.(string); ok {
+				return s
+			}
+			out, _ := json.Marshal(v)
+			return string(out)
+		}
+	}
+	return ""
+}
+
+// run executes a step and returns an optional detail line, e.g. the path of a
+// screenshot or the result of eval
+func (r *scriptRunner) run(step scriptStep) (string, error) {
+	o := step.Opts
+	switch step.Action {
+	case "sleep":
+		d, _ := scriptDuration(o["duration"], 0)
+		time.Sleep(d)
+		return "", nil
+	case "screenshot":
+		return r.screenshot(o["path"])
+	case "assert":
+		return "", r.assert(o)
+	}
+
+	endpoint, body, err := step.workerRequest()
+	if err != nil {
+		return "", err
+	}
+	timeout := 60 * time.Second
+	if t, ok := body["timeout"].(int64); ok {
+		timeout += time.Duration(t) * time.Millisecond
+	}
+	result, err := r.call(endpoint, body, timeout)
+	if err != nil {
+		return "", err
+	}
+	if step.Action == "eval" {
+		return resultData(result, "result"), nil
+	}
+	return "", nil
+}
+
+// screenshot saves a screenshot under the artifacts directory. Paths are
+// relative to it; the default name numbers screenshots in order.
+func (r *scriptRunner) screenshot(name string) (string, error) {
+	r.screenshots++
+	if name == "" {
+		name = fmt.Sprintf("screenshot-%02d.png", r.screenshots)
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.artifactsDir, name)
+	}
+
+	result, err := r.call("/screenshot", nil, 60*time.Second)
+	if err != nil {
+		return "", err
+	}
+	b64Data := resultData(result, "base64")
+	if b64Data == "" {
+		return "", fmt.Errorf("screenshot failed: no base64 data in response")
+	}
+	data, err := base64.StdEncoding.DecodeString(b64Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	return path, nil
+}
+
+// assert checks the page URL or title (substring match), an element's state
+// and text, or that a script returns a truthy value. Element checks wait up
+// to timeout (default 5s) for the page to settle.
+func (r *scriptRunner) assert(o map[string]string) error {
+	if want := o["url"]; want != "" {
+		result, err := r.call("/url", nil, 60*time.Second)
+		if err != nil {
+			return err
+		}
+		if got := resultData(result, "url"); !strings.Contains(got, want) {
+			return fmt.Errorf("expected URL to contain %q, got %q", want, got)
+		}
+	}
+	if want := o["title"]; want != "" {
+		result, err := r.call("/title", nil, 60*time.Second)
+		if err != nil {
+			return err
+		}
+		if got := resultData(result, "title"); !strings.Contains(got, want) {
+			return fmt.Errorf("expected title to contain %q, got %q", want, got)
+		}
+	}
+	if selector := o["selector"]; selector != "" {
+		timeout, _ := scriptDuration(o["timeout"], 5*time.Second)
+		state := o["state"]
+		if state == "" {
+			state = "visible"
+		}
+		if _, err := r.call("/wait", map[string]interface{}{
+			"selector": selector,
+			"state":    state,
+			"timeout":  timeout.Milliseconds(),
+		}, 60*time.Second+timeout); err != nil {
+			return fmt.Errorf("expected %s to be %s: %w", selector, state, err)
+		}
+		if want := o["text"]; want != "" {
+			script := fmt.Sprintf(`(() => { const el = document.querySelector(%s); return el ? el.innerText : null; })()`, strconv.Quote(selector))
+			result, err := r.call("/eval", map[string]interface{}{"script": script}, 60*time.Second)
+			if err != nil {
+				return err
+			}
+			if got := resultData(result, "result"); !strings.Contains(got, want) {
+				return fmt.Errorf("expected %s to contain %q, got %q", selector, want, got)
+			}
+		}
+	}
+	if script := o["eval"]; script != "" {
+		result, err := r.call("/eval", map[string]interface{}{"script": script}, 60*time.Second)
+		if err != nil {
+			return err
+		}
+		switch got := resultData(result, "result"); got {
+		case "", "false", "null", "0", `""`, "undefined":
+			return fmt.Errorf("expected %s to be truthy, got %s", script, got)
+		}
+	}
+	return nil
+}
+
+// scriptStepResult is one step in a script run's results.json
+type scriptStepResult struct {
+	Step       int    `json:"step"`
+	Action     string `json:"action"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMs int64  `json:"durationMs"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// scriptResult summarizes a script run
+type scriptResult struct {
+	Script       string             `json:"script"`
+	Instance     string             `json:"instance"`
+	Passed       bool               `json:"passed"`
+	DurationMs   int64              `json:"durationMs"`
+	ArtifactsDir string             `json:"artifactsDir"`
+	Steps        []scriptStepResult `json:"steps"`
+}
+
+var computerScriptCmd = &cobra.Command{
+	Use:   "script <id> <file>",
+	Short: "Run a browser automation script",
+	Long: `Run a sequence of browser steps from a YAML (or JSON) file.
+
+Each step is one action, written as "action: value" or as "action:" with
+options. Steps run in order and the script stops at the first failure,
+saving a screenshot of the failure. Screenshots and a results.json summary
+are saved to the artifacts directory.
+
+Actions:
+  open: <url>                        Navigate (alias: navigate)
+  click: <selector>                  Also {x, y, button} for coordinates
+  dblclick / hover: <selector>
+  type: <text>                       Also {selector, text}
+  fill: {selector, value}
+  key: <combo>                       e.g. ctrl+s (alias: press)
+  scroll: <direction> [pixels]       Also {direction, pixels}
+  wait: <selector>                   Also {selector, state, timeout}
+  sleep: <duration>                  e.g. 500ms, 2s
+  eval: <script>
+  screenshot: [file]                 Saved under the artifacts directory
+  assert: {url, title, selector, state, text, eval, timeout}
+
+Any step can have a "name" shown in the output. ${NAME} in a value is
+replaced with the local environment variable, e.g. for passwords.
+
+Example flow.yaml:
+  name: Login
+  steps:
+    - open: https://example.com/login
+    - fill: {selector: "#email", value: "${LOGIN_EMAIL}"}
+    - fill: {selector: "#password", value: "${LOGIN_PASSWORD}"}
+    - click: "#submit"
+    - assert:
+        selector: ".welcome"
+        text: Welcome
+    - screenshot: dashboard.png
+
+Examples:
+  cmux computer script cmux_abc123 flow.yaml
+  cmux computer script cmux_abc123 flow.yaml --artifacts ./out`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, scriptPath := args[0], args[1]
+		name, steps, err := parseScript(scriptPath)
+		if err != nil {
+			return output.WithExitCode(err, output.ExitUsage)
+		}
+		for i, step := range steps {
+			if err := step.validate(); err != nil {
+				return output.WithExitCode(fmt.Errorf("invalid script %s: step %d: %w", scriptPath, i+1, err), output.ExitUsage)
+			}
+		}
+
+		artifactsDir, _ := cmd.Flags().GetString("artifacts")
+		if artifactsDir == "" {
+			artifactsDir = filepath.Join("cmux-artifacts", fmt.Sprintf("%s-%s", name, time.Now().Format("20060102-150405")))
+		}
+		if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create artifacts directory: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		workerURL, token, err := getWorkerClient(ctx, instanceID)
+		cancel()
+		if err != nil {
+			return err
+		}
+		runner := &scriptRunner{workerURL: workerURL, token: token, artifactsDir: artifactsDir}
+
+		structured := output.Structured()
+		if !structured {
+			output.Infof("Running %s (%d steps) on %s\n", name, len(steps), instanceID)
+		}
+
+		summary := scriptResult{Script: name, Instance: instanceID, Passed: true, ArtifactsDir: artifactsDir}
+		started := time.Now()
+		var runErr error
+		for i, step := range steps {
+			stepStart := time.Now()
+			detail, err := runner.run(step)
+			res := scriptStepResult{
+				Step:       i + 1,
+				Action:     step.Action,
+				Name:       step.describe(),
+				Status:     "passed",
+				DurationMs: time.Since(stepStart).Milliseconds(),
+				Detail:     detail,
+			}
+			if err != nil {
+				res.Status = "failed"
+				res.Error = err.Error()
+			}
+			summary.Steps = append(summary.Steps, res)
+
+			if !structured {
+				mark := "✓"
+				if err != nil {
+					mark = "✗"
+				}
+				fmt.Printf("  %s [%d/%d] %s (%s)\n", mark, i+1, len(steps), res.Name, time.Duration(res.DurationMs)*time.Millisecond)
+				if detail != "" {
+					fmt.Printf("      %s\n", detail)
+				}
+				if err != nil {
+					fmt.Printf("      %v\n", err)
+				}
+			}
+
+			if err != nil {
+				summary.Passed = false
+				runErr = fmt.Errorf("step %d (%s) failed: %w", i+1, res.Name, err)
+				if path, shotErr := runner.screenshot("failure.png"); shotErr == nil && !structured {
+					fmt.Printf("      Screenshot: %s\n", path)
+				}
+				break
+			}
+		}
+		summary.DurationMs = time.Since(started).Milliseconds()
+
+		resultsPath := filepath.Join(artifactsDir, "results.json")
+		if data, err := json.MarshalIndent(summary, "", "  "); err == nil {
+			if err := os.WriteFile(resultsPath, append(data, '\n'), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", resultsPath, err)
+			}
+		}
+
+		if structured {
+			if err := output.Print(summary); err != nil {
+				return err
+			}
+		} else if runErr == nil {
+			fmt.Printf("✓ %d steps passed in %s\n", len(steps), time.Duration(summary.DurationMs)*time.Millisecond)
+			fmt.Printf("  Artifacts: %s\n", artifactsDir)
+		} else {
+			fmt.Printf("  Artifacts: %s\n", artifactsDir)
+		}
+		return runErr
+	},
+}
+
+func init() {
+	computerScriptCmd.Flags().String("artifacts", "", "Directory for screenshots and results.json (default: cmux-artifacts/<name>-<time>)")
+	computerCmd.AddCommand(computerScriptCmd)
+}
@@ -0,0 +1,303 @@
+// internal/cli/computer_script_yaml.go
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Script files are parsed with a small YAML subset rather than a full YAML
+// library: block mappings and sequences, quoted and plain scalars, # comments,
+// and single-line flow collections like [a, b] and {a: b}. Scalars are kept as
+// strings; steps convert numbers where they need them.
+
+type yamlLine struct {
+	num    int // 1-based line number in the file
+	indent int
+	text   string
+}
+
+// parseYAMLSubset parses a document into map[string]interface{},
+// []interface{}, and string values
+func parseYAMLSubset(data []byte) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		text := strings.TrimSpace(stripYAMLComment(trimmed))
+		if text == "" || text == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(raw) - len(trimmed), text: text})
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.parseNode(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].num)
+	}
+	return value, nil
+}
+
+// stripYAMLComment removes a trailing # comment outside quotes
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+		if !isSequenceItem(line.text) {
+			break
+		}
+
+		rest := strings.TrimSpace(line.text[1:])
+		switch {
+		case rest == "":
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				items = append(items, "")
+				continue
+			}
+			item, err := p.parseNode(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case yamlMappingKey(rest) >= 0 && !strings.HasPrefix(rest, "{"):
+			// "- key: value" starts a mapping indented to where key starts
+			p.lines[p.pos] = yamlLine{num: line.num, indent: line.indent + len(line.text) - len(rest), text: rest}
+			item, err := p.parseMapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			value, err := parseYAMLScalar(rest, line.num)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && isSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.num)
+		}
+
+		colon := yamlMappingKey(line.text)
+		if colon < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.num)
+		}
+		key, err := unquoteYAMLKey(strings.TrimSpace(line.text[:colon]), line.num)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.num, key)
+		}
+		rest := strings.TrimSpace(line.text[colon+1:])
+		p.pos++
+
+		if rest != "" {
+			value, err := parseYAMLScalar(rest, line.num)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+			continue
+		}
+
+		// A nested block: deeper lines, or a sequence at the same indent
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			if next.indent > indent || (next.indent == indent && isSequenceItem(next.text)) {
+				value, err := p.parseNode(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = value
+				continue
+			}
+		}
+		m[key] = ""
+	}
+	return m, nil
+}
+
+// yamlMappingKey returns the index of the colon ending a mapping key, or -1
+func yamlMappingKey(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+func unquoteYAMLKey(key string, lineNum int) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("line %d: empty key", lineNum)
+	}
+	value, err := parseYAMLScalar(key, lineNum)
+	if err != nil {
+		return "", err
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("line %d: invalid key %q", lineNum, key)
+	}
+	return s, nil
+}
+
+// parseYAMLScalar parses a quoted or plain scalar, or a one-line flow
+// collection
+func parseYAMLScalar(text string, lineNum int) (interface{}, error) {
+	switch text[0] {
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted string %s", lineNum, text)
+		}
+		return s, nil
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("line %d: unterminated quoted string %s", lineNum, text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '[':
+		if text[len(text)-1] != ']' {
+			return nil, fmt.Errorf("line %d: unterminated list %s", lineNum, text)
+		}
+		items := []interface{}{}
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			value, err := parseYAMLScalar(part, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case '{':
+		if text[len(text)-1] != '}' {
+			return nil, fmt.Errorf("line %d: unterminated mapping %s", lineNum, text)
+		}
+		m := map[string]interface{}{}
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			colon := yamlMappingKey(part)
+			if colon < 0 {
+				return nil, fmt.Errorf("line %d: expected \"key: value\" in %s", lineNum, text)
+			}
+			key, err := unquoteYAMLKey(strings.TrimSpace(part[:colon]), lineNum)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = ""
+			if rest := strings.TrimSpace(part[colon+1:]); rest != "" {
+				if m[key], err = parseYAMLScalar(rest, lineNum); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return m, nil
+	}
+	if text == "~" || text == "null" {
+		return "", nil
+	}
+	return text, nil
+}
+
+// splitFlowItems splits "a, 'b, c', d" on commas outside quotes
+func splitFlowItems(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
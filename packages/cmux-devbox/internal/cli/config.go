@@ -2,11 +2,10 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -18,15 +17,17 @@ var configCmd = &cobra.Command{
 Configuration priority (highest to lowest):
   1. CLI flags (--api-url, --convex-url)
   2. Environment variables (CMUX_API_URL, CONVEX_SITE_URL, etc.)
-  3. Build-time values (compiled into binary)
-  4. Hardcoded defaults
+  3. The active profile (see 'cmux profile')
+  4. Build-time values (compiled into binary)
+  5. Hardcoded defaults
 
 Environment variables:
   STACK_PROJECT_ID              Stack Auth project ID
   STACK_PUBLISHABLE_CLIENT_KEY  Stack Auth publishable client key
   CMUX_API_URL                  cmux web app URL
   CONVEX_SITE_URL               Convex HTTP site URL
-  AUTH_API_URL                  Stack Auth API URL`,
+  AUTH_API_URL                  Stack Auth API URL
+  CMUX_PROFILE                  Profile to use`,
 	RunE: runConfig,
 }
 
@@ -35,6 +36,7 @@ func init() {
 }
 
 type configOutput struct {
+	Profile        string `json:"profile"`
 	ProjectID      string `json:"project_id"`
 	CmuxURL        string `json:"cmux_url"`
 	ConvexSiteURL  string `json:"convex_site_url"`
@@ -46,8 +48,9 @@ type configOutput struct {
 func runConfig(cmd *cobra.Command, args []string) error {
 	cfg := auth.GetConfig()
 
-	if flagJSON {
-		output := configOutput{
+	if output.Structured() {
+		result := configOutput{
+			Profile:       auth.ActiveProfile(),
 			ProjectID:     cfg.ProjectID,
 			CmuxURL:       cfg.CmuxURL,
 			ConvexSiteURL: cfg.ConvexSiteURL,
@@ -55,13 +58,12 @@ func runConfig(cmd *cobra.Command, args []string) error {
 			IsDev:         cfg.IsDev,
 			BuildMode:     buildMode,
 		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(output)
+		return output.Print(result)
 	}
 
 	fmt.Println("Current configuration:")
 	fmt.Println()
+	fmt.Printf("  Profile:         %s\n", auth.ActiveProfile())
 	fmt.Printf("  Build mode:      %s\n", buildMode)
 	fmt.Printf("  Is dev:          %v\n", cfg.IsDev)
 	fmt.Println()
@@ -0,0 +1,107 @@
+// internal/cli/cp.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// parseCopyArg splits "<id>:<path>" into its parts. Anything else, including
+// Windows drive paths like C:\file, is a local path.
+func parseCopyArg(arg string) (instanceID, path string, remote bool) {
+	prefix, rest, ok := strings.Cut(arg, ":")
+	if !ok || len(prefix) < 2 || strings.ContainsAny(prefix, `/\`) {
+		return "", arg, false
+	}
+	return prefix, rest, true
+}
+
+var cpCmd = &cobra.Command{
+	Use:   "cp <source> <dest>",
+	Short: "Copy a file to or from a VM",
+	Long: `Copy a single file between your machine and a VM over SSH.
+
+Exactly one of source and dest is a VM path, written <id>:<path> (the id can
+be "last"). Relative VM paths are resolved against the VM workspace; a VM
+path ending in "/" copies into that directory. Use 'cmux sync' for whole
+directories.
+
+Examples:
+  cmux cp ./config.json cmux_abc123:config.json     # Into the workspace
+  cmux cp ./.env last:/root/workspace/app/          # Into a directory
+  cmux cp cmux_abc123:dist/app.tar.gz .             # Grab a build artifact`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		srcID, srcPath, srcRemote := parseCopyArg(args[0])
+		dstID, dstPath, dstRemote := parseCopyArg(args[1])
+		if srcRemote == dstRemote {
+			return fmt.Errorf("exactly one of source and dest must be a VM path (<id>:<path>)")
+		}
+
+		instanceID, remotePath := srcID, srcPath
+		if dstRemote {
+			instanceID, remotePath = dstID, dstPath
+		}
+		instanceID, err := resolveInstanceID(instanceID)
+		if err != nil {
+			return err
+		}
+		if remotePath == "" {
+			return fmt.Errorf("VM path is empty")
+		}
+
+		if dstRemote {
+			info, err := os.Stat(srcPath)
+			if err != nil {
+				return fmt.Errorf("path not found: %w", err)
+			}
+			if info.IsDir() {
+				return fmt.Errorf("%s is a directory; use 'cmux sync' to copy directories", srcPath)
+			}
+		}
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := vm.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client.SetTeamSlug(teamSlug)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		var progress io.Writer = os.Stdout
+		if output.Quiet() {
+			progress = nil
+		}
+
+		if dstRemote {
+			err = client.CopyToVM(ctx, instanceID, srcPath, remotePath, progress)
+		} else {
+			err = client.CopyFromVM(ctx, instanceID, remotePath, dstPath, progress)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy: %w", err)
+		}
+
+		fmt.Printf("✓ Copied %s to %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
@@ -39,11 +41,12 @@ Examples:
 		}
 		client.SetTeamSlug(teamSlug)
 
-		fmt.Printf("Deleting VM %s...\n", instanceID)
+		output.Infof("Deleting VM %s...\n", instanceID)
 		if err := client.StopInstance(ctx, instanceID); err != nil {
 			return fmt.Errorf("failed to delete VM: %w", err)
 		}
 
+		state.ClearAutoPaused(instanceID)
 		fmt.Println("✓ VM deleted")
 		return nil
 	},
@@ -0,0 +1,283 @@
+// internal/cli/doctor.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is the result of a single diagnostic
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+func checkVersion() doctorCheck {
+	return doctorCheck{
+		Name:   "Version",
+		Status: checkOK,
+		Detail: fmt.Sprintf("cmux %s (%s, %s build), %s, %s/%s", version, commit, buildMode, runtime.Version(), runtime.GOOS, runtime.GOARCH),
+	}
+}
+
+func checkConfig(cfg auth.Config) doctorCheck {
+	if err := cfg.Validate(); err != nil {
+		return doctorCheck{
+			Name:   "Configuration",
+			Status: checkFail,
+			Detail: err.Error(),
+			Fix:    "Set the missing values as environment variables, or reinstall an official build",
+		}
+	}
+	return doctorCheck{Name: "Configuration", Status: checkOK, Detail: "all required values set (see 'cmux config')"}
+}
+
+// checkCredentialStore verifies the refresh token store can be read
+func checkCredentialStore() doctorCheck {
+	check := doctorCheck{Name: "Credential store"}
+	location := auth.CredentialStoreLocation()
+
+	if _, source, ok := auth.APIKey(); ok {
+		check.Status = checkOK
+		check.Detail = "using API key from " + source
+		return check
+	}
+
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("security"); err != nil {
+			check.Status = checkFail
+			check.Detail = "macOS 'security' tool not found, so the Keychain can't be used"
+			check.Fix = "Make sure /usr/bin is on your PATH"
+			return check
+		}
+	} else if info, err := os.Stat(location); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s is readable by other users (mode %s)", location, info.Mode().Perm())
+		check.Fix = fmt.Sprintf("Run 'chmod 600 %s'", location)
+		return check
+	}
+
+	if _, err := auth.GetRefreshToken(); err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("no refresh token in %s", location)
+		check.Fix = "Run 'cmux auth login', or set " + auth.APIKeyEnv + " for non-interactive use"
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = "refresh token found in " + location
+	return check
+}
+
+// checkTokenRefresh forces a token refresh and looks up the user's team
+func checkTokenRefresh() doctorCheck {
+	check := doctorCheck{Name: "Authentication"}
+
+	if _, err := auth.RefreshAccessToken(); err != nil {
+		check.Status = checkFail
+		check.Detail = "token refresh failed: " + err.Error()
+		check.Fix = "Run 'cmux auth login' to sign in again"
+		return check
+	}
+
+	teamSlug, err := auth.GetTeamSlug()
+	if err != nil {
+		check.Status = checkWarn
+		check.Detail = "token refresh works, but no team was found: " + err.Error()
+		check.Fix = "Select a team in the cmux web app, then run 'cmux auth status'"
+		return check
+	}
+
+	check.Status = checkOK
+	check.Detail = "token refresh works, team " + teamSlug
+	return check
+}
+
+// checkReachable treats any HTTP response as reachable; only network errors fail
+func checkReachable(ctx context.Context, name, rawURL, fix string) doctorCheck {
+	check := doctorCheck{Name: name}
+	if rawURL == "" {
+		check.Status = checkFail
+		check.Detail = "URL not configured"
+		check.Fix = fix
+		return check
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("invalid URL %s: %v", rawURL, err)
+		check.Fix = fix
+		return check
+	}
+
+	start := time.Now()
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("%s unreachable: %v", rawURL, err)
+		check.Fix = "Check your network, proxy, or VPN settings, or the URL. " + fix
+		return check
+	}
+	resp.Body.Close()
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s (%d in %s)", rawURL, resp.StatusCode, time.Since(start).Round(time.Millisecond))
+	if resp.StatusCode >= 500 {
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%s returned %d", rawURL, resp.StatusCode)
+		check.Fix = "The service may be having problems; try again in a few minutes"
+	}
+	return check
+}
+
+// checkTool looks for an external program and reports the first line of its
+// version output
+func checkTool(ctx context.Context, name, neededFor string, versionArgs ...string) doctorCheck {
+	check := doctorCheck{Name: name}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		check.Status = checkFail
+		check.Detail = fmt.Sprintf("not found on PATH (needed for %s)", neededFor)
+		check.Fix = toolInstallHint(name)
+		return check
+	}
+
+	// ssh -V prints to stderr
+	output, _ := exec.CommandContext(ctx, path, versionArgs...).CombinedOutput()
+	versionLine := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	if versionLine == "" {
+		versionLine = "version unknown"
+	}
+
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("%s (%s)", versionLine, path)
+	return check
+}
+
+func toolInstallHint(name string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return fmt.Sprintf("Run 'brew install %s'", name)
+	case "windows":
+		if name == "ssh" {
+			return "Install the OpenSSH client from Settings > Optional features"
+		}
+		return fmt.Sprintf("Install %s via WSL, MSYS2, or cwRsync", name)
+	default:
+		if name == "ssh" {
+			return "Install the OpenSSH client (e.g. 'sudo apt install openssh-client')"
+		}
+		return fmt.Sprintf("Install %s with your package manager (e.g. 'sudo apt install %s')", name, name)
+	}
+}
+
+func printDoctorCheck(check doctorCheck) {
+	symbol := "✓"
+	switch check.Status {
+	case checkWarn:
+		symbol = "!"
+	case checkFail:
+		symbol = "✗"
+	}
+	fmt.Printf("%s %-18s %s\n", symbol, check.Name, check.Detail)
+	if check.Fix != "" {
+		fmt.Printf("  %-18s → %s\n", "", check.Fix)
+	}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common setup problems",
+	Long: `Check that cmux can authenticate, reach its services, and find the
+tools it depends on, with a suggested fix for each problem found.
+
+Checks:
+  - Version and configuration
+  - Credential store (macOS Keychain or credentials file)
+  - Authentication (forces a token refresh)
+  - Reachability of the Convex, cmux web, and Stack Auth URLs
+  - ssh and rsync availability
+
+Include the output when reporting a problem.
+
+Examples:
+  cmux doctor
+  cmux doctor --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		cfg := auth.GetConfig()
+		var checks []doctorCheck
+		run := func(check doctorCheck) {
+			checks = append(checks, check)
+			if !output.Structured() {
+				printDoctorCheck(check)
+			}
+		}
+
+		run(checkVersion())
+		run(checkConfig(cfg))
+		run(checkReachable(ctx, "Convex", cfg.ConvexSiteURL, "Override with --convex-url or CONVEX_SITE_URL"))
+		run(checkReachable(ctx, "cmux web", cfg.CmuxURL, "Override with --api-url or CMUX_API_URL"))
+		run(checkReachable(ctx, "Stack Auth", cfg.StackAuthURL, "Override with AUTH_API_URL"))
+		credentials := checkCredentialStore()
+		run(credentials)
+		if credentials.Status != checkFail {
+			run(checkTokenRefresh())
+		}
+		run(checkTool(ctx, "ssh", "cmux ssh", "-V"))
+		run(checkTool(ctx, "rsync", "cmux sync and cmux cp", "--version"))
+
+		failed, warned := 0, 0
+		for _, check := range checks {
+			switch check.Status {
+			case checkFail:
+				failed++
+			case checkWarn:
+				warned++
+			}
+		}
+
+		if output.Structured() {
+			output.Print(checks)
+		} else {
+			fmt.Println()
+			if failed == 0 && warned == 0 {
+				fmt.Println("✓ No problems found")
+			} else {
+				fmt.Printf("%d problem(s), %d warning(s)\n", failed, warned)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,473 @@
+// internal/cli/env.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environments",
+	Long: `Create, inspect, update, and delete environments.
+
+An environment is a snapshot of a configured VM together with the repos,
+scripts, and environment variables new sandboxes start with. Creating one
+from the CLI lets environment setup be scripted and kept in version control.
+
+Examples:
+  cmux env list
+  cmux env create --name web --from cmux_abc123 --repo acme/web --env-file .env
+  cmux env show <env-id>
+  cmux env update <env-id> --env API_URL=https://staging.acme.dev
+  cmux env delete <env-id>`,
+}
+
+// newTeamClient creates a VM client for the current team
+func newTeamClient() (*vm.Client, error) {
+	teamSlug, err := auth.GetTeamSlug()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	client, err := vm.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	client.SetTeamSlug(teamSlug)
+	return client, nil
+}
+
+// envVarKey returns the variable name a .env line assigns, or "" for blank
+// lines and comments
+func envVarKey(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	line = strings.TrimPrefix(line, "export ")
+	key, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
+
+// applyEnvVars sets KEY=VALUE assignments and removes unset keys in .env
+// content, keeping comments and the order of existing lines
+func applyEnvVars(content string, set []string, unset []string) (string, error) {
+	values := make(map[string]string)
+	var order []string
+	for _, assignment := range set {
+		key, value, ok := strings.Cut(assignment, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return "", fmt.Errorf("invalid --env %q (expected KEY=VALUE)", assignment)
+		}
+		if _, seen := values[key]; !seen {
+			order = append(order, key)
+		}
+		values[key] = key + "=" + value
+	}
+	removed := make(map[string]bool)
+	for _, key := range unset {
+		removed[key] = true
+	}
+
+	var lines []string
+	if content != "" {
+		for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+			key := envVarKey(line)
+			if removed[key] {
+				continue
+			}
+			if replacement, ok := values[key]; ok && key != "" {
+				line = replacement
+				delete(values, key)
+			}
+			lines = append(lines, line)
+		}
+	}
+	for _, key := range order {
+		if line, ok := values[key]; ok {
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// readScriptFlag returns a script given inline, or read from a file when the
+// value starts with "@"
+func readScriptFlag(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := os.ReadFile(value[1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to read script: %w", err)
+	}
+	return string(data), nil
+}
+
+func printEnvironment(env *vm.Environment) {
+	fmt.Printf("ID:          %s\n", env.ID)
+	fmt.Printf("Name:        %s\n", env.Name)
+	if env.Description != "" {
+		fmt.Printf("Description: %s\n", env.Description)
+	}
+	fmt.Printf("Snapshot:    %s\n", env.MorphSnapshotID)
+	if len(env.SelectedRepos) > 0 {
+		fmt.Printf("Repos:       %s\n", strings.Join(env.SelectedRepos, ", "))
+	}
+	if len(env.ExposedPorts) > 0 {
+		ports := make([]string, len(env.ExposedPorts))
+		for i, port := range env.ExposedPorts {
+			ports[i] = fmt.Sprint(port)
+		}
+		fmt.Printf("Ports:       %s\n", strings.Join(ports, ", "))
+	}
+	fmt.Printf("Updated:     %s\n", time.UnixMilli(env.UpdatedAt).Format(time.RFC3339))
+	if env.MaintenanceScript != "" {
+		fmt.Printf("\nMaintenance script:\n%s\n", env.MaintenanceScript)
+	}
+	if env.DevScript != "" {
+		fmt.Printf("\nDev script:\n%s\n", env.DevScript)
+	}
+}
+
+var envListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List environments",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		environments, err := client.ListEnvironments(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+
+		if output.Structured() {
+			return output.Print(environments)
+		}
+
+		if len(environments) == 0 {
+			fmt.Println("No environments found. Run 'cmux env create' to create one.")
+			return nil
+		}
+
+		fmt.Printf("%-34s %-24s %s\n", "ID", "NAME", "REPOS")
+		fmt.Println("---------------------------------- ------------------------ " + "------------------------------")
+		for _, env := range environments {
+			fmt.Printf("%-34s %-24s %s\n", env.ID, env.Name, strings.Join(env.SelectedRepos, ", "))
+		}
+
+		return nil
+	},
+}
+
+var envShowCmd = &cobra.Command{
+	Use:   "show <env-id>",
+	Short: "Show an environment",
+	Long: `Show an environment's settings and variable names.
+
+Variable values are hidden unless --reveal is set.
+
+Examples:
+  cmux env show <env-id>
+  cmux env show <env-id> --reveal > .env`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		reveal, _ := cmd.Flags().GetBool("reveal")
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		env, err := client.GetEnvironment(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+		vars, err := client.GetEnvironmentVars(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get environment variables: %w", err)
+		}
+
+		// --reveal prints the .env content alone so it can be redirected
+		if reveal {
+			fmt.Print(vars)
+			return nil
+		}
+
+		if output.Structured() {
+			return output.Print(env)
+		}
+
+		printEnvironment(env)
+		var keys []string
+		for _, line := range strings.Split(vars, "\n") {
+			if key := envVarKey(line); key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			fmt.Printf("\nVariables (values hidden, use --reveal):\n  %s\n", strings.Join(keys, "\n  "))
+		}
+
+		return nil
+	},
+}
+
+var envCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an environment from a VM",
+	Long: `Create an environment by snapshotting a running VM.
+
+Set up a VM the way new sandboxes should start (installed tools, cloned
+repos, caches), then snapshot it with --from. Scripts can be given inline or
+read from a file with @path.
+
+Examples:
+  cmux env create --name web --from cmux_abc123
+  cmux env create --name web --from last --repo acme/web --repo acme/api \
+    --env-file .env --env NODE_ENV=development \
+    --maintenance-script @scripts/maintenance.sh --dev-script "bun run dev"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		from, _ := cmd.Flags().GetString("from")
+		opts := vm.CreateEnvironmentOptions{Name: name}
+		opts.SelectedRepos, _ = cmd.Flags().GetStringArray("repo")
+		opts.Description, _ = cmd.Flags().GetString("description")
+		opts.ExposedPorts, _ = cmd.Flags().GetIntSlice("port")
+
+		var err error
+		maintenance, _ := cmd.Flags().GetString("maintenance-script")
+		if opts.MaintenanceScript, err = readScriptFlag(maintenance); err != nil {
+			return err
+		}
+		dev, _ := cmd.Flags().GetString("dev-script")
+		if opts.DevScript, err = readScriptFlag(dev); err != nil {
+			return err
+		}
+
+		envFile, _ := cmd.Flags().GetString("env-file")
+		var content string
+		if envFile != "" {
+			data, err := os.ReadFile(envFile)
+			if err != nil {
+				return fmt.Errorf("failed to read env file: %w", err)
+			}
+			content = string(data)
+		}
+		set, _ := cmd.Flags().GetStringArray("env")
+		if opts.EnvVarsContent, err = applyEnvVars(content, set, nil); err != nil {
+			return err
+		}
+
+		instanceID, err := resolveInstanceID(from)
+		if err != nil {
+			return err
+		}
+
+		// Snapshotting a VM can take a few minutes
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		instance, err := client.GetInstance(ctx, instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to get instance: %w", err)
+		}
+		if instance.MorphInstanceID == "" {
+			return fmt.Errorf("VM %s has no running sandbox to snapshot", instanceID)
+		}
+		opts.MorphInstanceID = instance.MorphInstanceID
+
+		output.Infof("Snapshotting %s into environment %q...\n", instanceID, name)
+		id, err := client.CreateEnvironment(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("failed to create environment: %w", err)
+		}
+
+		fmt.Printf("✓ Environment created: %s\n", id)
+		return nil
+	},
+}
+
+var envUpdateCmd = &cobra.Command{
+	Use:   "update <env-id>",
+	Short: "Update an environment",
+	Long: `Update an environment's settings. Only the flags you pass are changed.
+
+--env sets individual variables and --unset-env removes them, keeping the
+rest. --env-file replaces all variables with the file's content (combined
+with any --env flags). --repo replaces the repo list.
+
+Examples:
+  cmux env update <env-id> --name web-v2
+  cmux env update <env-id> --env API_URL=https://staging.acme.dev --unset-env DEBUG
+  cmux env update <env-id> --env-file .env.production
+  cmux env update <env-id> --dev-script @scripts/dev.sh`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		environmentID := args[0]
+		flags := cmd.Flags()
+		var opts vm.UpdateEnvironmentOptions
+
+		if flags.Changed("name") {
+			name, _ := flags.GetString("name")
+			opts.Name = &name
+		}
+		if flags.Changed("description") {
+			description, _ := flags.GetString("description")
+			opts.Description = &description
+		}
+		if flags.Changed("repo") {
+			repos, _ := flags.GetStringArray("repo")
+			opts.SelectedRepos = &repos
+		}
+		if flags.Changed("maintenance-script") {
+			value, _ := flags.GetString("maintenance-script")
+			script, err := readScriptFlag(value)
+			if err != nil {
+				return err
+			}
+			opts.MaintenanceScript = &script
+		}
+		if flags.Changed("dev-script") {
+			value, _ := flags.GetString("dev-script")
+			script, err := readScriptFlag(value)
+			if err != nil {
+				return err
+			}
+			opts.DevScript = &script
+		}
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		set, _ := flags.GetStringArray("env")
+		unset, _ := flags.GetStringArray("unset-env")
+		envFile, _ := flags.GetString("env-file")
+		if envFile != "" || len(set) > 0 || len(unset) > 0 {
+			var content string
+			if envFile != "" {
+				data, err := os.ReadFile(envFile)
+				if err != nil {
+					return fmt.Errorf("failed to read env file: %w", err)
+				}
+				content = string(data)
+			} else {
+				content, err = client.GetEnvironmentVars(ctx, environmentID)
+				if err != nil {
+					return fmt.Errorf("failed to get environment variables: %w", err)
+				}
+			}
+			content, err = applyEnvVars(content, set, unset)
+			if err != nil {
+				return err
+			}
+			opts.EnvVarsContent = &content
+		}
+
+		if opts == (vm.UpdateEnvironmentOptions{}) {
+			return fmt.Errorf("nothing to update (see 'cmux env update --help')")
+		}
+
+		env, err := client.UpdateEnvironment(ctx, environmentID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to update environment: %w", err)
+		}
+
+		fmt.Printf("✓ Environment %s updated\n", env.ID)
+		return nil
+	},
+}
+
+var envDeleteCmd = &cobra.Command{
+	Use:   "delete <env-id>",
+	Short: "Delete an environment",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteEnvironment(ctx, args[0]); err != nil {
+			return fmt.Errorf("failed to delete environment: %w", err)
+		}
+
+		fmt.Println("✓ Environment deleted")
+		return nil
+	},
+}
+
+func addEnvironmentFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Environment name")
+	cmd.Flags().String("description", "", "Environment description")
+	cmd.Flags().StringArray("repo", nil, "Repository (owner/name) to include, repeatable")
+	cmd.Flags().StringArray("env", nil, "Environment variable KEY=VALUE, repeatable")
+	cmd.Flags().String("env-file", "", "Read environment variables from a .env file")
+	cmd.Flags().String("maintenance-script", "", "Maintenance script, or @file to read it from a file")
+	cmd.Flags().String("dev-script", "", "Dev script, or @file to read it from a file")
+}
+
+func init() {
+	envShowCmd.Flags().Bool("reveal", false, "Print variables with their values in .env format")
+
+	addEnvironmentFlags(envCreateCmd)
+	envCreateCmd.Flags().String("from", "", "VM to snapshot (ID or 'last')")
+	envCreateCmd.Flags().IntSlice("port", nil, "Port to expose in sandboxes, repeatable")
+	envCreateCmd.MarkFlagRequired("name")
+	envCreateCmd.MarkFlagRequired("from")
+	envCreateCmd.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeInstanceIDsOrLast(cmd, nil, toComplete)
+	})
+
+	addEnvironmentFlags(envUpdateCmd)
+	envUpdateCmd.Flags().StringArray("unset-env", nil, "Remove an environment variable, repeatable")
+
+	envCmd.AddCommand(envListCmd)
+	envCmd.AddCommand(envShowCmd)
+	envCmd.AddCommand(envCreateCmd)
+	envCmd.AddCommand(envUpdateCmd)
+	envCmd.AddCommand(envDeleteCmd)
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,79 @@
+// internal/cli/errors.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// instanceArgPattern matches commands whose arguments name an instance, so
+// a 404 from them means the sandbox is gone rather than some other resource
+var instanceArgPattern = regexp.MustCompile(`[<\[]id[|>\]]`)
+
+// classifyError gives an error returned by cmd an error code and a hint,
+// unless the command already tagged it
+func classifyError(cmd *cobra.Command, err error) error {
+	if err == nil || output.HasCode(err) {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrNotLoggedIn):
+		return output.WithCode(err, output.CodeAuthRequired, "Run 'cmux auth login' to sign in")
+	case errors.Is(err, auth.ErrInvalidAPIKey):
+		return output.WithCode(err, output.CodeAuthExpired, "Check "+auth.APIKeyEnv+", or create a new API key in the cmux web app")
+	case errors.Is(err, auth.ErrSessionExpired):
+		return output.WithCode(err, output.CodeAuthExpired, "Run 'cmux auth login' to sign in again")
+	case errors.Is(err, context.DeadlineExceeded):
+		return output.WithCode(err, output.CodeTimeout, "Retry, or check the sandbox with 'cmux status <id>'")
+	}
+
+	var apiErr *vm.APIError
+	if errors.As(err, &apiErr) {
+		return classifyAPIError(cmd, err, apiErr)
+	}
+
+	var urlErr *url.Error
+	var netErr *net.OpError
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		if urlErr != nil && urlErr.Timeout() {
+			return output.WithCode(err, output.CodeTimeout, "Check your network connection and retry")
+		}
+		return output.WithCode(err, output.CodeNetworkError, "Check your network connection, or run 'cmux doctor'")
+	}
+	return err
+}
+
+// classifyAPIError maps an API status code to an error code
+func classifyAPIError(cmd *cobra.Command, err error, apiErr *vm.APIError) error {
+	body := strings.ToLower(apiErr.Body)
+	switch {
+	case apiErr.StatusCode == 401:
+		return output.WithCode(err, output.CodeAuthExpired, "Run 'cmux auth login' to sign in again")
+	case apiErr.StatusCode == 402 || strings.Contains(body, "quota"):
+		return output.WithCode(err, output.CodeQuotaExceeded, "Stop unused sandboxes with 'cmux ls' and 'cmux delete', or check 'cmux usage'")
+	case apiErr.StatusCode == 403:
+		return output.WithCode(err, output.CodePermissionDenied, "Check the selected team with 'cmux auth whoami --teams'")
+	case apiErr.StatusCode == 404:
+		if cmd != nil && instanceArgPattern.MatchString(cmd.Use) {
+			return output.WithCode(err, output.CodeSandboxNotFound, "Run 'cmux ls' to see your sandboxes")
+		}
+		return output.WithCode(err, output.CodeNotFound, "")
+	case apiErr.StatusCode == 408 || apiErr.StatusCode == 504:
+		return output.WithCode(err, output.CodeTimeout, "Retry in a moment")
+	case apiErr.StatusCode == 429:
+		return output.WithCode(err, output.CodeRateLimited, "Wait a moment and retry")
+	case apiErr.StatusCode == 502 || apiErr.StatusCode == 503:
+		return output.WithCode(err, output.CodeProviderUnavailable, "The sandbox provider is unavailable. Retry in a moment")
+	}
+	return err
+}
@@ -3,7 +3,10 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
 	"time"
 
@@ -13,22 +16,55 @@ import (
 )
 
 var execCmd = &cobra.Command{
-	Use:   "exec <id> <command>",
+	Use:   "exec [id] <command>",
 	Short: "Execute a command in a VM",
 	Long: `Execute a command in a VM.
 
+By default the output is printed once the command finishes. Use --stream to
+print output lines as they are produced; Ctrl-C then stops the command.
+
+Use -it for interactive programs such as htop, less, or a REPL: -i connects
+your stdin to the command and -t gives it a terminal, in raw mode so keys
+like Ctrl-C go to the program. Interactive commands run over SSH.
+
+Without an ID, the VM bound to the current directory ('cmux use') or the
+last used VM runs the command.
+
 Examples:
   cmux exec cmux_abc123 "ls -la"
   cmux exec cmux_abc123 "npm install"
-  cmux exec cmux_abc123 "cat /etc/os-release"`,
-	Args: cobra.MinimumNArgs(2),
+  cmux exec cmux_abc123 "cat /etc/os-release"
+  cmux exec cmux_abc123 --stream "npm run build"
+  cmux exec "npm test"
+  cmux exec -it cmux_abc123 htop
+  cat dump.sql | cmux exec -i cmux_abc123 "psql app"`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, rest, err := splitInstanceArg(args)
+		if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return fmt.Errorf("specify a command to run")
+		}
+		command := strings.Join(rest, " ")
+
+		stream, _ := cmd.Flags().GetBool("stream")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		tty, _ := cmd.Flags().GetBool("tty")
+		if interactive || tty {
+			if stream {
+				return fmt.Errorf("--stream can't be combined with -i or -t")
+			}
+			return runInteractiveExec(instanceID, command, tty)
+		}
+		if stream {
+			return runStreamingExec(cmd, instanceID, command)
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 		defer cancel()
 
-		instanceID := args[0]
-		command := strings.Join(args[1:], " ")
-
 		teamSlug, err := auth.GetTeamSlug()
 		if err != nil {
 			return fmt.Errorf("failed to get team: %w", err)
@@ -60,6 +96,80 @@ Examples:
 	},
 }
 
+// runStreamingExec prints command output as it arrives until the command
+// exits or the user presses Ctrl-C
+func runStreamingExec(cmd *cobra.Command, instanceID, command string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	teamSlug, err := auth.GetTeamSlug()
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+
+	client, err := vm.NewClient()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	client.SetTeamSlug(teamSlug)
+
+	exitCode, err := client.ExecStream(ctx, instanceID, command, timeout, func(event vm.ExecEvent) {
+		switch event.Type {
+		case "stdout":
+			fmt.Fprintln(os.Stdout, event.Data)
+		case "stderr":
+			fmt.Fprintln(os.Stderr, event.Data)
+		case "error":
+			fmt.Fprintf(os.Stderr, "Error: %s\n", event.Message)
+		}
+	})
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("command interrupted")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to execute command: %w", err)
+	}
+
+	if exitCode != 0 {
+		return fmt.Errorf("command exited with code %d", exitCode)
+	}
+
+	return nil
+}
+
+// runInteractiveExec runs command over SSH with stdin attached, allocating
+// a terminal when tty is set
+func runInteractiveExec(instanceID, command string, tty bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := newTeamClient()
+	if err != nil {
+		return err
+	}
+
+	sshTarget, err := client.SSHTarget(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+
+	sshArgs := vm.SSHOptions()
+	if tty {
+		if !isTerminal(os.Stdin) {
+			return fmt.Errorf("-t needs a terminal; use -i alone to pipe input")
+		}
+		sshArgs = append(sshArgs, "-t")
+	}
+	sshArgs = append(sshArgs, sshTarget, command)
+	return execSSH(sshArgs)
+}
+
 func init() {
+	execCmd.Flags().BoolP("interactive", "i", false, "Keep stdin attached to the command")
+	execCmd.Flags().BoolP("tty", "t", false, "Allocate a terminal for the command (use with -i)")
+	execCmd.Flags().Bool("stream", false, "Print output as it is produced (Ctrl-C stops the command)")
+	execCmd.Flags().Duration("timeout", 0, "Stop a streamed command after this long (default: no limit)")
 	rootCmd.AddCommand(execCmd)
 }
@@ -0,0 +1,95 @@
+// internal/cli/extend.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// keepAliveTTL is what attached commands reset the TTL to; it matches
+	// the default TTL of a new VM
+	keepAliveTTL = time.Hour
+	// keepAliveMargin is how close to expiry the TTL may get before an
+	// attached command extends it
+	keepAliveMargin = 30 * time.Minute
+	keepAliveEvery  = 5 * time.Minute
+)
+
+// keepInstanceAlive extends the instance's TTL whenever it gets close to
+// expiring, until ctx is done. Long-lived commands (watch, pty, forward) run
+// it so VMs aren't paused mid-work. TTLs that are further out are left
+// alone, and failures are only reported with --verbose.
+func keepInstanceAlive(ctx context.Context, client *vm.Client, instanceID string) {
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		instance, err := client.GetInstance(checkCtx, instanceID)
+		if err == nil && instance.TTLExpiresAt != 0 && time.Until(time.UnixMilli(instance.TTLExpiresAt)) < keepAliveMargin {
+			err = client.SetTTL(checkCtx, instanceID, keepAliveTTL)
+			if err == nil && flagVerbose {
+				fmt.Fprintf(os.Stderr, "Extended %s TTL by %s\r\n", instanceID, keepAliveTTL)
+			}
+		}
+		cancel()
+		if err != nil && ctx.Err() == nil && flagVerbose {
+			fmt.Fprintf(os.Stderr, "Warning: failed to extend TTL: %v\r\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(keepAliveEvery):
+		}
+	}
+}
+
+var extendCmd = &cobra.Command{
+	Use:   "extend <id|last>",
+	Short: "Extend a VM's TTL",
+	Long: `Reset a VM's TTL so its TTL action (usually pause) runs --ttl from now.
+
+'cmux watch', 'cmux pty', and 'cmux forward' extend the TTL automatically
+while they are attached, so this is only needed for VMs you leave running
+without one of them.
+
+Examples:
+  cmux extend cmux_abc123 --ttl 2h
+  cmux extend last --ttl 30m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		if ttl < time.Minute {
+			return fmt.Errorf("--ttl must be at least 1m")
+		}
+
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.SetTTL(ctx, instanceID, ttl); err != nil {
+			return fmt.Errorf("failed to extend TTL: %w", err)
+		}
+
+		fmt.Printf("✓ TTL for %s extended to %s from now (%s)\n", instanceID, ttl, time.Now().Add(ttl).Format("15:04"))
+		return nil
+	},
+}
+
+func init() {
+	extendCmd.Flags().Duration("ttl", keepAliveTTL, "New TTL, counted from now")
+	rootCmd.AddCommand(extendCmd)
+}
@@ -0,0 +1,187 @@
+// internal/cli/forward.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// portForward is a single local -> VM port mapping
+type portForward struct {
+	localPort  int
+	remoteHost string
+	remotePort int
+}
+
+// sshArg formats the forward for ssh -L
+func (f portForward) sshArg(bindAddress string) string {
+	return fmt.Sprintf("%s:%d:%s:%d", bindAddress, f.localPort, f.remoteHost, f.remotePort)
+}
+
+// parsePortForward parses "3000", "8080:80", or "5432:db:5432"
+func parsePortForward(spec string) (portForward, error) {
+	parts := strings.Split(spec, ":")
+	fwd := portForward{remoteHost: "localhost"}
+	var localPort, remotePort string
+	switch len(parts) {
+	case 1:
+		localPort, remotePort = parts[0], parts[0]
+	case 2:
+		localPort, remotePort = parts[0], parts[1]
+	case 3:
+		localPort, fwd.remoteHost, remotePort = parts[0], parts[1], parts[2]
+	default:
+		return fwd, fmt.Errorf("invalid forward %q (expected LOCAL:REMOTE)", spec)
+	}
+
+	var err error
+	if fwd.localPort, err = parsePortNumber(localPort); err != nil {
+		return fwd, fmt.Errorf("invalid forward %q: %w", spec, err)
+	}
+	if fwd.remotePort, err = parsePortNumber(remotePort); err != nil {
+		return fwd, fmt.Errorf("invalid forward %q: %w", spec, err)
+	}
+	if fwd.remoteHost == "" {
+		return fwd, fmt.Errorf("invalid forward %q: empty remote host", spec)
+	}
+	return fwd, nil
+}
+
+func parsePortNumber(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", value)
+	}
+	return port, nil
+}
+
+var forwardCmd = &cobra.Command{
+	Use:   "forward <id|last> <local:remote>...",
+	Short: "Forward local ports to a VM",
+	Long: `Forward local ports to services running inside a VM over SSH.
+
+Each mapping is LOCAL:REMOTE (or just PORT when both are the same), and
+LOCAL:HOST:REMOTE reaches a host other than localhost from inside the VM.
+The tunnel reconnects automatically if the connection drops. Press Ctrl-C
+to stop forwarding.
+
+Examples:
+  cmux forward cmux_abc123 3000              # localhost:3000 -> VM:3000
+  cmux forward cmux_abc123 3000:3000 8080:80 # Multiple ports
+  cmux forward last 5433:localhost:5432      # Postgres in the VM`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+
+		var forwards []portForward
+		for _, spec := range args[1:] {
+			fwd, err := parsePortForward(spec)
+			if err != nil {
+				return err
+			}
+			forwards = append(forwards, fwd)
+		}
+		bindAddress, _ := cmd.Flags().GetString("bind")
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := vm.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client.SetTeamSlug(teamSlug)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go keepInstanceAlive(ctx, client, instanceID)
+
+		for _, fwd := range forwards {
+			fmt.Printf("Forwarding %s:%d -> %s:%d in %s\n", bindAddress, fwd.localPort, fwd.remoteHost, fwd.remotePort, instanceID)
+		}
+		output.Infoln("Press Ctrl-C to stop")
+
+		return runForwardLoop(ctx, client, instanceID, forwards, bindAddress)
+	},
+}
+
+// runForwardLoop keeps an ssh tunnel running until ctx is canceled,
+// reconnecting with backoff whenever it exits
+func runForwardLoop(ctx context.Context, client *vm.Client, instanceID string, forwards []portForward, bindAddress string) error {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := runForwardOnce(ctx, client, instanceID, forwards, bindAddress)
+		if ctx.Err() != nil {
+			output.Infoln("\nStopped forwarding")
+			return nil
+		}
+
+		// A tunnel that stayed up for a while was healthy; start over
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		fmt.Fprintf(os.Stderr, "Tunnel closed (%v), reconnecting in %s...\n", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			output.Infoln("\nStopped forwarding")
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// runForwardOnce fetches fresh SSH credentials and runs ssh -N until it exits
+func runForwardOnce(ctx context.Context, client *vm.Client, instanceID string, forwards []portForward, bindAddress string) error {
+	credCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	sshTarget, err := client.SSHTarget(credCtx, instanceID)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	sshArgs := append(vm.SSHOptions(),
+		"-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+		"-o", "LogLevel=ERROR",
+	)
+	for _, fwd := range forwards {
+		sshArgs = append(sshArgs, "-L", fwd.sshArg(bindAddress))
+	}
+	sshArgs = append(sshArgs, sshTarget)
+
+	sshExec := exec.CommandContext(ctx, "ssh", sshArgs...)
+	sshExec.Stderr = os.Stderr
+	if flagVerbose {
+		sshExec.Stdout = os.Stdout
+	}
+	if err := sshExec.Run(); err != nil {
+		return err
+	}
+	return fmt.Errorf("ssh exited")
+}
+
+func init() {
+	forwardCmd.Flags().String("bind", "127.0.0.1", "Local address to listen on")
+	rootCmd.AddCommand(forwardCmd)
+}
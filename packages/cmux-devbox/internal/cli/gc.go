@@ -0,0 +1,176 @@
+// internal/cli/gc.go
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// gcResult is a VM picked up by 'cmux gc' and what happened to it
+type gcResult struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Status    string `json:"status"`
+	UpdatedAt int64  `json:"updatedAt,omitempty"`
+	Deleted   bool   `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// lastActive is when an instance last changed state, falling back to when
+// it was created
+func lastActive(inst vm.Instance) int64 {
+	if inst.UpdatedAt != 0 {
+		return inst.UpdatedAt
+	}
+	return inst.CreatedAt
+}
+
+// confirm asks a yes/no question on stderr; anything but y/yes is a no
+func confirm(prompt string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete stale VMs in bulk",
+	Long: `Find VMs that haven't changed state for --older-than and delete them.
+
+Matching VMs are listed and you're asked to confirm before anything is
+deleted. --dry-run only lists them; --yes skips the confirmation, e.g. in
+scripts. --status selects which states count as stale (running, paused,
+unknown). Running VMs are never collected unless listed explicitly.
+
+Examples:
+  cmux gc                                  # Paused VMs idle for 3 days
+  cmux gc --older-than 24h --dry-run
+  cmux gc --status paused,unknown --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, _ := cmd.Flags().GetDuration("older-than")
+		statuses, _ := cmd.Flags().GetString("status")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		if olderThan <= 0 {
+			return fmt.Errorf("--older-than must be positive")
+		}
+		if strings.TrimSpace(statuses) == "" {
+			return fmt.Errorf("--status must name at least one status")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		instances, err := client.ListInstances(ctx, false)
+		if err != nil {
+			return fmt.Errorf("failed to list instances: %w", err)
+		}
+
+		cutoff := time.Now().Add(-olderThan).UnixMilli()
+		results := []gcResult{}
+		for _, inst := range filterInstances(instances, statuses, "") {
+			if active := lastActive(inst); active == 0 || active > cutoff {
+				continue
+			}
+			results = append(results, gcResult{
+				ID:        inst.ID,
+				Name:      inst.Name,
+				Status:    inst.Status,
+				UpdatedAt: lastActive(inst),
+			})
+		}
+
+		if len(results) == 0 {
+			if output.Structured() {
+				return output.Print(results)
+			}
+			fmt.Printf("No %s VMs older than %s.\n", statuses, olderThan)
+			return nil
+		}
+
+		if !output.Structured() {
+			fmt.Printf("%-20s %-20s %-10s %s\n", "ID", "NAME", "STATUS", "IDLE")
+			fmt.Println("-------------------- -------------------- ---------- ------")
+			for _, result := range results {
+				fmt.Printf("%s %s %s %s\n",
+					fit(result.ID, 20),
+					fit(orDash(result.Name), 20),
+					fit(result.Status, 10),
+					formatAge(result.UpdatedAt),
+				)
+			}
+			fmt.Println()
+		}
+
+		if dryRun {
+			if output.Structured() {
+				return output.Print(results)
+			}
+			fmt.Printf("%d VM(s) would be deleted (dry run).\n", len(results))
+			return nil
+		}
+
+		if !yes {
+			if !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to delete without confirmation; pass --yes")
+			}
+			if !confirm(fmt.Sprintf("Delete %d VM(s)?", len(results))) {
+				fmt.Fprintln(os.Stderr, "Aborted.")
+				return nil
+			}
+		}
+
+		failed := 0
+		for i := range results {
+			result := &results[i]
+			if err := client.StopInstance(ctx, result.ID); err != nil {
+				result.Error = err.Error()
+				failed++
+				if !output.Structured() {
+					fmt.Fprintf(os.Stderr, "Warning: failed to delete %s: %v\n", result.ID, err)
+				}
+				continue
+			}
+			result.Deleted = true
+			state.ClearAutoPaused(result.ID)
+			if !output.Structured() {
+				fmt.Printf("✓ Deleted %s\n", result.ID)
+			}
+		}
+
+		if output.Structured() {
+			if err := output.Print(results); err != nil {
+				return err
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("failed to delete %d of %d VM(s)", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func init() {
+	gcCmd.Flags().Duration("older-than", 72*time.Hour, "Only delete VMs whose state hasn't changed for this long")
+	gcCmd.Flags().String("status", "paused", "Comma-separated statuses to collect (running, paused, unknown)")
+	gcCmd.Flags().Bool("dry-run", false, "List the VMs that would be deleted without deleting them")
+	gcCmd.Flags().BoolP("yes", "y", false, "Delete without asking for confirmation")
+	rootCmd.AddCommand(gcCmd)
+}
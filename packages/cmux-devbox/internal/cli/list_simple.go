@@ -4,56 +4,199 @@ package cli
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
 
+// listColumn is a column of the instance table
+type listColumn struct {
+	header string
+	width  int
+	value  func(inst vm.Instance) string
+}
+
+var listColumns = map[string]listColumn{
+	"id":       {"ID", 20, func(inst vm.Instance) string { return inst.ID }},
+	"name":     {"NAME", 20, func(inst vm.Instance) string { return orDash(inst.Name) }},
+	"status":   {"STATUS", 10, func(inst vm.Instance) string { return inst.Status }},
+	"provider": {"PROVIDER", 10, func(inst vm.Instance) string { return orDash(inst.Provider) }},
+	"ttl":      {"TTL", 14, formatTTL},
+	"age":      {"AGE", 6, func(inst vm.Instance) string { return formatAge(inst.CreatedAt) }},
+}
+
+const defaultListColumns = "id,name,status,provider,ttl,age"
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// formatTTL shows the time left before an instance's TTL action, e.g. "42m (pause)"
+func formatTTL(inst vm.Instance) string {
+	if inst.TTLExpiresAt == 0 {
+		return "-"
+	}
+	left := time.Until(time.UnixMilli(inst.TTLExpiresAt))
+	var remaining string
+	switch {
+	case left <= 0:
+		remaining = "expired"
+	case left < time.Hour:
+		remaining = fmt.Sprintf("%dm", int(left.Minutes()))
+	default:
+		remaining = fmt.Sprintf("%dh%02dm", int(left.Hours()), int(left.Minutes())%60)
+	}
+	if inst.TTLAction != "" && left > 0 {
+		remaining += " (" + inst.TTLAction + ")"
+	}
+	return remaining
+}
+
+// parseListColumns validates a comma-separated list of column names
+func parseListColumns(spec string) ([]listColumn, error) {
+	var columns []listColumn
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		column, ok := listColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, defaultListColumns)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return columns, nil
+}
+
+// filterInstances keeps instances matching status (comma-separated) and a
+// case-insensitive name substring
+func filterInstances(instances []vm.Instance, statuses, nameContains string) []vm.Instance {
+	wanted := map[string]bool{}
+	for _, status := range strings.Split(statuses, ",") {
+		if status = strings.ToLower(strings.TrimSpace(status)); status != "" {
+			wanted[status] = true
+		}
+	}
+	nameContains = strings.ToLower(nameContains)
+
+	var filtered []vm.Instance
+	for _, inst := range instances {
+		if len(wanted) > 0 && !wanted[strings.ToLower(inst.Status)] {
+			continue
+		}
+		if nameContains != "" && !strings.Contains(strings.ToLower(inst.Name), nameContains) {
+			continue
+		}
+		filtered = append(filtered, inst)
+	}
+	return filtered
+}
+
+func sortInstances(instances []vm.Instance, by string) error {
+	var less func(a, b vm.Instance) bool
+	switch by {
+	case "age":
+		// Newest first
+		less = func(a, b vm.Instance) bool { return a.CreatedAt > b.CreatedAt }
+	case "name":
+		less = func(a, b vm.Instance) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "status":
+		less = func(a, b vm.Instance) bool { return a.Status < b.Status }
+	default:
+		return fmt.Errorf("invalid --sort %q (use age, name, or status)", by)
+	}
+	sort.SliceStable(instances, func(i, j int) bool { return less(instances[i], instances[j]) })
+	return nil
+}
+
 var listCmd = &cobra.Command{
 	Use:     "ls",
 	Aliases: []string{"list", "ps"},
 	Short:   "List your VMs",
-	Long: `List all your VM instances.
+	Long: `List your VM instances with their provider and remaining TTL.
+
+Stopped instances are hidden unless --status includes "stopped".
+
+Columns: id, name, status, provider, ttl, age
 
 Examples:
   cmux ls
-  cmux list`,
+  cmux ls --status running
+  cmux ls --status paused,stopped --sort name
+  cmux ls --name-contains api --columns id,name,ttl
+  cmux ls --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		teamSlug, err := auth.GetTeamSlug()
+		statuses, _ := cmd.Flags().GetString("status")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		sortBy, _ := cmd.Flags().GetString("sort")
+		columnSpec, _ := cmd.Flags().GetString("columns")
+
+		columns, err := parseListColumns(columnSpec)
 		if err != nil {
-			return fmt.Errorf("failed to get team: %w", err)
+			return err
 		}
 
-		client, err := vm.NewClient()
+		client, err := newTeamClient()
 		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
+			return err
 		}
-		client.SetTeamSlug(teamSlug)
 
-		instances, err := client.ListInstances(ctx)
+		includeStopped := strings.Contains(strings.ToLower(statuses), "stopped")
+		instances, err := client.ListInstances(ctx, includeStopped)
 		if err != nil {
 			return fmt.Errorf("failed to list instances: %w", err)
 		}
 
+		instances = filterInstances(instances, statuses, nameContains)
+		if err := sortInstances(instances, sortBy); err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			if instances == nil {
+				instances = []vm.Instance{}
+			}
+			return output.Print(instances)
+		}
+
 		if len(instances) == 0 {
-			fmt.Println("No VMs found. Run 'cmux start' to create one.")
+			if statuses != "" || nameContains != "" {
+				fmt.Println("No VMs match the given filters.")
+			} else {
+				fmt.Println("No VMs found. Run 'cmux start' to create one.")
+			}
 			return nil
 		}
 
-		fmt.Printf("%-20s %-10s %s\n", "ID", "STATUS", "VS CODE URL")
-		fmt.Println("-------------------- ---------- " + "----------------------------------------")
+		headers := make([]string, len(columns))
+		rules := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = fmt.Sprintf("%-*s", column.width, column.header)
+			rules[i] = strings.Repeat("-", column.width)
+		}
+		fmt.Println(strings.TrimRight(strings.Join(headers, " "), " "))
+		fmt.Println(strings.Join(rules, " "))
 
 		for _, inst := range instances {
-			url := inst.VSCodeURL
-			if len(url) > 40 {
-				url = url[:40] + "..."
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = fmt.Sprintf("%-*s", column.width, column.value(inst))
 			}
-			fmt.Printf("%-20s %-10s %s\n", inst.ID, inst.Status, url)
+			fmt.Println(strings.TrimRight(strings.Join(cells, " "), " "))
 		}
 
 		return nil
@@ -61,5 +204,9 @@ Examples:
 }
 
 func init() {
+	listCmd.Flags().String("status", "", "Only show instances with these statuses (comma-separated: running,paused,stopped)")
+	listCmd.Flags().String("name-contains", "", "Only show instances whose name contains this text")
+	listCmd.Flags().String("sort", "age", "Sort by age, name, or status")
+	listCmd.Flags().String("columns", defaultListColumns, "Columns to show (comma-separated)")
 	rootCmd.AddCommand(listCmd)
 }
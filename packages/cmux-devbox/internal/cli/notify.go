@@ -0,0 +1,244 @@
+// internal/cli/notify.go
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// Events accepted by --on
+const (
+	notifyOnComplete = "task-complete" // every finished task, whatever its outcome
+	notifyOnFailed   = "task-failed"   // only tasks that failed
+)
+
+// taskNotification is the JSON body POSTed to --webhook
+type taskNotification struct {
+	Event      string  `json:"event"` // task-complete or task-failed
+	Task       vm.Task `json:"task"`
+	URL        string  `json:"url"`
+	FinishedAt int64   `json:"finishedAt"`
+}
+
+func isTaskFinished(status string) bool {
+	return status == "completed" || status == "failed"
+}
+
+func sendWebhook(ctx context.Context, webhookURL string, notification taskNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cmux/"+GetVersion())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func sendDesktopNotification(title, message string) error {
+	message = strings.Join(strings.Fields(message), " ")
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, quote(title), quote(message))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return fmt.Errorf("desktop notifications require notify-send (install libnotify)")
+		}
+		cmd = exec.Command("notify-send", "--app-name=cmux", title, message)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify <task-id>...",
+	Short: "Get notified when tasks finish",
+	Long: `Watch tasks and send a notification as each one finishes, so long agent
+runs don't need a terminal open to check on them.
+
+Notifications go to a webhook (a JSON POST with the event, task, and
+dashboard URL) and/or the desktop (osascript on macOS, notify-send on Linux,
+PowerShell on Windows). The command exits once every task has finished.
+
+Examples:
+  cmux notify <task-id> --desktop
+  cmux notify <task-id> <task-id> --webhook https://hooks.slack.com/... --on task-failed
+  cmux notify <task-id> --webhook https://example.com/hook --interval 1m`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		on, _ := cmd.Flags().GetString("on")
+		webhookURL, _ := cmd.Flags().GetString("webhook")
+		desktop, _ := cmd.Flags().GetBool("desktop")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		if on != notifyOnComplete && on != notifyOnFailed {
+			return fmt.Errorf("invalid --on %q (use %s or %s)", on, notifyOnComplete, notifyOnFailed)
+		}
+		if webhookURL == "" && !desktop {
+			return fmt.Errorf("choose where to notify with --webhook and/or --desktop")
+		}
+		if webhookURL != "" {
+			parsed, err := url.Parse(webhookURL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("invalid --webhook URL %q", webhookURL)
+			}
+		}
+		if interval < 5*time.Second {
+			return fmt.Errorf("--interval must be at least 5s")
+		}
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		pending := append([]string{}, args...)
+		failures := 0
+		output.Infof("Watching %d task(s) (Ctrl-C to stop)\n", len(pending))
+
+		for {
+			var still []string
+			for _, taskID := range pending {
+				detail, err := client.GetTask(ctx, taskID)
+				if err != nil {
+					if ctx.Err() != nil {
+						output.Infoln("\nStopped watching")
+						return nil
+					}
+					fmt.Fprintf(os.Stderr, "Warning: failed to check task %s: %v\n", taskID, err)
+					still = append(still, taskID)
+					continue
+				}
+				if !isTaskFinished(detail.Status) {
+					still = append(still, taskID)
+					continue
+				}
+
+				fmt.Printf("✓ Task %s %s: %s\n", taskID, detail.Status, strings.TrimSpace(fit(detail.Text, 60)))
+				if on == notifyOnFailed && detail.Status != "failed" {
+					continue
+				}
+
+				event := notifyOnComplete
+				if detail.Status == "failed" {
+					event = notifyOnFailed
+				}
+				notification := taskNotification{
+					Event:      event,
+					Task:       detail.Task,
+					URL:        taskDashboardURL(teamSlug, detail.ID),
+					FinishedAt: time.Now().UnixMilli(),
+				}
+
+				if webhookURL != "" {
+					if err := sendWebhook(ctx, webhookURL, notification); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to send webhook: %v\n", err)
+						failures++
+					} else {
+						fmt.Println("  Sent webhook")
+					}
+				}
+				if desktop {
+					title := fmt.Sprintf("cmux task %s", detail.Status)
+					if err := sendDesktopNotification(title, detail.Text); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to send desktop notification: %v\n", err)
+						failures++
+					} else {
+						fmt.Println("  Sent desktop notification")
+					}
+				}
+			}
+			pending = still
+
+			if len(pending) == 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				output.Infoln("\nStopped watching")
+				return nil
+			case <-time.After(interval):
+			}
+		}
+
+		if failures > 0 {
+			return fmt.Errorf("%d notification(s) failed to send", failures)
+		}
+		return nil
+	},
+}
+
+func init() {
+	notifyCmd.Flags().String("on", notifyOnComplete, "When to notify: task-complete (any outcome) or task-failed")
+	notifyCmd.Flags().String("webhook", "", "POST a JSON notification to this URL")
+	notifyCmd.Flags().Bool("desktop", false, "Show a desktop notification")
+	notifyCmd.Flags().Duration("interval", 15*time.Second, "How often to check task status")
+	notifyCmd.RegisterFlagCompletionFunc("on", cobra.FixedCompletions([]string{notifyOnComplete, notifyOnFailed}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(notifyCmd)
+}
@@ -8,10 +8,10 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
@@ -38,121 +38,138 @@ func getAuthToken(ctx context.Context, client *vm.Client, instanceID string) (st
 	return token, nil
 }
 
-var codeCmd = &cobra.Command{
-	Use:   "code <id>",
-	Short: "Open VS Code in browser",
-	Long: `Open VS Code for a VM in your browser.
+const (
+	codePath = "/code/?folder=/home/cmux/workspace"
+	vncPath  = "/vnc/vnc.html?path=vnc/websockify&resize=scale&quality=9&compression=0"
+)
 
-Examples:
-  cmux code cmux_abc123`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+// openWorkerPage prints a pre-authenticated URL for a page served by the
+// VM's worker and, unless --no-open is set, opens it in the browser
+func openWorkerPage(cmd *cobra.Command, args []string, name, targetPath string) error {
+	instanceID, rest, err := splitInstanceArg(args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("not an instance ID: %s", rest[0])
+	}
+	noOpen, _ := cmd.Flags().GetBool("no-open")
 
-		instanceID := args[0]
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		teamSlug, err := auth.GetTeamSlug()
-		if err != nil {
-			return fmt.Errorf("failed to get team: %w", err)
-		}
+	teamSlug, err := auth.GetTeamSlug()
+	if err != nil {
+		return fmt.Errorf("failed to get team: %w", err)
+	}
 
-		client, err := vm.NewClient()
-		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
-		}
-		client.SetTeamSlug(teamSlug)
+	client, err := vm.NewClient()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	client.SetTeamSlug(teamSlug)
 
-		instance, err := client.GetInstance(ctx, instanceID)
-		if err != nil {
-			return fmt.Errorf("failed to get instance: %w", err)
-		}
+	instance, err := client.GetInstance(ctx, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
 
-		if instance.WorkerURL == "" {
-			return fmt.Errorf("worker URL not available")
-		}
+	if instance.WorkerURL == "" {
+		return fmt.Errorf("worker URL not available")
+	}
 
-		// Generate one-time auth token
-		token, err := getAuthToken(ctx, client, instanceID)
-		if err != nil {
-			return err
-		}
+	// Generate one-time auth token
+	token, err := getAuthToken(ctx, client, instanceID)
+	if err != nil {
+		return err
+	}
 
-		// Build auth URL that sets session cookie and redirects to VS Code
-		authURL, err := buildAuthURL(instance.WorkerURL, "/code/?folder=/home/cmux/workspace", token)
-		if err != nil {
+	// Build auth URL that sets session cookie and redirects to the page
+	authURL, err := buildAuthURL(instance.WorkerURL, targetPath, token)
+	if err != nil {
+		return err
+	}
+
+	if output.Structured() {
+		if err := output.Print(map[string]string{"id": instanceID, "url": authURL}); err != nil {
 			return err
 		}
+	} else {
+		fmt.Printf("%s: %s\n", name, authURL)
+		fmt.Println("The link works once; run this command again for a new one.")
+	}
 
-		fmt.Printf("Opening VS Code...\n")
-		return openBrowser(authURL)
-	},
+	if noOpen {
+		return nil
+	}
+	if !output.Structured() {
+		output.Infof("Opening %s...\n", name)
+	}
+	if err := openBrowser(authURL); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not open browser: %v\n", err)
+	}
+	return nil
 }
 
-var vncCmd = &cobra.Command{
-	Use:   "vnc <id>",
-	Short: "Open VNC desktop in browser",
-	Long: `Open VNC desktop for a VM in your browser.
+var codeCmd = &cobra.Command{
+	Use:   "code [id|last]",
+	Short: "Open VS Code in browser",
+	Long: `Print a pre-authenticated VS Code URL for a VM and open it in your
+browser. Without an ID, the VM bound to the current directory ('cmux use')
+or the last used VM is used.
+
+--no-open only prints the URL, e.g. to open it on another machine.
 
 Examples:
-  cmux vnc cmux_abc123`,
-	Args: cobra.ExactArgs(1),
+  cmux code cmux_abc123
+  cmux code last --no-open`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		instanceID := args[0]
-
-		teamSlug, err := auth.GetTeamSlug()
-		if err != nil {
-			return fmt.Errorf("failed to get team: %w", err)
-		}
-
-		client, err := vm.NewClient()
-		if err != nil {
-			return fmt.Errorf("failed to create client: %w", err)
-		}
-		client.SetTeamSlug(teamSlug)
-
-		instance, err := client.GetInstance(ctx, instanceID)
-		if err != nil {
-			return fmt.Errorf("failed to get instance: %w", err)
-		}
+		return openWorkerPage(cmd, args, "VS Code", codePath)
+	},
+}
 
-		if instance.WorkerURL == "" {
-			return fmt.Errorf("worker URL not available")
-		}
+var vncCmd = &cobra.Command{
+	Use:   "vnc [id|last]",
+	Short: "Open VNC desktop in browser",
+	Long: `Print a pre-authenticated VNC desktop URL for a VM and open it in your
+browser. Without an ID, the VM bound to the current directory ('cmux use')
+or the last used VM is used.
 
-		// Generate one-time auth token
-		token, err := getAuthToken(ctx, client, instanceID)
-		if err != nil {
-			return err
-		}
+--no-open only prints the URL, e.g. to open it on another machine.
 
-		// Build auth URL that sets session cookie and redirects to VNC
-		authURL, err := buildAuthURL(instance.WorkerURL, "/vnc/vnc.html?path=vnc/websockify&resize=scale&quality=9&compression=0", token)
-		if err != nil {
-			return err
-		}
-
-		fmt.Printf("Opening VNC...\n")
-		return openBrowser(authURL)
+Examples:
+  cmux vnc cmux_abc123
+  cmux vnc --no-open`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return openWorkerPage(cmd, args, "VNC", vncPath)
 	},
 }
 
 var sshCmd = &cobra.Command{
-	Use:   "ssh <id>",
+	Use:   "ssh [id|last] [command...]",
 	Short: "SSH into a VM",
-	Long: `SSH into a VM.
+	Long: `Open an interactive shell in a VM, or run a single command over SSH.
+
+Use "last" to connect to the most recently started or resumed VM. Without
+an ID, the VM bound to the current directory ('cmux use') or the last used
+VM is used.
 
 Examples:
-  cmux ssh cmux_abc123`,
-	Args: cobra.ExactArgs(1),
+  cmux ssh cmux_abc123
+  cmux ssh last
+  cmux ssh cmux_abc123 htop
+  cmux ssh cmux_abc123 -- ls -la /home/cmux/workspace
+  cmux ssh`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
-		instanceID := args[0]
+		instanceID, remoteCommand, err := splitInstanceArg(args)
+		if err != nil {
+			return err
+		}
 
 		teamSlug, err := auth.GetTeamSlug()
 		if err != nil {
@@ -165,29 +182,23 @@ Examples:
 		}
 		client.SetTeamSlug(teamSlug)
 
-		sshCommand, err := client.GetSSHCredentials(ctx, instanceID)
+		sshTarget, err := client.SSHTarget(ctx, instanceID)
 		if err != nil {
-			return fmt.Errorf("failed to get SSH credentials: %w", err)
+			return err
 		}
 
-		fmt.Printf("Connecting: %s\n", sshCommand)
-
-		// Parse SSH command: "ssh token@ssh.cloud.morph.so"
-		parts := strings.Fields(sshCommand)
-		if len(parts) < 2 {
-			return fmt.Errorf("invalid SSH command format")
+		sshArgs := vm.SSHOptions()
+		if len(remoteCommand) > 0 && isTerminal(os.Stdin) {
+			// Allocate a TTY so interactive commands like htop work
+			sshArgs = append(sshArgs, "-t")
 		}
+		sshArgs = append(sshArgs, sshTarget)
+		sshArgs = append(sshArgs, remoteCommand...)
 
-		sshExec := exec.Command("ssh",
-			"-o", "StrictHostKeyChecking=no",
-			"-o", "UserKnownHostsFile=/dev/null",
-			parts[1],
-		)
-		sshExec.Stdin = os.Stdin
-		sshExec.Stdout = os.Stdout
-		sshExec.Stderr = os.Stderr
-
-		return sshExec.Run()
+		if flagVerbose {
+			fmt.Fprintf(os.Stderr, "Connecting to %s...\n", instanceID)
+		}
+		return execSSH(sshArgs)
 	},
 }
 
@@ -221,35 +232,28 @@ Examples:
 			return fmt.Errorf("failed to get instance: %w", err)
 		}
 
-		fmt.Printf("ID:       %s\n", instance.ID)
-		fmt.Printf("Status:   %s\n", instance.Status)
-
-		// Generate authenticated URLs if the instance is running
+		// Use authenticated URLs if the instance is running, falling back
+		// to raw URLs
 		if instance.WorkerURL != "" && instance.Status == "running" {
-			token, err := getAuthToken(ctx, client, instanceID)
-			if err != nil {
-				// Fall back to raw URLs
-				if instance.VSCodeURL != "" {
-					fmt.Printf("VS Code:  %s\n", instance.VSCodeURL)
-				}
-				if instance.VNCURL != "" {
-					fmt.Printf("VNC:      %s\n", instance.VNCURL)
-				}
-			} else {
-				codeAuthURL, _ := buildAuthURL(instance.WorkerURL, "/code/?folder=/home/cmux/workspace", token)
-				vncAuthURL, _ := buildAuthURL(instance.WorkerURL, "/vnc/vnc.html?path=vnc/websockify&resize=scale&quality=9&compression=0", token)
-				fmt.Printf("VS Code:  %s\n", codeAuthURL)
-				fmt.Printf("VNC:      %s\n", vncAuthURL)
-			}
-		} else {
-			if instance.VSCodeURL != "" {
-				fmt.Printf("VS Code:  %s\n", instance.VSCodeURL)
-			}
-			if instance.VNCURL != "" {
-				fmt.Printf("VNC:      %s\n", instance.VNCURL)
+			if token, err := getAuthToken(ctx, client, instanceID); err == nil {
+				instance.VSCodeURL, _ = buildAuthURL(instance.WorkerURL, codePath, token)
+				instance.VNCURL, _ = buildAuthURL(instance.WorkerURL, vncPath, token)
 			}
 		}
 
+		if output.Structured() {
+			return output.Print(instance)
+		}
+
+		fmt.Printf("ID:       %s\n", instance.ID)
+		fmt.Printf("Status:   %s\n", instance.Status)
+		if instance.VSCodeURL != "" {
+			fmt.Printf("VS Code:  %s\n", instance.VSCodeURL)
+		}
+		if instance.VNCURL != "" {
+			fmt.Printf("VNC:      %s\n", instance.VNCURL)
+		}
+
 		return nil
 	},
 }
@@ -272,6 +276,8 @@ func openBrowser(url string) error {
 }
 
 func init() {
+	codeCmd.Flags().Bool("no-open", false, "Print the URL without opening a browser")
+	vncCmd.Flags().Bool("no-open", false, "Print the URL without opening a browser")
 	rootCmd.AddCommand(codeCmd)
 	rootCmd.AddCommand(vncCmd)
 	rootCmd.AddCommand(sshCmd)
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
@@ -37,13 +39,14 @@ Examples:
 		}
 		client.SetTeamSlug(teamSlug)
 
-		fmt.Printf("Pausing VM %s...\n", instanceID)
+		output.Infof("Pausing VM %s...\n", instanceID)
 		if err := client.PauseInstance(ctx, instanceID); err != nil {
 			return fmt.Errorf("failed to pause VM: %w", err)
 		}
 
+		state.ClearAutoPaused(instanceID)
 		fmt.Println("✓ VM paused")
-		fmt.Printf("  Resume with: cmux resume %s\n", instanceID)
+		output.Infof("  Resume with: cmux resume %s\n", instanceID)
 		return nil
 	},
 }
@@ -0,0 +1,152 @@
+// internal/cli/profile.go
+package cli
+
+import (
+	"fmt"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage profiles for multiple accounts and teams",
+	Long: `Manage profiles. A profile bundles its own login, a team, and endpoint
+overrides, so you can switch between organizations without logging out.
+
+Select a profile with --profile, the CMUX_PROFILE environment variable, or
+'cmux profile use'. The "default" profile is built in and uses your existing
+login.
+
+Examples:
+  cmux profile create acme --team acme
+  cmux --profile acme login
+  cmux profile use acme
+  cmux profile list`,
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Long: `Create a profile. Endpoint overrides are taken from the global
+--api-url and --convex-url flags; anything not set falls back to the usual
+configuration.
+
+After creating a profile, log in to it with 'cmux --profile <name> login'.
+
+Examples:
+  cmux profile create acme --team acme
+  cmux profile create staging --api-url https://staging.example.com --convex-url https://staging.convex.site
+  cmux profile create acme --team acme --use`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		team, _ := cmd.Flags().GetString("team")
+		authURL, _ := cmd.Flags().GetString("auth-url")
+		use, _ := cmd.Flags().GetBool("use")
+		force, _ := cmd.Flags().GetBool("force")
+
+		_, profiles, err := auth.ListProfiles()
+		if err != nil {
+			return fmt.Errorf("failed to load profiles: %w", err)
+		}
+		if _, exists := profiles[name]; exists && !force {
+			return fmt.Errorf("profile %q already exists (use --force to replace it)", name)
+		}
+
+		profile := auth.Profile{
+			TeamSlug:      team,
+			CmuxURL:       flagAPIURL,
+			ConvexSiteURL: flagConvexSiteURL,
+			StackAuthURL:  authURL,
+		}
+		if err := auth.SaveProfile(name, profile); err != nil {
+			return fmt.Errorf("failed to save profile: %w", err)
+		}
+		fmt.Printf("✓ Created profile %s\n", name)
+
+		if use {
+			if err := auth.UseProfile(name); err != nil {
+				return fmt.Errorf("failed to switch profile: %w", err)
+			}
+			fmt.Printf("✓ Switched to profile %s\n", name)
+			output.Infoln("  Run 'cmux login' to sign in")
+		} else {
+			output.Infof("  Run 'cmux --profile %s login' to sign in\n", name)
+		}
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch the current profile",
+	Long: `Make a profile the current one for future commands. Use "default" to
+switch back to the built-in profile.
+
+--profile and CMUX_PROFILE still take precedence.
+
+Examples:
+  cmux profile use acme
+  cmux profile use default`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := auth.UseProfile(args[0]); err != nil {
+			return fmt.Errorf("failed to switch profile: %w", err)
+		}
+		fmt.Printf("✓ Switched to profile %s\n", args[0])
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, profiles, err := auth.ListProfiles()
+		if err != nil {
+			return fmt.Errorf("failed to load profiles: %w", err)
+		}
+		active := auth.ActiveProfile()
+
+		if output.Structured() {
+			type profileOutput struct {
+				Name   string `json:"name"`
+				Active bool   `json:"active"`
+				auth.Profile
+			}
+			result := make([]profileOutput, 0, len(names))
+			for _, name := range names {
+				result = append(result, profileOutput{Name: name, Active: name == active, Profile: profiles[name]})
+			}
+			return output.Print(result)
+		}
+
+		fmt.Printf("  %-20s %-20s %s\n", "NAME", "TEAM", "API URL")
+		fmt.Println("  -------------------- -------------------- " + "------------------------------")
+		for _, name := range names {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			profile := profiles[name]
+			fmt.Printf("%s %-20s %-20s %s\n", marker, name, orDash(profile.TeamSlug), orDash(profile.CmuxURL))
+		}
+		return nil
+	},
+}
+
+func init() {
+	profileCreateCmd.Flags().String("team", "", "Team slug or ID (default: your account's team)")
+	profileCreateCmd.Flags().String("auth-url", "", "Stack Auth API URL override")
+	profileCreateCmd.Flags().Bool("use", false, "Switch to the profile after creating it")
+	profileCreateCmd.Flags().Bool("force", false, "Replace an existing profile")
+
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileListCmd)
+	rootCmd.AddCommand(profileCmd)
+}
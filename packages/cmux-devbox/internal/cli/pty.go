@@ -2,6 +2,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,32 +10,55 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
+// parseDetachKey parses a key like "ctrl-]" or "ctrl-q" into the byte the
+// terminal sends for it in raw mode
+func parseDetachKey(key string) (byte, error) {
+	rest, ok := strings.CutPrefix(strings.ToLower(key), "ctrl-")
+	if !ok || len(rest) != 1 || rest[0] < '@' || rest[0] > '_' && (rest[0] < 'a' || rest[0] > 'z') {
+		return 0, fmt.Errorf("invalid --detach-key %q (use ctrl-<key>, e.g. ctrl-] or ctrl-q)", key)
+	}
+	return rest[0] & 0x1f, nil
+}
+
 var ptyCmd = &cobra.Command{
-	Use:   "pty <id>",
-	Short: "Open a terminal session in the VM",
-	Long: `Open an interactive terminal session in a VM.
+	Use:   "pty <id> [session-id]",
+	Short: "Open or attach to a terminal session in the VM",
+	Long: `Open an interactive terminal session in a VM, or attach to a running one.
 
-This provides a tmux-like terminal experience with persistent sessions.
+This provides a tmux-like terminal experience with persistent sessions:
+press the detach key (Ctrl-] by default) to leave the session running, and
+attach to it again later. Find session IDs with 'cmux pty-list <id>'.
 
 Examples:
-  cmux pty cmux_abc123              # Open new terminal session
-  cmux pty cmux_abc123 --session=pty_xyz  # Attach to existing session`,
-	Args: cobra.ExactArgs(1),
+  cmux pty cmux_abc123                    # Open new terminal session
+  cmux pty cmux_abc123 pty_xyz            # Attach to existing session
+  cmux pty cmux_abc123 --detach-key ctrl-q`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
 		instanceID := args[0]
 		sessionID, _ := cmd.Flags().GetString("session")
+		if len(args) > 1 {
+			sessionID = args[1]
+		}
+		detachKeyFlag, _ := cmd.Flags().GetString("detach-key")
+		detachKey, err := parseDetachKey(detachKeyFlag)
+		if err != nil {
+			return err
+		}
 
 		teamSlug, err := auth.GetTeamSlug()
 		if err != nil {
@@ -68,7 +92,22 @@ Examples:
 			return fmt.Errorf("failed to build WebSocket URL: %w", err)
 		}
 
-		return runPtySession(wsURL)
+		keepAliveCtx, stopKeepAlive := context.WithCancel(context.Background())
+		defer stopKeepAlive()
+		go keepInstanceAlive(keepAliveCtx, client, instanceID)
+
+		detached, err := runPtySession(wsURL, detachKey)
+		if err != nil {
+			return err
+		}
+		if detached {
+			if sessionID != "" {
+				fmt.Printf("Detached. Reattach with: cmux pty %s %s\n", instanceID, sessionID)
+			} else {
+				fmt.Printf("Detached. Find the session with 'cmux pty-list %s' to reattach\n", instanceID)
+			}
+		}
+		return nil
 	},
 }
 
@@ -102,6 +141,13 @@ Examples:
 			return fmt.Errorf("failed to list PTY sessions: %w", err)
 		}
 
+		if output.Structured() {
+			if sessions == nil {
+				sessions = []vm.PtySession{}
+			}
+			return output.Print(sessions)
+		}
+
 		if len(sessions) == 0 {
 			fmt.Println("No active PTY sessions")
 			return nil
@@ -153,7 +199,9 @@ func buildPtyWebSocketURL(workerURL, sessionID, token string) (string, error) {
 	return parsed.String(), nil
 }
 
-func runPtySession(wsURL string) error {
+// runPtySession bridges the terminal to a PTY WebSocket until the session
+// exits or the user presses detachKey, which leaves it running
+func runPtySession(wsURL string, detachKey byte) (detached bool, err error) {
 	// Connect to WebSocket
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
@@ -163,16 +211,16 @@ func runPtySession(wsURL string) error {
 	if err != nil {
 		if resp != nil {
 			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("failed to connect: %w (status: %d, body: %s)", err, resp.StatusCode, string(body))
+			return false, fmt.Errorf("failed to connect: %w (status: %d, body: %s)", err, resp.StatusCode, string(body))
 		}
-		return fmt.Errorf("failed to connect: %w", err)
+		return false, fmt.Errorf("failed to connect: %w", err)
 	}
 	defer conn.Close()
 
 	// Put terminal in raw mode
 	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
 	if err != nil {
-		return fmt.Errorf("failed to set raw mode: %w", err)
+		return false, fmt.Errorf("failed to set raw mode: %w", err)
 	}
 	defer term.Restore(int(os.Stdin.Fd()), oldState)
 
@@ -239,6 +287,7 @@ func runPtySession(wsURL string) error {
 	}()
 
 	// Read from stdin and write to WebSocket
+	detach := make(chan struct{})
 	go func() {
 		buf := make([]byte, 1024)
 		for {
@@ -246,22 +295,43 @@ func runPtySession(wsURL string) error {
 			if err != nil {
 				return
 			}
-			msg, _ := json.Marshal(map[string]interface{}{
-				"type": "input",
-				"data": string(buf[:n]),
-			})
-			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+			input := buf[:n]
+			i := bytes.IndexByte(input, detachKey)
+			if i >= 0 {
+				input = input[:i]
+			}
+			if len(input) > 0 {
+				msg, _ := json.Marshal(map[string]interface{}{
+					"type": "input",
+					"data": string(input),
+				})
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					return
+				}
+			}
+			if i >= 0 {
+				close(detach)
 				return
 			}
 		}
 	}()
 
-	<-done
-	return nil
+	select {
+	case <-done:
+		return false, nil
+	case <-detach:
+		// Closing the socket leaves the session running on the worker
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "detach"))
+		conn.Close()
+		term.Restore(int(os.Stdin.Fd()), oldState)
+		fmt.Print("\r\n")
+		return true, nil
+	}
 }
 
 func init() {
-	ptyCmd.Flags().String("session", "", "Attach to existing PTY session ID")
+	ptyCmd.Flags().String("session", "", "Attach to existing PTY session ID (same as the session-id argument)")
+	ptyCmd.Flags().String("detach-key", "ctrl-]", "Key that detaches, leaving the session running")
 	rootCmd.AddCommand(ptyCmd)
 	rootCmd.AddCommand(ptyListCmd)
 }
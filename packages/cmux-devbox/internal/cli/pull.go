@@ -0,0 +1,72 @@
+// internal/cli/pull.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+var pullCmd = &cobra.Command{
+	Use:   "pull <id> <remote-path> [local-dir]",
+	Short: "Fetch files or directories from a VM",
+	Long: `Copy files or directories out of a VM without syncing the whole
+workspace, e.g. build artifacts, screenshots, or coverage reports an agent
+produced. Files are streamed as a tar archive over SSH.
+
+Relative remote paths are resolved against the VM workspace. The last path
+segment may contain wildcards (quote it so your local shell doesn't expand
+it). Matches keep their names inside local-dir, which defaults to the
+current directory.
+
+Examples:
+  cmux pull cmux_abc123 coverage               # ./coverage
+  cmux pull cmux_abc123 'screenshots/*.png' ./shots
+  cmux pull last /tmp/build.log`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+		remotePath := args[1]
+		localDir := "."
+		if len(args) > 2 {
+			localDir = args[2]
+		}
+		absDir, err := filepath.Abs(localDir)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		if info, err := os.Stat(absDir); err == nil && !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", localDir)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		output.Infof("Pulling %s from VM %s...\n", remotePath, instanceID)
+		transferred, err := client.PullPaths(ctx, instanceID, remotePath, absDir)
+		if err != nil {
+			return fmt.Errorf("failed to pull %s: %w", remotePath, err)
+		}
+
+		fmt.Printf("✓ Pulled %s to %s (%s)\n", remotePath, absDir, vm.FormatBytes(transferred))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+}
@@ -7,24 +7,40 @@ import (
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
 
 var resumeCmd = &cobra.Command{
-	Use:   "resume <id>",
+	Use:   "resume <id|last>",
 	Short: "Resume a paused VM",
-	Long: `Resume a paused VM by its ID.
+	Long: `Resume a paused VM by its ID, wait until it is running, and print fresh
+authenticated URLs.
+
+With --sync, the local directory last pushed to the VM (by 'cmux start',
+'cmux sync', or 'cmux watch') is pushed again, picking up edits made while
+the VM was paused.
 
 Examples:
-  cmux resume cmux_abc123`,
+  cmux resume cmux_abc123
+  cmux resume last --sync`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 7*time.Minute)
 		defer cancel()
 
-		instanceID := args[0]
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+
+		resync, _ := cmd.Flags().GetBool("sync")
+		syncPath := state.GetSyncPath(instanceID)
+		if resync && syncPath == "" {
+			return fmt.Errorf("no sync path recorded for %s; run 'cmux sync %s <path>' instead", instanceID, instanceID)
+		}
 
 		// Get team slug
 		teamSlug, err := auth.GetTeamSlug()
@@ -38,13 +54,13 @@ Examples:
 		}
 		client.SetTeamSlug(teamSlug)
 
-		fmt.Printf("Resuming VM %s...\n", instanceID)
+		output.Infof("Resuming VM %s...\n", instanceID)
 		if err := client.ResumeInstance(ctx, instanceID); err != nil {
 			return fmt.Errorf("failed to resume VM: %w", err)
 		}
 
 		// Wait for ready
-		fmt.Println("Waiting for VM to be ready...")
+		output.Infoln("Waiting for VM to be ready...")
 		instance, err := client.WaitForReady(ctx, instanceID, 2*time.Minute)
 		if err != nil {
 			return fmt.Errorf("VM failed to resume: %w", err)
@@ -52,6 +68,20 @@ Examples:
 
 		// Save as last used
 		state.SetLastInstance(instanceID, teamSlug)
+		state.ClearAutoPaused(instanceID)
+
+		if resync {
+			filters, err := vm.LoadSyncFilters(syncPath, nil, nil)
+			if err != nil {
+				return err
+			}
+			output.Infof("Syncing %s to VM...\n", syncPath)
+			if err := syncToVM(ctx, client, instanceID, syncPath, filters); err != nil {
+				fmt.Printf("Warning: failed to sync files: %v\n", err)
+			} else {
+				output.Infoln("Files synced successfully")
+			}
+		}
 
 		// Generate auth token for authenticated URLs
 		token, err := getAuthToken(ctx, client, instance.ID)
@@ -66,11 +96,11 @@ Examples:
 		}
 
 		// Build authenticated URLs
-		codeAuthURL, err := buildAuthURL(instance.WorkerURL, "/code/?folder=/home/cmux/workspace", token)
+		codeAuthURL, err := buildAuthURL(instance.WorkerURL, codePath, token)
 		if err != nil {
 			return fmt.Errorf("failed to build VS Code URL: %w", err)
 		}
-		vncAuthURL, err := buildAuthURL(instance.WorkerURL, "/vnc/vnc.html?path=vnc/websockify&resize=scale&quality=9&compression=0", token)
+		vncAuthURL, err := buildAuthURL(instance.WorkerURL, vncPath, token)
 		if err != nil {
 			return fmt.Errorf("failed to build VNC URL: %w", err)
 		}
@@ -84,5 +114,6 @@ Examples:
 }
 
 func init() {
+	resumeCmd.Flags().Bool("sync", false, "Push the directory last synced to this VM after it resumes")
 	rootCmd.AddCommand(resumeCmd)
 }
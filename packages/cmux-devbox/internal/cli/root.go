@@ -2,20 +2,29 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
 	flagJSON    bool
+	flagOutput  string
 	flagVerbose bool
+	flagQuiet   bool
+	flagNoColor bool
 
 	// Config override flags
 	flagAPIURL        string
 	flagConvexSiteURL string
+	flagProfile       string
 )
 
 var rootCmd = &cobra.Command{
@@ -38,20 +47,39 @@ Quick start:
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	// Apply config overrides before any command runs
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Set config overrides from CLI flags (empty strings are ignored)
 		auth.SetConfigOverrides("", "", flagAPIURL, flagConvexSiteURL)
+		auth.SetProfile(flagProfile)
+
+		if err := applyOutputFlags(cmd); err != nil {
+			return err
+		}
+
+		// Profile commands must work even when the selected profile is missing
+		if cmd == profileCmd || cmd.Parent() == profileCmd {
+			return nil
+		}
+		if err := auth.ValidateActiveProfile(); err != nil {
+			return err
+		}
+		return autoResume(cmd, args)
 	},
 }
 
 func init() {
 	// Global flags available to all commands
-	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "Output as JSON")
+	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "Output as JSON (same as --output json)")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "table", "Output format: table, json, or yaml")
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Suppress progress and informational messages; print only results")
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output (also set by NO_COLOR)")
 
 	// Config override flags (override env vars and build-time values)
 	rootCmd.PersistentFlags().StringVar(&flagAPIURL, "api-url", "", "Override API URL (default: https://manaflow.com)")
 	rootCmd.PersistentFlags().StringVar(&flagConvexSiteURL, "convex-url", "", "Override Convex site URL")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Profile to use (default: $CMUX_PROFILE or 'cmux profile use')")
 
 	// Version command
 	rootCmd.AddCommand(versionCmd)
@@ -65,9 +93,110 @@ func init() {
 	rootCmd.AddCommand(whoamiCmd)
 }
 
-// Execute runs the root command
+// Execute runs the root command. Errors carry an error code and exit code
+// for output.PrintError: usage errors for bad flags and arguments, and
+// classifyError's codes for auth, API, and network failures.
 func Execute() error {
-	return rootCmd.Execute()
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return output.WithExitCode(err, output.ExitUsage)
+	})
+	markArgErrors(rootCmd)
+
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		// Flag and argument errors happen before PersistentPreRunE, so apply
+		// --json and friends here too, for output.PrintError
+		_ = applyOutputFlags(cmd)
+	}
+	if err != nil && strings.HasPrefix(err.Error(), "unknown command") {
+		return output.WithExitCode(err, output.ExitUsage)
+	}
+	return classifyError(cmd, err)
+}
+
+// applyOutputFlags sets the output format, quiet mode and color from the
+// global flags
+func applyOutputFlags(cmd *cobra.Command) error {
+	format := flagOutput
+	if flagJSON {
+		if cmd.Flags().Changed("output") && flagOutput != "json" {
+			return output.WithExitCode(fmt.Errorf("--json conflicts with --output %s", flagOutput), output.ExitUsage)
+		}
+		format = "json"
+	}
+	if err := output.SetFormat(format); err != nil {
+		return output.WithExitCode(err, output.ExitUsage)
+	}
+	output.SetQuiet(flagQuiet)
+	output.SetNoColor(flagNoColor)
+	return nil
+}
+
+// markArgErrors gives argument validation errors the usage exit code
+func markArgErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return output.WithExitCode(validate(cmd, args), output.ExitUsage)
+		}
+	}
+	for _, child := range cmd.Commands() {
+		markArgErrors(child)
+	}
+}
+
+// instanceIDPattern matches instance IDs, to tell them apart from the
+// commands and paths that may follow an omitted ID
+var instanceIDPattern = regexp.MustCompile(`^(?:manaflow|cmux)_[a-zA-Z0-9]{8,}$`)
+
+// resolveInstanceID expands "last" to the most recently used instance ID
+// and "." to the instance bound to the current directory
+func resolveInstanceID(arg string) (string, error) {
+	switch arg {
+	case ".":
+		instanceID, _ := state.BoundInstance(currentDir())
+		if instanceID == "" {
+			return "", fmt.Errorf("no instance bound to this directory; run 'cmux use <id>' first")
+		}
+		return instanceID, nil
+	case "last":
+		instanceID, _, err := state.GetLastInstance()
+		if err != nil {
+			return "", fmt.Errorf("failed to read last instance: %w", err)
+		}
+		if instanceID == "" {
+			return "", fmt.Errorf("no last instance recorded; run 'cmux start' first")
+		}
+		return instanceID, nil
+	}
+	return arg, nil
+}
+
+// splitInstanceArg takes the instance from the first argument when it is an
+// ID, "last", or "." followed by more arguments, and otherwise uses the
+// instance bound to the current directory, falling back to the last used
+// instance. It returns the remaining arguments. A lone "." is left as an
+// argument, since commands like 'cmux sync .' mean the current directory.
+func splitInstanceArg(args []string) (string, []string, error) {
+	if len(args) > 0 && ((args[0] == "." && len(args) > 1) || args[0] == "last" || instanceIDPattern.MatchString(args[0])) {
+		instanceID, err := resolveInstanceID(args[0])
+		return instanceID, args[1:], err
+	}
+	if instanceID, _ := state.BoundInstance(currentDir()); instanceID != "" {
+		return instanceID, args, nil
+	}
+	instanceID, _, _ := state.GetLastInstance()
+	if instanceID == "" {
+		return "", nil, fmt.Errorf("no instance given; pass an ID or bind this directory with 'cmux use <id>'")
+	}
+	return instanceID, args, nil
+}
+
+func currentDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return dir
 }
 
 // Helper to check if output is a terminal
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+)
+
+func TestSplitInstanceArgSyncDot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Chdir(t.TempDir())
+
+	if err := state.SetLastInstance("cmux_last12345", "acme"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 'cmux sync .' syncs the current directory to the last instance
+	instanceID, rest, err := splitInstanceArg([]string{"."})
+	if err != nil {
+		t.Fatalf("cmux sync .: %v", err)
+	}
+	if instanceID != "cmux_last12345" || !reflect.DeepEqual(rest, []string{"."}) {
+		t.Fatalf("cmux sync . = %q %v, want the last instance and path .", instanceID, rest)
+	}
+
+	// With more arguments, "." is the bound instance
+	if _, _, err := splitInstanceArg([]string{".", "./src"}); err == nil {
+		t.Fatalf("cmux sync . ./src: expected an error with no bound instance")
+	}
+	if err := state.BindDirectory(currentDir(), "cmux_bound1234"); err != nil {
+		t.Fatal(err)
+	}
+	instanceID, rest, err = splitInstanceArg([]string{".", "./src"})
+	if err != nil || instanceID != "cmux_bound1234" || !reflect.DeepEqual(rest, []string{"./src"}) {
+		t.Fatalf("cmux sync . ./src = %q %v %v, want the bound instance and path ./src", instanceID, rest, err)
+	}
+
+	// A lone "." is still a path when the directory is bound
+	instanceID, rest, err = splitInstanceArg([]string{"."})
+	if err != nil || instanceID != "cmux_bound1234" || !reflect.DeepEqual(rest, []string{"."}) {
+		t.Fatalf("cmux sync . = %q %v %v, want the bound instance and path .", instanceID, rest, err)
+	}
+}
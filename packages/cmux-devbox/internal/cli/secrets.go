@@ -0,0 +1,176 @@
+// internal/cli/secrets.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateSecretName(name string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (must be a valid environment variable name)", name)
+	}
+	return nil
+}
+
+// readSecretValue prompts for a value without echo on a terminal, or reads
+// all of stdin when piped
+func readSecretValue(name string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value: %w", err)
+		}
+		return string(value), nil
+	}
+
+	value, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value from stdin: %w", err)
+	}
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
+
+var secretsCmd = &cobra.Command{
+	Use:     "secrets",
+	Aliases: []string{"secret"},
+	Short:   "Manage team secrets",
+	Long: `Manage secrets that can be injected into VMs as environment variables.
+
+Secrets are shared with the whole team. Only a secret's creator or a team
+admin can change or delete it. Values are write-only: they can be set and
+deleted, but never read back. Inject them when creating a VM with
+'cmux start --secret NAME'.
+
+Examples:
+  cmux secrets set OPENAI_API_KEY                # Prompt for the value
+  cat key.txt | cmux secrets set OPENAI_API_KEY  # Read the value from stdin
+  cmux secrets list
+  cmux secrets rm OPENAI_API_KEY
+  cmux start --secret OPENAI_API_KEY`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Create or update a secret",
+	Long: `Create or update a secret. The value is prompted for without echo, or
+read from stdin when piped, so it never ends up in your shell history.
+
+Examples:
+  cmux secrets set OPENAI_API_KEY
+  cmux secrets set NPM_TOKEN --description "Read-only npm token" < token.txt`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		description, _ := cmd.Flags().GetString("description")
+		if err := validateSecretName(name); err != nil {
+			return err
+		}
+
+		value, err := readSecretValue(name)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("secret value is empty")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.SetSecret(ctx, name, value, description); err != nil {
+			return fmt.Errorf("failed to set secret: %w", err)
+		}
+
+		fmt.Printf("✓ Secret %s saved\n", name)
+		return nil
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List secret names",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		secrets, err := client.ListSecrets(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list secrets: %w", err)
+		}
+
+		if output.Structured() {
+			return output.Print(secrets)
+		}
+
+		if len(secrets) == 0 {
+			fmt.Println("No secrets found. Run 'cmux secrets set <name>' to add one.")
+			return nil
+		}
+
+		fmt.Printf("%-32s %-8s %s\n", "NAME", "UPDATED", "DESCRIPTION")
+		fmt.Println("-------------------------------- -------- " + "------------------------------")
+		for _, secret := range secrets {
+			fmt.Printf("%-32s %-8s %s\n", secret.Name, formatAge(secret.UpdatedAt), secret.Description)
+		}
+		return nil
+	},
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:     "rm <name>...",
+	Aliases: []string{"delete"},
+	Short:   "Delete secrets",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range args {
+			if err := client.DeleteSecret(ctx, name); err != nil {
+				return fmt.Errorf("failed to delete secret %s: %w", name, err)
+			}
+			fmt.Printf("✓ Secret %s deleted\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	secretsSetCmd.Flags().String("description", "", "Description shown in 'cmux secrets list'")
+
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRmCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
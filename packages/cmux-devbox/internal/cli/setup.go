@@ -0,0 +1,99 @@
+// internal/cli/setup.go
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// setupFileName is the repo-level file listing setup commands, one per line
+const setupFileName = ".cmuxsetup"
+
+// setupStepTimeout bounds each setup command, so a hung install doesn't
+// block forever
+const setupStepTimeout = 20 * time.Minute
+
+// addSetupScriptFlag registers --setup-script on a syncing command
+func addSetupScriptFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("setup-script", nil, "Command to run in the VM's workspace after syncing (repeatable)")
+}
+
+// loadSetupCommands returns the commands in root's .cmuxsetup file, when
+// useFile is set and there is one, followed by the --setup-script flags.
+// Blank lines and lines starting with "#" are skipped.
+func loadSetupCommands(cmd *cobra.Command, root string, useFile bool) ([]string, error) {
+	scripts, _ := cmd.Flags().GetStringArray("setup-script")
+
+	var commands []string
+	if useFile && root != "" {
+		file, err := os.Open(filepath.Join(root, setupFileName))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", setupFileName, err)
+		}
+		if err == nil {
+			defer file.Close()
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				commands = append(commands, line)
+			}
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", setupFileName, err)
+			}
+		}
+	}
+	return append(commands, scripts...), nil
+}
+
+// runSetupCommands runs each command in the VM's workspace, streaming its
+// output, and stops at the first one that fails. Ctrl-C stops the current
+// command.
+func runSetupCommands(instanceID string, commands []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newTeamClient()
+	if err != nil {
+		return err
+	}
+
+	for i, command := range commands {
+		output.Infof("[setup %d/%d] %s\n", i+1, len(commands), command)
+		start := time.Now()
+		exitCode, err := client.ExecStream(ctx, instanceID, vm.InWorkspace(command), setupStepTimeout, func(event vm.ExecEvent) {
+			switch event.Type {
+			case "stdout":
+				fmt.Fprintln(os.Stdout, event.Data)
+			case "stderr":
+				fmt.Fprintln(os.Stderr, event.Data)
+			case "error":
+				fmt.Fprintf(os.Stderr, "Error: %s\n", event.Message)
+			}
+		})
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("setup interrupted at %q", command)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to run setup command %q: %w", command, err)
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("setup command %q exited with code %d", command, exitCode)
+		}
+		fmt.Printf("✓ %s (%s)\n", command, time.Since(start).Round(time.Second))
+	}
+	return nil
+}
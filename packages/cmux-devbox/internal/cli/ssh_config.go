@@ -0,0 +1,241 @@
+// internal/cli/ssh_config.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+)
+
+// sshConfigHostPrefix prefixes generated Host aliases, e.g. cmux-cmux_abc123
+const sshConfigHostPrefix = "cmux-"
+
+var sshConfigIncludePattern = regexp.MustCompile(`(?mi)^\s*Include\s+.*config\.d/(cmux|\*)\s*$`)
+
+// sshConfigPaths returns ~/.ssh/config and the file cmux manages,
+// ~/.ssh/config.d/cmux
+func sshConfigPaths() (string, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	sshDir := filepath.Join(home, ".ssh")
+	return filepath.Join(sshDir, "config"), filepath.Join(sshDir, "config.d", "cmux"), nil
+}
+
+// sshConfigBlock renders a Host block for an instance's user@host target,
+// carrying over vm.SSHOptions as config directives
+func sshConfigBlock(instanceID, sshTarget string) (string, error) {
+	at := strings.LastIndex(sshTarget, "@")
+	if at <= 0 || at == len(sshTarget)-1 {
+		return "", fmt.Errorf("invalid SSH target for %s", instanceID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Host %s%s\n", sshConfigHostPrefix, instanceID)
+	fmt.Fprintf(&b, "  HostName %s\n", sshTarget[at+1:])
+	fmt.Fprintf(&b, "  User %s\n", sshTarget[:at])
+	options := vm.SSHOptions()
+	for i := 0; i+1 < len(options); i += 2 {
+		if options[i] != "-o" {
+			continue
+		}
+		if key, value, ok := strings.Cut(options[i+1], "="); ok {
+			fmt.Fprintf(&b, "  %s %s\n", key, value)
+		}
+	}
+	b.WriteString("  LogLevel ERROR\n")
+	return b.String(), nil
+}
+
+// parseSSHConfigBlocks splits a managed config file into blocks keyed by
+// Host alias
+func parseSSHConfigBlocks(data string) map[string]string {
+	blocks := map[string]string{}
+	var host string
+	var b strings.Builder
+	flush := func() {
+		if host != "" {
+			blocks[host] = b.String()
+		}
+		b.Reset()
+	}
+	for _, line := range strings.SplitAfter(data, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && strings.EqualFold(fields[0], "Host") {
+			flush()
+			host = fields[1]
+		}
+		if host != "" && strings.TrimSpace(line) != "" {
+			b.WriteString(line)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// ensureSSHConfigInclude adds "Include config.d/cmux" to the top of
+// ~/.ssh/config (Include only applies globally before the first Host line).
+// It reports whether the file changed.
+func ensureSSHConfigInclude(configPath string) (bool, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if sshConfigIncludePattern.Match(data) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return false, err
+	}
+	updated := "Include config.d/cmux\n\n" + string(data)
+	return true, os.WriteFile(configPath, []byte(updated), 0600)
+}
+
+var sshConfigCmd = &cobra.Command{
+	Use:   "ssh-config [id]...",
+	Short: "Write SSH config entries for VMs",
+	Long: `Write a "Host cmux-<id>" entry for each VM to ~/.ssh/config.d/cmux, so
+plain 'ssh cmux-<id>', scp, and VS Code Remote-SSH connect to VMs directly.
+
+With no IDs, entries are written for every running VM and entries for VMs
+that are no longer running are removed. With IDs, only those entries are
+added or refreshed. An "Include config.d/cmux" line is added to the top of
+~/.ssh/config if it isn't there yet.
+
+Entries embed the VM's SSH credentials; run the command again if a VM's
+credentials change (for example after it is recreated).
+
+Examples:
+  cmux ssh-config                  # All running VMs
+  cmux ssh-config cmux_abc123      # Then: ssh cmux-cmux_abc123
+  cmux ssh-config last --print     # Print the entry instead of writing it
+  cmux ssh-config --remove cmux_abc123`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printOnly, _ := cmd.Flags().GetBool("print")
+		remove, _ := cmd.Flags().GetBool("remove")
+		if remove && len(args) == 0 {
+			return fmt.Errorf("specify the VMs whose entries to remove")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		instanceIDs := make([]string, 0, len(args))
+		for _, arg := range args {
+			instanceID, err := resolveInstanceID(arg)
+			if err != nil {
+				return err
+			}
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+
+		configPath, managedPath, err := sshConfigPaths()
+		if err != nil {
+			return fmt.Errorf("failed to find home directory: %w", err)
+		}
+
+		blocks := map[string]string{}
+		if len(args) > 0 {
+			if data, err := os.ReadFile(managedPath); err == nil {
+				blocks = parseSSHConfigBlocks(string(data))
+			} else if !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read %s: %w", managedPath, err)
+			}
+		}
+
+		if remove {
+			for _, instanceID := range instanceIDs {
+				delete(blocks, sshConfigHostPrefix+instanceID)
+			}
+		} else {
+			client, err := newTeamClient()
+			if err != nil {
+				return err
+			}
+
+			if len(instanceIDs) == 0 {
+				instances, err := client.ListInstances(ctx, false)
+				if err != nil {
+					return fmt.Errorf("failed to list instances: %w", err)
+				}
+				for _, instance := range instances {
+					if instance.Status == "running" {
+						instanceIDs = append(instanceIDs, instance.ID)
+					}
+				}
+				if len(instanceIDs) == 0 && printOnly {
+					return fmt.Errorf("no running VMs")
+				}
+			}
+
+			var printed []string
+			for _, instanceID := range instanceIDs {
+				sshTarget, err := client.SSHTarget(ctx, instanceID)
+				if err != nil {
+					return fmt.Errorf("%s: %w", instanceID, err)
+				}
+				block, err := sshConfigBlock(instanceID, sshTarget)
+				if err != nil {
+					return err
+				}
+				blocks[sshConfigHostPrefix+instanceID] = block
+				printed = append(printed, block)
+			}
+
+			if printOnly {
+				fmt.Print(strings.Join(printed, "\n"))
+				return nil
+			}
+		}
+
+		hosts := make([]string, 0, len(blocks))
+		for host := range blocks {
+			hosts = append(hosts, host)
+		}
+		sort.Strings(hosts)
+		var out strings.Builder
+		out.WriteString("# Managed by 'cmux ssh-config'; changes will be overwritten\n")
+		for _, host := range hosts {
+			out.WriteString("\n" + blocks[host])
+		}
+
+		if err := os.MkdirAll(filepath.Dir(managedPath), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(managedPath), err)
+		}
+		if err := os.WriteFile(managedPath, []byte(out.String()), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", managedPath, err)
+		}
+		added, err := ensureSSHConfigInclude(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", configPath, err)
+		}
+
+		if remove {
+			fmt.Printf("✓ Removed SSH config for %d VM(s) from %s\n", len(instanceIDs), managedPath)
+		} else {
+			fmt.Printf("✓ Wrote SSH config for %d VM(s) to %s\n", len(instanceIDs), managedPath)
+			for _, instanceID := range instanceIDs {
+				fmt.Printf("  ssh %s%s\n", sshConfigHostPrefix, instanceID)
+			}
+		}
+		if added {
+			fmt.Printf("  Added \"Include config.d/cmux\" to %s\n", configPath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sshConfigCmd.Flags().Bool("print", false, "Print entries to stdout instead of writing them")
+	sshConfigCmd.Flags().Bool("remove", false, "Remove the entries for the given VMs")
+	sshConfigCmd.MarkFlagsMutuallyExclusive("print", "remove")
+	rootCmd.AddCommand(sshConfigCmd)
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// execSSH replaces the current process with ssh so the terminal, signals,
+// and exit status belong to the SSH session
+func execSSH(args []string) error {
+	path, err := exec.LookPath("ssh")
+	if err != nil {
+		return fmt.Errorf("ssh not found in PATH: %w", err)
+	}
+	return syscall.Exec(path, append([]string{"ssh"}, args...), os.Environ())
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// execSSH runs ssh as a child process on Windows (no exec syscall) and exits
+// with its status
+func execSSH(args []string) error {
+	sshExec := exec.Command("ssh", args...)
+	sshExec.Stdin = os.Stdin
+	sshExec.Stdout = os.Stdout
+	sshExec.Stderr = os.Stderr
+
+	err := sshExec.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
@@ -22,12 +23,19 @@ var startCmd = &cobra.Command{
 
 Each call creates a NEW VM. Use 'cmux resume <id>' to resume a paused VM.
 
+After syncing, the commands in the directory's .cmuxsetup file (one per
+line) and any --setup-script commands run in the VM's workspace, e.g. to
+install dependencies or run migrations. Output is streamed, and the first
+failing command stops setup. --no-setup skips the file.
+
 Examples:
   cmux start                    # Create VM (no sync)
   cmux new                      # Same as 'cmux start'
   cmux start .                  # Create VM, sync current directory
   cmux start ./my-project       # Create VM, sync specific directory
   cmux start --snapshot=snap_x  # Create from specific snapshot
+  cmux start --secret OPENAI_API_KEY  # Inject a team secret as an env var
+  cmux start . --setup-script "npm ci"  # Run a setup command after syncing
   cmux start -i                 # Create VM and open VS Code`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -50,9 +58,17 @@ Examples:
 		// Get snapshot ID
 		snapshotID, _ := cmd.Flags().GetString("snapshot")
 
+		secrets, _ := cmd.Flags().GetStringSlice("secret")
+		for _, secret := range secrets {
+			if err := validateSecretName(secret); err != nil {
+				return err
+			}
+		}
+
 		// Determine name from path if provided
 		name := ""
 		var syncPath string
+		var filters vm.SyncFilters
 		if len(args) > 0 {
 			syncPath = args[0]
 			absPath, err := filepath.Abs(syncPath)
@@ -70,39 +86,63 @@ Examples:
 				return fmt.Errorf("path must be a directory")
 			}
 			name = filepath.Base(syncPath)
+
+			filters, err = syncFiltersFromFlags(cmd, syncPath)
+			if err != nil {
+				return err
+			}
 		}
 
-		fmt.Println("Creating VM...")
+		noSetup, _ := cmd.Flags().GetBool("no-setup")
+		setupCommands, err := loadSetupCommands(cmd, syncPath, !noSetup)
+		if err != nil {
+			return err
+		}
+
+		output.Infoln("Creating VM...")
 		instance, err := client.CreateInstance(ctx, vm.CreateOptions{
 			SnapshotID: snapshotID,
 			Name:       name,
+			Secrets:    secrets,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create VM: %w", err)
 		}
 
-		fmt.Printf("VM created: %s\n", instance.ID)
+		output.Infof("VM created: %s\n", instance.ID)
 
 		// Wait for VM to be ready
-		fmt.Println("Waiting for VM to be ready...")
+		output.Infoln("Waiting for VM to be ready...")
 		instance, err = client.WaitForReady(ctx, instance.ID, 2*time.Minute)
 		if err != nil {
 			return fmt.Errorf("VM failed to start: %w", err)
 		}
 
 		// Sync directory if specified
+		synced := syncPath == ""
 		if syncPath != "" {
-			fmt.Printf("Syncing %s to VM...\n", syncPath)
-			if err := client.SyncToVM(ctx, instance.ID, syncPath); err != nil {
+			output.Infof("Syncing %s to VM...\n", syncPath)
+			if err := syncToVM(ctx, client, instance.ID, syncPath, filters); err != nil {
 				fmt.Printf("Warning: failed to sync files: %v\n", err)
 			} else {
-				fmt.Println("Files synced successfully")
+				output.Infoln("Files synced successfully")
+				state.SetSyncPath(instance.ID, syncPath)
+				synced = true
 			}
 		}
 
 		// Save as last used instance
 		state.SetLastInstance(instance.ID, teamSlug)
 
+		// Run setup commands against the synced files
+		if len(setupCommands) > 0 {
+			if !synced {
+				fmt.Println("Warning: skipping setup because the sync failed")
+			} else if err := runSetupCommands(instance.ID, setupCommands); err != nil {
+				return fmt.Errorf("%w\nThe VM %s is still running; re-run setup with 'cmux sync %s --setup'", err, instance.ID, instance.ID)
+			}
+		}
+
 		// Generate auth token for authenticated URLs
 		token, err := getAuthToken(ctx, client, instance.ID)
 		if err != nil {
@@ -116,11 +156,11 @@ Examples:
 		}
 
 		// Build authenticated URLs
-		codeAuthURL, err := buildAuthURL(instance.WorkerURL, "/code/?folder=/home/cmux/workspace", token)
+		codeAuthURL, err := buildAuthURL(instance.WorkerURL, codePath, token)
 		if err != nil {
 			return fmt.Errorf("failed to build VS Code URL: %w", err)
 		}
-		vncAuthURL, err := buildAuthURL(instance.WorkerURL, "/vnc/vnc.html?path=vnc/websockify&resize=scale&quality=9&compression=0", token)
+		vncAuthURL, err := buildAuthURL(instance.WorkerURL, vncPath, token)
 		if err != nil {
 			return fmt.Errorf("failed to build VNC URL: %w", err)
 		}
@@ -134,7 +174,7 @@ Examples:
 		// Open VS Code in browser if interactive mode
 		interactive, _ := cmd.Flags().GetBool("interactive")
 		if interactive {
-			fmt.Println("\nOpening VS Code in browser...")
+			output.Infoln("\nOpening VS Code in browser...")
 			if err := openBrowser(codeAuthURL); err != nil {
 				fmt.Printf("Warning: could not open browser: %v\n", err)
 			}
@@ -147,5 +187,10 @@ Examples:
 func init() {
 	startCmd.Flags().String("snapshot", "", "Snapshot ID to create from")
 	startCmd.Flags().BoolP("interactive", "i", false, "Open VS Code in browser after creation")
+	startCmd.Flags().StringSlice("secret", nil, "Team secret to inject as an environment variable (repeatable)")
+	startCmd.RegisterFlagCompletionFunc("secret", completeSecretNames)
+	startCmd.Flags().Bool("no-setup", false, "Don't run the synced directory's .cmuxsetup commands")
+	addSyncFilterFlags(startCmd)
+	addSetupScriptFlag(startCmd)
 	rootCmd.AddCommand(startCmd)
 }
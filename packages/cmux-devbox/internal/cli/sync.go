@@ -9,36 +9,118 @@ import (
 	"time"
 
 	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
 	"github.com/cmux-cli/cmux-devbox/internal/vm"
 	"github.com/spf13/cobra"
 )
 
 var syncCmd = &cobra.Command{
-	Use:   "sync <id> <path>",
+	Use:   "sync [id] [path]",
 	Short: "Sync files to a VM",
 	Long: `Sync a local directory to a VM.
 
 Use --pull to sync from VM to local instead.
 
+Without an ID, the VM bound to the current directory ('cmux use') or the
+last used VM is used. Without a path, the bound directory (or the current
+directory) is synced.
+
+Large and generated directories (.git, node_modules, dist, build, ...) are
+skipped by default. Add patterns to a .cmuxignore file in the synced
+directory, or pass --exclude/--include. A .cmuxignore line starting with "!"
+and --include re-include paths that would otherwise be skipped.
+
+Pushes split top-level directories across --parallel rsync processes, and
+all SSH traffic to a VM shares one connection. --progress shows overall
+progress (rsync 3.1+), and --compress zstd compresses faster than the
+default zlib (rsync 3.2+ on both ends).
+
+Pushes delete remote files that no longer exist locally. When a push would
+delete more than --delete-threshold files, they are listed and you're asked
+to confirm; declining syncs without deleting. --no-delete never deletes,
+and --yes skips the confirmation. --dry-run lists what would be transferred
+or deleted without changing anything, and --checksum compares file contents
+instead of sizes and modification times.
+
+--setup runs the directory's .cmuxsetup commands in the VM after pushing,
+and --setup-script adds more, as 'cmux start' does for new VMs.
+
 Examples:
   cmux sync cmux_abc123 .              # Sync current directory to VM
   cmux sync cmux_abc123 ./my-project   # Sync specific directory
-  cmux sync cmux_abc123 ./output --pull  # Pull from VM to local`,
-	Args: cobra.ExactArgs(2),
+  cmux sync cmux_abc123 ./output --pull  # Pull from VM to local
+  cmux sync cmux_abc123 . --exclude '*.log' --include dist
+  cmux sync cmux_abc123 . --progress --compress zstd
+  cmux sync cmux_abc123 . --setup      # Sync, then run .cmuxsetup
+  cmux sync cmux_abc123 . --dry-run    # Show what would change
+  cmux sync cmux_abc123 . --no-delete  # Keep files that only exist in the VM
+  cmux sync                            # Bound VM and directory`,
+	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
 
-		instanceID := args[0]
-		localPath := args[1]
+		instanceID, rest, err := splitInstanceArg(args)
+		if err != nil {
+			return err
+		}
+		localPath := "."
+		if _, boundDir := state.BoundInstance(currentDir()); boundDir != "" {
+			localPath = boundDir
+		}
+		switch len(rest) {
+		case 0:
+		case 1:
+			localPath = rest[0]
+		default:
+			return fmt.Errorf("too many arguments; use 'cmux sync [id] [path]'")
+		}
 
 		pull, _ := cmd.Flags().GetBool("pull")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		progress, _ := cmd.Flags().GetBool("progress")
+		compress, _ := cmd.Flags().GetString("compress")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		checksum, _ := cmd.Flags().GetBool("checksum")
+		noDelete, _ := cmd.Flags().GetBool("no-delete")
+		deleteThreshold, _ := cmd.Flags().GetInt("delete-threshold")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		switch compress {
+		case "zlib", "zstd", "none":
+		default:
+			return fmt.Errorf("invalid --compress %q (use zlib, zstd, or none)", compress)
+		}
+		if parallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+		if deleteThreshold < 0 {
+			return fmt.Errorf("--delete-threshold can't be negative")
+		}
 
 		absPath, err := filepath.Abs(localPath)
 		if err != nil {
 			return fmt.Errorf("invalid path: %w", err)
 		}
 
+		filters, err := syncFiltersFromFlags(cmd, absPath)
+		if err != nil {
+			return err
+		}
+
+		setup, _ := cmd.Flags().GetBool("setup")
+		setupCommands, err := loadSetupCommands(cmd, absPath, setup)
+		if err != nil {
+			return err
+		}
+		if pull && len(setupCommands) > 0 {
+			return fmt.Errorf("--setup and --setup-script can't be used with --pull")
+		}
+		if setup && len(setupCommands) == 0 {
+			return fmt.Errorf("no setup commands: %s has no %s file", absPath, setupFileName)
+		}
+
 		// Get team slug
 		teamSlug, err := auth.GetTeamSlug()
 		if err != nil {
@@ -51,14 +133,38 @@ Examples:
 		}
 		client.SetTeamSlug(teamSlug)
 
+		syncer, err := client.NewSyncer(ctx, instanceID)
+		if err != nil {
+			return err
+		}
+		syncer.Filters = filters
+		syncer.Parallel = parallel
+		syncer.Progress = progress
+		if output.Quiet() && !dryRun {
+			syncer.Output = nil
+		}
+		syncer.Compression = compress
+		syncer.Checksum = checksum
+		syncer.DryRun = dryRun
+		syncer.Delete = !noDelete
+
 		if pull {
+			if dryRun {
+				fmt.Printf("Changes a pull from VM %s to %s would make:\n", instanceID, absPath)
+				if err := syncer.Pull(ctx, absPath); err != nil {
+					return fmt.Errorf("failed to sync: %w", err)
+				}
+				fmt.Println("✓ Dry run complete; nothing was changed")
+				return nil
+			}
+
 			// Ensure local directory exists for pull
 			if err := os.MkdirAll(absPath, 0755); err != nil {
 				return fmt.Errorf("failed to create directory: %w", err)
 			}
 
-			fmt.Printf("Pulling from VM %s to %s...\n", instanceID, absPath)
-			if err := client.SyncFromVM(ctx, instanceID, absPath); err != nil {
+			output.Infof("Pulling from VM %s to %s...\n", instanceID, absPath)
+			if err := syncer.Pull(ctx, absPath); err != nil {
 				return fmt.Errorf("failed to sync: %w", err)
 			}
 			fmt.Println("✓ Files synced from VM")
@@ -72,18 +178,111 @@ Examples:
 				return fmt.Errorf("path must be a directory")
 			}
 
-			fmt.Printf("Syncing %s to VM %s...\n", absPath, instanceID)
-			if err := client.SyncToVM(ctx, instanceID, absPath); err != nil {
+			if dryRun {
+				fmt.Printf("Changes a sync of %s to VM %s would make:\n", absPath, instanceID)
+				if err := syncer.Push(ctx, absPath); err != nil {
+					return fmt.Errorf("failed to sync: %w", err)
+				}
+				fmt.Println("✓ Dry run complete; nothing was changed")
+				return nil
+			}
+
+			if syncer.Delete && !yes {
+				if err := confirmSyncDeletions(ctx, syncer, absPath, deleteThreshold); err != nil {
+					return err
+				}
+			}
+
+			output.Infof("Syncing %s to VM %s...\n", absPath, instanceID)
+			start := time.Now()
+			if err := syncer.Push(ctx, absPath); err != nil {
 				return fmt.Errorf("failed to sync: %w", err)
 			}
-			fmt.Println("✓ Files synced to VM")
+			state.SetSyncPath(instanceID, absPath)
+			fmt.Printf("✓ Files synced to VM in %s\n", time.Since(start).Round(time.Millisecond))
+
+			if len(setupCommands) > 0 {
+				if err := runSetupCommands(instanceID, setupCommands); err != nil {
+					return err
+				}
+			}
 		}
 
 		return nil
 	},
 }
 
+// confirmSyncDeletions asks before a push deletes more than threshold
+// remote files, and turns deletion off if the answer is no
+func confirmSyncDeletions(ctx context.Context, syncer *vm.Syncer, localPath string, threshold int) error {
+	deletions, err := syncer.Deletions(ctx, localPath)
+	if err != nil {
+		return fmt.Errorf("failed to check for remote deletions: %w", err)
+	}
+	if len(deletions) <= threshold {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "This sync would delete %d file(s) in the VM that don't exist locally:\n", len(deletions))
+	for i, path := range deletions {
+		if i == 10 {
+			fmt.Fprintf(os.Stderr, "  ... and %d more\n", len(deletions)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "  %s\n", path)
+	}
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("refusing to delete %d remote files without confirmation; pass --yes to delete them or --no-delete to keep them", len(deletions))
+	}
+	if !confirm("Delete them?") {
+		fmt.Fprintln(os.Stderr, "Keeping them; syncing without deleting.")
+		syncer.Delete = false
+	}
+	return nil
+}
+
+// syncToVM pushes localPath like vm.Client.SyncToVM, without rsync's file
+// list when --quiet is set
+func syncToVM(ctx context.Context, client *vm.Client, instanceID, localPath string, filters vm.SyncFilters) error {
+	syncer, err := client.NewSyncer(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	syncer.Filters = filters
+	syncer.Parallel = vm.DefaultSyncParallel
+	if output.Quiet() {
+		syncer.Output = nil
+	}
+	return syncer.Push(ctx, localPath)
+}
+
+// addSyncFilterFlags registers --exclude and --include on a syncing command
+func addSyncFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("exclude", nil, "Skip paths matching this rsync pattern (repeatable)")
+	cmd.Flags().StringArray("include", nil, "Sync paths matching this pattern even if excluded (repeatable)")
+}
+
+// syncFiltersFromFlags layers the command's --include/--exclude flags over
+// the .cmuxignore file in root
+func syncFiltersFromFlags(cmd *cobra.Command, root string) (vm.SyncFilters, error) {
+	includes, _ := cmd.Flags().GetStringArray("include")
+	excludes, _ := cmd.Flags().GetStringArray("exclude")
+	return vm.LoadSyncFilters(root, includes, excludes)
+}
+
 func init() {
 	syncCmd.Flags().Bool("pull", false, "Pull from VM instead of push to VM")
+	syncCmd.Flags().Int("parallel", vm.DefaultSyncParallel, "Number of rsync processes for pushes")
+	syncCmd.Flags().Bool("progress", false, "Show overall progress instead of the file list (rsync 3.1+)")
+	syncCmd.Flags().String("compress", "zlib", "Compression: zlib, zstd (rsync 3.2+), or none")
+	syncCmd.RegisterFlagCompletionFunc("compress", cobra.FixedCompletions([]string{"zlib", "zstd", "none"}, cobra.ShellCompDirectiveNoFileComp))
+	syncCmd.Flags().Bool("dry-run", false, "List what would be transferred or deleted without changing anything")
+	syncCmd.Flags().Bool("checksum", false, "Compare file contents instead of sizes and modification times")
+	syncCmd.Flags().Bool("no-delete", false, "Keep remote files that no longer exist locally")
+	syncCmd.Flags().Int("delete-threshold", 20, "Ask before a push deletes more than this many remote files")
+	syncCmd.Flags().BoolP("yes", "y", false, "Delete remote files without asking")
+	syncCmd.Flags().Bool("setup", false, "Run the directory's .cmuxsetup commands in the VM after pushing")
+	addSyncFilterFlags(syncCmd)
+	addSetupScriptFlag(syncCmd)
 	rootCmd.AddCommand(syncCmd)
 }
@@ -0,0 +1,187 @@
+// internal/cli/task.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cobra"
+)
+
+// taskStatusInterval is how often --follow checks whether the run finished
+const taskStatusInterval = 5 * time.Second
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Inspect agent task runs",
+	Long: `Inspect task runs started from the cmux web app.
+
+Examples:
+  cmux task logs <task-run-id>            # Print the agent's output so far
+  cmux task logs <task-run-id> --follow   # Stream output as the agent works`,
+}
+
+var taskLogsCmd = &cobra.Command{
+	Use:   "logs <task-run-id>",
+	Short: "Show an agent's terminal output",
+	Long: `Show the terminal output of the agent in a task run.
+
+Output is passed through unmodified, so colors and other terminal escape
+sequences render as they do in the web app. With --follow, output streams
+until the run finishes or you press Ctrl-C.
+
+Examples:
+  cmux task logs k57a8h3fx2e5m9q1p0w4r6t8y
+  cmux task logs k57a8h3fx2e5m9q1p0w4r6t8y --follow`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskRunID := args[0]
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := vm.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client.SetTeamSlug(teamSlug)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		lookupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		taskRun, err := client.GetTaskRun(lookupCtx, taskRunID)
+		if err != nil {
+			return fmt.Errorf("failed to get task run: %w", err)
+		}
+		if taskRun.PtyURL == "" {
+			return fmt.Errorf("task run %s has no cloud terminal (status: %s)", taskRunID, taskRun.Status)
+		}
+
+		session, err := client.FindAgentTerminal(lookupCtx, taskRun.PtyURL)
+		if err != nil {
+			return fmt.Errorf("failed to find agent terminal: %w", err)
+		}
+
+		if !follow {
+			content, err := client.CaptureTerminal(lookupCtx, taskRun.PtyURL, session.ID)
+			if err != nil {
+				return fmt.Errorf("failed to read agent output: %w", err)
+			}
+			fmt.Print(content)
+			return nil
+		}
+
+		return followTaskLogs(ctx, client, taskRun, session.ID)
+	},
+}
+
+// followTaskLogs streams the agent terminal to stdout until the run finishes,
+// the terminal closes, or ctx is canceled. The connection is read-only; no
+// input is ever sent to the agent.
+func followTaskLogs(ctx context.Context, client *vm.Client, taskRun *vm.TaskRun, sessionID string) error {
+	wsURL, err := buildTerminalWebSocketURL(taskRun.PtyURL, sessionID)
+	if err != nil {
+		return err
+	}
+
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+	conn, resp, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		if resp != nil {
+			body, _ := io.ReadAll(resp.Body)
+			return fmt.Errorf("failed to connect: %w (status: %d, body: %s)", err, resp.StatusCode, string(body))
+		}
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	// The server replays scrollback first, then live output
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			os.Stdout.Write(message)
+		}
+	}()
+
+	ticker := time.NewTicker(taskStatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(os.Stderr, "\nStopped following")
+			return nil
+		case <-done:
+			fmt.Fprintln(os.Stderr, "\nTerminal closed")
+			return nil
+		case <-ticker.C:
+		}
+
+		statusCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		current, err := client.GetTaskRun(statusCtx, taskRun.ID)
+		cancel()
+		if err != nil || !current.Finished() {
+			continue
+		}
+
+		// Give trailing output a moment to arrive before disconnecting
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+		}
+		fmt.Fprintf(os.Stderr, "\nTask run %s\n", describeTaskRunEnd(current))
+		return nil
+	}
+}
+
+func describeTaskRunEnd(taskRun *vm.TaskRun) string {
+	switch {
+	case taskRun.ErrorMessage != "":
+		return fmt.Sprintf("%s: %s", taskRun.Status, taskRun.ErrorMessage)
+	case taskRun.ExitCode != nil:
+		return fmt.Sprintf("%s (exit code %d)", taskRun.Status, *taskRun.ExitCode)
+	default:
+		return taskRun.Status
+	}
+}
+
+func buildTerminalWebSocketURL(ptyURL, sessionID string) (string, error) {
+	parsed, err := url.Parse(ptyURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid terminal URL: %w", err)
+	}
+
+	if parsed.Scheme == "https" {
+		parsed.Scheme = "wss"
+	} else {
+		parsed.Scheme = "ws"
+	}
+	parsed.Path = "/sessions/" + url.PathEscape(sessionID) + "/ws"
+
+	return parsed.String(), nil
+}
+
+func init() {
+	taskLogsCmd.Flags().BoolP("follow", "f", false, "Stream output until the run finishes")
+
+	taskCmd.AddCommand(taskLogsCmd)
+	rootCmd.AddCommand(taskCmd)
+}
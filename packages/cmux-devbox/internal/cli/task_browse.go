@@ -0,0 +1,548 @@
+// internal/cli/task_browse.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences used by the browser
+const (
+	ansiAltScreenOn  = "\x1b[?1049h\x1b[?25l"
+	ansiAltScreenOff = "\x1b[?25h\x1b[?1049l"
+	ansiClear        = "\x1b[H\x1b[2J"
+	ansiReverse      = "\x1b[7m"
+	ansiBold         = "\x1b[1m"
+	ansiDim          = "\x1b[2m"
+	ansiReset        = "\x1b[0m"
+)
+
+type browseKey int
+
+const (
+	keyNone browseKey = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyBack
+	keyQuit
+	keyRune
+)
+
+type browseInput struct {
+	key browseKey
+	r   byte
+}
+
+// decodeKeys turns raw terminal input into key presses
+func decodeKeys(buf []byte) []browseInput {
+	var keys []browseInput
+	for i := 0; i < len(buf); i++ {
+		switch b := buf[i]; {
+		case b == 0x1b && i+2 < len(buf) && buf[i+1] == '[':
+			switch buf[i+2] {
+			case 'A':
+				keys = append(keys, browseInput{key: keyUp})
+			case 'B':
+				keys = append(keys, browseInput{key: keyDown})
+			}
+			i += 2
+		case b == 0x1b:
+			keys = append(keys, browseInput{key: keyBack})
+		case b == 0x03:
+			keys = append(keys, browseInput{key: keyQuit})
+		case b == '\r' || b == '\n':
+			keys = append(keys, browseInput{key: keyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, browseInput{key: keyBack})
+		default:
+			keys = append(keys, browseInput{key: keyRune, r: b})
+		}
+	}
+	return keys
+}
+
+type browseView int
+
+const (
+	viewTasks browseView = iota
+	viewRuns
+	viewRunDetail
+)
+
+// taskBrowser holds the state of the interactive task browser
+type taskBrowser struct {
+	client *vm.Client
+
+	view       browseView
+	archived   bool
+	tasks      []vm.Task
+	taskCursor int
+	detail     *vm.TaskDetail
+	runCursor  int
+
+	message string
+	updated time.Time
+}
+
+// preferredRun picks the run to act on for a task: the crowned run if there
+// is one, otherwise the most recently started run
+func preferredRun(runs []vm.TaskRun) *vm.TaskRun {
+	var latest *vm.TaskRun
+	for i := range runs {
+		if runs[i].Crowned {
+			return &runs[i]
+		}
+		if latest == nil || runs[i].CreatedAt > latest.CreatedAt {
+			latest = &runs[i]
+		}
+	}
+	return latest
+}
+
+func formatAge(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	age := time.Since(time.UnixMilli(ms))
+	switch {
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	}
+}
+
+// fit pads or truncates s to exactly width columns
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) > width {
+		if width == 1 {
+			return "…"
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+func (b *taskBrowser) selectedTask() *vm.Task {
+	if b.taskCursor < 0 || b.taskCursor >= len(b.tasks) {
+		return nil
+	}
+	return &b.tasks[b.taskCursor]
+}
+
+func (b *taskBrowser) selectedRun() *vm.TaskRun {
+	if b.detail == nil || b.runCursor < 0 || b.runCursor >= len(b.detail.Runs) {
+		return nil
+	}
+	return &b.detail.Runs[b.runCursor]
+}
+
+// refresh reloads the task list, and the open task's runs, keeping the
+// cursor on the same task
+func (b *taskBrowser) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var selectedID string
+	if task := b.selectedTask(); task != nil {
+		selectedID = task.ID
+	}
+
+	tasks, err := b.client.ListTasks(ctx, b.archived, 0)
+	if err != nil {
+		b.message = fmt.Sprintf("refresh failed: %v", err)
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Pinned && !tasks[j].Pinned
+	})
+	b.tasks = tasks
+	b.taskCursor = 0
+	for i := range tasks {
+		if tasks[i].ID == selectedID {
+			b.taskCursor = i
+		}
+	}
+
+	if b.view != viewTasks && b.detail != nil {
+		detail, err := b.client.GetTask(ctx, b.detail.ID)
+		if err != nil {
+			b.message = fmt.Sprintf("refresh failed: %v", err)
+			return
+		}
+		b.detail = detail
+		b.runCursor = min(b.runCursor, max(len(detail.Runs)-1, 0))
+	}
+	b.updated = time.Now()
+}
+
+// handle applies one key press and reports whether the browser should exit
+func (b *taskBrowser) handle(ctx context.Context, in browseInput) bool {
+	b.message = ""
+	switch in.key {
+	case keyQuit:
+		return true
+	case keyUp:
+		b.move(-1)
+	case keyDown:
+		b.move(1)
+	case keyEnter:
+		b.enter(ctx)
+	case keyBack:
+		if b.view == viewTasks {
+			return false
+		}
+		b.view--
+	case keyRune:
+		switch in.r {
+		case 'q':
+			return true
+		case 'k':
+			b.move(-1)
+		case 'j':
+			b.move(1)
+		case 'o':
+			b.open(ctx)
+		case 'a':
+			b.toggleArchived(ctx)
+		case 'p':
+			b.togglePinned(ctx)
+		case 'r':
+			b.refresh(ctx)
+		case 'v':
+			if b.view == viewTasks {
+				b.archived = !b.archived
+				b.taskCursor = 0
+				b.refresh(ctx)
+			}
+		}
+	}
+	return false
+}
+
+func (b *taskBrowser) move(delta int) {
+	switch b.view {
+	case viewTasks:
+		b.taskCursor = max(0, min(b.taskCursor+delta, len(b.tasks)-1))
+	case viewRuns:
+		if b.detail != nil {
+			b.runCursor = max(0, min(b.runCursor+delta, len(b.detail.Runs)-1))
+		}
+	}
+}
+
+func (b *taskBrowser) enter(ctx context.Context) {
+	switch b.view {
+	case viewTasks:
+		task := b.selectedTask()
+		if task == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		detail, err := b.client.GetTask(ctx, task.ID)
+		if err != nil {
+			b.message = fmt.Sprintf("failed to load runs: %v", err)
+			return
+		}
+		b.detail = detail
+		b.runCursor = 0
+		if run := preferredRun(detail.Runs); run != nil {
+			for i := range detail.Runs {
+				if detail.Runs[i].ID == run.ID {
+					b.runCursor = i
+				}
+			}
+		}
+		b.view = viewRuns
+	case viewRuns:
+		if b.selectedRun() != nil {
+			b.view = viewRunDetail
+		}
+	}
+}
+
+// open opens the VS Code workspace of the selected run, or of the selected
+// task's crowned or latest run
+func (b *taskBrowser) open(ctx context.Context) {
+	run := b.selectedRun()
+	if b.view == viewTasks {
+		task := b.selectedTask()
+		if task == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		detail, err := b.client.GetTask(ctx, task.ID)
+		if err != nil {
+			b.message = fmt.Sprintf("failed to load runs: %v", err)
+			return
+		}
+		run = preferredRun(detail.Runs)
+	}
+	if run == nil || run.VSCodeURL == "" {
+		b.message = "no VS Code workspace for this run"
+		return
+	}
+	if err := openBrowser(run.VSCodeURL); err != nil {
+		b.message = fmt.Sprintf("failed to open browser: %v", err)
+		return
+	}
+	b.message = "opened " + run.VSCodeURL
+}
+
+func (b *taskBrowser) toggleArchived(ctx context.Context) {
+	task := b.selectedTask()
+	if b.view != viewTasks || task == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := b.client.SetTaskArchived(ctx, task.ID, !task.Archived); err != nil {
+		b.message = fmt.Sprintf("failed: %v", err)
+		return
+	}
+	verb := "archived"
+	if task.Archived {
+		verb = "unarchived"
+	}
+	b.refresh(ctx)
+	b.message = verb + " task"
+}
+
+func (b *taskBrowser) togglePinned(ctx context.Context) {
+	task := b.selectedTask()
+	if b.view != viewTasks || task == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := b.client.SetTaskPinned(ctx, task.ID, !task.Pinned); err != nil {
+		b.message = fmt.Sprintf("failed: %v", err)
+		return
+	}
+	verb := "pinned"
+	if task.Pinned {
+		verb = "unpinned"
+	}
+	b.refresh(ctx)
+	b.message = verb + " task"
+}
+
+// window returns the range of rows to show so the cursor stays visible
+func window(cursor, total, rows int) (int, int) {
+	if rows <= 0 || total <= rows {
+		return 0, total
+	}
+	start := max(0, min(cursor-rows/2, total-rows))
+	return start, start + rows
+}
+
+func (b *taskBrowser) render(width, height int) string {
+	var lines []string
+	var help string
+
+	switch b.view {
+	case viewTasks:
+		title := "Tasks"
+		if b.archived {
+			title = "Archived tasks"
+		}
+		lines = append(lines, ansiBold+fit(fmt.Sprintf("%s (%d)", title, len(b.tasks)), width)+ansiReset)
+		textWidth := max(width-2-11-19-25-6, 10)
+		lines = append(lines, ansiDim+fit(fmt.Sprintf("  %-10s %-18s %-24s %-5s %s", "STATUS", "AGENT", "REPO", "AGE", "TASK"), width)+ansiReset)
+		start, end := window(b.taskCursor, len(b.tasks), height-4)
+		for i := start; i < end; i++ {
+			task := b.tasks[i]
+			marker := "  "
+			if task.Pinned {
+				marker = "* "
+			}
+			row := marker + fit(task.Status, 10) + " " + fit(strings.Join(task.Agents, ","), 18) + " " +
+				fit(task.Repo, 24) + " " + fit(formatAge(task.CreatedAt), 5) + " " + fit(task.Text, textWidth)
+			row = fit(row, width)
+			if i == b.taskCursor {
+				row = ansiReverse + row + ansiReset
+			}
+			lines = append(lines, row)
+		}
+		if len(b.tasks) == 0 {
+			lines = append(lines, "  No tasks")
+		}
+		help = "↑/↓ move  enter runs  o open VS Code  p pin  a archive  v archived view  r refresh  q quit"
+
+	case viewRuns:
+		lines = append(lines, ansiBold+fit(b.detail.Text, width)+ansiReset)
+		lines = append(lines, ansiDim+fit(fmt.Sprintf("  %-10s %-20s %-32s %s", "STATUS", "AGENT", "BRANCH", "PR"), width)+ansiReset)
+		start, end := window(b.runCursor, len(b.detail.Runs), height-4)
+		for i := start; i < end; i++ {
+			run := b.detail.Runs[i]
+			marker := "  "
+			if run.Crowned {
+				marker = "♛ "
+			}
+			row := fit(marker+fit(run.Status, 10)+" "+fit(run.AgentName, 20)+" "+fit(run.Branch, 32)+" "+run.PullRequestURL, width)
+			if i == b.runCursor {
+				row = ansiReverse + row + ansiReset
+			}
+			lines = append(lines, row)
+		}
+		if len(b.detail.Runs) == 0 {
+			lines = append(lines, "  No runs")
+		}
+		help = "↑/↓ move  enter details  o open VS Code  esc back  r refresh  q quit"
+
+	case viewRunDetail:
+		run := b.selectedRun()
+		lines = append(lines, ansiBold+fit(b.detail.Text, width)+ansiReset, "")
+		field := func(name, value string) {
+			if value != "" {
+				lines = append(lines, fit(fmt.Sprintf("%-14s %s", name, value), width))
+			}
+		}
+		field("Run ID", run.ID)
+		field("Agent", run.AgentName)
+		field("Status", run.Status)
+		if run.Crowned {
+			field("Crowned", "yes")
+		}
+		if run.ExitCode != nil {
+			field("Exit code", fmt.Sprint(*run.ExitCode))
+		}
+		field("Error", run.ErrorMessage)
+		field("Branch", run.Branch)
+		field("Pull request", run.PullRequestURL)
+		field("VS Code", run.VSCodeURL)
+		field("Started", formatAge(run.CreatedAt)+" ago")
+		if run.CompletedAt != 0 {
+			field("Finished", formatAge(run.CompletedAt)+" ago")
+		}
+		lines = append(lines, "", ansiDim+fit("Logs: cmux task logs "+run.ID+" --follow", width)+ansiReset)
+		help = "o open VS Code  esc back  r refresh  q quit"
+	}
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = lines[:height-1]
+
+	status := help
+	if b.message != "" {
+		status = b.message
+	} else if !b.updated.IsZero() {
+		status = help + "  · updated " + b.updated.Format("15:04:05")
+	}
+	lines = append(lines, ansiDim+fit(status, width)+ansiReset)
+
+	return ansiClear + strings.Join(lines, "\r\n")
+}
+
+var taskBrowseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse tasks interactively",
+	Long: `Browse your tasks in an interactive terminal view.
+
+Tasks are listed with their status, agents, repo, and age, and refresh
+automatically. Press enter to see a task's runs and again for a run's
+details.
+
+Keys:
+  ↑/↓ or j/k   Move
+  enter        Drill into runs / run details
+  esc          Go back
+  o            Open the VS Code workspace (crowned or latest run)
+  p            Pin or unpin the task
+  a            Archive or unarchive the task
+  v            Toggle between active and archived tasks
+  r            Refresh now
+  q            Quit`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("refresh")
+
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("task browse needs an interactive terminal")
+		}
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		browser := &taskBrowser{client: client}
+		browser.refresh(ctx)
+		if browser.updated.IsZero() {
+			return fmt.Errorf("failed to list tasks: %s", browser.message)
+		}
+
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to set raw mode: %w", err)
+		}
+		defer term.Restore(fd, oldState)
+		fmt.Print(ansiAltScreenOn)
+		defer fmt.Print(ansiAltScreenOff)
+
+		keys := make(chan []browseInput)
+		go func() {
+			buf := make([]byte, 64)
+			for {
+				n, err := os.Stdin.Read(buf)
+				if err != nil {
+					close(keys)
+					return
+				}
+				keys <- decodeKeys(buf[:n])
+			}
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			width, height, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || width <= 0 || height <= 0 {
+				width, height = 80, 24
+			}
+			fmt.Print(browser.render(width, height))
+
+			select {
+			case inputs, ok := <-keys:
+				if !ok {
+					return nil
+				}
+				for _, in := range inputs {
+					if browser.handle(ctx, in) {
+						return nil
+					}
+				}
+			case <-ticker.C:
+				browser.refresh(ctx)
+			}
+		}
+	},
+}
+
+func init() {
+	taskBrowseCmd.Flags().Duration("refresh", 5*time.Second, "How often to refresh the task list")
+	taskCmd.AddCommand(taskBrowseCmd)
+}
@@ -0,0 +1,231 @@
+// internal/cli/task_diff.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	githubAPIURL      = "https://api.github.com"
+	diffViewerBaseURL = "https://0github.com"
+)
+
+// githubToken returns a token for private repositories from GITHUB_TOKEN or
+// the GitHub CLI, or "" to make anonymous requests
+func githubToken(ctx context.Context) string {
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	output, err := exec.CommandContext(ctx, "gh", "auth", "token").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func githubGet(ctx context.Context, path, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", githubAPIURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if token := githubToken(ctx); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("GitHub returned 404 (private repo? set GITHUB_TOKEN or run 'gh auth login')")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// defaultBranch looks up a repository's default branch
+func defaultBranch(ctx context.Context, repo string) (string, error) {
+	body, err := githubGet(ctx, "/repos/"+repo, "application/vnd.github+json")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	return result.DefaultBranch, nil
+}
+
+func compareRef(base, head string) string {
+	return url.PathEscape(base) + "..." + url.PathEscape(head)
+}
+
+// colorizeDiff adds git-style colors to a unified diff
+func colorizeDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "index "):
+			lines[i] = ansiBold + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = "\x1b[36m" + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = "\x1b[32m" + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = "\x1b[31m" + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// writePaged shows output through $PAGER (default less) when stdout is a
+// terminal, like git does
+func writePaged(output string) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		_, err := io.WriteString(os.Stdout, output)
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		if _, err := exec.LookPath("less"); err != nil {
+			_, err := io.WriteString(os.Stdout, output)
+			return err
+		}
+		pager = "less -FRX"
+	}
+
+	fields := strings.Fields(pager)
+	pagerCmd := exec.Command(fields[0], fields[1:]...)
+	pagerCmd.Stdin = strings.NewReader(output)
+	pagerCmd.Stdout = os.Stdout
+	pagerCmd.Stderr = os.Stderr
+	return pagerCmd.Run()
+}
+
+// findRun returns the run with the given ID, or the task's preferred run
+func findRun(detail *vm.TaskDetail, runID string) (*vm.TaskRun, error) {
+	if runID == "" {
+		run := preferredRun(detail.Runs)
+		if run == nil {
+			return nil, fmt.Errorf("task %s has no runs", detail.ID)
+		}
+		return run, nil
+	}
+	for i := range detail.Runs {
+		if detail.Runs[i].ID == runID {
+			return &detail.Runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("run %s not found in task %s", runID, detail.ID)
+}
+
+var taskDiffCmd = &cobra.Command{
+	Use:   "diff <task-id>",
+	Short: "Show the code changes an agent produced",
+	Long: `Show the diff between a task run's branch and the task's base branch.
+
+The crowned run is used when there is one, otherwise the latest run; pick
+another with --run. The diff comes from GitHub, so the agent's branch must
+have been pushed. Private repositories need GITHUB_TOKEN or a logged-in
+GitHub CLI (gh).
+
+Examples:
+  cmux task diff <task-id>
+  cmux task diff <task-id> --run <task-run-id>
+  cmux task diff <task-id> --web       # Open the annotated diff viewer`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		runID, _ := cmd.Flags().GetString("run")
+		base, _ := cmd.Flags().GetString("base")
+		web, _ := cmd.Flags().GetBool("web")
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		detail, err := client.GetTask(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+		if detail.Repo == "" {
+			return fmt.Errorf("task %s is not linked to a GitHub repository", detail.ID)
+		}
+
+		run, err := findRun(detail, runID)
+		if err != nil {
+			return err
+		}
+		if run.Branch == "" {
+			return fmt.Errorf("run %s has no branch yet", run.ID)
+		}
+
+		if base == "" {
+			base = detail.BaseBranch
+		}
+		if base == "" {
+			if base, err = defaultBranch(ctx, detail.Repo); err != nil {
+				return fmt.Errorf("failed to find base branch: %w", err)
+			}
+		}
+
+		if web {
+			viewerURL := fmt.Sprintf("%s/%s/compare/%s", diffViewerBaseURL, detail.Repo, compareRef(base, run.Branch))
+			output.Infof("Opening %s\n", viewerURL)
+			return openBrowser(viewerURL)
+		}
+
+		body, err := githubGet(ctx, fmt.Sprintf("/repos/%s/compare/%s", detail.Repo, compareRef(base, run.Branch)), "application/vnd.github.diff")
+		if err != nil {
+			return fmt.Errorf("failed to get diff: %w", err)
+		}
+
+		diff := string(body)
+		if diff == "" {
+			fmt.Printf("No changes between %s and %s\n", base, run.Branch)
+			return nil
+		}
+		if output.Color(os.Stdout) {
+			diff = colorizeDiff(diff)
+		}
+		return writePaged(diff)
+	},
+}
+
+func init() {
+	taskDiffCmd.Flags().String("run", "", "Task run to diff (default: crowned or latest run)")
+	taskDiffCmd.Flags().String("base", "", "Base branch (default: the task's base branch)")
+	taskDiffCmd.Flags().Bool("web", false, "Open the annotated diff viewer in the browser")
+	taskCmd.AddCommand(taskDiffCmd)
+}
@@ -0,0 +1,106 @@
+// internal/cli/task_open.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var taskOpenCmd = &cobra.Command{
+	Use:   "open <task-id>",
+	Short: "Open a task in the browser",
+	Long: `Open a task's dashboard page, or jump straight to a run's VS Code
+workspace, pull request, or VNC desktop.
+
+The crowned run is used when there is one, otherwise the latest run; pick
+another with --run.
+
+Examples:
+  cmux task open <task-id>              # Task dashboard
+  cmux task open <task-id> --vscode     # VS Code workspace
+  cmux task open <task-id> --pr         # Pull request
+  cmux task open <task-id> --vnc --run <task-run-id>
+  cmux task open <task-id> --pr --print # Print the URL instead`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		runID, _ := cmd.Flags().GetString("run")
+		openPR, _ := cmd.Flags().GetBool("pr")
+		openVSCode, _ := cmd.Flags().GetBool("vscode")
+		openVNC, _ := cmd.Flags().GetBool("vnc")
+		printOnly, _ := cmd.Flags().GetBool("print")
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		detail, err := client.GetTask(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get task: %w", err)
+		}
+
+		run, err := findRun(detail, runID)
+		if err != nil && (openPR || openVSCode || openVNC || runID != "") {
+			return err
+		}
+
+		var target string
+		switch {
+		case openPR:
+			if target = run.PullRequestURL; target == "" {
+				return fmt.Errorf("run %s has no pull request yet", run.ID)
+			}
+		case openVSCode:
+			if target = run.VSCodeURL; target == "" {
+				return fmt.Errorf("run %s has no VS Code workspace", run.ID)
+			}
+		case openVNC:
+			if target = run.VNCURL; target == "" {
+				return fmt.Errorf("run %s has no VNC desktop", run.ID)
+			}
+		default:
+			target = taskDashboardURL(teamSlug, detail.ID)
+			if runID != "" {
+				target += "/run/" + url.PathEscape(run.ID)
+			}
+		}
+
+		if printOnly {
+			fmt.Println(target)
+			return nil
+		}
+
+		output.Infof("Opening %s\n", target)
+		return openBrowser(target)
+	},
+}
+
+// taskDashboardURL returns the web app page for a task
+func taskDashboardURL(teamSlug, taskID string) string {
+	return fmt.Sprintf("%s/%s/task/%s", strings.TrimRight(auth.GetConfig().CmuxURL, "/"), url.PathEscape(teamSlug), url.PathEscape(taskID))
+}
+
+func init() {
+	taskOpenCmd.Flags().String("run", "", "Task run to open (default: crowned or latest run)")
+	taskOpenCmd.Flags().Bool("pr", false, "Open the run's pull request")
+	taskOpenCmd.Flags().Bool("vscode", false, "Open the run's VS Code workspace")
+	taskOpenCmd.Flags().Bool("vnc", false, "Open the run's VNC desktop")
+	taskOpenCmd.Flags().Bool("print", false, "Print the URL instead of opening it")
+	taskOpenCmd.MarkFlagsMutuallyExclusive("pr", "vscode", "vnc")
+	taskCmd.AddCommand(taskOpenCmd)
+}
@@ -0,0 +1,235 @@
+// internal/cli/task_prs.go
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// pullRequestStatus is a task run's pull request as GitHub currently sees it
+type pullRequestStatus struct {
+	TaskID string `json:"taskId"`
+	RunID  string `json:"runId"`
+	Agent  string `json:"agent"`
+	URL    string `json:"url"`
+	State  string `json:"state,omitempty"`  // open, draft, merged, closed
+	Checks string `json:"checks,omitempty"` // passing, failing, pending, none
+	Review string `json:"review,omitempty"` // approved, changes_requested, pending
+	Error  string `json:"error,omitempty"`
+}
+
+// parsePullRequestURL splits https://github.com/owner/repo/pull/123 into
+// "owner/repo" and 123
+func parsePullRequestURL(raw string) (string, int, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host != "github.com" {
+		return "", 0, fmt.Errorf("not a GitHub pull request URL: %s", raw)
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return "", 0, fmt.Errorf("not a GitHub pull request URL: %s", raw)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return "", 0, fmt.Errorf("not a GitHub pull request URL: %s", raw)
+	}
+	return parts[0] + "/" + parts[1], number, nil
+}
+
+// fetchPullRequestStatus fills in a pull request's state, combined check
+// results, and review decision from the GitHub API
+func fetchPullRequestStatus(ctx context.Context, status *pullRequestStatus) error {
+	repo, number, err := parsePullRequestURL(status.URL)
+	if err != nil {
+		return err
+	}
+	const accept = "application/vnd.github+json"
+
+	body, err := githubGet(ctx, fmt.Sprintf("/repos/%s/pulls/%d", repo, number), accept)
+	if err != nil {
+		return err
+	}
+	var pr struct {
+		State  string `json:"state"`
+		Draft  bool   `json:"draft"`
+		Merged bool   `json:"merged"`
+		Head   struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	switch {
+	case pr.Merged:
+		status.State = "merged"
+	case pr.State == "open" && pr.Draft:
+		status.State = "draft"
+	default:
+		status.State = pr.State
+	}
+
+	body, err = githubGet(ctx, fmt.Sprintf("/repos/%s/commits/%s/check-runs?per_page=100", repo, pr.Head.SHA), accept)
+	if err != nil {
+		return err
+	}
+	var checks struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := json.Unmarshal(body, &checks); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	status.Checks = "none"
+	if len(checks.CheckRuns) > 0 {
+		status.Checks = "passing"
+	}
+	for _, run := range checks.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			if status.Checks == "passing" {
+				status.Checks = "pending"
+			}
+		case run.Conclusion == "failure" || run.Conclusion == "timed_out" || run.Conclusion == "cancelled" || run.Conclusion == "action_required":
+			status.Checks = "failing"
+		}
+	}
+
+	body, err = githubGet(ctx, fmt.Sprintf("/repos/%s/pulls/%d/reviews?per_page=100", repo, number), accept)
+	if err != nil {
+		return err
+	}
+	var reviews []struct {
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(body, &reviews); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	// A reviewer's latest approval or change request is their decision
+	latest := map[string]string{}
+	for _, review := range reviews {
+		if review.State == "APPROVED" || review.State == "CHANGES_REQUESTED" || review.State == "DISMISSED" {
+			latest[review.User.Login] = review.State
+		}
+	}
+	status.Review = "pending"
+	for _, state := range latest {
+		if state == "CHANGES_REQUESTED" {
+			status.Review = "changes_requested"
+			break
+		}
+		if state == "APPROVED" {
+			status.Review = "approved"
+		}
+	}
+	return nil
+}
+
+var taskPRsCmd = &cobra.Command{
+	Use:     "prs [task-id]...",
+	Aliases: []string{"pr"},
+	Short:   "Show the GitHub status of task runs' pull requests",
+	Long: `Show each task run's pull request with its current GitHub state (open,
+draft, merged, closed), check results, and review decision, so you can see
+which agent branches still need attention.
+
+With no task IDs, runs of your most recent tasks are shown. Private
+repositories need GITHUB_TOKEN or a logged-in GitHub CLI.
+
+Examples:
+  cmux task prs
+  cmux task prs <task-id> <task-id>
+  cmux task prs --limit 50 --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		taskIDs := args
+		if len(taskIDs) == 0 {
+			tasks, err := client.ListTasks(ctx, false, limit)
+			if err != nil {
+				return fmt.Errorf("failed to list tasks: %w", err)
+			}
+			for _, task := range tasks {
+				taskIDs = append(taskIDs, task.ID)
+			}
+		}
+
+		statuses := []pullRequestStatus{}
+		for _, taskID := range taskIDs {
+			detail, err := client.GetTask(ctx, taskID)
+			if err != nil {
+				return fmt.Errorf("failed to get task %s: %w", taskID, err)
+			}
+			for _, run := range detail.Runs {
+				if run.PullRequestURL == "" {
+					continue
+				}
+				status := pullRequestStatus{
+					TaskID: detail.ID,
+					RunID:  run.ID,
+					Agent:  run.AgentName,
+					URL:    run.PullRequestURL,
+				}
+				if err := fetchPullRequestStatus(ctx, &status); err != nil {
+					status.Error = err.Error()
+				}
+				statuses = append(statuses, status)
+			}
+		}
+
+		if output.Structured() {
+			return output.Print(statuses)
+		}
+
+		if len(statuses) == 0 {
+			fmt.Println("No pull requests found.")
+			return nil
+		}
+
+		fmt.Printf("%-32s %-20s %-8s %-8s %-18s %s\n", "TASK", "AGENT", "STATE", "CHECKS", "REVIEW", "PULL REQUEST")
+		fmt.Println("-------------------------------- -------------------- -------- -------- ------------------ " + "------------------------------")
+		for _, status := range statuses {
+			fmt.Printf("%s %s %s %s %s %s\n",
+				fit(status.TaskID, 32),
+				fit(status.Agent, 20),
+				fit(orDash(status.State), 8),
+				fit(orDash(status.Checks), 8),
+				fit(orDash(status.Review), 18),
+				status.URL,
+			)
+		}
+		for _, status := range statuses {
+			if status.Error != "" {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", status.URL, status.Error)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	taskPRsCmd.Flags().Int("limit", 20, "Number of recent tasks to check when no task IDs are given")
+	taskCmd.AddCommand(taskPRsCmd)
+}
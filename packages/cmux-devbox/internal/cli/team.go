@@ -0,0 +1,161 @@
+// internal/cli/team.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Manage your team's members",
+	Long: `List, invite, and remove members of the current team without the web
+dashboard. Inviting and removing members needs the corresponding team
+permission.
+
+Examples:
+  cmux team members list
+  cmux team invite teammate@example.com
+  cmux team invite lead@example.com --role admin
+  cmux team remove teammate@example.com`,
+}
+
+var teamMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "Manage team members",
+}
+
+var teamMembersListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List team members and their roles",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		members, err := client.ListTeamMembers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list team members: %w", err)
+		}
+
+		if output.Structured() {
+			return output.Print(members)
+		}
+
+		fmt.Printf("%-24s %-32s %-7s %s\n", "NAME", "EMAIL", "ROLE", "USER ID")
+		fmt.Println("------------------------ -------------------------------- ------- " + "------------------------------")
+		for _, member := range members {
+			fmt.Printf("%s %s %-7s %s\n",
+				fit(orDash(member.DisplayName), 24),
+				fit(orDash(member.Email), 32),
+				member.Role,
+				member.UserID,
+			)
+		}
+		return nil
+	},
+}
+
+var teamInviteCmd = &cobra.Command{
+	Use:   "invite <email>...",
+	Short: "Invite people to the team by email",
+	Long: `Email an invitation to join the current team. --role admin grants the
+team admin role once the invitation is accepted by an account with that
+email verified. Only team admins can invite admins.
+
+Examples:
+  cmux team invite teammate@example.com
+  cmux team invite a@example.com b@example.com --role admin`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, _ := cmd.Flags().GetString("role")
+		role = strings.ToLower(role)
+		if role != "admin" && role != "member" {
+			return fmt.Errorf("invalid --role %q (use admin or member)", role)
+		}
+		for _, email := range args {
+			if !strings.Contains(email, "@") {
+				return fmt.Errorf("invalid email address %q", email)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		for _, email := range args {
+			if err := client.InviteTeamMember(ctx, email, role); err != nil {
+				return fmt.Errorf("failed to invite %s: %w", email, err)
+			}
+			fmt.Printf("✓ Invited %s as %s\n", email, role)
+		}
+		return nil
+	},
+}
+
+var teamRemoveCmd = &cobra.Command{
+	Use:   "remove <email|user-id>",
+	Short: "Remove a member from the team",
+	Long: `Remove a member from the current team. They lose access to the team's
+VMs, tasks, and secrets immediately.
+
+Examples:
+  cmux team remove teammate@example.com
+  cmux team remove teammate@example.com --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+		member := args[0]
+
+		if !yes {
+			if !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to remove %s without confirmation; pass --yes", member)
+			}
+			if !confirm(fmt.Sprintf("Remove %s from the team?", member)) {
+				fmt.Fprintln(os.Stderr, "Aborted.")
+				return nil
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.RemoveTeamMember(ctx, member); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", member, err)
+		}
+		fmt.Printf("✓ Removed %s from the team\n", member)
+		return nil
+	},
+}
+
+func init() {
+	teamInviteCmd.Flags().String("role", "member", "Role to grant: admin or member")
+	teamRemoveCmd.Flags().BoolP("yes", "y", false, "Remove without asking for confirmation")
+
+	teamMembersCmd.AddCommand(teamMembersListCmd)
+	teamCmd.AddCommand(teamMembersCmd)
+	teamCmd.AddCommand(teamInviteCmd)
+	teamCmd.AddCommand(teamRemoveCmd)
+	rootCmd.AddCommand(teamCmd)
+}
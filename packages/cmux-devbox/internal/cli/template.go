@@ -0,0 +1,256 @@
+// internal/cli/template.go
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// readPromptFile reads a prompt from a file, or from stdin when path is "-"
+func readPromptFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// parseTemplateVars parses repeated --var name=value flags
+func parseTemplateVars(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q (use name=value)", pair)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Manage reusable task prompt templates",
+	Long: `Manage reusable task prompts. A template is a prompt with {{var}}
+placeholders plus default agents, repo, branch, and environment, stored in
+~/.config/cmux/templates.json.
+
+Examples:
+  cmux template save upgrade-dep --prompt "Upgrade {{package}} to {{version}} and fix any breakage" --agent claude/opus-4.1 --repo acme/web
+  cmux template list
+  cmux template render upgrade-dep --var package=react --var version=19`,
+}
+
+var templateSaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Create or update a template",
+	Long: `Create or update a template. Give the prompt with --prompt, or with
+--prompt-file for long prompts ("-" reads stdin). Placeholders are written
+{{name}}.
+
+Examples:
+  cmux template save write-tests --prompt "Write tests for {{path}}" --agent codex/gpt-5
+  cmux template save release --prompt-file release.md --repo acme/web --branch main
+  cat prompt.md | cmux template save triage --prompt-file -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		prompt, _ := cmd.Flags().GetString("prompt")
+		promptFile, _ := cmd.Flags().GetString("prompt-file")
+		agents, _ := cmd.Flags().GetStringArray("agent")
+		repo, _ := cmd.Flags().GetString("repo")
+		branch, _ := cmd.Flags().GetString("branch")
+		environment, _ := cmd.Flags().GetString("env")
+		force, _ := cmd.Flags().GetBool("force")
+
+		if !templateNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid template name %q (use letters, digits, '.', '_', and '-')", name)
+		}
+		if promptFile != "" {
+			text, err := readPromptFile(promptFile)
+			if err != nil {
+				return err
+			}
+			prompt = text
+		}
+		if strings.TrimSpace(prompt) == "" {
+			return fmt.Errorf("a prompt is required (use --prompt or --prompt-file)")
+		}
+
+		if !force {
+			if _, err := state.GetTemplate(name); err == nil {
+				return fmt.Errorf("template %q already exists (use --force to replace it)", name)
+			}
+		}
+
+		template := state.Template{
+			Name:        name,
+			Prompt:      prompt,
+			Agents:      agents,
+			Repo:        repo,
+			Branch:      branch,
+			Environment: environment,
+			UpdatedAt:   time.Now().UnixMilli(),
+		}
+		if err := state.SaveTemplate(template); err != nil {
+			return fmt.Errorf("failed to save template: %w", err)
+		}
+
+		fmt.Printf("✓ Saved template %s\n", name)
+		if vars := template.Vars(); len(vars) > 0 {
+			fmt.Printf("  Variables: %s\n", strings.Join(vars, ", "))
+		}
+		return nil
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List templates",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := state.ListTemplates()
+		if err != nil {
+			return fmt.Errorf("failed to load templates: %w", err)
+		}
+
+		if output.Structured() {
+			return output.Print(templates)
+		}
+
+		if len(templates) == 0 {
+			fmt.Println("No templates found. Run 'cmux template save <name>' to add one.")
+			return nil
+		}
+
+		fmt.Printf("%-20s %-24s %-24s %s\n", "NAME", "VARIABLES", "AGENTS", "PROMPT")
+		fmt.Println("-------------------- ------------------------ ------------------------ " + "------------------------------")
+		for _, template := range templates {
+			fmt.Printf("%-20s %s %s %s\n",
+				template.Name,
+				fit(orDash(strings.Join(template.Vars(), ",")), 24),
+				fit(orDash(strings.Join(template.Agents, ",")), 24),
+				strings.TrimSpace(fit(template.Prompt, 50)),
+			)
+		}
+		return nil
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a template's prompt and defaults",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, err := state.GetTemplate(args[0])
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			return output.Print(template)
+		}
+
+		fmt.Printf("Name:        %s\n", template.Name)
+		fmt.Printf("Variables:   %s\n", orDash(strings.Join(template.Vars(), ", ")))
+		fmt.Printf("Agents:      %s\n", orDash(strings.Join(template.Agents, ", ")))
+		fmt.Printf("Repo:        %s\n", orDash(template.Repo))
+		fmt.Printf("Branch:      %s\n", orDash(template.Branch))
+		fmt.Printf("Environment: %s\n", orDash(template.Environment))
+		fmt.Printf("Updated:     %s\n", time.UnixMilli(template.UpdatedAt).Format("2006-01-02 15:04"))
+		fmt.Printf("\n%s\n", template.Prompt)
+		return nil
+	},
+}
+
+var templateRenderCmd = &cobra.Command{
+	Use:   "render <name>",
+	Short: "Print a template's prompt with variables filled in",
+	Long: `Print a template's prompt with every {{var}} replaced by its --var
+value. With --json, the template's default agents, repo, branch, and
+environment are included.
+
+Examples:
+  cmux template render upgrade-dep --var package=react --var version=19
+  cmux template render write-tests --var path=src/api --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pairs, _ := cmd.Flags().GetStringArray("var")
+		values, err := parseTemplateVars(pairs)
+		if err != nil {
+			return err
+		}
+
+		template, err := state.GetTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		prompt, err := template.Render(values)
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			rendered := *template
+			rendered.Prompt = prompt
+			return output.Print(rendered)
+		}
+
+		fmt.Println(prompt)
+		return nil
+	},
+}
+
+var templateRmCmd = &cobra.Command{
+	Use:     "rm <name>...",
+	Aliases: []string{"delete"},
+	Short:   "Delete templates",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range args {
+			if err := state.DeleteTemplate(name); err != nil {
+				return fmt.Errorf("failed to delete template: %w", err)
+			}
+			fmt.Printf("✓ Template %s deleted\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	templateSaveCmd.Flags().String("prompt", "", "Prompt text with {{var}} placeholders")
+	templateSaveCmd.Flags().String("prompt-file", "", "Read the prompt from a file (\"-\" for stdin)")
+	templateSaveCmd.Flags().StringArray("agent", nil, "Default agent (repeatable)")
+	templateSaveCmd.Flags().String("repo", "", "Default repository (owner/name)")
+	templateSaveCmd.Flags().String("branch", "", "Default base branch")
+	templateSaveCmd.Flags().String("env", "", "Default environment ID")
+	templateSaveCmd.Flags().Bool("force", false, "Replace an existing template")
+	templateSaveCmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file")
+
+	templateRenderCmd.Flags().StringArray("var", nil, "Variable value as name=value (repeatable)")
+
+	templateCmd.AddCommand(templateSaveCmd)
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateRenderCmd)
+	templateCmd.AddCommand(templateRmCmd)
+	rootCmd.AddCommand(templateCmd)
+}
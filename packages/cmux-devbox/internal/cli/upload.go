@@ -0,0 +1,145 @@
+// internal/cli/upload.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// progressBar renders a single-line transfer bar, redrawn at most a few
+// times a second
+type progressBar struct {
+	out      io.Writer
+	label    string
+	rendered time.Time
+}
+
+func newProgressBar(out io.Writer, label string) *progressBar {
+	return &progressBar{out: out, label: label}
+}
+
+func (b *progressBar) update(sent, total int64) {
+	if sent < total && time.Since(b.rendered) < 100*time.Millisecond {
+		return
+	}
+	b.rendered = time.Now()
+
+	const width = 30
+	percent := 100.0
+	if total > 0 {
+		percent = float64(sent) / float64(total) * 100
+	}
+	filled := int(percent / 100 * width)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+	fmt.Fprintf(b.out, "\r  %s [%s] %3.0f%%  %s / %s   ", b.label, bar, percent, vm.FormatBytes(sent), vm.FormatBytes(total))
+}
+
+func (b *progressBar) done() {
+	fmt.Fprintln(b.out)
+}
+
+// uploadFiles uploads each path to team storage, drawing a progress bar
+// labeled with the matching label on stderr unless quiet
+func uploadFiles(ctx context.Context, client *vm.Client, paths, labels []string, quiet bool) ([]*vm.UploadResult, error) {
+	showProgress := !quiet && term.IsTerminal(int(os.Stderr.Fd()))
+
+	results := make([]*vm.UploadResult, 0, len(paths))
+	for i, path := range paths {
+		var onProgress vm.UploadProgress
+		var bar *progressBar
+		if showProgress {
+			bar = newProgressBar(os.Stderr, fit(filepath.Base(labels[i]), 24))
+			onProgress = bar.update
+		}
+
+		result, err := client.UploadFileToStorage(ctx, path, onProgress)
+		if bar != nil {
+			bar.done()
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				err = fmt.Errorf("%w (run the same command again to resume)", err)
+			}
+			return nil, fmt.Errorf("failed to upload %s: %w", labels[i], err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+var uploadCmd = &cobra.Command{
+	Use:   "upload [file]...",
+	Short: "Upload files to team storage",
+	Long: `Upload files, such as images or assets to attach to tasks, to team storage
+and print their storage IDs and URLs.
+
+Large files are sent in 8 MiB chunks, each retried on network errors. If an
+upload is interrupted, run the same command again to resume it.
+
+--from-clipboard uploads the image on the clipboard, such as a screenshot
+you just took. It uses pngpaste or osascript on macOS, wl-paste on Wayland,
+xclip on X11, and PowerShell on Windows.
+
+Examples:
+  cmux upload ./screenshot.png
+  cmux upload ./design.fig ./mockup.png --json
+  cmux upload --from-clipboard`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromClipboard, _ := cmd.Flags().GetBool("from-clipboard")
+		if len(args) == 0 && !fromClipboard {
+			return fmt.Errorf("specify files to upload or use --from-clipboard")
+		}
+
+		paths := append([]string{}, args...)
+		labels := append([]string{}, args...)
+		if fromClipboard {
+			path, err := clipboardImage()
+			if err != nil {
+				return err
+			}
+			defer os.Remove(path)
+			paths = append(paths, path)
+			labels = append(labels, "clipboard.png")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		results, err := uploadFiles(ctx, client, paths, labels, output.Quiet())
+		if err != nil {
+			return err
+		}
+
+		if output.Structured() {
+			return output.Print(results)
+		}
+
+		for i, result := range results {
+			fmt.Printf("✓ Uploaded %s (%s)\n", labels[i], vm.FormatBytes(result.Size))
+			fmt.Printf("  Storage ID: %s\n", result.StorageID)
+			fmt.Printf("  URL:        %s\n", result.URL)
+		}
+		return nil
+	},
+}
+
+func init() {
+	uploadCmd.Flags().Bool("from-clipboard", false, "Also upload the image on the clipboard as a PNG")
+	rootCmd.AddCommand(uploadCmd)
+}
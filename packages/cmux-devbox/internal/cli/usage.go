@@ -0,0 +1,139 @@
+// internal/cli/usage.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// parseSince parses a lookback like "7d", "12h", or "90m"
+func parseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --since %q (use e.g. 7d, 12h)", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since %q (use e.g. 7d, 12h)", s)
+	}
+	return d, nil
+}
+
+// parseDate accepts YYYY-MM-DD (local midnight) or RFC 3339
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", s)
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show team usage and runtime",
+	Long: `Show your team's sandbox usage over a time window: instance hours by
+provider, task run counts, and per-agent runs and runtime.
+
+Instance hours are wall-clock time from creation until the instance was
+paused or stopped.
+
+Examples:
+  cmux usage                             # Last 30 days
+  cmux usage --since 7d
+  cmux usage --from 2026-09-01 --to 2026-10-01
+  cmux usage --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, _ := cmd.Flags().GetString("since")
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+
+		to := time.Now()
+		if toFlag != "" {
+			t, err := parseDate(toFlag)
+			if err != nil {
+				return err
+			}
+			to = t
+		}
+
+		var from time.Time
+		if fromFlag != "" {
+			if cmd.Flags().Changed("since") {
+				return fmt.Errorf("--from and --since can't be used together")
+			}
+			t, err := parseDate(fromFlag)
+			if err != nil {
+				return err
+			}
+			from = t
+		} else {
+			lookback, err := parseSince(since)
+			if err != nil {
+				return err
+			}
+			from = to.Add(-lookback)
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("start of the window must be before the end")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		client, err := newTeamClient()
+		if err != nil {
+			return err
+		}
+
+		usage, err := client.GetUsage(ctx, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get usage: %w", err)
+		}
+
+		if output.Structured() {
+			return output.Print(usage)
+		}
+
+		const dateFormat = "2006-01-02 15:04"
+		fmt.Printf("Usage from %s to %s\n\n", time.UnixMilli(usage.From).Format(dateFormat), time.UnixMilli(usage.To).Format(dateFormat))
+
+		fmt.Printf("Instances: %d (%.1f hours)\n", usage.Instances.Count, usage.Instances.Hours)
+		if len(usage.Instances.ByProvider) > 0 {
+			fmt.Printf("  %-12s %8s %10s\n", "PROVIDER", "COUNT", "HOURS")
+			for _, provider := range usage.Instances.ByProvider {
+				fmt.Printf("  %-12s %8d %10.1f\n", provider.Provider, provider.Count, provider.Hours)
+			}
+		}
+		fmt.Println()
+
+		runs := usage.TaskRuns
+		fmt.Printf("Task runs: %d (%d completed, %d failed, %d running; %.1f hours)\n", runs.Total, runs.Completed, runs.Failed, runs.Running, runs.Hours)
+		if len(usage.Agents) > 0 {
+			fmt.Printf("  %-32s %6s %9s %6s %8s\n", "AGENT", "RUNS", "COMPLETED", "FAILED", "HOURS")
+			for _, agent := range usage.Agents {
+				fmt.Printf("  %-32s %6d %9d %6d %8.1f\n", agent.AgentName, agent.Runs, agent.Completed, agent.Failed, agent.Hours)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	usageCmd.Flags().String("since", "30d", "Report the last N days/hours (e.g. 7d, 12h)")
+	usageCmd.Flags().String("from", "", "Window start (YYYY-MM-DD or RFC 3339)")
+	usageCmd.Flags().String("to", "", "Window end (default: now)")
+	rootCmd.AddCommand(usageCmd)
+}
@@ -0,0 +1,81 @@
+// internal/cli/use.go
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use [id|last]",
+	Short: "Bind a VM to the current project directory",
+	Long: `Bind a VM to the current directory, so commands run anywhere in the
+project use it when no ID is given:
+
+  cmux ssh                # SSH into the bound VM
+  cmux exec "npm test"    # Run a command in it
+  cmux sync               # Sync the bound directory to it
+
+Without a binding these commands fall back to the last used VM. "." names
+the bound VM explicitly, e.g. 'cmux status .'. With no arguments, the
+current binding is shown.
+
+Examples:
+  cmux use cmux_abc123
+  cmux use last
+  cmux use            # Show the binding for this directory
+  cmux use --clear    # Remove it`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unbind, _ := cmd.Flags().GetBool("clear")
+
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current directory: %w", err)
+		}
+
+		if unbind {
+			if len(args) > 0 {
+				return fmt.Errorf("--clear doesn't take an ID")
+			}
+			removed, err := state.UnbindDirectory(dir)
+			if err != nil {
+				return fmt.Errorf("failed to update bindings: %w", err)
+			}
+			if !removed {
+				fmt.Printf("No VM is bound to %s\n", dir)
+				return nil
+			}
+			fmt.Printf("✓ Removed binding for %s\n", dir)
+			return nil
+		}
+
+		if len(args) == 0 {
+			instanceID, boundDir := state.BoundInstance(dir)
+			if instanceID == "" {
+				fmt.Println("No VM is bound to this directory. Run 'cmux use <id>' to bind one.")
+				return nil
+			}
+			fmt.Printf("%s (bound to %s)\n", instanceID, boundDir)
+			return nil
+		}
+
+		instanceID, err := resolveInstanceID(args[0])
+		if err != nil {
+			return err
+		}
+		if err := state.BindDirectory(dir, instanceID); err != nil {
+			return fmt.Errorf("failed to save binding: %w", err)
+		}
+		fmt.Printf("✓ Bound %s to %s\n", instanceID, dir)
+		return nil
+	},
+}
+
+func init() {
+	useCmd.Flags().Bool("clear", false, "Remove the binding for the current directory")
+	rootCmd.AddCommand(useCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 
+	"github.com/cmux-cli/cmux-devbox/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -47,11 +48,23 @@ func GetVersion() string {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if output.Structured() {
+			return output.Print(map[string]string{
+				"version":   version,
+				"commit":    commit,
+				"buildTime": buildTime,
+				"go":        runtime.Version(),
+				"os":        runtime.GOOS,
+				"arch":      runtime.GOARCH,
+			})
+		}
+
 		fmt.Printf("cmux devbox version %s\n", version)
 		fmt.Printf("  commit:  %s\n", commit)
 		fmt.Printf("  built:   %s\n", buildTime)
 		fmt.Printf("  go:      %s\n", runtime.Version())
 		fmt.Printf("  os/arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+		return nil
 	},
 }
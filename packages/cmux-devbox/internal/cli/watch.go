@@ -0,0 +1,216 @@
+// internal/cli/watch.go
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+	"github.com/cmux-cli/cmux-devbox/internal/output"
+	"github.com/cmux-cli/cmux-devbox/internal/state"
+	"github.com/cmux-cli/cmux-devbox/internal/vm"
+	"github.com/manaflow-ai/fswatch"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [id] [path]",
+	Short: "Continuously sync a directory to a VM",
+	Long: `Watch a local directory and sync changes to a VM as you edit.
+
+Changes are batched: a sync starts once no file has changed for the
+debounce interval. With --pull, files changed inside the VM are also pulled
+back periodically; in that mode neither side deletes files and newer files
+are never overwritten by older ones. Press Ctrl-C to stop.
+
+Without an ID, the VM bound to the current directory ('cmux use') is
+watched into, or else the last used VM.
+
+Examples:
+  cmux watch cmux_abc123               # Watch current directory
+  cmux watch last ./my-project
+  cmux watch cmux_abc123 . --pull      # Bidirectional sync
+  cmux watch .                         # Bound or last VM, current directory`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		instanceID, rest, err := splitInstanceArg(args)
+		if err != nil {
+			return err
+		}
+
+		localPath := "."
+		if _, boundDir := state.BoundInstance(currentDir()); boundDir != "" {
+			localPath = boundDir
+		}
+		switch len(rest) {
+		case 0:
+		case 1:
+			localPath = rest[0]
+		default:
+			return fmt.Errorf("too many arguments; use 'cmux watch [id] [path]'")
+		}
+		absPath, err := filepath.Abs(localPath)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("path not found: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("path must be a directory")
+		}
+
+		debounce, _ := cmd.Flags().GetDuration("debounce")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		pull, _ := cmd.Flags().GetBool("pull")
+		pullInterval, _ := cmd.Flags().GetDuration("pull-interval")
+
+		teamSlug, err := auth.GetTeamSlug()
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		client, err := vm.NewClient()
+		if err != nil {
+			return fmt.Errorf("failed to create client: %w", err)
+		}
+		client.SetTeamSlug(teamSlug)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go keepInstanceAlive(ctx, client, instanceID)
+
+		setupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		syncer, err := client.NewSyncer(setupCtx, instanceID)
+		cancel()
+		if err != nil {
+			return err
+		}
+		if !flagVerbose || output.Quiet() {
+			syncer.Output = nil
+		}
+		if syncer.Filters, err = syncFiltersFromFlags(cmd, absPath); err != nil {
+			return err
+		}
+		if pull {
+			syncer.Delete = false
+			syncer.Update = true
+		}
+
+		// Watch before the initial sync, so edits made during it are synced
+		watcher, err := fswatch.New(absPath, fswatch.Options{
+			Skip:         func(name string, isDir bool) bool { return isDir && syncer.Filters.SkipsDir(name) },
+			PollInterval: interval,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", absPath, err)
+		}
+		defer watcher.Close()
+
+		output.Infof("Syncing %s to %s:%s...\n", absPath, instanceID, syncer.RemotePath())
+		if err := syncer.Push(ctx, absPath); err != nil {
+			return fmt.Errorf("initial sync failed: %w", err)
+		}
+		state.SetSyncPath(instanceID, absPath)
+		fmt.Println("✓ Initial sync complete, watching for changes (Ctrl-C to stop)")
+
+		return watchAndSync(ctx, syncer, watcher, absPath, interval, debounce, pull, pullInterval)
+	},
+}
+
+// watchAndSync pushes root whenever the watcher reports changes, and with
+// pull also pulls changes made inside the VM
+func watchAndSync(ctx context.Context, syncer *vm.Syncer, watcher *fswatch.Watcher, root string, interval, debounce time.Duration, pull bool, pullInterval time.Duration) error {
+	var pullTick <-chan time.Time
+	if pull {
+		ticker := time.NewTicker(pullInterval)
+		defer ticker.Stop()
+		pullTick = ticker.C
+	}
+
+	// A sync starts once no change has arrived for the debounce interval
+	pending := make(map[string]bool)
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			output.Infoln("\nStopped watching")
+			return nil
+
+		case err := <-watcher.Errors():
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+
+		case rel := <-watcher.Changes():
+			pending[rel] = true
+			settled = time.After(debounce)
+
+		case <-settled:
+			settled = nil
+			start := time.Now()
+			if err := syncer.Push(ctx, root); err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				// Keep the changes pending and retry after another debounce
+				fmt.Fprintf(os.Stderr, "Warning: sync failed: %v\n", err)
+				settled = time.After(debounce)
+				continue
+			}
+			fmt.Printf("[%s] ↑ synced %d change(s) in %s\n", time.Now().Format("15:04:05"), len(pending), time.Since(start).Round(time.Millisecond))
+			clear(pending)
+
+		case <-pullTick:
+			if len(pending) > 0 {
+				continue
+			}
+			if err := syncer.Pull(ctx, root); err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(os.Stderr, "Warning: pull failed: %v\n", err)
+				}
+				continue
+			}
+			// Absorb the pulled files' changes so they are not pushed
+			// straight back
+			quiet := debounce
+			if watcher.Polling() {
+				quiet += interval
+			}
+			if n := absorbChanges(ctx, watcher, quiet); n > 0 {
+				fmt.Printf("[%s] ↓ pulled %d change(s)\n", time.Now().Format("15:04:05"), n)
+			}
+		}
+	}
+}
+
+// absorbChanges drains the watcher until no change has arrived for quiet,
+// and returns how many distinct paths changed
+func absorbChanges(ctx context.Context, watcher *fswatch.Watcher, quiet time.Duration) int {
+	changed := make(map[string]bool)
+	timer := time.NewTimer(quiet)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return len(changed)
+		case <-timer.C:
+			return len(changed)
+		case rel := <-watcher.Changes():
+			changed[rel] = true
+			timer.Reset(quiet)
+		}
+	}
+}
+
+func init() {
+	watchCmd.Flags().Duration("debounce", 500*time.Millisecond, "Quiet period before syncing a batch of changes")
+	watchCmd.Flags().Duration("interval", fswatch.DefaultPollInterval, "How often to scan for local changes when file events are unavailable")
+	watchCmd.Flags().Bool("pull", false, "Also pull changes made inside the VM")
+	watchCmd.Flags().Duration("pull-interval", 10*time.Second, "How often to pull changes when --pull is set")
+	addSyncFilterFlags(watchCmd)
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+var (
+	quiet   bool
+	noColor bool
+)
+
+// SetQuiet suppresses progress and informational messages, so commands
+// print only their results
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// Quiet reports whether progress and informational messages are suppressed
+func Quiet() bool {
+	return quiet
+}
+
+// SetNoColor disables ANSI colors regardless of the terminal
+func SetNoColor(disabled bool) {
+	noColor = disabled
+}
+
+// Color reports whether ANSI colors may be written to f. Colors are off with
+// --no-color, when NO_COLOR is set (https://no-color.org), for TERM=dumb,
+// and when f isn't a terminal.
+func Color(f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Infof prints a progress or informational message to stdout unless
+// --quiet is set
+func Infof(format string, a ...interface{}) {
+	if !quiet {
+		fmt.Printf(format, a...)
+	}
+}
+
+// Infoln is Infof with fmt.Println formatting
+func Infoln(a ...interface{}) {
+	if !quiet {
+		fmt.Println(a...)
+	}
+}
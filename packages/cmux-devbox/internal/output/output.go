@@ -0,0 +1,201 @@
+// Package output renders command results for the cmux devbox CLI.
+// Commands print human-readable tables themselves and hand structured
+// results to Print, which renders them as JSON or YAML when requested, so
+// every command uses the same field names and exit codes.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Format is how command results are rendered
+type Format string
+
+const (
+	FormatTable Format = "table" // human-readable output (default)
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// Exit codes. Scripts can rely on these staying stable.
+const (
+	ExitOK          = 0 // the command succeeded
+	ExitError       = 1 // the command failed
+	ExitUsage       = 2 // invalid flags or arguments
+	ExitAuth        = 3 // not logged in or session expired; run 'cmux auth login'
+	ExitNotFound    = 4 // the sandbox or resource doesn't exist
+	ExitPermission  = 5 // the team doesn't allow this
+	ExitQuota       = 6 // a quota or rate limit was hit
+	ExitUnavailable = 7 // the provider or API is unavailable or unreachable
+	ExitTimeout     = 8 // the operation timed out
+)
+
+// Code identifies the kind of failure in structured error output. Like exit
+// codes, these stay stable so scripts can branch on them.
+type Code string
+
+const (
+	CodeError               Code = "ERROR"
+	CodeUsage               Code = "USAGE"
+	CodeAuthRequired        Code = "AUTH_REQUIRED"
+	CodeAuthExpired         Code = "AUTH_EXPIRED"
+	CodeSandboxNotFound     Code = "SANDBOX_NOT_FOUND"
+	CodeNotFound            Code = "NOT_FOUND"
+	CodePermissionDenied    Code = "PERMISSION_DENIED"
+	CodeQuotaExceeded       Code = "QUOTA_EXCEEDED"
+	CodeRateLimited         Code = "RATE_LIMITED"
+	CodeProviderUnavailable Code = "PROVIDER_UNAVAILABLE"
+	CodeNetworkError        Code = "NETWORK_ERROR"
+	CodeTimeout             Code = "TIMEOUT"
+)
+
+// codeExits maps each error code to the exit code the CLI ends with
+var codeExits = map[Code]int{
+	CodeError:               ExitError,
+	CodeUsage:               ExitUsage,
+	CodeAuthRequired:        ExitAuth,
+	CodeAuthExpired:         ExitAuth,
+	CodeSandboxNotFound:     ExitNotFound,
+	CodeNotFound:            ExitNotFound,
+	CodePermissionDenied:    ExitPermission,
+	CodeQuotaExceeded:       ExitQuota,
+	CodeRateLimited:         ExitQuota,
+	CodeProviderUnavailable: ExitUnavailable,
+	CodeNetworkError:        ExitUnavailable,
+	CodeTimeout:             ExitTimeout,
+}
+
+var format = FormatTable
+
+// SetFormat selects the output format by name
+func SetFormat(name string) error {
+	switch Format(name) {
+	case FormatTable, FormatJSON, FormatYAML:
+		format = Format(name)
+		return nil
+	}
+	return fmt.Errorf("invalid output format %q (use table, json, or yaml)", name)
+}
+
+// Structured reports whether results should be rendered as JSON or YAML
+// instead of tables
+func Structured() bool {
+	return format != FormatTable
+}
+
+// Print writes v to stdout in the selected structured format. Field names
+// come from v's json tags in both formats.
+func Print(v interface{}) error {
+	return Fprint(os.Stdout, v)
+}
+
+// Fprint writes v to w in the selected structured format
+func Fprint(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if format == FormatYAML {
+		data, err = jsonToYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// codedError attaches an error code and an optional hint to an error
+type codedError struct {
+	err  error
+	code Code
+	hint string
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+func (e *codedError) Unwrap() error { return e.err }
+
+// WithCode tags err with an error code and a hint on how to fix it. The CLI
+// exits with the exit code that belongs to code.
+func WithCode(err error, code Code, hint string) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{err: err, code: code, hint: hint}
+}
+
+// WithExitCode makes the CLI exit with code when err is returned
+func WithExitCode(err error, code int) error {
+	switch code {
+	case ExitUsage:
+		return WithCode(err, CodeUsage, "")
+	case ExitAuth:
+		return WithCode(err, CodeAuthRequired, "")
+	}
+	return WithCode(err, CodeError, "")
+}
+
+// ErrorCode returns the error code for an error returned by a command, or
+// CodeError when it has none
+func ErrorCode(err error) Code {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return CodeError
+}
+
+// HasCode reports whether err was already tagged with an error code
+func HasCode(err error) bool {
+	var coded *codedError
+	return errors.As(err, &coded)
+}
+
+// ExitCode returns the exit code for an error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := codeExits[ErrorCode(err)]; ok {
+		return code
+	}
+	return ExitError
+}
+
+// errorHint returns the hint attached to err, if any
+func errorHint(err error) string {
+	var coded *codedError
+	if errors.As(err, &coded) {
+		return coded.hint
+	}
+	return ""
+}
+
+// PrintError reports a command's error on stderr, as
+// {"error": {"code": ..., "message": ..., "hint": ...}} when structured
+// output is selected
+func PrintError(err error) {
+	hint := errorHint(err)
+	if !Structured() {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+		return
+	}
+	type errorOutput struct {
+		Code     Code   `json:"code"`
+		Message  string `json:"message"`
+		Hint     string `json:"hint,omitempty"`
+		ExitCode int    `json:"exitCode"`
+	}
+	Fprint(os.Stderr, map[string]errorOutput{
+		"error": {Code: ErrorCode(err), Message: err.Error(), Hint: hint, ExitCode: ExitCode(err)},
+	})
+}
@@ -0,0 +1,181 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// yamlField is one key of a JSON object, kept in document order so YAML
+// fields appear in the same order as JSON ones
+type yamlField struct {
+	key   string
+	value interface{}
+}
+
+type yamlObject []yamlField
+
+// plainScalar matches strings that YAML reads back unchanged without quotes
+var plainScalar = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./@+-]*( [A-Za-z0-9_./@+()-]+)*$`)
+
+// jsonToYAML converts a JSON document to block-style YAML
+func jsonToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	switch v := value.(type) {
+	case yamlObject:
+		if len(v) == 0 {
+			b.WriteString("{}\n")
+		} else {
+			writeYAMLObject(&b, v, 0, false)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("[]\n")
+		} else {
+			writeYAMLList(&b, v, 0)
+		}
+	default:
+		b.WriteString(yamlScalar(v) + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		object := yamlObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yamlField{key: key.(string), value: value})
+		}
+		_, err := dec.Token()
+		return object, err
+	case '[':
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// writeYAMLObject writes an object's fields at indent. In a list, the first
+// field follows the "- " marker, so inList skips its indentation.
+func writeYAMLObject(b *bytes.Buffer, object yamlObject, indent int, inList bool) {
+	pad := strings.Repeat(" ", indent)
+	for i, field := range object {
+		if i > 0 || !inList {
+			b.WriteString(pad)
+		}
+		b.WriteString(yamlScalar(field.key) + ":")
+		writeYAMLValue(b, field.value, indent)
+	}
+}
+
+func writeYAMLList(b *bytes.Buffer, list []interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range list {
+		b.WriteString(pad + "-")
+		switch v := item.(type) {
+		case yamlObject:
+			if len(v) == 0 {
+				b.WriteString(" {}\n")
+				continue
+			}
+			b.WriteString(" ")
+			writeYAMLObject(b, v, indent+2, true)
+		case []interface{}:
+			if len(v) == 0 {
+				b.WriteString(" []\n")
+				continue
+			}
+			b.WriteString("\n")
+			writeYAMLList(b, v, indent+2)
+		default:
+			b.WriteString(" " + yamlScalar(v) + "\n")
+		}
+	}
+}
+
+// writeYAMLValue writes the value of a "key:" that has been written at indent
+func writeYAMLValue(b *bytes.Buffer, value interface{}, indent int) {
+	switch v := value.(type) {
+	case yamlObject:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLObject(b, v, indent+2, false)
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLList(b, v, indent+2)
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+			return quoteYAML(v)
+		}
+		if plainScalar.MatchString(v) {
+			return v
+		}
+		return quoteYAML(v)
+	}
+	return quoteYAML(fmt.Sprint(value))
+}
+
+// quoteYAML double-quotes s; JSON string escapes are valid in YAML
+func quoteYAML(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
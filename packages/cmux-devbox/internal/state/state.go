@@ -1,5 +1,6 @@
 // Package state manages minimal local state for the cmux devbox CLI.
-// Just tracks the last used instance for convenience.
+// It tracks the last used instance and per-directory instance bindings for
+// convenience.
 package state
 
 import (
@@ -14,6 +15,17 @@ import (
 type State struct {
 	LastInstanceID string `json:"lastInstanceId,omitempty"`
 	LastTeamSlug   string `json:"lastTeamSlug,omitempty"`
+
+	// AutoPaused maps instances paused by 'cmux autopause' to when they were
+	// paused (Unix ms), so the next command that uses them resumes them
+	AutoPaused map[string]int64 `json:"autoPaused,omitempty"`
+
+	// SyncPaths maps instances to the local directory last pushed to them
+	SyncPaths map[string]string `json:"syncPaths,omitempty"`
+
+	// Bindings maps project directories to the instance 'cmux use' bound
+	// them to
+	Bindings map[string]string `json:"bindings,omitempty"`
 }
 
 // statePath returns the path to the state file
@@ -24,9 +36,9 @@ func statePath() (string, error) {
 	}
 
 	cfg := auth.GetConfig()
-	filename := "cmux_devbox_state_prod.json"
+	filename := "cmux_devbox_state_prod" + auth.ProfileSuffix() + ".json"
 	if cfg.IsDev {
-		filename = "cmux_devbox_state_dev.json"
+		filename = "cmux_devbox_state_dev" + auth.ProfileSuffix() + ".json"
 	}
 
 	return filepath.Join(home, ".config", "cmux", filename), nil
@@ -94,6 +106,111 @@ func GetLastInstance() (string, string, error) {
 	return s.LastInstanceID, s.LastTeamSlug, nil
 }
 
+// SetAutoPaused records that an instance was paused for being idle
+func SetAutoPaused(instanceID string, pausedAt int64) error {
+	s, _ := Load()
+	if s == nil {
+		s = &State{}
+	}
+	if s.AutoPaused == nil {
+		s.AutoPaused = map[string]int64{}
+	}
+	s.AutoPaused[instanceID] = pausedAt
+	return Save(s)
+}
+
+// IsAutoPaused reports whether an instance was paused for being idle and
+// hasn't been resumed since
+func IsAutoPaused(instanceID string) bool {
+	s, err := Load()
+	if err != nil {
+		return false
+	}
+	_, ok := s.AutoPaused[instanceID]
+	return ok
+}
+
+// ClearAutoPaused forgets that an instance was paused for being idle
+func ClearAutoPaused(instanceID string) error {
+	s, err := Load()
+	if err != nil {
+		return err
+	}
+	if _, ok := s.AutoPaused[instanceID]; !ok {
+		return nil
+	}
+	delete(s.AutoPaused, instanceID)
+	return Save(s)
+}
+
+// SetSyncPath records the local directory last pushed to an instance
+func SetSyncPath(instanceID, localPath string) error {
+	s, _ := Load()
+	if s == nil {
+		s = &State{}
+	}
+	if s.SyncPaths == nil {
+		s.SyncPaths = map[string]string{}
+	}
+	s.SyncPaths[instanceID] = localPath
+	return Save(s)
+}
+
+// GetSyncPath returns the local directory last pushed to an instance, or ""
+func GetSyncPath(instanceID string) string {
+	s, err := Load()
+	if err != nil {
+		return ""
+	}
+	return s.SyncPaths[instanceID]
+}
+
+// BindDirectory makes instanceID the default instance for dir and its
+// subdirectories
+func BindDirectory(dir, instanceID string) error {
+	s, _ := Load()
+	if s == nil {
+		s = &State{}
+	}
+	if s.Bindings == nil {
+		s.Bindings = map[string]string{}
+	}
+	s.Bindings[dir] = instanceID
+	return Save(s)
+}
+
+// UnbindDirectory removes dir's binding and reports whether it had one
+func UnbindDirectory(dir string) (bool, error) {
+	s, err := Load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := s.Bindings[dir]; !ok {
+		return false, nil
+	}
+	delete(s.Bindings, dir)
+	return true, Save(s)
+}
+
+// BoundInstance returns the instance bound to dir or its nearest bound
+// parent, along with the bound directory, or "" if there is none
+func BoundInstance(dir string) (string, string) {
+	s, err := Load()
+	if err != nil || len(s.Bindings) == 0 {
+		return "", ""
+	}
+	for {
+		if instanceID, ok := s.Bindings[dir]; ok {
+			return instanceID, dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
 // Clear removes the state file
 func Clear() error {
 	path, err := statePath()
@@ -0,0 +1,149 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Template is a reusable task prompt with {{var}} placeholders and default
+// task settings
+type Template struct {
+	Name        string   `json:"name"`
+	Prompt      string   `json:"prompt"`
+	Agents      []string `json:"agents,omitempty"`
+	Repo        string   `json:"repo,omitempty"`
+	Branch      string   `json:"branch,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	UpdatedAt   int64    `json:"updatedAt"`
+}
+
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// Vars returns the placeholder names in the prompt, in order of first use
+func (t Template) Vars() []string {
+	var vars []string
+	seen := map[string]bool{}
+	for _, match := range templateVarPattern.FindAllStringSubmatch(t.Prompt, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			vars = append(vars, match[1])
+		}
+	}
+	return vars
+}
+
+// Render substitutes values into the prompt. Every placeholder needs a value.
+func (t Template) Render(values map[string]string) (string, error) {
+	var missing []string
+	for _, name := range t.Vars() {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for %s (pass --var name=value)", strings.Join(missing, ", "))
+	}
+	return templateVarPattern.ReplaceAllStringFunc(t.Prompt, func(match string) string {
+		return values[templateVarPattern.FindStringSubmatch(match)[1]]
+	}), nil
+}
+
+// templatesPath returns the path to the templates file. Templates are
+// personal, so they are shared by all profiles.
+func templatesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "cmux", "templates.json"), nil
+}
+
+func loadTemplates() (map[string]Template, error) {
+	path, err := templatesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := map[string]Template{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return templates, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	return templates, nil
+}
+
+func saveTemplates(templates map[string]Template) error {
+	path, err := templatesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// ListTemplates returns all templates sorted by name
+func ListTemplates() ([]Template, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Template, 0, len(templates))
+	for _, t := range templates {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// GetTemplate looks up a template by name
+func GetTemplate(name string) (*Template, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	t, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	return &t, nil
+}
+
+// SaveTemplate creates or replaces a template
+func SaveTemplate(t Template) error {
+	templates, err := loadTemplates()
+	if err != nil {
+		return err
+	}
+	templates[t.Name] = t
+	return saveTemplates(templates)
+}
+
+// DeleteTemplate removes a template
+func DeleteTemplate(name string) error {
+	templates, err := loadTemplates()
+	if err != nil {
+		return err
+	}
+	if _, ok := templates[name]; !ok {
+		return fmt.Errorf("template %q not found", name)
+	}
+	delete(templates, name)
+	return saveTemplates(templates)
+}
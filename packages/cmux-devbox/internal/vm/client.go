@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -29,6 +30,22 @@ func readErrorBody(body io.Reader) string {
 	return string(data)
 }
 
+// APIError is an error response from the API. Callers can inspect the
+// status code to tell missing instances, auth failures, and outages apart.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (%d): %s", e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError from an unsuccessful response
+func newAPIError(resp *http.Response) error {
+	return &APIError{StatusCode: resp.StatusCode, Body: readErrorBody(resp.Body)}
+}
+
 // Instance represents a VM instance
 type Instance struct {
 	ID              string `json:"id"`              // Our cmux ID (Convex doc ID)
@@ -38,6 +55,12 @@ type Instance struct {
 	VNCURL          string `json:"vncUrl"`
 	WorkerURL       string `json:"workerUrl"`
 	ChromeURL       string `json:"chromeUrl"` // Chrome DevTools proxy URL
+	Name            string `json:"name,omitempty"`
+	Provider        string `json:"provider,omitempty"`     // morph, e2b, modal, ...
+	TTLExpiresAt    int64  `json:"ttlExpiresAt,omitempty"` // Unix ms; 0 if no TTL
+	TTLAction       string `json:"ttlAction,omitempty"`    // What happens at expiry (pause/stop)
+	CreatedAt       int64  `json:"createdAt,omitempty"`
+	UpdatedAt       int64  `json:"updatedAt,omitempty"`
 }
 
 // Client is a simple VM management client
@@ -51,7 +74,9 @@ type Client struct {
 func NewClient() (*Client, error) {
 	cfg := auth.GetConfig()
 	return &Client{
-		httpClient: &http.Client{Timeout: 180 * time.Second}, // 3 minutes for slow Morph operations
+		// No client-wide timeout: requests are bounded by the caller's context
+		// and the retry policy's attempt timeout, and streams by their context
+		httpClient: &http.Client{},
 		baseURL:    cfg.ConvexSiteURL,
 	}, nil
 }
@@ -61,33 +86,38 @@ func (c *Client) SetTeamSlug(teamSlug string) {
 	c.teamSlug = teamSlug
 }
 
-// doRequest makes an authenticated request to the API
+// doRequest makes an authenticated request to the API, retrying transient
+// failures (see doWithRetry)
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
 	accessToken, err := auth.GetAccessToken()
 	if err != nil {
 		return nil, fmt.Errorf("not authenticated: %w", err)
 	}
 
-	var bodyReader io.Reader
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		data, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		bodyReader = bytes.NewReader(data)
 	}
 
 	url := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	return c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		var bodyReader io.Reader
+		if data != nil {
+			bodyReader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+		if err != nil {
+			return nil, err
+		}
 
-	return c.httpClient.Do(req)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
 }
 
 // CreateOptions for creating a VM
@@ -95,6 +125,7 @@ type CreateOptions struct {
 	SnapshotID string
 	Name       string
 	TTLSeconds int
+	Secrets    []string // Team secret names to inject as environment variables
 }
 
 // CreateInstance creates a new VM instance
@@ -115,6 +146,9 @@ func (c *Client) CreateInstance(ctx context.Context, opts CreateOptions) (*Insta
 	if opts.TTLSeconds > 0 {
 		body["ttlSeconds"] = opts.TTLSeconds
 	}
+	if len(opts.Secrets) > 0 {
+		body["secrets"] = opts.Secrets
+	}
 
 	resp, err := c.doRequest(ctx, "POST", "/api/v1/cmux/instances", body)
 	if err != nil {
@@ -123,7 +157,7 @@ func (c *Client) CreateInstance(ctx context.Context, opts CreateOptions) (*Insta
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return nil, newAPIError(resp)
 	}
 
 	var result Instance
@@ -148,7 +182,7 @@ func (c *Client) GetInstance(ctx context.Context, instanceID string) (*Instance,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return nil, newAPIError(resp)
 	}
 
 	var result Instance
@@ -169,14 +203,15 @@ func (c *Client) StopInstance(ctx context.Context, instanceID string) error {
 		"teamSlugOrId": c.teamSlug,
 	}
 
-	resp, err := c.doRequest(ctx, "POST", fmt.Sprintf("/api/v1/cmux/instances/%s/stop", instanceID), body)
+	// Stopping an instance that is already stopped succeeds, so retries are safe
+	resp, err := c.doRequest(Idempotent(ctx), "POST", fmt.Sprintf("/api/v1/cmux/instances/%s/stop", instanceID), body)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return newAPIError(resp)
 	}
 
 	return nil
@@ -199,7 +234,7 @@ func (c *Client) PauseInstance(ctx context.Context, instanceID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return newAPIError(resp)
 	}
 
 	return nil
@@ -222,19 +257,47 @@ func (c *Client) ResumeInstance(ctx context.Context, instanceID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return newAPIError(resp)
 	}
 
 	return nil
 }
 
-// ListInstances lists all instances for the team
-func (c *Client) ListInstances(ctx context.Context) ([]Instance, error) {
+// SetTTL resets an instance's TTL so its TTL action runs ttl from now
+func (c *Client) SetTTL(ctx context.Context, instanceID string, ttl time.Duration) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	body := map[string]interface{}{
+		"teamSlugOrId": c.teamSlug,
+		"ttlSeconds":   int(ttl.Seconds()),
+	}
+
+	resp, err := c.doRequest(Idempotent(ctx), "POST", fmt.Sprintf("/api/v1/cmux/instances/%s/ttl", instanceID), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
+
+// ListInstances lists the team's instances. Stopped instances are only
+// included when includeStopped is set.
+func (c *Client) ListInstances(ctx context.Context, includeStopped bool) ([]Instance, error) {
 	if c.teamSlug == "" {
 		return nil, fmt.Errorf("team slug not set")
 	}
 
 	path := fmt.Sprintf("/api/v1/cmux/instances?teamSlugOrId=%s", c.teamSlug)
+	if includeStopped {
+		path += "&includeStopped=true"
+	}
 	resp, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -242,7 +305,7 @@ func (c *Client) ListInstances(ctx context.Context) ([]Instance, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return nil, newAPIError(resp)
 	}
 
 	var result struct {
@@ -257,28 +320,30 @@ func (c *Client) ListInstances(ctx context.Context) ([]Instance, error) {
 
 // WaitForReady waits for an instance to be ready
 func (c *Client) WaitForReady(ctx context.Context, instanceID string, timeout time.Duration) (*Instance, error) {
-	deadline := time.Now().Add(timeout)
-
-	for time.Now().Before(deadline) {
-		instance, err := c.GetInstance(ctx, instanceID)
-		if err != nil {
-			// Keep trying on transient errors
-			time.Sleep(2 * time.Second)
-			continue
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		instance, err := c.GetInstance(waitCtx, instanceID)
+		// Keep trying on transient errors
+		if err == nil {
+			if instance.Status == "running" {
+				return instance, nil
+			}
+			if instance.Status == "stopped" || instance.Status == "error" {
+				return nil, fmt.Errorf("instance failed with status: %s", instance.Status)
+			}
 		}
 
-		if instance.Status == "running" {
-			return instance, nil
+		select {
+		case <-time.After(2 * time.Second):
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("timeout waiting for instance to be ready")
 		}
-
-		if instance.Status == "stopped" || instance.Status == "error" {
-			return nil, fmt.Errorf("instance failed with status: %s", instance.Status)
-		}
-
-		time.Sleep(2 * time.Second)
 	}
-
-	return nil, fmt.Errorf("timeout waiting for instance to be ready")
 }
 
 // ExecCommand executes a command in the VM
@@ -300,7 +365,7 @@ func (c *Client) ExecCommand(ctx context.Context, instanceID string, command str
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", -1, fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return "", "", -1, newAPIError(resp)
 	}
 
 	var result struct {
@@ -315,6 +380,109 @@ func (c *Client) ExecCommand(ctx context.Context, instanceID string, command str
 	return result.Stdout, result.Stderr, result.ExitCode, nil
 }
 
+// ExecEvent is one event from a streamed command: a line of "stdout" or
+// "stderr" output, the final "exit" code, or an "error"
+type ExecEvent struct {
+	Type    string `json:"type"`
+	Data    string `json:"data,omitempty"`
+	Code    *int   `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ExecStream runs a command in the VM through the worker's execd proxy and
+// calls onEvent for each event as it arrives. Canceling ctx stops the
+// command. It returns the command's exit code.
+func (c *Client) ExecStream(ctx context.Context, instanceID string, command string, timeout time.Duration, onEvent func(ExecEvent)) (int, error) {
+	if c.teamSlug == "" {
+		return -1, fmt.Errorf("team slug not set")
+	}
+
+	instance, err := c.GetInstance(ctx, instanceID)
+	if err != nil {
+		return -1, fmt.Errorf("failed to get instance: %w", err)
+	}
+	if instance.WorkerURL == "" {
+		return -1, fmt.Errorf("worker URL not available")
+	}
+
+	accessToken, err := auth.GetAccessToken()
+	if err != nil {
+		return -1, fmt.Errorf("not authenticated: %w", err)
+	}
+
+	body := map[string]interface{}{
+		"command": command,
+	}
+	if timeout > 0 {
+		body["timeout_ms"] = timeout.Milliseconds()
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return -1, err
+	}
+
+	execURL := strings.TrimRight(instance.WorkerURL, "/") + "/_cmux/exec"
+	req, err := http.NewRequestWithContext(ctx, "POST", execURL+"/exec", bytes.NewReader(data))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Not retried or given an attempt timeout: the stream lasts as long as
+	// the command does, and canceling ctx closes it
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return -1, fmt.Errorf("failed to call worker: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("worker error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	jobID := resp.Header.Get("X-Execd-Job-Id")
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event ExecEvent
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				// Closing the stream alone leaves the command running for
+				// execd's reconnect grace period
+				if jobID != "" {
+					c.cancelExecJob(execURL, accessToken, jobID)
+				}
+				return -1, ctx.Err()
+			}
+			if err == io.EOF {
+				return -1, fmt.Errorf("output stream ended without an exit code")
+			}
+			return -1, fmt.Errorf("failed to read output stream: %w", err)
+		}
+		onEvent(event)
+		if event.Type == "exit" && event.Code != nil {
+			return *event.Code, nil
+		}
+	}
+}
+
+// cancelExecJob kills an execd job and its process tree. Errors are
+// ignored: the command is killed anyway once the reconnect grace period
+// passes.
+func (c *Client) cancelExecJob(execURL, accessToken, jobID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", execURL+"/jobs/"+url.PathEscape(jobID), nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if resp, err := c.httpClient.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
 // GenerateAuthToken generates a one-time auth token for browser access
 func (c *Client) GenerateAuthToken(ctx context.Context, instanceID string) (string, error) {
 	if c.teamSlug == "" {
@@ -339,15 +507,16 @@ func (c *Client) GenerateAuthToken(ctx context.Context, instanceID string) (stri
 	// Call the worker's /_cmux/generate-token endpoint
 	workerURL := strings.TrimRight(instance.WorkerURL, "/") + "/_cmux/generate-token"
 
-	req, err := http.NewRequestWithContext(ctx, "POST", workerURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	// Unused tokens expire, so asking for another one is harmless
+	resp, err := c.doWithRetry(Idempotent(ctx), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", workerURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to call worker: %w", err)
 	}
@@ -381,7 +550,7 @@ func (c *Client) GetSSHCredentials(ctx context.Context, instanceID string) (stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+		return "", newAPIError(resp)
 	}
 
 	var result struct {
@@ -394,7 +563,22 @@ func (c *Client) GetSSHCredentials(ctx context.Context, instanceID string) (stri
 	return result.SSHCommand, nil
 }
 
-// sshOptions returns SSH options for connecting to ephemeral VMs.
+// SSHTarget returns the user@host SSH destination for an instance
+func (c *Client) SSHTarget(ctx context.Context, instanceID string) (string, error) {
+	sshCmd, err := c.GetSSHCredentials(ctx, instanceID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get SSH credentials: %w", err)
+	}
+
+	// Parse SSH command: "ssh token@ssh.cloud.morph.so"
+	parts := strings.Fields(sshCmd)
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid SSH command format")
+	}
+	return parts[1], nil
+}
+
+// SSHOptions returns SSH options for connecting to ephemeral VMs.
 //
 // Security Note: Host key verification is disabled because:
 // 1. VMs are ephemeral and get new host keys on each creation
@@ -403,17 +587,27 @@ func (c *Client) GetSSHCredentials(ctx context.Context, instanceID string) (stri
 //
 // This is a deliberate tradeoff for usability with ephemeral development
 // environments. Production systems should use proper host key verification.
-func sshOptions() []string {
+func SSHOptions() []string {
 	return []string{
 		"-o", "StrictHostKeyChecking=no",
 		"-o", "UserKnownHostsFile=/dev/null",
 	}
 }
 
-func resolveRemoteSyncPath(ctx context.Context, sshTarget string) (string, error) {
+// remoteWorkspaceDirs are where VM images keep the workspace, in the order
+// they're looked for
+const remoteWorkspaceDirs = "/home/cmux/workspace /root/workspace /workspace /home/user/project"
+
+// InWorkspace wraps a shell command so it runs in the VM's workspace
+// directory, the one files are synced to
+func InWorkspace(command string) string {
+	return `for p in ` + remoteWorkspaceDirs + `; do [ -d "$p" ] && cd "$p" && break; done; ` + command
+}
+
+func resolveRemoteSyncPath(ctx context.Context, sshTarget string, sshOptions []string) (string, error) {
 	// Use a single-line command that works reliably over SSH
-	script := `for p in /home/cmux/workspace /root/workspace /workspace /home/user/project; do [ -d "$p" ] && echo "$p" && exit 0; done; echo "$HOME"`
-	cmdArgs := append(sshOptions(), sshTarget, script)
+	script := `for p in ` + remoteWorkspaceDirs + `; do [ -d "$p" ] && echo "$p" && exit 0; done; echo "$HOME"`
+	cmdArgs := append(append([]string{}, sshOptions...), sshTarget, script)
 	cmd := exec.CommandContext(ctx, "ssh", cmdArgs...)
 	// Use Output() not CombinedOutput() to avoid stderr (SSH warnings) in the path
 	output, err := cmd.Output()
@@ -429,10 +623,10 @@ func resolveRemoteSyncPath(ctx context.Context, sshTarget string) (string, error
 	return remotePath, nil
 }
 
-func ensureRemoteDir(ctx context.Context, sshTarget, remotePath string) error {
+func ensureRemoteDir(ctx context.Context, sshTarget, remotePath string, sshOptions []string) error {
 	// Use a single command string to avoid issues with argument parsing
 	mkdirCmd := fmt.Sprintf("mkdir -p %s", remotePath)
-	cmdArgs := append(sshOptions(), sshTarget, mkdirCmd)
+	cmdArgs := append(append([]string{}, sshOptions...), sshTarget, mkdirCmd)
 	cmd := exec.CommandContext(ctx, "ssh", cmdArgs...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -458,53 +652,174 @@ func formatRemotePath(remotePath string) string {
 	return remotePath + "/"
 }
 
-// SyncToVM syncs a local directory to the VM using rsync over SSH
-func (c *Client) SyncToVM(ctx context.Context, instanceID string, localPath string) error {
-	// Get SSH credentials
-	sshCmd, err := c.GetSSHCredentials(ctx, instanceID)
+// Syncer runs rsync against one VM. SSH credentials and the remote
+// workspace path are resolved once, so repeated syncs stay fast.
+type Syncer struct {
+	sshTarget  string
+	remotePath string
+	remoteDir  bool     // remote directory has been created
+	sshOptions []string // SSHOptions plus connection sharing where supported
+
+	// Output receives rsync's file list; nil discards it
+	Output io.Writer
+	// Delete removes remote files that no longer exist locally on Push
+	Delete bool
+	// Update skips files that are newer on the receiving side
+	Update bool
+	// Filters selects which paths are synced
+	Filters SyncFilters
+	// Parallel splits pushes across up to this many rsync processes by
+	// top-level directory; 0 or 1 pushes with a single rsync
+	Parallel int
+	// Progress writes an overall progress line to Output instead of the file
+	// list. Requires rsync 3.1+.
+	Progress bool
+	// Compression is "zlib" (the default when empty), "zstd", or "none".
+	// zstd requires rsync 3.2+ on both ends.
+	Compression string
+	// Checksum compares file contents instead of size and modification time
+	Checksum bool
+	// DryRun lists what would be transferred or deleted without changing
+	// anything
+	DryRun bool
+}
+
+// DefaultSyncParallel is the number of rsync processes SyncToVM uses
+const DefaultSyncParallel = 4
+
+// NewSyncer resolves SSH credentials and the remote workspace for an instance
+func (c *Client) NewSyncer(ctx context.Context, instanceID string) (*Syncer, error) {
+	sshTarget, err := c.SSHTarget(ctx, instanceID)
 	if err != nil {
-		return fmt.Errorf("failed to get SSH credentials: %w", err)
+		return nil, err
 	}
 
-	// Parse SSH command: "ssh token@ssh.cloud.morph.so"
-	parts := strings.Fields(sshCmd)
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid SSH command format")
+	sshOptions := append(SSHOptions(), sshControlOptions()...)
+	remotePath, err := resolveRemoteSyncPath(ctx, sshTarget, sshOptions)
+	if err != nil {
+		return nil, err
 	}
-	sshTarget := parts[1] // token@ssh.cloud.morph.so
 
-	remotePath, err := resolveRemoteSyncPath(ctx, sshTarget)
-	if err != nil {
-		return err
+	return &Syncer{
+		sshTarget:  sshTarget,
+		remotePath: remotePath,
+		sshOptions: sshOptions,
+		Output:     os.Stdout,
+		Delete:     true,
+	}, nil
+}
+
+// RemotePath returns the workspace directory synced inside the VM
+func (s *Syncer) RemotePath() string {
+	return s.remotePath
+}
+
+// Push syncs a local directory to the VM
+func (s *Syncer) Push(ctx context.Context, localPath string) error {
+	if !s.remoteDir {
+		if err := ensureRemoteDir(ctx, s.sshTarget, s.remotePath, s.sshOptions); err != nil {
+			return err
+		}
+		s.remoteDir = true
 	}
 
-	if err := ensureRemoteDir(ctx, sshTarget, remotePath); err != nil {
-		return err
+	// Dry runs list changes from a single rsync so the output stays in order
+	if s.Parallel > 1 && !s.DryRun {
+		return s.pushParallel(ctx, localPath)
 	}
+	return s.rsync(ctx, s.pushArgs(nil), s.Output, localPath+"/", s.remoteDest())
+}
 
-	remoteDest := formatRemotePath(remotePath)
+// Deletions lists the remote files a push with Delete set would remove
+func (s *Syncer) Deletions(ctx context.Context, localPath string) ([]string, error) {
+	if !s.remoteDir {
+		if err := ensureRemoteDir(ctx, s.sshTarget, s.remotePath, s.sshOptions); err != nil {
+			return nil, err
+		}
+		s.remoteDir = true
+	}
 
-	// Use rsync to sync files
-	// Exclude common large/generated directories
-	rsyncArgs := []string{
-		"-avz",
-		"--delete",
-		"--exclude", ".git",
-		"--exclude", "node_modules",
-		"--exclude", ".next",
-		"--exclude", "dist",
-		"--exclude", "build",
-		"--exclude", "__pycache__",
-		"--exclude", ".venv",
-		"--exclude", "venv",
-		"--exclude", "target",
-		"-e", "ssh " + strings.Join(sshOptions(), " "),
-		localPath + "/",
-		fmt.Sprintf("%s:%s", sshTarget, remoteDest),
+	plan := *s
+	plan.Delete = true
+	plan.DryRun = true
+	var out bytes.Buffer
+	if err := plan.rsync(ctx, plan.pushArgs(nil), &out, localPath+"/", plan.remoteDest()); err != nil {
+		return nil, err
 	}
 
+	var deletions []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if path, ok := strings.CutPrefix(line, "*deleting"); ok {
+			deletions = append(deletions, strings.TrimSpace(path))
+		}
+	}
+	return deletions, nil
+}
+
+// pushArgs returns the rsync arguments for a push. extraExcludes take
+// precedence over the sync filters.
+func (s *Syncer) pushArgs(extraExcludes []string) []string {
+	rsyncArgs := []string{"-a"}
+	if s.Delete {
+		rsyncArgs = append(rsyncArgs, "--delete")
+	}
+	for _, pattern := range extraExcludes {
+		rsyncArgs = append(rsyncArgs, "--exclude", pattern)
+	}
+	return append(rsyncArgs, s.Filters.rsyncArgs(DefaultSyncExcludes)...)
+}
+
+func (s *Syncer) remoteDest() string {
+	return fmt.Sprintf("%s:%s", s.sshTarget, formatRemotePath(s.remotePath))
+}
+
+// Pull syncs the VM workspace into a local directory
+func (s *Syncer) Pull(ctx context.Context, localPath string) error {
+	// Unlike pushes, pulls include .git so a repository can be fetched into
+	// an empty directory
+	var defaults []string
+	for _, pattern := range DefaultSyncExcludes {
+		if pattern != ".git" {
+			defaults = append(defaults, pattern)
+		}
+	}
+	rsyncArgs := append([]string{"-a"}, s.Filters.rsyncArgs(defaults)...)
+	return s.rsync(ctx, rsyncArgs, s.Output, s.remoteDest(), filepath.Clean(localPath)+"/")
+}
+
+// rsync runs rsync with the syncer's output, compression, and SSH settings.
+// The last path is the destination.
+func (s *Syncer) rsync(ctx context.Context, rsyncArgs []string, stdout io.Writer, paths ...string) error {
+	if s.DryRun {
+		rsyncArgs = append(rsyncArgs, "--dry-run")
+	}
+	switch {
+	case stdout == nil:
+	case s.DryRun:
+		rsyncArgs = append(rsyncArgs, "--itemize-changes")
+	case s.Progress:
+		rsyncArgs = append(rsyncArgs, "--info=progress2")
+	default:
+		rsyncArgs = append(rsyncArgs, "-v")
+	}
+	switch s.Compression {
+	case "none":
+	case "", "zlib":
+		rsyncArgs = append(rsyncArgs, "-z")
+	default:
+		rsyncArgs = append(rsyncArgs, "-z", "--compress-choice="+s.Compression)
+	}
+	if s.Update {
+		rsyncArgs = append(rsyncArgs, "--update")
+	}
+	if s.Checksum {
+		rsyncArgs = append(rsyncArgs, "--checksum")
+	}
+	rsyncArgs = append(rsyncArgs, "-e", "ssh "+strings.Join(s.sshOptions, " "))
+	rsyncArgs = append(rsyncArgs, paths...)
+
 	cmd := exec.CommandContext(ctx, "rsync", rsyncArgs...)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -514,51 +829,80 @@ func (c *Client) SyncToVM(ctx context.Context, instanceID string, localPath stri
 	return nil
 }
 
+// SyncToVM syncs a local directory to the VM using rsync over SSH
+func (c *Client) SyncToVM(ctx context.Context, instanceID string, localPath string, filters SyncFilters) error {
+	syncer, err := c.NewSyncer(ctx, instanceID)
+	if err != nil {
+		return err
+	}
+	syncer.Filters = filters
+	syncer.Parallel = DefaultSyncParallel
+	return syncer.Push(ctx, localPath)
+}
+
 // SyncFromVM syncs files from the VM to a local directory
-func (c *Client) SyncFromVM(ctx context.Context, instanceID string, localPath string) error {
-	// Get SSH credentials
-	sshCmd, err := c.GetSSHCredentials(ctx, instanceID)
+func (c *Client) SyncFromVM(ctx context.Context, instanceID string, localPath string, filters SyncFilters) error {
+	syncer, err := c.NewSyncer(ctx, instanceID)
 	if err != nil {
-		return fmt.Errorf("failed to get SSH credentials: %w", err)
+		return err
 	}
+	syncer.Filters = filters
 
-	// Parse SSH command
-	parts := strings.Fields(sshCmd)
-	if len(parts) < 2 {
-		return fmt.Errorf("invalid SSH command format")
+	// Ensure local directory exists
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	return syncer.Pull(ctx, localPath)
+}
+
+// CopyToVM copies a single local file into the VM with rsync over SSH.
+// Relative remote paths are resolved against the VM workspace, and a remote
+// path ending in "/" names a directory to copy into. Progress is written to
+// progress when it is non-nil.
+func (c *Client) CopyToVM(ctx context.Context, instanceID string, localPath, remotePath string, progress io.Writer) error {
+	sshTarget, remotePath, err := c.resolveCopyTarget(ctx, instanceID, remotePath)
+	if err != nil {
+		return err
 	}
-	sshTarget := parts[1]
+	return runCopy(ctx, localPath, fmt.Sprintf("%s:%s", sshTarget, remotePath), progress)
+}
 
-	remotePath, err := resolveRemoteSyncPath(ctx, sshTarget)
+// CopyFromVM copies a single file out of the VM with rsync over SSH.
+// Relative remote paths are resolved against the VM workspace.
+func (c *Client) CopyFromVM(ctx context.Context, instanceID string, remotePath, localPath string, progress io.Writer) error {
+	sshTarget, remotePath, err := c.resolveCopyTarget(ctx, instanceID, remotePath)
 	if err != nil {
 		return err
 	}
+	return runCopy(ctx, fmt.Sprintf("%s:%s", sshTarget, remotePath), localPath, progress)
+}
 
-	remoteSource := formatRemotePath(remotePath)
+func (c *Client) resolveCopyTarget(ctx context.Context, instanceID, remotePath string) (string, string, error) {
+	sshTarget, err := c.SSHTarget(ctx, instanceID)
+	if err != nil {
+		return "", "", err
+	}
 
-	// Ensure local directory exists
-	if err := os.MkdirAll(localPath, 0755); err != nil {
-		return fmt.Errorf("failed to create local directory: %w", err)
+	if !strings.HasPrefix(remotePath, "/") && !strings.HasPrefix(remotePath, "~") {
+		workspace, err := resolveRemoteSyncPath(ctx, sshTarget, SSHOptions())
+		if err != nil {
+			return "", "", err
+		}
+		remotePath = formatRemotePath(workspace) + remotePath
 	}
+	return sshTarget, remotePath, nil
+}
 
-	// Use rsync to sync files
-	rsyncArgs := []string{
-		"-avz",
-		"--exclude", "node_modules",
-		"--exclude", ".next",
-		"--exclude", "dist",
-		"--exclude", "build",
-		"--exclude", "__pycache__",
-		"--exclude", ".venv",
-		"--exclude", "venv",
-		"--exclude", "target",
-		"-e", "ssh " + strings.Join(sshOptions(), " "),
-		fmt.Sprintf("%s:%s", sshTarget, remoteSource),
-		filepath.Clean(localPath) + "/",
+func runCopy(ctx context.Context, source, dest string, progress io.Writer) error {
+	rsyncArgs := []string{"-tz"}
+	if progress != nil {
+		rsyncArgs = append(rsyncArgs, "--progress")
 	}
+	rsyncArgs = append(rsyncArgs, "-e", "ssh "+strings.Join(SSHOptions(), " "), source, dest)
 
 	cmd := exec.CommandContext(ctx, "rsync", rsyncArgs...)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = progress
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -599,15 +943,15 @@ func (c *Client) ListPtySessions(ctx context.Context, instanceID string) ([]PtyS
 	// Call worker's PTY list endpoint
 	workerURL := strings.TrimRight(instance.WorkerURL, "/") + "/_cmux/pty/list"
 
-	req, err := http.NewRequestWithContext(ctx, "POST", workerURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doWithRetry(Idempotent(ctx), func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", workerURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to call worker: %w", err)
 	}
@@ -0,0 +1,237 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+)
+
+// Environment is a reusable sandbox setup: a snapshot plus repos, scripts,
+// and environment variables
+type Environment struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	MorphSnapshotID   string   `json:"morphSnapshotId"`
+	SelectedRepos     []string `json:"selectedRepos,omitempty"`
+	Description       string   `json:"description,omitempty"`
+	MaintenanceScript string   `json:"maintenanceScript,omitempty"`
+	DevScript         string   `json:"devScript,omitempty"`
+	ExposedPorts      []int    `json:"exposedPorts,omitempty"`
+	CreatedAt         int64    `json:"createdAt"`
+	UpdatedAt         int64    `json:"updatedAt"`
+}
+
+// CreateEnvironmentOptions describes a new environment. The environment's
+// snapshot is taken from the running Morph instance.
+type CreateEnvironmentOptions struct {
+	Name              string   `json:"name"`
+	MorphInstanceID   string   `json:"morphInstanceId"`
+	EnvVarsContent    string   `json:"envVarsContent"`
+	SelectedRepos     []string `json:"selectedRepos,omitempty"`
+	Description       string   `json:"description,omitempty"`
+	MaintenanceScript string   `json:"maintenanceScript,omitempty"`
+	DevScript         string   `json:"devScript,omitempty"`
+	ExposedPorts      []int    `json:"exposedPorts,omitempty"`
+}
+
+// UpdateEnvironmentOptions changes an environment; nil fields are left as is
+type UpdateEnvironmentOptions struct {
+	Name              *string   `json:"name,omitempty"`
+	Description       *string   `json:"description,omitempty"`
+	MaintenanceScript *string   `json:"maintenanceScript,omitempty"`
+	DevScript         *string   `json:"devScript,omitempty"`
+	SelectedRepos     *[]string `json:"selectedRepos,omitempty"`
+	EnvVarsContent    *string   `json:"envVarsContent,omitempty"`
+}
+
+// doAPIRequest calls the cmux web API, which owns environments and their
+// encrypted variables. Transient failures are retried like doRequest's.
+func (c *Client) doAPIRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	accessToken, err := auth.GetAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("not authenticated: %w", err)
+	}
+
+	var data []byte
+	if body != nil {
+		data, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	apiURL := strings.TrimRight(auth.GetConfig().CmuxURL, "/") + "/api" + path
+	return c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, apiURL, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+}
+
+func (c *Client) environmentPath(environmentID, suffix string) string {
+	return fmt.Sprintf("/environments/%s%s?teamSlugOrId=%s", url.PathEscape(environmentID), suffix, url.QueryEscape(c.teamSlug))
+}
+
+// ListEnvironments lists the team's environments
+func (c *Client) ListEnvironments(ctx context.Context) ([]Environment, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "GET", "/environments?teamSlugOrId="+url.QueryEscape(c.teamSlug), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result []Environment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetEnvironment gets a single environment
+func (c *Client) GetEnvironment(ctx context.Context, environmentID string) (*Environment, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "GET", c.environmentPath(environmentID, ""), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result Environment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// GetEnvironmentVars returns an environment's variables as .env file content
+func (c *Client) GetEnvironmentVars(ctx context.Context, environmentID string) (string, error) {
+	if c.teamSlug == "" {
+		return "", fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "GET", c.environmentPath(environmentID, "/vars"), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var result struct {
+		EnvVarsContent string `json:"envVarsContent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.EnvVarsContent, nil
+}
+
+// CreateEnvironment snapshots a running instance into a new environment and
+// returns its ID
+func (c *Client) CreateEnvironment(ctx context.Context, opts CreateEnvironmentOptions) (string, error) {
+	if c.teamSlug == "" {
+		return "", fmt.Errorf("team slug not set")
+	}
+
+	body := struct {
+		TeamSlugOrID string `json:"teamSlugOrId"`
+		CreateEnvironmentOptions
+	}{c.teamSlug, opts}
+
+	resp, err := c.doAPIRequest(ctx, "POST", "/environments", body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+// UpdateEnvironment changes an environment's settings and returns the result
+func (c *Client) UpdateEnvironment(ctx context.Context, environmentID string, opts UpdateEnvironmentOptions) (*Environment, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	body := struct {
+		TeamSlugOrID string `json:"teamSlugOrId"`
+		UpdateEnvironmentOptions
+	}{c.teamSlug, opts}
+
+	resp, err := c.doAPIRequest(ctx, "PATCH", "/environments/"+url.PathEscape(environmentID), body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result Environment
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// DeleteEnvironment deletes an environment
+func (c *Client) DeleteEnvironment(ctx context.Context, environmentID string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "DELETE", c.environmentPath(environmentID, ""), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package vm
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the repo-level file listing extra sync exclusions
+const IgnoreFileName = ".cmuxignore"
+
+// DefaultSyncExcludes are large or generated directories that are never
+// worth shipping to a VM
+var DefaultSyncExcludes = []string{
+	".git",
+	"node_modules",
+	".next",
+	"dist",
+	"build",
+	"__pycache__",
+	".venv",
+	"venv",
+	"target",
+}
+
+// SyncFilters layers rsync include/exclude rules. rsync uses the first rule
+// that matches, so the layers are applied in priority order: --include
+// flags, --exclude flags, the repo's .cmuxignore, then the built-in defaults.
+// The zero value applies only the defaults.
+type SyncFilters struct {
+	Includes []string
+	Excludes []string
+	// IgnoreRules come from .cmuxignore; a leading "!" re-includes a path
+	IgnoreRules []string
+}
+
+// LoadSyncFilters combines flag rules with the .cmuxignore file in root, if
+// there is one. Patterns use rsync syntax, which matches .gitignore for the
+// common cases: "*.log", "/tmp", "cache/", "**/fixtures".
+func LoadSyncFilters(root string, includes, excludes []string) (SyncFilters, error) {
+	filters := SyncFilters{Includes: includes, Excludes: excludes}
+
+	path := filepath.Join(root, IgnoreFileName)
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return filters, nil
+	}
+	if err != nil {
+		return filters, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		filters.IgnoreRules = append(filters.IgnoreRules, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return filters, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	return filters, nil
+}
+
+// rsyncArgs returns the --include/--exclude arguments, ending with the given
+// default excludes
+func (f SyncFilters) rsyncArgs(defaults []string) []string {
+	var args []string
+	for _, pattern := range f.Includes {
+		args = append(args, "--include", pattern)
+	}
+	for _, pattern := range f.Excludes {
+		args = append(args, "--exclude", pattern)
+	}
+	for _, rule := range f.IgnoreRules {
+		if pattern, ok := strings.CutPrefix(rule, "!"); ok {
+			args = append(args, "--include", pattern)
+		} else {
+			args = append(args, "--exclude", rule)
+		}
+	}
+	for _, pattern := range defaults {
+		args = append(args, "--exclude", pattern)
+	}
+	return args
+}
+
+// SkipsDir reports whether a directory with this name is excluded from
+// pushes outright. Only unanchored, slash-free patterns are considered, which
+// is enough for callers that want to avoid scanning excluded trees.
+func (f SyncFilters) SkipsDir(name string) bool {
+	var rules []string
+	for _, pattern := range f.Includes {
+		rules = append(rules, "!"+pattern)
+	}
+	rules = append(rules, f.Excludes...)
+	rules = append(rules, f.IgnoreRules...)
+	rules = append(rules, DefaultSyncExcludes...)
+
+	for _, rule := range rules {
+		pattern, include := strings.CutPrefix(rule, "!")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if strings.Contains(pattern, "/") {
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return !include
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pushParallel splits a push by top-level directory. The first worker syncs
+// the root, including top-level files and deletions, with every directory
+// handed to another worker excluded; rsync never deletes excluded paths, so
+// the workers don't step on each other. The other workers sync their
+// directories with --relative so anchored filter patterns still match.
+func (s *Syncer) pushParallel(ctx context.Context, localPath string) error {
+	buckets, err := s.splitTopLevel(localPath)
+	if err != nil {
+		return err
+	}
+	if len(buckets) < 2 {
+		return s.rsync(ctx, s.pushArgs(nil), s.Output, localPath+"/", s.remoteDest())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var progress *progressTracker
+	var output io.Writer
+	switch {
+	case s.Output == nil:
+	case s.Progress:
+		progress = newProgressTracker(s.Output, len(buckets))
+		defer progress.finish()
+	default:
+		output = &lockedWriter{w: s.Output}
+	}
+	workerOutput := func(i int) io.Writer {
+		if progress != nil {
+			return progress.writer(i)
+		}
+		return output
+	}
+
+	var excluded []string
+	for _, bucket := range buckets[1:] {
+		for _, dir := range bucket {
+			excluded = append(excluded, "/"+dir+"/")
+		}
+	}
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	run := func(i int, rsyncArgs []string, paths ...string) {
+		defer wg.Done()
+		if err := s.rsync(ctx, rsyncArgs, workerOutput(i), paths...); err != nil {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+	}
+
+	wg.Add(len(buckets))
+	go run(0, s.pushArgs(excluded), localPath+"/", s.remoteDest())
+	for i, bucket := range buckets[1:] {
+		paths := make([]string, 0, len(bucket)+1)
+		for _, dir := range bucket {
+			paths = append(paths, localPath+"/./"+dir)
+		}
+		paths = append(paths, s.remoteDest())
+		go run(i+1, append([]string{"--relative"}, s.pushArgs(nil)...), paths...)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// splitTopLevel groups the synced top-level directories of root into at most
+// s.Parallel buckets of roughly equal size, largest directories first.
+// Bucket 0 belongs to the root worker and may be empty.
+func (s *Syncer) splitTopLevel(root string) ([][]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+
+	type dirSize struct {
+		name string
+		size int64
+	}
+	var dirs []dirSize
+	for _, entry := range entries {
+		if !entry.IsDir() || s.Filters.SkipsDir(entry.Name()) {
+			continue
+		}
+		dirs = append(dirs, dirSize{entry.Name(), s.treeSize(filepath.Join(root, entry.Name()))})
+	}
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].size > dirs[j].size })
+
+	n := min(s.Parallel, len(dirs)+1)
+	buckets := make([][]string, n)
+	sizes := make([]int64, n)
+	for _, dir := range dirs {
+		smallest := 0
+		for i := range sizes {
+			if sizes[i] < sizes[smallest] {
+				smallest = i
+			}
+		}
+		buckets[smallest] = append(buckets[smallest], dir.name)
+		sizes[smallest] += dir.size
+	}
+
+	// Drop workers that ended up with nothing to do
+	nonEmpty := buckets[:1]
+	for _, bucket := range buckets[1:] {
+		if len(bucket) > 0 {
+			nonEmpty = append(nonEmpty, bucket)
+		}
+	}
+	return nonEmpty, nil
+}
+
+// treeSize estimates how many bytes a push of dir sends, skipping excluded
+// directories
+func (s *Syncer) treeSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && s.Filters.SkipsDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// lockedWriter serializes writes from concurrent rsync processes
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// progressTracker merges the --info=progress2 output of several rsync
+// processes into a single line
+type progressTracker struct {
+	mu       sync.Mutex
+	out      io.Writer
+	bytes    []int64
+	start    time.Time
+	rendered time.Time
+}
+
+func newProgressTracker(out io.Writer, workers int) *progressTracker {
+	return &progressTracker{out: out, bytes: make([]int64, workers), start: time.Now()}
+}
+
+func (p *progressTracker) writer(worker int) io.Writer {
+	return &progressWriter{tracker: p, worker: worker}
+}
+
+func (p *progressTracker) update(worker int, transferred int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes[worker] = transferred
+	if time.Since(p.rendered) >= 200*time.Millisecond {
+		p.render()
+	}
+}
+
+// render must be called with p.mu held
+func (p *progressTracker) render() {
+	var total int64
+	for _, n := range p.bytes {
+		total += n
+	}
+	elapsed := time.Since(p.start)
+	rate := float64(total) / max(elapsed.Seconds(), 0.001)
+	fmt.Fprintf(p.out, "\r  %s transferred  %s/s  %s (%d workers)   ",
+		FormatBytes(total), FormatBytes(int64(rate)), elapsed.Round(time.Second), len(p.bytes))
+	p.rendered = time.Now()
+}
+
+func (p *progressTracker) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+	fmt.Fprintln(p.out)
+}
+
+// progressWriter parses one rsync's progress lines, which are separated by
+// carriage returns and start with the bytes transferred so far
+type progressWriter struct {
+	tracker *progressTracker
+	worker  int
+	pending []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := strings.IndexAny(string(w.pending), "\r\n")
+		if i < 0 {
+			break
+		}
+		line := string(w.pending[:i])
+		w.pending = w.pending[i+1:]
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if n, err := strconv.ParseInt(strings.ReplaceAll(fields[0], ",", ""), 10, 64); err == nil {
+			w.tracker.update(w.worker, n)
+		}
+	}
+	return len(p), nil
+}
+
+// FormatBytes formats a byte count with binary units, e.g. "12.3 MiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,142 @@
+package vm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// globChars are the shell wildcards PullPaths leaves unquoted
+const globChars = "*?["
+
+// splitRemotePattern splits a remote path into the directory to archive
+// from and the (possibly wildcard) entry to archive, e.g.
+// "out/screens/*.png" into "out/screens" and "*.png", and "coverage" into
+// "." and "coverage". Wildcards are only supported in the last segment.
+func splitRemotePattern(pattern string) (dir, entry string, err error) {
+	pattern = strings.TrimRight(pattern, "/")
+	if pattern == "" {
+		return "", "", fmt.Errorf("remote path is empty")
+	}
+	slash := strings.LastIndex(pattern, "/")
+	dir, entry = ".", pattern
+	if slash == 0 {
+		dir, entry = "/", pattern[1:]
+	} else if slash > 0 {
+		dir, entry = pattern[:slash], pattern[slash+1:]
+	}
+	if strings.ContainsAny(dir, globChars) {
+		return "", "", fmt.Errorf("wildcards are only supported in the last path segment: %s", pattern)
+	}
+	if entry == "." || entry == ".." {
+		return "", "", fmt.Errorf("can't pull %s; name a file or directory", pattern)
+	}
+	return dir, entry, nil
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellGlob quotes s for a POSIX shell but leaves wildcards unquoted so the
+// shell expands them
+func shellGlob(s string) string {
+	var b strings.Builder
+	start := 0
+	for i, r := range s {
+		if strings.ContainsRune(globChars+"]", r) {
+			if i > start {
+				b.WriteString(shellQuote(s[start:i]))
+			}
+			b.WriteRune(r)
+			start = i + 1
+		}
+	}
+	if start < len(s) {
+		b.WriteString(shellQuote(s[start:]))
+	}
+	return b.String()
+}
+
+// remoteDir quotes a remote directory, expanding a leading "~"
+func remoteDir(dir string) string {
+	if dir == "~" {
+		return `"$HOME"`
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return `"$HOME"/` + shellQuote(rest)
+	}
+	return shellQuote(dir)
+}
+
+// PullPaths copies files or directories matching a remote path into
+// localDir by streaming a tar archive over SSH. Relative remote paths are
+// resolved against the VM workspace, and the last path segment may contain
+// shell wildcards ("screenshots/*.png"). Matches keep their names, so
+// pulling "coverage" creates localDir/coverage. It returns the number of
+// compressed bytes transferred.
+func (c *Client) PullPaths(ctx context.Context, instanceID, remotePath, localDir string) (int64, error) {
+	dir, entry, err := splitRemotePattern(remotePath)
+	if err != nil {
+		return 0, err
+	}
+
+	sshTarget, err := c.SSHTarget(ctx, instanceID)
+	if err != nil {
+		return 0, err
+	}
+
+	script := fmt.Sprintf(`cd %s || exit 1; set -- %s; [ -e "$1" ] || { echo no such file or directory: %s >&2; exit 1; }; tar -czf - -- "$@"`,
+		remoteDir(dir), shellGlob(entry), shellQuote(remotePath))
+	if !strings.HasPrefix(dir, "/") && !strings.HasPrefix(dir, "~") {
+		script = InWorkspace(script)
+	}
+
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	sshArgs := append(append([]string{}, SSHOptions()...), "-o", "LogLevel=ERROR", sshTarget, script)
+	sshCmd := exec.CommandContext(ctx, "ssh", sshArgs...)
+	var sshStderr bytes.Buffer
+	sshCmd.Stderr = &sshStderr
+	archive, err := sshCmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+
+	counter := &countingReader{r: archive, onRead: func(int64) {}}
+	tarCmd := exec.CommandContext(ctx, "tar", "-xzf", "-", "-C", localDir)
+	tarCmd.Stdin = counter
+	var tarStderr bytes.Buffer
+	tarCmd.Stderr = &tarStderr
+
+	if err := sshCmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start ssh: %w", err)
+	}
+	tarErr := tarCmd.Run()
+	if tarErr != nil {
+		// Unblock ssh if tar stopped reading
+		io.Copy(io.Discard, archive)
+	}
+	sshErr := sshCmd.Wait()
+
+	if sshErr != nil {
+		if msg := strings.TrimSpace(sshStderr.String()); msg != "" {
+			return 0, fmt.Errorf("%s", msg)
+		}
+		return 0, fmt.Errorf("ssh failed: %w", sshErr)
+	}
+	if tarErr != nil {
+		if msg := strings.TrimSpace(tarStderr.String()); msg != "" {
+			return 0, fmt.Errorf("failed to extract files: %s", msg)
+		}
+		return 0, fmt.Errorf("failed to extract files: %w", tarErr)
+	}
+	return counter.n, nil
+}
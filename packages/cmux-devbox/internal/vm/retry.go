@@ -0,0 +1,198 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how long API requests may take and how they are
+// retried after transient failures: 429 and 5xx responses and dropped
+// connections
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles with each
+	// attempt, with jitter, up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// AttemptTimeout bounds each attempt, including reading the response
+	// body, within the caller's own deadline; 0 leaves only the caller's
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy is used unless a request's context overrides it. The
+// attempt timeout allows for slow Morph operations.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	BaseDelay:      500 * time.Millisecond,
+	MaxDelay:       10 * time.Second,
+	AttemptTimeout: 3 * time.Minute,
+}
+
+// maxRetryAfter is the longest Retry-After the client waits out; longer
+// ones return the response to the caller
+const maxRetryAfter = time.Minute
+
+type retryPolicyKey struct{}
+type idempotentKey struct{}
+
+// WithRetryPolicy overrides the retry policy for requests made with ctx
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// WithoutRetries makes requests made with ctx fail on the first error
+func WithoutRetries(ctx context.Context) context.Context {
+	policy := retryPolicyFrom(ctx)
+	policy.MaxAttempts = 1
+	return WithRetryPolicy(ctx, policy)
+}
+
+// Idempotent marks requests made with ctx as safe to repeat. Without it,
+// POST and PATCH requests are only retried when the server can't have acted
+// on them: refused connections and 429 or 503 responses.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func retryPolicyFrom(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+func isRepeatable(ctx context.Context, method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// notSent reports whether err happened before the request reached the
+// server, so even non-idempotent requests can be repeated
+func notSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// transientNetError reports whether err is a dropped or refused connection.
+// Timeouts aren't retried; the caller's deadline already covers them.
+func transientNetError(err error) bool {
+	return notSent(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// backoff returns the jittered delay before retry number attempt
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// cancelOnClose releases an attempt's context once its response body is
+// closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doWithRetry sends the request built by newRequest, retrying transient
+// failures according to the context's retry policy. newRequest is called
+// with each attempt's context, which carries the attempt timeout, so request
+// bodies can be re-read. The attempt's context lives until the response
+// body is closed.
+func (c *Client) doWithRetry(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	policy := retryPolicyFrom(ctx)
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.AttemptTimeout)
+		}
+		req, err := newRequest(attemptCtx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		repeatable := isRepeatable(ctx, req.Method)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			cancel()
+		} else {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := backoff(policy, attempt)
+		if err != nil {
+			if !transientNetError(err) || (!repeatable && !notSent(err)) {
+				return nil, err
+			}
+		} else {
+			switch {
+			case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+			case resp.StatusCode >= 500 && repeatable:
+			default:
+				return resp, nil
+			}
+			if wait := retryAfter(resp); wait > delay {
+				if wait > maxRetryAfter {
+					return resp, nil
+				}
+				delay = wait
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
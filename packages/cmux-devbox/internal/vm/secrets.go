@@ -0,0 +1,91 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Secret is a team secret. Values are write-only and never returned.
+type Secret struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+	UpdatedAt   int64  `json:"updatedAt"`
+}
+
+// ListSecrets lists the names of the team's secrets
+func (c *Client) ListSecrets(ctx context.Context) ([]Secret, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doRequest(ctx, "GET", "/api/v1/cmux/secrets?teamSlugOrId="+url.QueryEscape(c.teamSlug), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result struct {
+		Secrets []Secret `json:"secrets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Secrets, nil
+}
+
+// SetSecret creates or updates a secret
+func (c *Client) SetSecret(ctx context.Context, name, value, description string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	body := map[string]interface{}{
+		"teamSlugOrId": c.teamSlug,
+		"name":         name,
+		"value":        value,
+	}
+	if description != "" {
+		body["description"] = description
+	}
+
+	resp, err := c.doRequest(ctx, "PUT", "/api/v1/cmux/secrets", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
+
+// DeleteSecret deletes a secret
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	path := fmt.Sprintf("/api/v1/cmux/secrets/%s?teamSlugOrId=%s", url.PathEscape(name), url.QueryEscape(c.teamSlug))
+	resp, err := c.doRequest(ctx, "DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
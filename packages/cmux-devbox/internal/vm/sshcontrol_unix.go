@@ -0,0 +1,35 @@
+//go:build !windows
+
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cmux-cli/cmux-devbox/internal/auth"
+)
+
+// sshControlOptions shares one SSH connection per VM across the ssh and
+// rsync processes a sync starts. The master connection lingers for a few
+// minutes after last use, so back-to-back commands skip the handshake too.
+func sshControlOptions() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	// Socket paths are limited to ~100 bytes; %C is a 40-character hash
+	dir := filepath.Join(home, ".config", auth.ConfigDirName, "ssh")
+	// rsync splits its -e command on whitespace
+	if strings.ContainsAny(dir, " \t") {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil
+	}
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(dir, "%C"),
+		"-o", "ControlPersist=5m",
+	}
+}
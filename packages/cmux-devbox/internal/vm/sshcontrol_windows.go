@@ -0,0 +1,9 @@
+//go:build windows
+
+package vm
+
+// sshControlOptions returns nothing on Windows, where OpenSSH doesn't
+// support connection sharing
+func sshControlOptions() []string {
+	return nil
+}
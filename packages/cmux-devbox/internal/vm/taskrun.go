@@ -0,0 +1,120 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TaskRun is an agent run started from the cmux web app
+type TaskRun struct {
+	ID             string `json:"id"`
+	ParentRunID    string `json:"parentRunId,omitempty"`
+	Status         string `json:"status"` // pending, running, completed, failed, skipped
+	AgentName      string `json:"agentName"`
+	Crowned        bool   `json:"crowned,omitempty"`
+	ExitCode       *int   `json:"exitCode"`
+	ErrorMessage   string `json:"errorMessage"`
+	Branch         string `json:"branch,omitempty"`
+	PullRequestURL string `json:"pullRequestUrl,omitempty"`
+	VSCodeURL      string `json:"vscodeUrl,omitempty"`
+	PtyURL         string `json:"ptyUrl"` // cmux-pty server in the run's VM
+	VNCURL         string `json:"vncUrl,omitempty"`
+	CreatedAt      int64  `json:"createdAt,omitempty"`
+	CompletedAt    int64  `json:"completedAt,omitempty"`
+}
+
+// Finished reports whether the run has stopped producing output
+func (t *TaskRun) Finished() bool {
+	return t.Status != "pending" && t.Status != "running"
+}
+
+// GetTaskRun gets a task run's status and terminal server
+func (c *Client) GetTaskRun(ctx context.Context, taskRunID string) (*TaskRun, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	path := fmt.Sprintf("/api/v1/cmux/task-runs/%s?teamSlugOrId=%s", url.PathEscape(taskRunID), url.QueryEscape(c.teamSlug))
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result TaskRun
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// TerminalSession is a terminal hosted by the cmux-pty server
+type TerminalSession struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Alive    bool                   `json:"alive"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FindAgentTerminal returns the terminal the worker started the agent in
+func (c *Client) FindAgentTerminal(ctx context.Context, ptyURL string) (*TerminalSession, error) {
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", strings.TrimRight(ptyURL, "/")+"/sessions", nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach terminal server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("terminal server error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	var result struct {
+		Sessions []TerminalSession `json:"sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for i := range result.Sessions {
+		if result.Sessions[i].Metadata["type"] == "agent" {
+			return &result.Sessions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no agent terminal found")
+}
+
+// CaptureTerminal returns a terminal's scrollback with ANSI sequences intact
+func (c *Client) CaptureTerminal(ctx context.Context, ptyURL, sessionID string) (string, error) {
+	captureURL := fmt.Sprintf("%s/sessions/%s/capture", strings.TrimRight(ptyURL, "/"), url.PathEscape(sessionID))
+	resp, err := c.doWithRetry(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", captureURL, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to reach terminal server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("terminal server error (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	var result struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Content, nil
+}
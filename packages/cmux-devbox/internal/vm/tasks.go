@@ -0,0 +1,128 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Task is a prompt started from the cmux web app, run by one or more agents
+type Task struct {
+	ID             string   `json:"id"`
+	Text           string   `json:"text"`
+	Status         string   `json:"status"` // pending, running, completed, failed
+	Agents         []string `json:"agents"`
+	Repo           string   `json:"repo,omitempty"`
+	BaseBranch     string   `json:"baseBranch,omitempty"`
+	Pinned         bool     `json:"pinned"`
+	Archived       bool     `json:"archived"`
+	Unread         bool     `json:"unread"`
+	MergeStatus    string   `json:"mergeStatus,omitempty"`
+	CreatedAt      int64    `json:"createdAt"`
+	LastActivityAt int64    `json:"lastActivityAt,omitempty"`
+}
+
+// TaskDetail is a task together with all of its runs
+type TaskDetail struct {
+	Task
+	Runs []TaskRun `json:"runs"`
+}
+
+// ListTasks lists the current user's tasks, most recent first
+func (c *Client) ListTasks(ctx context.Context, archived bool, limit int) ([]Task, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	query := url.Values{}
+	query.Set("teamSlugOrId", c.teamSlug)
+	if archived {
+		query.Set("archived", "true")
+	}
+	if limit > 0 {
+		query.Set("limit", fmt.Sprint(limit))
+	}
+
+	resp, err := c.doRequest(ctx, "GET", "/api/v1/cmux/tasks?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Tasks, nil
+}
+
+// GetTask gets a task and its runs
+func (c *Client) GetTask(ctx context.Context, taskID string) (*TaskDetail, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	path := fmt.Sprintf("/api/v1/cmux/tasks/%s?teamSlugOrId=%s", url.PathEscape(taskID), url.QueryEscape(c.teamSlug))
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result TaskDetail
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (c *Client) taskAction(ctx context.Context, taskID, action string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	body := map[string]interface{}{
+		"teamSlugOrId": c.teamSlug,
+	}
+	resp, err := c.doRequest(ctx, "POST", fmt.Sprintf("/api/v1/cmux/tasks/%s/%s", url.PathEscape(taskID), action), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
+
+// SetTaskArchived archives or unarchives a task
+func (c *Client) SetTaskArchived(ctx context.Context, taskID string, archived bool) error {
+	if archived {
+		return c.taskAction(ctx, taskID, "archive")
+	}
+	return c.taskAction(ctx, taskID, "unarchive")
+}
+
+// SetTaskPinned pins or unpins a task
+func (c *Client) SetTaskPinned(ctx context.Context, taskID string, pinned bool) error {
+	if pinned {
+		return c.taskAction(ctx, taskID, "pin")
+	}
+	return c.taskAction(ctx, taskID, "unpin")
+}
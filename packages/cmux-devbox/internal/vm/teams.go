@@ -0,0 +1,131 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TeamMember is a member of the current team
+type TeamMember struct {
+	UserID      string `json:"userId"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Role        string `json:"role"` // admin or member
+}
+
+// Team is a team the user belongs to
+type Team struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Slug        string `json:"slug,omitempty"`
+	Role        string `json:"role"`     // admin or member
+	Selected    bool   `json:"selected"` // the user's selected team on the server
+}
+
+// SlugOrID returns the team's slug, or its ID if it has none
+func (t Team) SlugOrID() string {
+	if t.Slug != "" {
+		return t.Slug
+	}
+	return t.ID
+}
+
+// ListTeams lists the teams the user belongs to
+func (c *Client) ListTeams(ctx context.Context) ([]Team, error) {
+	resp, err := c.doAPIRequest(ctx, "GET", "/teams", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result struct {
+		Teams []Team `json:"teams"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Teams, nil
+}
+
+func (c *Client) teamPath(suffix string) string {
+	return "/teams/" + url.PathEscape(c.teamSlug) + suffix
+}
+
+// ListTeamMembers lists the members of the team
+func (c *Client) ListTeamMembers(ctx context.Context) ([]TeamMember, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "GET", c.teamPath("/members"), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result struct {
+		Members []TeamMember `json:"members"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Members, nil
+}
+
+// InviteTeamMember emails an invitation to join the team. role (admin or
+// member) is granted once the invitation is accepted.
+func (c *Client) InviteTeamMember(ctx context.Context, email, role string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	body := map[string]string{
+		"email": email,
+		"role":  role,
+	}
+
+	resp, err := c.doAPIRequest(ctx, "POST", c.teamPath("/invitations"), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
+
+// RemoveTeamMember removes a member, given their user ID or email, from the
+// team
+func (c *Client) RemoveTeamMember(ctx context.Context, member string) error {
+	if c.teamSlug == "" {
+		return fmt.Errorf("team slug not set")
+	}
+
+	resp, err := c.doAPIRequest(ctx, "DELETE", c.teamPath("/members/"+url.PathEscape(member)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
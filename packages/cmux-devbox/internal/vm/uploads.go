@@ -0,0 +1,314 @@
+package vm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// UploadChunkSize is the size of each separately uploaded and retried piece
+// of a file
+const UploadChunkSize = 8 << 20
+
+const uploadAttempts = 5
+
+// UploadResult identifies an uploaded file in team storage
+type UploadResult struct {
+	StorageID   string `json:"storageId"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// UploadProgress is called as bytes are sent. Chunks finished by an earlier,
+// interrupted upload count as sent.
+type UploadProgress func(sent, total int64)
+
+// uploadState records finished chunks so an interrupted upload can resume
+type uploadState struct {
+	Chunks []string `json:"chunks"` // storage ID per chunk; "" if not yet uploaded
+}
+
+// retryable reports whether err is a network error or a 5xx or 429 response
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// UploadFileToStorage uploads a local file to team storage. Files are sent in
+// UploadChunkSize pieces straight from disk, each retried with backoff on
+// network or server errors. Finished chunks are remembered, so running the
+// same upload again after a failure resumes where it stopped.
+func (c *Client) UploadFileToStorage(ctx context.Context, path string, onProgress UploadProgress) (*UploadResult, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	size := info.Size()
+
+	contentType, err := detectContentType(file, path)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkCount := int((size + UploadChunkSize - 1) / UploadChunkSize)
+	if chunkCount == 0 {
+		chunkCount = 1
+	}
+
+	statePath := c.uploadStatePath(path, info)
+	state := loadUploadState(statePath, chunkCount)
+
+	var sent int64
+	for i, storageID := range state.Chunks {
+		if storageID != "" {
+			sent += chunkLength(i, size)
+		}
+	}
+	if onProgress != nil {
+		onProgress(sent, size)
+	}
+
+	for i := range state.Chunks {
+		if state.Chunks[i] != "" {
+			continue
+		}
+
+		offset := int64(i) * UploadChunkSize
+		length := chunkLength(i, size)
+		// A lone chunk is the file itself, so it carries the real type
+		chunkType := "application/octet-stream"
+		if chunkCount == 1 {
+			chunkType = contentType
+		}
+
+		var storageID string
+		for attempt := 1; ; attempt++ {
+			reader := io.NewSectionReader(file, offset, length)
+			var body io.Reader = reader
+			if onProgress != nil {
+				base := sent
+				body = &countingReader{r: reader, onRead: func(n int64) { onProgress(base+n, size) }}
+			}
+
+			storageID, err = c.uploadChunk(ctx, body, length, chunkType)
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if attempt == uploadAttempts || !retryable(err) {
+				return nil, fmt.Errorf("failed to upload chunk %d/%d: %w", i+1, chunkCount, err)
+			}
+
+			select {
+			case <-time.After(time.Duration(1<<(attempt-1)) * time.Second):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		state.Chunks[i] = storageID
+		sent += length
+		if chunkCount > 1 {
+			saveUploadState(statePath, state)
+		}
+	}
+
+	result, err := c.completeUpload(ctx, state.Chunks, contentType)
+	if err != nil {
+		// Start over next time if the server rejected the chunks
+		if !retryable(err) {
+			os.Remove(statePath)
+		}
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+	os.Remove(statePath)
+
+	result.ContentType = contentType
+	result.Size = size
+	return result, nil
+}
+
+func chunkLength(i int, size int64) int64 {
+	return min(UploadChunkSize, size-int64(i)*UploadChunkSize)
+}
+
+// detectContentType uses the file extension, falling back to sniffing the
+// first bytes
+func detectContentType(file *os.File, path string) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType, nil
+	}
+	head := make([]byte, 512)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// uploadChunk uploads one piece through a fresh one-time upload URL
+func (c *Client) uploadChunk(ctx context.Context, body io.Reader, length int64, contentType string) (string, error) {
+	resp, err := c.doRequest(Idempotent(ctx), "POST", "/api/v1/cmux/uploads", map[string]string{"teamSlugOrId": c.teamSlug})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(resp)
+	}
+
+	var target struct {
+		UploadURL string `json:"uploadUrl"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&target); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// The storage upload bypasses doWithRetry (the caller retries the whole
+	// chunk), so it gets the attempt timeout here
+	if timeout := retryPolicyFrom(ctx).AttemptTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", target.UploadURL, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = length
+	req.Header.Set("Content-Type", contentType)
+
+	uploadResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer uploadResp.Body.Close()
+
+	if uploadResp.StatusCode != http.StatusOK {
+		return "", newAPIError(uploadResp)
+	}
+
+	var result struct {
+		StorageID string `json:"storageId"`
+	}
+	if err := json.NewDecoder(uploadResp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode upload response: %w", err)
+	}
+	return result.StorageID, nil
+}
+
+func (c *Client) completeUpload(ctx context.Context, chunks []string, contentType string) (*UploadResult, error) {
+	body := map[string]interface{}{
+		"teamSlugOrId": c.teamSlug,
+		"chunks":       chunks,
+		"contentType":  contentType,
+	}
+
+	resp, err := c.doRequest(ctx, "POST", "/api/v1/cmux/uploads/complete", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result UploadResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &result, nil
+}
+
+// uploadStatePath keys resume state on the team and the file's path, size,
+// and modification time, so a changed file starts a fresh upload
+func (c *Client) uploadStatePath(path string, info os.FileInfo) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	absPath, _ := filepath.Abs(path)
+	key := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%d",
+		c.teamSlug, absPath, info.Size(), info.ModTime().UnixNano(), UploadChunkSize)))
+	return filepath.Join(cacheDir, "cmux", "uploads", hex.EncodeToString(key[:16])+".json")
+}
+
+func loadUploadState(path string, chunkCount int) *uploadState {
+	state := &uploadState{Chunks: make([]string, chunkCount)}
+	if path == "" {
+		return state
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state
+	}
+	var saved uploadState
+	if json.Unmarshal(data, &saved) == nil && len(saved.Chunks) == chunkCount {
+		return &saved
+	}
+	return state
+}
+
+// saveUploadState is best effort; without it an upload just can't resume
+func saveUploadState(path string, state *uploadState) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0600)
+}
+
+// countingReader reports the running total of bytes read
+type countingReader struct {
+	r      io.Reader
+	n      int64
+	onRead func(n int64)
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if n > 0 {
+		c.onRead(c.n)
+	}
+	return n, err
+}
@@ -0,0 +1,76 @@
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ProviderUsage is instance usage for one sandbox provider
+type ProviderUsage struct {
+	Provider string  `json:"provider"`
+	Count    int     `json:"count"`
+	Hours    float64 `json:"hours"`
+}
+
+// AgentUsage is task run usage for one agent
+type AgentUsage struct {
+	AgentName string  `json:"agentName"`
+	Runs      int     `json:"runs"`
+	Completed int     `json:"completed"`
+	Failed    int     `json:"failed"`
+	Hours     float64 `json:"hours"`
+}
+
+// Usage is a team's usage over a time window. From and To are Unix ms.
+type Usage struct {
+	From      int64 `json:"from"`
+	To        int64 `json:"to"`
+	Instances struct {
+		Count      int             `json:"count"`
+		Hours      float64         `json:"hours"`
+		ByProvider []ProviderUsage `json:"byProvider"`
+	} `json:"instances"`
+	TaskRuns struct {
+		Total     int     `json:"total"`
+		Completed int     `json:"completed"`
+		Failed    int     `json:"failed"`
+		Running   int     `json:"running"`
+		Hours     float64 `json:"hours"`
+	} `json:"taskRuns"`
+	Agents []AgentUsage `json:"agents"`
+}
+
+// GetUsage reports the team's instance hours, task runs, and per-agent usage
+// between from and to
+func (c *Client) GetUsage(ctx context.Context, from, to time.Time) (*Usage, error) {
+	if c.teamSlug == "" {
+		return nil, fmt.Errorf("team slug not set")
+	}
+
+	query := url.Values{}
+	query.Set("teamSlugOrId", c.teamSlug)
+	query.Set("from", strconv.FormatInt(from.UnixMilli(), 10))
+	query.Set("to", strconv.FormatInt(to.UnixMilli(), 10))
+
+	resp, err := c.doAPIRequest(ctx, "GET", "/usage?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var result Usage
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -23,6 +23,7 @@ const PORT = process.env.PORT || 39377;
 const VSCODE_PORT = Number(process.env.CMUX_VSCODE_PORT || 39378);
 const VNC_PORT = Number(process.env.CMUX_VNC_PORT || 39380);
 const PTY_PORT = Number(process.env.CMUX_PTY_PORT || 39379);
+const EXECD_PORT = Number(process.env.CMUX_EXECD_PORT || 39375);
 const AUTH_COOKIE_NAME = 'cmux_auth';
 const VNC_PREFIX = '/vnc';
 const CMUX_PREFIX = '/_cmux';
@@ -221,12 +222,20 @@ loadAuthConfig();
  * Run an agent-browser command and return the result
  * Uses CDP to connect to the existing Chrome on port 9222
  */
-async function runAgentBrowser(args) {
+async function runAgentBrowser(args, timeoutMs) {
   return new Promise((resolve, reject) => {
     const proc = spawn('agent-browser', [...args, '--cdp', '9222', '--json'], {
       env: { ...process.env, FORCE_COLOR: '0' },
     });
 
+    let timer = null;
+    if (timeoutMs) {
+      timer = setTimeout(() => {
+        proc.kill();
+        reject(new Error(`Timed out after ${timeoutMs}ms`));
+      }, timeoutMs);
+    }
+
     let stdout = '';
     let stderr = '';
 
@@ -239,6 +248,7 @@ async function runAgentBrowser(args) {
     });
 
     proc.on('close', (code) => {
+      if (timer) clearTimeout(timer);
       if (code !== 0) {
         reject(new Error(stderr || stdout || `Exit code ${code}`));
       } else {
@@ -256,6 +266,153 @@ async function runAgentBrowser(args) {
   });
 }
 
+const WAIT_POLL_INTERVAL_MS = 250;
+const WAIT_STATES = ['visible', 'hidden', 'attached', 'detached'];
+
+/**
+ * Report a CSS selector's state in the page: missing, hidden, or visible
+ */
+async function selectorState(selector) {
+  const script = `(() => {
+    const el = document.querySelector(${JSON.stringify(selector)});
+    if (!el) return 'missing';
+    const rect = el.getBoundingClientRect();
+    const style = getComputedStyle(el);
+    const shown = rect.width > 0 && rect.height > 0 &&
+      style.visibility !== 'hidden' && style.display !== 'none';
+    return shown ? 'visible' : 'hidden';
+  })()`;
+  const result = await runAgentBrowser(['eval', script]);
+  return result && result.data ? result.data.result : undefined;
+}
+
+function selectorMatchesState(current, state) {
+  switch (state) {
+    case 'visible':
+      return current === 'visible';
+    case 'hidden':
+      return current === 'hidden' || current === 'missing';
+    case 'attached':
+      return current === 'visible' || current === 'hidden';
+    case 'detached':
+      return current === 'missing';
+    default:
+      return false;
+  }
+}
+
+/**
+ * Wait until a selector reaches a state. Element refs (@e1) can only be
+ * waited on for visibility, via agent-browser itself.
+ */
+async function waitForSelector(selector, state, timeoutMs) {
+  const start = Date.now();
+  if (selector.startsWith('@')) {
+    if (state !== 'visible') {
+      throw new Error(`state ${state} requires a CSS selector, not a ref`);
+    }
+    await runAgentBrowser(['wait', selector], timeoutMs);
+    return { selector, state, elapsedMs: Date.now() - start };
+  }
+
+  for (;;) {
+    const current = await selectorState(selector);
+    if (selectorMatchesState(current, state)) {
+      return { selector, state, elapsedMs: Date.now() - start };
+    }
+    if (Date.now() - start >= timeoutMs) {
+      return null;
+    }
+    await new Promise((resolve) => setTimeout(resolve, WAIT_POLL_INTERVAL_MS));
+  }
+}
+
+const RECORDINGS_DIR = '/tmp/cmux-recordings';
+const RECORD_DISPLAY = process.env.DISPLAY || ':1';
+const RECORD_MAX_SECONDS = 3600;
+const RECORD_STOP_TIMEOUT_MS = 15000;
+const RECORD_FILE_PATTERN = /^rec-[0-9a-f]+\.(webm|mp4)$/;
+const RECORD_CODECS = {
+  webm: ['-c:v', 'libvpx', '-deadline', 'realtime', '-cpu-used', '8', '-b:v', '1M'],
+  mp4: ['-c:v', 'libx264', '-preset', 'ultrafast', '-crf', '26', '-pix_fmt', 'yuv420p', '-movflags', '+faststart'],
+};
+
+// Only one recording of the display runs at a time
+let activeRecording = null;
+
+/**
+ * Start recording the VNC display with ffmpeg. The recording stops by itself
+ * after maxSeconds so an abandoned recording can't fill the disk.
+ */
+function startRecording(format, fps, maxSeconds) {
+  fs.mkdirSync(RECORDINGS_DIR, { recursive: true });
+  const file = `rec-${crypto.randomBytes(6).toString('hex')}.${format}`;
+  const filePath = path.join(RECORDINGS_DIR, file);
+  const args = [
+    '-y', '-loglevel', 'error',
+    '-f', 'x11grab', '-framerate', String(fps), '-i', RECORD_DISPLAY,
+    '-t', String(maxSeconds),
+    ...RECORD_CODECS[format],
+    filePath,
+  ];
+  const proc = spawn('ffmpeg', args, {
+    env: { ...process.env, DISPLAY: RECORD_DISPLAY },
+    stdio: ['pipe', 'ignore', 'pipe'],
+  });
+
+  const recording = { proc, file, filePath, format, startedAt: Date.now(), stoppedAt: null, stderr: '' };
+  recording.done = new Promise((resolve) => {
+    proc.stderr.on('data', (data) => {
+      recording.stderr += data.toString();
+    });
+    proc.on('close', (code) => {
+      recording.stoppedAt = Date.now();
+      recording.exitCode = code;
+      resolve();
+    });
+    proc.on('error', (err) => {
+      recording.stoppedAt = Date.now();
+      recording.stderr += err.message;
+      resolve();
+    });
+  });
+  return recording;
+}
+
+/**
+ * Stop the active recording: ask ffmpeg to finish the file cleanly with "q",
+ * falling back to SIGKILL if it doesn't exit in time.
+ */
+async function stopRecording(recording) {
+  if (recording.stoppedAt === null) {
+    try {
+      recording.proc.stdin.write('q');
+      recording.proc.stdin.end();
+    } catch (e) {
+      recording.proc.kill('SIGINT');
+    }
+    const timer = setTimeout(() => recording.proc.kill('SIGKILL'), RECORD_STOP_TIMEOUT_MS);
+    await recording.done;
+    clearTimeout(timer);
+  }
+
+  let bytes = 0;
+  try {
+    bytes = fs.statSync(recording.filePath).size;
+  } catch (e) {
+    // ffmpeg never produced output
+  }
+  if (bytes === 0) {
+    throw new Error(`recording failed: ${recording.stderr.trim() || `ffmpeg exited with code ${recording.exitCode}`}`);
+  }
+  return {
+    file: recording.file,
+    format: recording.format,
+    bytes,
+    durationMs: recording.stoppedAt - recording.startedAt,
+  };
+}
+
 /**
  * Parse JSON body from request
  */
@@ -327,6 +484,9 @@ function isApiPath(pathname) {
     '/title',
     '/wait',
     '/eval',
+    '/record/start',
+    '/record/stop',
+    '/record/download',
   ].includes(pathname);
 }
 
@@ -366,10 +526,17 @@ function proxyHttp(req, res, targetHost, targetPort, upstreamPath) {
     },
     (proxyRes) => {
       res.writeHead(proxyRes.statusCode || 502, proxyRes.headers);
+      // Forward headers right away, e.g. execd's job ID before a quiet
+      // command prints anything
+      res.flushHeaders();
       proxyRes.pipe(res);
     }
   );
 
+  // Abort the upstream request when the client goes away so streaming
+  // work (e.g. a running exec) is canceled too
+  res.on('close', () => proxyReq.destroy());
+
   proxyReq.on('error', (err) => {
     console.error('Proxy error:', err.message);
     if (!res.headersSent) {
@@ -485,8 +652,16 @@ async function handleRequest(req, res) {
           break;
 
         case '/click':
+          // Coordinates click at a viewport position, e.g. on a canvas
+          if (typeof body.x === 'number' && typeof body.y === 'number') {
+            const button = body.button || 'left';
+            await runAgentBrowser(['mouse', 'move', String(body.x), String(body.y)]);
+            await runAgentBrowser(['mouse', 'down', button]);
+            result = await runAgentBrowser(['mouse', 'up', button]);
+            break;
+          }
           if (!body.selector) {
-            sendJson(res, { error: 'selector required' }, 400);
+            sendJson(res, { error: 'selector or x and y required' }, 400);
             return;
           }
           result = await runAgentBrowser(['click', body.selector]);
@@ -578,13 +753,25 @@ async function handleRequest(req, res) {
           result = await runAgentBrowser(['get', 'title']);
           break;
 
-        case '/wait':
+        case '/wait': {
           if (!body.selector) {
             sendJson(res, { error: 'selector required' }, 400);
             return;
           }
-          result = await runAgentBrowser(['wait', body.selector]);
+          const state = body.state || 'visible';
+          if (!WAIT_STATES.includes(state)) {
+            sendJson(res, { error: `state must be one of: ${WAIT_STATES.join(', ')}` }, 400);
+            return;
+          }
+          const timeoutMs = Number(body.timeout) > 0 ? Number(body.timeout) : 30000;
+          const waited = await waitForSelector(body.selector, state, timeoutMs);
+          if (!waited) {
+            sendJson(res, { error: `Timed out after ${timeoutMs}ms waiting for ${body.selector} to be ${state}` }, 408);
+            return;
+          }
+          result = { success: true, data: waited };
           break;
+        }
 
         case '/eval':
           if (!body.script) {
@@ -594,6 +781,68 @@ async function handleRequest(req, res) {
           result = await runAgentBrowser(['eval', body.script]);
           break;
 
+        case '/record/start': {
+          if (activeRecording && activeRecording.stoppedAt === null) {
+            sendJson(res, { error: 'a recording is already in progress; stop it first' }, 409);
+            return;
+          }
+          const format = body.format || 'webm';
+          if (!RECORD_CODECS[format]) {
+            sendJson(res, { error: `format must be one of: ${Object.keys(RECORD_CODECS).join(', ')}` }, 400);
+            return;
+          }
+          const fps = Number(body.fps) > 0 ? Math.min(Number(body.fps), 60) : 15;
+          const maxSeconds = Number(body.maxSeconds) > 0
+            ? Math.min(Number(body.maxSeconds), RECORD_MAX_SECONDS)
+            : RECORD_MAX_SECONDS;
+          activeRecording = startRecording(format, fps, maxSeconds);
+          result = {
+            success: true,
+            data: { file: activeRecording.file, format, fps, maxSeconds },
+          };
+          break;
+        }
+
+        case '/record/stop': {
+          if (!activeRecording) {
+            sendJson(res, { error: 'no recording in progress' }, 409);
+            return;
+          }
+          const recording = activeRecording;
+          activeRecording = null;
+          result = { success: true, data: await stopRecording(recording) };
+          break;
+        }
+
+        case '/record/download': {
+          // Streams the finished file; cleanup=1 deletes it once sent
+          const file = url.searchParams.get('file') || '';
+          if (!RECORD_FILE_PATTERN.test(file)) {
+            sendJson(res, { error: 'invalid recording file' }, 400);
+            return;
+          }
+          const filePath = path.join(RECORDINGS_DIR, file);
+          let stat;
+          try {
+            stat = fs.statSync(filePath);
+          } catch (e) {
+            sendJson(res, { error: 'recording not found' }, 404);
+            return;
+          }
+          res.writeHead(200, {
+            'Content-Type': file.endsWith('.mp4') ? 'video/mp4' : 'video/webm',
+            'Content-Length': stat.size,
+          });
+          const stream = fs.createReadStream(filePath);
+          stream.on('end', () => {
+            if (url.searchParams.get('cleanup') === '1') {
+              fs.unlink(filePath, () => {});
+            }
+          });
+          stream.pipe(res);
+          return;
+        }
+
         default:
           sendJson(res, { error: 'Not found' }, 404);
           return;
@@ -628,6 +877,13 @@ async function handleRequest(req, res) {
         return;
       }
 
+      // /_cmux/exec/* - Proxy to execd (streams NDJSON command output)
+      if (reqPath.startsWith('/_cmux/exec/')) {
+        const execPath = reqPath.slice('/_cmux/exec'.length) + url.search;
+        proxyHttp(req, res, '127.0.0.1', EXECD_PORT, execPath);
+        return;
+      }
+
       sendJson(res, { error: 'Not found' }, 404);
       return;
     }
@@ -0,0 +1,75 @@
+import { describe, expect, test } from "vitest";
+import { envExportLine, parseDockerfile, shellQuote } from "./dockerfile";
+
+describe("parseDockerfile", () => {
+  test("parses RUN, ENV, ARG, and WORKDIR steps", () => {
+    const parsed = parseDockerfile(`
+# toolchain
+FROM ubuntu:22.04
+ARG RUST_VERSION=1.80
+ENV PATH="/root/.cargo/bin:$PATH" LANG=C.UTF-8
+WORKDIR /opt/tools
+RUN apt-get update && \\
+    apt-get install -y \\
+      build-essential
+RUN ["echo", "it's done"]
+`);
+
+    expect(parsed.from).toBe("ubuntu:22.04");
+    expect(parsed.steps).toEqual([
+      { kind: "env", vars: { RUST_VERSION: "1.80" }, persist: false },
+      {
+        kind: "env",
+        vars: { PATH: "/root/.cargo/bin:$PATH", LANG: "C.UTF-8" },
+        persist: true,
+      },
+      { kind: "workdir", path: "/opt/tools" },
+      {
+        kind: "run",
+        command: "apt-get update &&  apt-get install -y  build-essential",
+      },
+      { kind: "run", command: `'echo' 'it'\\''s done'` },
+    ]);
+    expect(parsed.warnings).toEqual([
+      "FROM ubuntu:22.04 is ignored: templates are built on the cloudrouter base image",
+    ]);
+  });
+
+  test("supports legacy ENV syntax and warns on ignored instructions", () => {
+    const parsed = parseDockerfile("ENV GREETING hello world\nCMD [\"bash\"]\nRUN true");
+    expect(parsed.steps[0]).toEqual({
+      kind: "env",
+      vars: { GREETING: "hello world" },
+      persist: true,
+    });
+    expect(parsed.warnings).toEqual(["CMD is ignored"]);
+  });
+
+  test("rejects COPY, multi-stage builds, and files without RUN", () => {
+    expect(() => parseDockerfile("COPY . /app\nRUN true")).toThrowError(
+      /COPY isn't supported/,
+    );
+    expect(() =>
+      parseDockerfile("FROM a AS build\nRUN true\nFROM b\nRUN true"),
+    ).toThrowError("Multi-stage builds aren't supported");
+    expect(() => parseDockerfile("FROM ubuntu\nENV A=1")).toThrowError(
+      "Dockerfile has no RUN instructions",
+    );
+  });
+});
+
+describe("envExportLine", () => {
+  test("keeps variable references and escapes quotes", () => {
+    expect(envExportLine("PATH", "/opt/bin:$PATH")).toBe(
+      'export PATH="/opt/bin:$PATH"',
+    );
+    expect(envExportLine("MSG", 'say "hi"')).toBe('export MSG="say \\"hi\\""');
+  });
+});
+
+describe("shellQuote", () => {
+  test("wraps values in single quotes", () => {
+    expect(shellQuote("a b")).toBe("'a b'");
+    expect(shellQuote("it's")).toBe(`'it'\\''s'`);
+  });
+});
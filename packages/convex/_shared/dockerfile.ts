@@ -0,0 +1,242 @@
+/**
+ * Minimal Dockerfile parser for cloudrouter custom templates.
+ *
+ * Templates are built by replaying a Dockerfile's instructions inside a
+ * sandbox booted from the cloudrouter base image, so only instructions that
+ * make sense without a build context are supported.
+ */
+
+export type DockerfileStep =
+  | { kind: "run"; command: string }
+  | { kind: "env"; vars: Record<string, string>; persist: boolean }
+  | { kind: "workdir"; path: string };
+
+export interface ParsedDockerfile {
+  from?: string;
+  steps: DockerfileStep[];
+  warnings: string[];
+}
+
+const IGNORED_INSTRUCTIONS = new Set([
+  "CMD",
+  "ENTRYPOINT",
+  "EXPOSE",
+  "HEALTHCHECK",
+  "LABEL",
+  "MAINTAINER",
+  "ONBUILD",
+  "SHELL",
+  "STOPSIGNAL",
+  "USER",
+  "VOLUME",
+]);
+
+/**
+ * Quote a string for safe use as a single POSIX shell word.
+ */
+export function shellQuote(value: string): string {
+  return `'${value.replace(/'/g, `'\\''`)}'`;
+}
+
+/**
+ * Render an `export` line for an ENV/ARG value. Like Docker, `$VAR`
+ * references in the value are expanded.
+ */
+export function envExportLine(key: string, value: string): string {
+  return `export ${key}="${value.replace(/(["\\`])/g, "\\$1")}"`;
+}
+
+/**
+ * Split logical lines: drop comments and blank lines, join backslash
+ * continuations.
+ */
+function logicalLines(source: string): string[] {
+  const lines: string[] = [];
+  let current = "";
+  for (const raw of source.split(/\r?\n/)) {
+    const trimmed = raw.trim();
+    if (current === "" && (trimmed === "" || trimmed.startsWith("#"))) {
+      continue;
+    }
+    if (trimmed.startsWith("#")) {
+      continue; // Comment inside a continuation
+    }
+    if (trimmed.endsWith("\\")) {
+      current += trimmed.slice(0, -1) + " ";
+      continue;
+    }
+    current += trimmed;
+    if (current.trim() !== "") {
+      lines.push(current.trim());
+    }
+    current = "";
+  }
+  if (current.trim() !== "") {
+    lines.push(current.trim());
+  }
+  return lines;
+}
+
+/**
+ * Split a string into words, honoring single/double quotes and backslashes.
+ */
+function splitWords(input: string): string[] {
+  const words: string[] = [];
+  let word = "";
+  let inWord = false;
+  let quote: '"' | "'" | null = null;
+  for (let i = 0; i < input.length; i++) {
+    const ch = input[i];
+    if (quote) {
+      if (ch === quote) {
+        quote = null;
+      } else if (ch === "\\" && quote === '"' && i + 1 < input.length) {
+        word += input[++i];
+      } else {
+        word += ch;
+      }
+      continue;
+    }
+    if (ch === '"' || ch === "'") {
+      quote = ch;
+      inWord = true;
+    } else if (ch === "\\" && i + 1 < input.length) {
+      word += input[++i];
+      inWord = true;
+    } else if (/\s/.test(ch)) {
+      if (inWord) {
+        words.push(word);
+        word = "";
+        inWord = false;
+      }
+    } else {
+      word += ch;
+      inWord = true;
+    }
+  }
+  if (quote) {
+    throw new Error(`Unterminated quote in: ${input}`);
+  }
+  if (inWord) {
+    words.push(word);
+  }
+  return words;
+}
+
+/**
+ * Parse `KEY=value KEY2="v 2"` (or legacy `KEY value`) into a map.
+ */
+function parseKeyValues(instruction: string, args: string): Record<string, string> {
+  const vars: Record<string, string> = {};
+  const words = splitWords(args);
+  if (words.length === 0) {
+    throw new Error(`${instruction} requires at least one variable`);
+  }
+  if (!words[0].includes("=")) {
+    if (instruction === "ARG") {
+      // `ARG NAME` without a default declares an empty build arg.
+      vars[words[0]] = "";
+      return vars;
+    }
+    const [key, ...rest] = args.trim().split(/\s+/);
+    vars[key] = rest.join(" ");
+    return vars;
+  }
+  for (const word of words) {
+    const eq = word.indexOf("=");
+    if (eq <= 0) {
+      throw new Error(`Invalid ${instruction} entry: ${word}`);
+    }
+    vars[word.slice(0, eq)] = word.slice(eq + 1);
+  }
+  for (const key of Object.keys(vars)) {
+    if (!/^[A-Za-z_][A-Za-z0-9_]*$/.test(key)) {
+      throw new Error(`Invalid ${instruction} name: ${key}`);
+    }
+  }
+  return vars;
+}
+
+/**
+ * Parse a Dockerfile into build steps. Throws on unsupported instructions
+ * (COPY/ADD, multi-stage builds) with a user-facing message.
+ */
+export function parseDockerfile(source: string): ParsedDockerfile {
+  const result: ParsedDockerfile = { steps: [], warnings: [] };
+
+  for (const line of logicalLines(source)) {
+    const match = line.match(/^(\S+)\s*(.*)$/);
+    if (!match) continue;
+    const instruction = match[1].toUpperCase();
+    const args = match[2];
+
+    switch (instruction) {
+      case "FROM":
+        if (result.from !== undefined) {
+          throw new Error("Multi-stage builds aren't supported");
+        }
+        result.from = args;
+        break;
+
+      case "RUN": {
+        let command = args;
+        if (args.startsWith("[")) {
+          let argv: unknown;
+          try {
+            argv = JSON.parse(args);
+          } catch {
+            throw new Error(`Invalid RUN exec form: ${args}`);
+          }
+          if (!Array.isArray(argv) || !argv.every((a) => typeof a === "string")) {
+            throw new Error(`Invalid RUN exec form: ${args}`);
+          }
+          command = argv.map(shellQuote).join(" ");
+        }
+        if (command.startsWith("--")) {
+          throw new Error(`RUN flags aren't supported: ${args}`);
+        }
+        result.steps.push({ kind: "run", command });
+        break;
+      }
+
+      case "ENV":
+      case "ARG":
+        result.steps.push({
+          kind: "env",
+          vars: parseKeyValues(instruction, args),
+          persist: instruction === "ENV",
+        });
+        break;
+
+      case "WORKDIR":
+        if (!args) {
+          throw new Error("WORKDIR requires a path");
+        }
+        result.steps.push({ kind: "workdir", path: args });
+        break;
+
+      case "COPY":
+      case "ADD":
+        throw new Error(
+          `${instruction} isn't supported: templates are built without a build context. Fetch files in a RUN step instead (e.g. curl or git clone)`,
+        );
+
+      default:
+        if (IGNORED_INSTRUCTIONS.has(instruction)) {
+          result.warnings.push(`${instruction} is ignored`);
+          break;
+        }
+        throw new Error(`Unknown Dockerfile instruction: ${instruction}`);
+    }
+  }
+
+  if (!result.steps.some((step) => step.kind === "run")) {
+    throw new Error("Dockerfile has no RUN instructions");
+  }
+  if (result.from !== undefined) {
+    result.warnings.push(
+      `FROM ${result.from} is ignored: templates are built on the cloudrouter base image`,
+    );
+  }
+  return result;
+}
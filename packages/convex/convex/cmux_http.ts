@@ -7,7 +7,9 @@
 import { httpAction, type ActionCtx } from "./_generated/server";
 import { api, internal } from "./_generated/api";
 import { env } from "../_shared/convex-env";
+import type { Id } from "./_generated/dataModel";
 import type { FunctionReference } from "convex/server";
+import { stringToBase64 } from "../_shared/encoding";
 
 const MORPH_API_BASE_URL = "https://cloud.morph.so/api";
 
@@ -146,6 +148,14 @@ const devboxInternalApi = (internal as any).devboxInstances as {
   getInfo: FunctionReference<"query", "internal">;
 };
 
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+const teamSecretsApi = (api as any).teamSecrets as {
+  list: FunctionReference<"query", "public">;
+  resolve: FunctionReference<"query", "public">;
+  upsert: FunctionReference<"mutation", "public">;
+  remove: FunctionReference<"mutation", "public">;
+};
+
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
 const morphInstancesApi = (internal as any).morphInstances as {
   recordResumeInternal: FunctionReference<"mutation", "internal">;
@@ -213,6 +223,7 @@ export const createInstance = httpAction(async (ctx, req) => {
     memory?: number;
     diskSize?: number;
     metadata?: Record<string, string>;
+    secrets?: string[];
   };
 
   try {
@@ -236,6 +247,26 @@ export const createInstance = httpAction(async (ctx, req) => {
     );
   }
 
+  // Resolve requested secrets before booting so a typo doesn't leak a VM
+  let secretValues: Record<string, string> = {};
+  if (body.secrets && body.secrets.length > 0) {
+    const invalid = body.secrets.filter((name) => !SECRET_NAME_REGEX.test(name));
+    if (invalid.length > 0) {
+      return jsonResponse(
+        { code: 400, message: `Invalid secret names: ${invalid.join(", ")}` },
+        400
+      );
+    }
+    const resolved = await resolveSecrets(ctx, body.teamSlugOrId, body.secrets);
+    if (resolved.missing.length > 0) {
+      return jsonResponse(
+        { code: 400, message: `Secrets not found: ${resolved.missing.join(", ")}` },
+        400
+      );
+    }
+    secretValues = resolved.values;
+  }
+
   try {
     const snapshotId = body.snapshotId ?? DEFAULT_CMUX_SNAPSHOT_ID;
     const startTime = Date.now();
@@ -403,6 +434,36 @@ export const createInstance = httpAction(async (ctx, req) => {
       console.warn("[cmux.create] NEXT_PUBLIC_STACK_PROJECT_ID not set, worker auth will be disabled");
     }
 
+    // Inject requested secrets as environment variables via envctl
+    if (Object.keys(secretValues).length > 0) {
+      const secretsStart = Date.now();
+      try {
+        const response = await morphFetch(`/instance/${morphData.id}/exec`, {
+          method: "POST",
+          body: JSON.stringify({
+            command: ["envctl", "load", "--base64", stringToBase64(formatSecretsEnv(secretValues))],
+            timeout: 10,
+          }),
+        });
+        const result = response.ok
+          ? ((await response.json()) as { exit_code?: number })
+          : null;
+        if (!result || result.exit_code !== 0) {
+          throw new Error(`envctl failed (${response.status}, exit ${result?.exit_code ?? "unknown"})`);
+        }
+      } catch (e) {
+        // Never log secret values, only which step failed
+        console.error("[cmux.create] Failed to inject secrets:", e);
+        await cleanupMorphInstance(`Secret injection failed: ${e instanceof Error ? e.message : "unknown error"}`);
+        return jsonResponse(
+          { code: 500, message: "Failed to inject secrets" },
+          500
+        );
+      }
+      timings.injectSecrets = Date.now() - secretsStart;
+      console.log(`[cmux.create] Injected ${Object.keys(secretValues).length} secrets in ${timings.injectSecrets}ms`);
+    }
+
     // Store the instance in Convex with provider mapping (no URL caching)
     console.log("[cmux.create] Storing in Convex...");
     const convexStart = Date.now();
@@ -447,6 +508,28 @@ export const createInstance = httpAction(async (ctx, req) => {
   }
 });
 
+/**
+ * Get when a Morph instance's TTL expires (ms since epoch) and what happens then.
+ * Returns null if the instance has no TTL or Morph can't be reached.
+ */
+async function getMorphInstanceTtl(
+  providerInstanceId: string
+): Promise<{ expiresAt: number; action?: string } | null> {
+  try {
+    const response = await morphFetch(`/instance/${providerInstanceId}`);
+    if (!response.ok) return null;
+    const data = (await response.json()) as {
+      ttl?: { ttl_expire_at?: number | null; ttl_action?: string | null };
+    };
+    const expireAt = data.ttl?.ttl_expire_at;
+    if (!expireAt) return null;
+    return { expiresAt: expireAt * 1000, action: data.ttl?.ttl_action ?? undefined };
+  } catch (error) {
+    console.error("[cmux.list] Failed to get TTL:", error);
+    return null;
+  }
+}
+
 // ============================================================================
 // GET /api/v1/cmux/instances - List instances
 // ============================================================================
@@ -464,9 +547,12 @@ export const listInstances = httpAction(async (ctx, req) => {
     );
   }
 
+  const includeStopped = url.searchParams.get("includeStopped") === "true";
+
   try {
     const rawInstances = await ctx.runQuery(devboxApi.list, {
       teamSlugOrId,
+      ...(includeStopped ? { includeStoppedAfter: 0 } : {}),
     }) as Array<{
       devboxId: string;
       status: string;
@@ -475,14 +561,31 @@ export const listInstances = httpAction(async (ctx, req) => {
       updatedAt: number;
     }>;
 
-    // Return basic instance info with id field (URLs are fetched via GET /instances/{id})
-    const instances = rawInstances.map((inst) => ({
-      id: inst.devboxId,
-      status: inst.status,
-      name: inst.name,
-      createdAt: inst.createdAt,
-      updatedAt: inst.updatedAt,
-    }));
+    // Return basic instance info with id field (URLs are fetched via GET /instances/{id}).
+    // Provider and TTL are looked up in parallel so the CLI can show them per row.
+    const instances = await Promise.all(
+      rawInstances.map(async (inst) => {
+        const info = await ctx.runQuery(devboxInternalApi.getInfo, {
+          devboxId: inst.devboxId,
+        }) as { provider: string; providerInstanceId: string } | null;
+
+        const ttl =
+          info?.provider === "morph" && inst.status !== "stopped"
+            ? await getMorphInstanceTtl(info.providerInstanceId)
+            : null;
+
+        return {
+          id: inst.devboxId,
+          status: inst.status,
+          name: inst.name,
+          provider: info?.provider,
+          ttlExpiresAt: ttl?.expiresAt,
+          ttlAction: ttl?.action,
+          createdAt: inst.createdAt,
+          updatedAt: inst.updatedAt,
+        };
+      })
+    );
 
     return jsonResponse({ instances });
   } catch (error) {
@@ -551,6 +654,7 @@ async function handleGetInstance(
         memory?: number;
         disk_size?: number;
       };
+      ttl?: { ttl_expire_at?: number | null; ttl_action?: string | null };
     };
 
     // Map Morph status to our status
@@ -580,12 +684,17 @@ async function handleGetInstance(
 
     return jsonResponse({
       id,
+      morphInstanceId: providerInstanceId,
       status,
       name: instance.name,
       vscodeUrl: proxyUrls.vscodeUrl,
       workerUrl,
       vncUrl: proxyUrls.vncUrl,
       spec: morphData.spec,
+      ttlExpiresAt: morphData.ttl?.ttl_expire_at
+        ? morphData.ttl.ttl_expire_at * 1000
+        : undefined,
+      ttlAction: morphData.ttl?.ttl_action ?? undefined,
     });
   } catch (error) {
     console.error("[cmux.get] Error:", error);
@@ -1377,6 +1486,116 @@ export const getMe = httpAction(async (ctx) => {
   }
 });
 
+// ============================================================================
+// POST /api/v1/cmux/auth/api-key - Exchange a user API key for an access token
+// ============================================================================
+// Lets CI authenticate without the browser-based CLI login. The key is a
+// Stack Auth user API key; it's verified server-side and a short-lived
+// session is created for its owner.
+const STACK_AUTH_API_URL = "https://api.stack-auth.com";
+const API_KEY_SESSION_TTL_MS = 60 * 60 * 1000;
+
+export const exchangeApiKey = httpAction(async (_ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  let body: { apiKey?: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+  if (typeof body.apiKey !== "string" || body.apiKey.trim() === "") {
+    return jsonResponse({ code: 400, message: "apiKey is required" }, 400);
+  }
+
+  const projectId = env.NEXT_PUBLIC_STACK_PROJECT_ID;
+  const secretServerKey = env.STACK_SECRET_SERVER_KEY;
+  if (!projectId || !secretServerKey) {
+    console.error("[cmux.auth.apiKey] Stack Auth server key not configured");
+    return jsonResponse(
+      { code: 503, message: "API key authentication is not configured" },
+      503
+    );
+  }
+
+  const stackHeaders = {
+    "Content-Type": "application/json",
+    "x-stack-project-id": projectId,
+    "x-stack-secret-server-key": secretServerKey,
+    "x-stack-access-type": "server",
+  };
+
+  try {
+    const checkResponse = await fetch(
+      `${STACK_AUTH_API_URL}/api/v1/user-api-keys/check`,
+      {
+        method: "POST",
+        headers: stackHeaders,
+        body: JSON.stringify({ api_key: body.apiKey.trim() }),
+      }
+    );
+    if (!checkResponse.ok) {
+      return jsonResponse({ code: 401, message: "Invalid API key" }, 401);
+    }
+    const key = (await checkResponse.json()) as {
+      user_id?: string;
+      manually_revoked_at_millis?: number | null;
+      expires_at_millis?: number | null;
+    };
+    if (
+      !key.user_id ||
+      key.manually_revoked_at_millis ||
+      (key.expires_at_millis && key.expires_at_millis < Date.now())
+    ) {
+      return jsonResponse({ code: 401, message: "Invalid API key" }, 401);
+    }
+
+    const sessionResponse = await fetch(
+      `${STACK_AUTH_API_URL}/api/v1/auth/sessions`,
+      {
+        method: "POST",
+        headers: stackHeaders,
+        body: JSON.stringify({
+          user_id: key.user_id,
+          expires_in_millis: API_KEY_SESSION_TTL_MS,
+        }),
+      }
+    );
+    if (!sessionResponse.ok) {
+      const errorText = await sessionResponse.text();
+      console.error("[cmux.auth.apiKey] Failed to create session:", {
+        status: sessionResponse.status,
+        body: errorText.slice(0, 500),
+      });
+      return jsonResponse(
+        { code: 502, message: "Failed to create session" },
+        502
+      );
+    }
+    const session = (await sessionResponse.json()) as {
+      access_token?: string;
+    };
+    if (!session.access_token) {
+      return jsonResponse(
+        { code: 502, message: "Failed to create session" },
+        502
+      );
+    }
+
+    return jsonResponse({
+      accessToken: session.access_token,
+      expiresAt: Math.floor((Date.now() + API_KEY_SESSION_TTL_MS) / 1000),
+    });
+  } catch (err) {
+    console.error("[cmux.auth.apiKey] Error:", err);
+    return jsonResponse(
+      { code: 500, message: "Failed to exchange API key" },
+      500
+    );
+  }
+});
+
 // ============================================================================
 // Route handler for instance-specific POST actions
 // ============================================================================
@@ -1582,3 +1801,629 @@ export const instanceDeleteRouter = httpAction(async (ctx, req) => {
 
   return jsonResponse({ code: 404, message: "Not found" }, 404);
 });
+
+// ============================================================================
+// GET /api/v1/cmux/task-runs/{id} - Get task run status and terminal server
+// ============================================================================
+const TASK_RUN_ID_REGEX = /^[a-z0-9]{16,}$/;
+const MORPH_PORT_HOST_REGEX = /^port-\d+-(morphvm-[^.]+\.http\.cloud\.morph\.so)$/;
+const PTY_SERVER_PORT = 39383;
+const VNC_PORT = 39380;
+
+/**
+ * Derive the URL of another port on the same Morph VM as a task run's
+ * workspace URL. Only Morph-hosted workspaces expose their ports publicly.
+ */
+function toMorphPortUrl(
+  workspaceUrl: string | undefined,
+  port: number
+): string | undefined {
+  if (!workspaceUrl) return undefined;
+  try {
+    const url = new URL(workspaceUrl);
+    const match = url.hostname.match(MORPH_PORT_HOST_REGEX);
+    if (!match) return undefined;
+    return `https://port-${port}-${match[1]}`;
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Derive the noVNC desktop URL for a task run's VM.
+ */
+function toMorphVncUrl(workspaceUrl: string | undefined): string | undefined {
+  const baseUrl = toMorphPortUrl(workspaceUrl, VNC_PORT);
+  if (!baseUrl) return undefined;
+  return `${baseUrl}/vnc.html?autoconnect=1&resize=scale&reconnect=1&reconnect_delay=1000`;
+}
+
+export const getTaskRun = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  // Parse path: /api/v1/cmux/task-runs/{id}
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  const id = pathParts[4];
+
+  if (!id || !TASK_RUN_ID_REGEX.test(id)) {
+    return jsonResponse(
+      { code: 400, message: "Invalid task run ID format" },
+      400
+    );
+  }
+
+  try {
+    const taskRun = await ctx.runQuery(api.taskRuns.get, {
+      teamSlugOrId,
+      id: id as Id<"taskRuns">,
+    });
+
+    if (!taskRun) {
+      return jsonResponse({ code: 404, message: "Task run not found" }, 404);
+    }
+
+    return jsonResponse({
+      id,
+      status: taskRun.status,
+      agentName: taskRun.agentName,
+      exitCode: taskRun.exitCode,
+      errorMessage: taskRun.errorMessage,
+      ptyUrl: toMorphPortUrl(
+        taskRun.vscode?.url ?? taskRun.vscode?.workspaceUrl,
+        PTY_SERVER_PORT
+      ),
+    });
+  } catch (err) {
+    console.error("[cmux.task-runs.get] Error:", err);
+    return jsonResponse({ code: 500, message: "Failed to get task run" }, 500);
+  }
+});
+
+// ============================================================================
+// Tasks - /api/v1/cmux/tasks
+// ============================================================================
+const TASK_ID_REGEX = /^[a-z0-9]{16,}$/;
+const DEFAULT_TASK_LIST_LIMIT = 50;
+const MAX_TASK_LIST_LIMIT = 200;
+
+type CliTaskRun = {
+  _id: string;
+  parentRunId?: string;
+  agentName?: string;
+  status: string;
+  isCrowned?: boolean;
+  isArchived?: boolean;
+  exitCode?: number;
+  errorMessage?: string;
+  newBranch?: string;
+  pullRequestUrl?: string;
+  vscode?: { url?: string; workspaceUrl?: string };
+  createdAt: number;
+  completedAt?: number;
+  children: CliTaskRun[];
+};
+
+type CliTask = {
+  _id: string;
+  text: string;
+  projectFullName?: string;
+  baseBranch?: string;
+  pinned?: boolean;
+  isArchived?: boolean;
+  isCompleted: boolean;
+  mergeStatus?: string;
+  createdAt?: number;
+  lastActivityAt?: number;
+  hasUnread?: boolean;
+};
+
+function flattenTaskRuns(runs: CliTaskRun[]): CliTaskRun[] {
+  return runs.flatMap((run) => [run, ...flattenTaskRuns(run.children)]);
+}
+
+/**
+ * Summarize a task's state from its runs: running while any run is active,
+ * otherwise completed if any run succeeded.
+ */
+function summarizeTaskStatus(runs: CliTaskRun[]): string {
+  if (runs.some((run) => run.status === "pending" || run.status === "running")) {
+    return "running";
+  }
+  if (runs.some((run) => run.status === "completed")) return "completed";
+  if (runs.some((run) => run.status === "failed")) return "failed";
+  return "pending";
+}
+
+function serializeTask(task: CliTask, runs: CliTaskRun[]) {
+  const agents = Array.from(
+    new Set(runs.map((run) => run.agentName).filter((name) => name))
+  );
+  return {
+    id: task._id,
+    text: task.text,
+    status: summarizeTaskStatus(runs),
+    agents,
+    repo: task.projectFullName,
+    baseBranch: task.baseBranch,
+    pinned: task.pinned ?? false,
+    archived: task.isArchived ?? false,
+    unread: task.hasUnread ?? false,
+    mergeStatus: task.mergeStatus,
+    createdAt: task.createdAt,
+    lastActivityAt: task.lastActivityAt,
+  };
+}
+
+function serializeTaskRun(run: CliTaskRun) {
+  const vmUrl = run.vscode?.url ?? run.vscode?.workspaceUrl;
+  return {
+    id: run._id,
+    parentRunId: run.parentRunId,
+    agentName: run.agentName,
+    status: run.status,
+    crowned: run.isCrowned ?? false,
+    archived: run.isArchived ?? false,
+    exitCode: run.exitCode,
+    errorMessage: run.errorMessage,
+    branch: run.newBranch,
+    pullRequestUrl: run.pullRequestUrl,
+    vscodeUrl: run.vscode?.workspaceUrl ?? run.vscode?.url,
+    ptyUrl: toMorphPortUrl(vmUrl, PTY_SERVER_PORT),
+    vncUrl: toMorphVncUrl(vmUrl),
+    createdAt: run.createdAt,
+    completedAt: run.completedAt,
+  };
+}
+
+async function getTaskRunsForTask(
+  ctx: ActionCtx,
+  teamSlugOrId: string,
+  taskId: string
+): Promise<CliTaskRun[]> {
+  const runs = (await ctx.runQuery(api.taskRuns.getByTask, {
+    teamSlugOrId,
+    taskId: taskId as Id<"tasks">,
+  })) as unknown as CliTaskRun[];
+  return flattenTaskRuns(runs);
+}
+
+// ============================================================================
+// GET /api/v1/cmux/tasks - List tasks with a status summary
+// ============================================================================
+export const listTasks = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  const archived = url.searchParams.get("archived") === "true";
+  const requestedLimit = Number(url.searchParams.get("limit"));
+  const limit =
+    Number.isInteger(requestedLimit) && requestedLimit > 0
+      ? Math.min(requestedLimit, MAX_TASK_LIST_LIMIT)
+      : DEFAULT_TASK_LIST_LIMIT;
+
+  try {
+    const tasks = (await ctx.runQuery(api.tasks.get, {
+      teamSlugOrId,
+      archived,
+    })) as unknown as CliTask[];
+
+    const result = await Promise.all(
+      tasks.slice(0, limit).map(async (task) =>
+        serializeTask(
+          task,
+          await getTaskRunsForTask(ctx, teamSlugOrId, task._id)
+        )
+      )
+    );
+
+    return jsonResponse({ tasks: result });
+  } catch (err) {
+    console.error("[cmux.tasks.list] Error:", err);
+    return jsonResponse({ code: 500, message: "Failed to list tasks" }, 500);
+  }
+});
+
+// ============================================================================
+// GET /api/v1/cmux/tasks/{id} - Get a task with all of its runs
+// ============================================================================
+export const taskGetRouter = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  // Parse path: /api/v1/cmux/tasks/{id}
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  const id = pathParts[4];
+
+  if (!id || !TASK_ID_REGEX.test(id)) {
+    return jsonResponse({ code: 400, message: "Invalid task ID format" }, 400);
+  }
+
+  try {
+    const task = (await ctx.runQuery(api.tasks.getById, {
+      teamSlugOrId,
+      id: id as Id<"tasks">,
+    })) as unknown as CliTask | null;
+
+    if (!task) {
+      return jsonResponse({ code: 404, message: "Task not found" }, 404);
+    }
+
+    const runs = await getTaskRunsForTask(ctx, teamSlugOrId, id);
+    return jsonResponse({
+      ...serializeTask(task, runs),
+      runs: runs.map(serializeTaskRun),
+    });
+  } catch (err) {
+    console.error("[cmux.tasks.get] Error:", err);
+    return jsonResponse({ code: 500, message: "Failed to get task" }, 500);
+  }
+});
+
+// ============================================================================
+// POST /api/v1/cmux/tasks/{id}/{archive|unarchive|pin|unpin}
+// ============================================================================
+export const taskActionRouter = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  // pathParts: ["api", "v1", "cmux", "tasks", "{id}", "{action}"]
+  const id = pathParts[4];
+  const action = pathParts[5];
+
+  if (!id || !TASK_ID_REGEX.test(id)) {
+    return jsonResponse({ code: 400, message: "Invalid task ID format" }, 400);
+  }
+
+  let body: { teamSlugOrId?: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+
+  const mutations = {
+    archive: api.tasks.archive,
+    unarchive: api.tasks.unarchive,
+    pin: api.tasks.pin,
+    unpin: api.tasks.unpin,
+  };
+  if (!action || !(action in mutations)) {
+    return jsonResponse({ code: 404, message: "Not found" }, 404);
+  }
+
+  try {
+    await ctx.runMutation(mutations[action as keyof typeof mutations], {
+      teamSlugOrId: body.teamSlugOrId,
+      id: id as Id<"tasks">,
+    });
+    return jsonResponse({ id, action, success: true });
+  } catch (err) {
+    console.error(`[cmux.tasks.${action}] Error:`, err);
+    return jsonResponse(
+      { code: 500, message: `Failed to ${action} task` },
+      500
+    );
+  }
+});
+
+// ============================================================================
+// Team secrets - shared by every member of the team and injected into
+// sandboxes as environment variables. Values are write-only over this API.
+// ============================================================================
+const SECRET_NAME_REGEX = /^[A-Za-z_][A-Za-z0-9_]*$/;
+
+type CliSecret = {
+  name: string;
+  description?: string;
+  createdByUserId: string;
+  createdAt: number;
+  updatedAt: number;
+};
+
+/**
+ * Look up secret values by name. Returns the names that don't exist so the
+ * caller can fail before provisioning anything.
+ */
+async function resolveSecrets(
+  ctx: ActionCtx,
+  teamSlugOrId: string,
+  names: string[]
+): Promise<{ values: Record<string, string>; missing: string[] }> {
+  return (await ctx.runQuery(teamSecretsApi.resolve, {
+    teamSlugOrId,
+    names,
+  })) as { values: Record<string, string>; missing: string[] };
+}
+
+/**
+ * Format secrets as a dotenv payload for envctl. Values are single-quoted so
+ * $, backticks and backslashes stay literal when the result is sourced.
+ */
+function formatSecretsEnv(values: Record<string, string>): string {
+  return Object.entries(values)
+    .map(([name, value]) => `${name}='${value.replace(/'/g, "'\\''")}'`)
+    .join("\n");
+}
+
+function secretErrorResponse(err: unknown, fallback: string): Response {
+  const message = err instanceof Error ? err.message : "";
+  if (message.includes("Forbidden")) {
+    return jsonResponse(
+      { code: 403, message: message.slice(message.indexOf("Forbidden")) },
+      403
+    );
+  }
+  return jsonResponse({ code: 500, message: fallback }, 500);
+}
+
+// ============================================================================
+// GET /api/v1/cmux/secrets - List secret names (never values)
+// ============================================================================
+export const listSecrets = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  try {
+    const secrets = (await ctx.runQuery(teamSecretsApi.list, {
+      teamSlugOrId,
+    })) as CliSecret[];
+
+    return jsonResponse({
+      secrets: secrets.map((secret) => ({
+        name: secret.name,
+        description: secret.description,
+        createdBy: secret.createdByUserId,
+        createdAt: secret.createdAt,
+        updatedAt: secret.updatedAt,
+      })),
+    });
+  } catch (err) {
+    console.error("[cmux.secrets.list] Error:", err);
+    return secretErrorResponse(err, "Failed to list secrets");
+  }
+});
+
+// ============================================================================
+// PUT /api/v1/cmux/secrets - Create or update a secret
+// ============================================================================
+export const setSecret = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  let body: {
+    teamSlugOrId?: string;
+    name?: string;
+    value?: string;
+    description?: string;
+  };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+  if (!body.name || !SECRET_NAME_REGEX.test(body.name)) {
+    return jsonResponse(
+      { code: 400, message: "name must be a valid environment variable name" },
+      400
+    );
+  }
+  if (typeof body.value !== "string" || body.value === "") {
+    return jsonResponse({ code: 400, message: "value is required" }, 400);
+  }
+
+  try {
+    await ctx.runMutation(teamSecretsApi.upsert, {
+      teamSlugOrId: body.teamSlugOrId,
+      name: body.name,
+      value: body.value,
+      description: body.description,
+    });
+    return jsonResponse({ name: body.name, success: true });
+  } catch (err) {
+    console.error("[cmux.secrets.set] Error:", err);
+    return secretErrorResponse(err, "Failed to save secret");
+  }
+});
+
+// ============================================================================
+// DELETE /api/v1/cmux/secrets/{name} - Delete a secret
+// ============================================================================
+export const deleteSecret = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  // Parse path: /api/v1/cmux/secrets/{name}
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  const name = pathParts[4];
+
+  if (!name || !SECRET_NAME_REGEX.test(name)) {
+    return jsonResponse({ code: 400, message: "Invalid secret name" }, 400);
+  }
+
+  try {
+    const deleted = (await ctx.runMutation(teamSecretsApi.remove, {
+      teamSlugOrId,
+      name,
+    })) as boolean;
+    if (!deleted) {
+      return jsonResponse({ code: 404, message: "Secret not found" }, 404);
+    }
+    return jsonResponse({ name, deleted: true });
+  } catch (err) {
+    console.error("[cmux.secrets.delete] Error:", err);
+    return secretErrorResponse(err, "Failed to delete secret");
+  }
+});
+
+// ============================================================================
+// POST /api/v1/cmux/uploads - Get a one-time URL for uploading a file or chunk
+// ============================================================================
+export const createUploadUrl = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  let body: { teamSlugOrId?: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+
+  try {
+    const uploadUrl = await ctx.runMutation(api.storage.generateUploadUrl, {
+      teamSlugOrId: body.teamSlugOrId,
+    });
+    return jsonResponse({ uploadUrl });
+  } catch (err) {
+    console.error("[cmux.uploads.create] Error:", err);
+    return jsonResponse(
+      { code: 500, message: "Failed to create upload URL" },
+      500
+    );
+  }
+});
+
+// ============================================================================
+// POST /api/v1/cmux/uploads/complete - Join uploaded chunks into one file
+// ============================================================================
+export const completeUpload = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  let body: {
+    teamSlugOrId?: string;
+    chunks?: string[];
+    contentType?: string;
+  };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+  if (
+    !Array.isArray(body.chunks) ||
+    body.chunks.length === 0 ||
+    !body.chunks.every((chunk) => typeof chunk === "string")
+  ) {
+    return jsonResponse(
+      { code: 400, message: "chunks must be a non-empty array of storage IDs" },
+      400
+    );
+  }
+
+  try {
+    const chunks = body.chunks as Id<"_storage">[];
+    // A single chunk was uploaded with its real content type and is the file
+    const storageId =
+      chunks.length === 1
+        ? chunks[0]
+        : await ctx.runAction(internal.storage_actions.composeChunks, {
+            chunks,
+            contentType: body.contentType,
+          });
+    const url = await ctx.runQuery(api.storage.getUrl, {
+      teamSlugOrId: body.teamSlugOrId,
+      storageId,
+    });
+    return jsonResponse({ storageId, url });
+  } catch (err) {
+    console.error("[cmux.uploads.complete] Error:", err);
+    return jsonResponse(
+      { code: 500, message: "Failed to complete upload" },
+      500
+    );
+  }
+});
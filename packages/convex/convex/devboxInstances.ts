@@ -213,6 +213,7 @@ export const create = authMutation({
     environmentId: v.optional(v.id("environments")),
     metadata: v.optional(v.record(v.string(), v.string())),
     source: v.optional(v.union(v.literal("cli"), v.literal("web"))),
+    expiresAt: v.optional(v.number()),
   },
   handler: async (ctx, args) => {
     const userId = ctx.identity.subject;
@@ -248,6 +249,7 @@ export const create = authMutation({
           status: "running",
           name: args.name ?? existing.name,
           metadata: args.metadata ?? existing.metadata,
+          expiresAt: args.expiresAt ?? existing.expiresAt,
           updatedAt: now,
           lastAccessedAt: now,
         });
@@ -268,6 +270,7 @@ export const create = authMutation({
       status: "running",
       environmentId: args.environmentId,
       metadata: args.metadata,
+      expiresAt: args.expiresAt,
       createdAt: now,
       updatedAt: now,
       lastAccessedAt: now,
@@ -405,6 +408,166 @@ export const recordAccess = authMutation({
   },
 });
 
+/**
+ * Record a publicly exposed port for a devbox instance, replacing any
+ * existing entry for the same port.
+ */
+export const setExposedPort = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The devboxId
+    port: v.number(),
+    url: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.id))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    const now = Date.now();
+    const exposedPorts = (instance.exposedPorts ?? []).filter(
+      (entry) => entry.port !== args.port
+    );
+    exposedPorts.push({ port: args.port, url: args.url, createdAt: now });
+    exposedPorts.sort((a, b) => a.port - b.port);
+
+    await ctx.db.patch(instance._id, { exposedPorts, updatedAt: now });
+  },
+});
+
+/**
+ * Remove an exposed port from a devbox instance.
+ * Returns whether the port was exposed.
+ */
+export const removeExposedPort = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The devboxId
+    port: v.number(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.id))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    const existing = instance.exposedPorts ?? [];
+    const exposedPorts = existing.filter((entry) => entry.port !== args.port);
+    if (exposedPorts.length === existing.length) {
+      return false;
+    }
+
+    await ctx.db.patch(instance._id, { exposedPorts, updatedAt: Date.now() });
+    return true;
+  },
+});
+
+/**
+ * Record when the provider will stop a devbox instance.
+ */
+export const setExpiresAt = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The devboxId
+    expiresAt: v.number(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.id))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    await ctx.db.patch(instance._id, {
+      expiresAt: args.expiresAt,
+      updatedAt: Date.now(),
+    });
+  },
+});
+
+/**
+ * Rename a devbox instance.
+ */
+export const rename = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The devboxId
+    name: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.id))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    await ctx.db.patch(instance._id, { name: args.name, updatedAt: Date.now() });
+  },
+});
+
+/**
+ * Set and remove tags on a devbox instance. Returns the resulting tags.
+ */
+export const updateTags = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The devboxId
+    set: v.optional(v.record(v.string(), v.string())),
+    remove: v.optional(v.array(v.string())),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.id))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    const tags: Record<string, string> = {
+      ...(instance.tags ?? {}),
+      ...(args.set ?? {}),
+    };
+    for (const key of args.remove ?? []) {
+      delete tags[key];
+    }
+
+    await ctx.db.patch(instance._id, { tags, updatedAt: Date.now() });
+    return tags;
+  },
+});
+
 /**
  * Internal mutation to update instance status (for cron jobs or internal use).
  */
@@ -0,0 +1,102 @@
+import { v } from "convex/values";
+import { authQuery, authMutation } from "./users/utils";
+import { getTeamId } from "../_shared/team";
+
+/**
+ * Generate a friendly ID for CLI users (snap_xxxxxxxx)
+ */
+function generateSnapshotId(): string {
+  const chars = "abcdefghijklmnopqrstuvwxyz0123456789";
+  let result = "snap_";
+  const array = new Uint8Array(8);
+  crypto.getRandomValues(array);
+  for (let i = 0; i < 8; i++) {
+    result += chars[array[i] % chars.length];
+  }
+  return result;
+}
+
+/**
+ * List snapshots shared with a team, newest first.
+ */
+export const list = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    return await ctx.db
+      .query("devboxSnapshots")
+      .withIndex("by_team", (q) => q.eq("teamId", teamId))
+      .order("desc")
+      .collect();
+  },
+});
+
+/**
+ * Get a snapshot by its friendly ID. Any member of the team can read it.
+ */
+export const getById = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The snapshotId (snap_xxx)
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const snapshot = await ctx.db
+      .query("devboxSnapshots")
+      .withIndex("by_snapshotId", (q) => q.eq("snapshotId", args.id))
+      .first();
+
+    if (!snapshot || snapshot.teamId !== teamId) {
+      return null;
+    }
+
+    return snapshot;
+  },
+});
+
+/**
+ * Record a snapshot captured from one of the user's devbox instances.
+ */
+export const create = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    name: v.string(),
+    providerSnapshotId: v.string(),
+    sourceDevboxId: v.string(),
+    gpu: v.optional(v.string()),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const instance = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_devboxId", (q) => q.eq("devboxId", args.sourceDevboxId))
+      .first();
+
+    if (!instance || instance.teamId !== teamId || instance.userId !== userId) {
+      throw new Error("Instance not found or not authorized");
+    }
+
+    const snapshotId = generateSnapshotId();
+    const now = Date.now();
+
+    await ctx.db.insert("devboxSnapshots", {
+      snapshotId,
+      teamId,
+      userId,
+      name: args.name,
+      provider: "modal",
+      providerSnapshotId: args.providerSnapshotId,
+      sourceDevboxId: args.sourceDevboxId,
+      gpu: args.gpu,
+      createdAt: now,
+    });
+
+    return { id: snapshotId, createdAt: now };
+  },
+});
@@ -0,0 +1,213 @@
+import { v } from "convex/values";
+import { internalMutation } from "./_generated/server";
+import { authQuery, authMutation } from "./users/utils";
+import { getTeamId } from "../_shared/team";
+
+// Keep individual log entries well under Convex's document size limit.
+const MAX_LOG_TEXT_LENGTH = 16 * 1024;
+
+/**
+ * Generate a friendly ID for CLI users (tpl_xxxxxxxx)
+ */
+function generateTemplateId(): string {
+  const chars = "abcdefghijklmnopqrstuvwxyz0123456789";
+  let result = "tpl_";
+  const array = new Uint8Array(8);
+  crypto.getRandomValues(array);
+  for (let i = 0; i < 8; i++) {
+    result += chars[array[i] % chars.length];
+  }
+  return result;
+}
+
+/**
+ * List custom templates shared with a team, newest first.
+ */
+export const list = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    return await ctx.db
+      .query("devboxTemplates")
+      .withIndex("by_team", (q) => q.eq("teamId", teamId))
+      .order("desc")
+      .collect();
+  },
+});
+
+/**
+ * Get a custom template by its friendly ID. Any member of the team can read it.
+ */
+export const getById = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The templateId (tpl_xxx)
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const template = await ctx.db
+      .query("devboxTemplates")
+      .withIndex("by_templateId", (q) => q.eq("templateId", args.id))
+      .first();
+
+    if (!template || template.teamId !== teamId) {
+      return null;
+    }
+
+    return template;
+  },
+});
+
+/**
+ * Get build log lines after a sequence number.
+ */
+export const getLogs = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The templateId (tpl_xxx)
+    after: v.optional(v.number()),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const template = await ctx.db
+      .query("devboxTemplates")
+      .withIndex("by_templateId", (q) => q.eq("templateId", args.id))
+      .first();
+
+    if (!template || template.teamId !== teamId) {
+      return [];
+    }
+
+    const logs = await ctx.db
+      .query("devboxTemplateLogs")
+      .withIndex("by_template_seq", (q) =>
+        q.eq("templateId", args.id).gt("seq", args.after ?? 0)
+      )
+      .take(500);
+
+    return logs.map((log) => ({ seq: log.seq, text: log.text }));
+  },
+});
+
+/**
+ * Register a template build for the team.
+ */
+export const create = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    name: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const templateId = generateTemplateId();
+    const now = Date.now();
+
+    await ctx.db.insert("devboxTemplates", {
+      templateId,
+      teamId,
+      userId,
+      name: args.name,
+      provider: "modal",
+      status: "building",
+      createdAt: now,
+      updatedAt: now,
+    });
+
+    return { id: templateId, createdAt: now };
+  },
+});
+
+/**
+ * Delete a custom template and its build logs.
+ */
+export const remove = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    id: v.string(), // The templateId (tpl_xxx)
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const template = await ctx.db
+      .query("devboxTemplates")
+      .withIndex("by_templateId", (q) => q.eq("templateId", args.id))
+      .first();
+
+    if (!template || template.teamId !== teamId) {
+      throw new Error("Template not found or not authorized");
+    }
+
+    const logs = await ctx.db
+      .query("devboxTemplateLogs")
+      .withIndex("by_template_seq", (q) => q.eq("templateId", args.id))
+      .collect();
+    for (const log of logs) {
+      await ctx.db.delete(log._id);
+    }
+
+    await ctx.db.delete(template._id);
+  },
+});
+
+/**
+ * Internal mutation to append build log lines (called by the build action).
+ */
+export const appendLogsInternal = internalMutation({
+  args: {
+    templateId: v.string(),
+    lines: v.array(v.string()),
+  },
+  handler: async (ctx, args) => {
+    const last = await ctx.db
+      .query("devboxTemplateLogs")
+      .withIndex("by_template_seq", (q) => q.eq("templateId", args.templateId))
+      .order("desc")
+      .first();
+
+    let seq = last?.seq ?? 0;
+    for (const line of args.lines) {
+      seq++;
+      await ctx.db.insert("devboxTemplateLogs", {
+        templateId: args.templateId,
+        seq,
+        text: line.slice(0, MAX_LOG_TEXT_LENGTH),
+      });
+    }
+  },
+});
+
+/**
+ * Internal mutation to record the outcome of a template build.
+ */
+export const finishBuildInternal = internalMutation({
+  args: {
+    templateId: v.string(),
+    providerImageId: v.optional(v.string()),
+    error: v.optional(v.string()),
+  },
+  handler: async (ctx, args) => {
+    const template = await ctx.db
+      .query("devboxTemplates")
+      .withIndex("by_templateId", (q) => q.eq("templateId", args.templateId))
+      .first();
+
+    // The template may have been deleted while it was building
+    if (!template) {
+      return;
+    }
+
+    await ctx.db.patch(template._id, {
+      status: args.providerImageId ? "ready" : "failed",
+      providerImageId: args.providerImageId,
+      error: args.error,
+      updatedAt: Date.now(),
+    });
+  },
+});
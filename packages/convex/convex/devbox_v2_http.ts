@@ -18,6 +18,8 @@ import {
   MODAL_TEMPLATE_PRESETS,
   isModalGpuGated,
 } from "@cmux/shared/modal-templates";
+import { parseDockerfile } from "../_shared/dockerfile";
+import { stringToBase64 } from "../_shared/encoding";
 
 type SandboxProvider = "e2b" | "modal";
 
@@ -75,6 +77,32 @@ const devboxApi = (api as any).devboxInstances as {
   updateStatus: FunctionReference<"mutation", "public">;
   recordAccess: FunctionReference<"mutation", "public">;
   remove: FunctionReference<"mutation", "public">;
+  setExposedPort: FunctionReference<"mutation", "public">;
+  removeExposedPort: FunctionReference<"mutation", "public">;
+  rename: FunctionReference<"mutation", "public">;
+  setExpiresAt: FunctionReference<"mutation", "public">;
+  updateTags: FunctionReference<"mutation", "public">;
+};
+
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+const teamSecretsApi = (api as any).teamSecrets as {
+  resolve: FunctionReference<"query", "public">;
+};
+
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+const devboxSnapshotsApi = (api as any).devboxSnapshots as {
+  create: FunctionReference<"mutation", "public">;
+  list: FunctionReference<"query", "public">;
+  getById: FunctionReference<"query", "public">;
+};
+
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+const devboxTemplatesApi = (api as any).devboxTemplates as {
+  create: FunctionReference<"mutation", "public">;
+  list: FunctionReference<"query", "public">;
+  getById: FunctionReference<"query", "public">;
+  getLogs: FunctionReference<"query", "public">;
+  remove: FunctionReference<"mutation", "public">;
 };
 
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
@@ -89,6 +117,7 @@ const e2bActionsApi = (internal as any).e2b_actions as {
   execCommand: FunctionReference<"action", "internal">;
   extendTimeout: FunctionReference<"action", "internal">;
   stopInstance: FunctionReference<"action", "internal">;
+  getPortUrl: FunctionReference<"action", "internal">;
 };
 
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
@@ -97,6 +126,9 @@ const modalActionsApi = (internal as any).modal_actions as {
   getInstance: FunctionReference<"action", "internal">;
   execCommand: FunctionReference<"action", "internal">;
   stopInstance: FunctionReference<"action", "internal">;
+  getPortUrl: FunctionReference<"action", "internal">;
+  snapshotInstance: FunctionReference<"action", "internal">;
+  buildTemplate: FunctionReference<"action", "internal">;
 };
 
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
@@ -144,6 +176,78 @@ async function recordProviderActivity(
   }
 }
 
+const SECRET_NAME_REGEX = /^[A-Za-z_][A-Za-z0-9_]*$/;
+const SECRETS_PROFILE = "/home/user/.cloudrouter/secrets.sh";
+
+/**
+ * Write team secrets to a profile script sourced by login and interactive
+ * shells. Provider env vars only reach the sandbox's own processes, not SSH
+ * or PTY sessions. Throws if the profile couldn't be written. Never logs
+ * secret values.
+ */
+async function writeSecretsProfile(
+  ctx: ActionCtx,
+  provider: SandboxProvider,
+  providerInstanceId: string,
+  values: Record<string, string>
+): Promise<void> {
+  const script = Object.entries(values)
+    .map(([name, value]) => `export ${name}='${value.replace(/'/g, "'\\''")}'`)
+    .join("\n");
+  const source = `[ -f ${SECRETS_PROFILE} ] && . ${SECRETS_PROFILE}`;
+  const command = [
+    "mkdir -p /home/user/.cloudrouter",
+    `echo ${stringToBase64(script + "\n")} | base64 -d > ${SECRETS_PROFILE}`,
+    `chmod 600 ${SECRETS_PROFILE}`,
+    "{ chown -R user:user /home/user/.cloudrouter 2>/dev/null || true; }",
+    `for rc in /home/user/.bashrc /home/user/.profile; do grep -qsF '${SECRETS_PROFILE}' "$rc" || echo '${source}' >> "$rc"; done`,
+  ].join(" && ");
+
+  const actions = provider === "modal" ? modalActionsApi : e2bActionsApi;
+  const result = (await ctx.runAction(actions.execCommand, {
+    instanceId: providerInstanceId,
+    command,
+  })) as { exit_code: number };
+  if (result.exit_code !== 0) {
+    throw new Error(`writing the secrets profile exited with code ${result.exit_code}`);
+  }
+}
+
+/**
+ * Inject secrets into a freshly started sandbox. On failure the sandbox is
+ * stopped and an error response returned, so the caller never gets a
+ * sandbox that is missing the secrets it asked for.
+ */
+async function injectSecretsOrStop(
+  ctx: ActionCtx,
+  provider: SandboxProvider,
+  providerInstanceId: string,
+  values: Record<string, string>
+): Promise<Response | null> {
+  try {
+    await writeSecretsProfile(ctx, provider, providerInstanceId, values);
+    return null;
+  } catch (error) {
+    // Never log secret values, only which step failed
+    console.error("[devbox_v2.create] Failed to inject secrets:", error);
+    const actions = provider === "modal" ? modalActionsApi : e2bActionsApi;
+    try {
+      await ctx.runAction(actions.stopInstance, {
+        instanceId: providerInstanceId,
+      });
+    } catch (stopError) {
+      console.error("[devbox_v2.create] Failed to stop sandbox after secret injection failed:", stopError);
+    }
+    return jsonResponse(
+      {
+        code: 500,
+        message: `Failed to inject secrets: ${error instanceof Error ? error.message : "unknown error"}`,
+      },
+      500
+    );
+  }
+}
+
 /**
  * Get the provider info for a devbox ID
  */
@@ -179,11 +283,16 @@ export const createInstance = httpAction(async (ctx, req) => {
     ttlSeconds?: number;
     metadata?: Record<string, string>;
     envs?: Record<string, string>;
+    // Team secrets to inject as env vars, by name
+    secrets?: string[];
+    // Inject all of the team's secrets as env vars
+    withSecrets?: boolean;
     // Modal-specific options
     gpu?: string;
     cpu?: number;
     memoryMiB?: number;
     image?: string;
+    snapshotId?: string;
   };
 
   try {
@@ -199,7 +308,86 @@ export const createInstance = httpAction(async (ctx, req) => {
     );
   }
 
-  const provider: SandboxProvider = body.provider ?? "e2b";
+  // Team snapshots are Modal images, so starting from one implies Modal.
+  let snapshot: { providerSnapshotId: string; gpu?: string } | null = null;
+  if (body.snapshotId) {
+    if (body.provider && body.provider !== "modal") {
+      return jsonResponse(
+        {
+          code: 400,
+          message: `Snapshots can only be started on modal, not ${body.provider}`,
+        },
+        400
+      );
+    }
+    snapshot = (await ctx.runQuery(devboxSnapshotsApi.getById, {
+      teamSlugOrId: body.teamSlugOrId,
+      id: body.snapshotId,
+    })) as { providerSnapshotId: string; gpu?: string } | null;
+    if (!snapshot) {
+      return jsonResponse({ code: 404, message: "Snapshot not found" }, 404);
+    }
+  }
+
+  // Custom team templates (tpl_xxx) are Modal images too.
+  let customTemplate: { providerImageId?: string; status: string } | null =
+    null;
+  if (body.templateId?.startsWith("tpl_")) {
+    if (snapshot) {
+      return jsonResponse(
+        { code: 400, message: "Use either a snapshot or a template, not both" },
+        400
+      );
+    }
+    customTemplate = (await ctx.runQuery(devboxTemplatesApi.getById, {
+      teamSlugOrId: body.teamSlugOrId,
+      id: body.templateId,
+    })) as { providerImageId?: string; status: string } | null;
+    if (!customTemplate) {
+      return jsonResponse({ code: 404, message: "Template not found" }, 404);
+    }
+    if (customTemplate.status !== "ready" || !customTemplate.providerImageId) {
+      return jsonResponse(
+        {
+          code: 409,
+          message: `Template ${body.templateId} is not ready (status: ${customTemplate.status})`,
+        },
+        409
+      );
+    }
+  }
+
+  // Resolve secrets before booting so a typo doesn't leak a sandbox. Values
+  // are resolved here so they never pass through the CLI.
+  let secretValues: Record<string, string> = {};
+  if (body.withSecrets || (body.secrets && body.secrets.length > 0)) {
+    const names = body.withSecrets ? undefined : body.secrets;
+    const invalid = (names ?? []).filter((name) => !SECRET_NAME_REGEX.test(name));
+    if (invalid.length > 0) {
+      return jsonResponse(
+        { code: 400, message: `Invalid secret names: ${invalid.join(", ")}` },
+        400
+      );
+    }
+    const resolved = (await ctx.runQuery(teamSecretsApi.resolve, {
+      teamSlugOrId: body.teamSlugOrId,
+      names,
+    })) as { values: Record<string, string>; missing: string[] };
+    if (resolved.missing.length > 0) {
+      return jsonResponse(
+        { code: 400, message: `Secrets not found: ${resolved.missing.join(", ")}` },
+        400
+      );
+    }
+    secretValues = resolved.values;
+  }
+  const secretNames = Object.keys(secretValues);
+
+  const provider: SandboxProvider =
+    snapshot || customTemplate ? "modal" : (body.provider ?? "e2b");
+  const gpu = body.gpu ?? snapshot?.gpu;
+
+  const ttlSeconds = body.ttlSeconds ?? 600;
 
   // Check concurrency limit before creating a new instance
   const concurrency = await ctx.runQuery(
@@ -218,15 +406,19 @@ export const createInstance = httpAction(async (ctx, req) => {
   }
 
   try {
+    // Explicit envs win over secrets with the same name
+    const envs =
+      secretNames.length > 0 ? { ...secretValues, ...body.envs } : body.envs;
+
     if (provider === "modal") {
       // Gate expensive GPUs — check if user's tier unlocks it
-      if (body.gpu && isModalGpuGated(body.gpu)) {
-        const baseGpu = body.gpu.split(":")[0]?.toUpperCase() ?? "";
+      if (gpu && isModalGpuGated(gpu)) {
+        const baseGpu = gpu.split(":")[0]?.toUpperCase() ?? "";
         if (!concurrency.ungatedGpus.includes(baseGpu)) {
           return jsonResponse(
             {
               code: 403,
-              message: `GPU type "${body.gpu}" requires approval. Please contact founders@manaflow.ai to get this GPU enabled for your account.`,
+              message: `GPU type "${gpu}" requires approval. Please contact founders@manaflow.ai to get this GPU enabled for your account.`,
             },
             403,
           );
@@ -236,18 +428,21 @@ export const createInstance = httpAction(async (ctx, req) => {
       const templateId = body.templateId ?? DEFAULT_MODAL_TEMPLATE_ID;
 
       const result = (await ctx.runAction(modalActionsApi.startInstance, {
-        templateId,
-        gpu: body.gpu,
+        // Custom templates use the default preset's resources
+        templateId: customTemplate ? DEFAULT_MODAL_TEMPLATE_ID : templateId,
+        gpu,
         cpu: body.cpu,
         memoryMiB: body.memoryMiB,
-        ttlSeconds: body.ttlSeconds ?? 600,
+        ttlSeconds,
         metadata: {
           app: "cmux-devbox-v2",
           userId: identity!.subject,
           ...(body.metadata || {}),
         },
-        envs: body.envs,
+        envs,
         image: body.image,
+        snapshotImageId:
+          snapshot?.providerSnapshotId ?? customTemplate?.providerImageId,
       })) as {
         instanceId: string;
         status: string;
@@ -259,11 +454,17 @@ export const createInstance = httpAction(async (ctx, req) => {
         vncUrl?: string;
       };
 
+      if (secretNames.length > 0) {
+        const failure = await injectSecretsOrStop(ctx, "modal", result.instanceId, secretValues);
+        if (failure) return failure;
+      }
+
       const instanceResult = (await ctx.runMutation(devboxApi.create, {
         teamSlugOrId: body.teamSlugOrId,
         providerInstanceId: result.instanceId,
         provider: "modal",
         name: body.name,
+        snapshotId: body.snapshotId,
         templateId,
         vscodeUrl: result.vscodeUrl,
         workerUrl: result.workerUrl,
@@ -272,6 +473,7 @@ export const createInstance = httpAction(async (ctx, req) => {
           ...(result.gpu ? { gpu: result.gpu } : {}),
         },
         source: "cli",
+        expiresAt: Date.now() + ttlSeconds * 1000,
       })) as { id: string; isExisting: boolean };
 
       return jsonResponse({
@@ -279,7 +481,9 @@ export const createInstance = httpAction(async (ctx, req) => {
         provider: "modal",
         status: result.status,
         templateId,
+        snapshotId: body.snapshotId,
         gpu: result.gpu ?? undefined,
+        secrets: secretNames.length > 0 ? secretNames : undefined,
         jupyterUrl: result.jupyterUrl,
         vscodeUrl: result.vscodeUrl,
         workerUrl: result.workerUrl,
@@ -297,13 +501,13 @@ export const createInstance = httpAction(async (ctx, req) => {
 
     const result = (await ctx.runAction(e2bActionsApi.startInstance, {
       templateId,
-      ttlSeconds: body.ttlSeconds ?? 600,
+      ttlSeconds,
       metadata: {
         app: "cmux-devbox-v2",
         userId: identity!.subject,
         ...(body.metadata || {}),
       },
-      envs: body.envs,
+      envs,
     })) as {
       instanceId: string;
       status: string;
@@ -313,6 +517,11 @@ export const createInstance = httpAction(async (ctx, req) => {
       vncUrl?: string;
     };
 
+    if (secretNames.length > 0) {
+      const failure = await injectSecretsOrStop(ctx, "e2b", result.instanceId, secretValues);
+      if (failure) return failure;
+    }
+
     const instanceResult = (await ctx.runMutation(devboxApi.create, {
       teamSlugOrId: body.teamSlugOrId,
       providerInstanceId: result.instanceId,
@@ -323,6 +532,7 @@ export const createInstance = httpAction(async (ctx, req) => {
       workerUrl: result.workerUrl,
       metadata: body.metadata,
       source: "cli",
+      expiresAt: Date.now() + ttlSeconds * 1000,
     })) as { id: string; isExisting: boolean };
 
     return jsonResponse({
@@ -330,6 +540,7 @@ export const createInstance = httpAction(async (ctx, req) => {
       provider: "e2b",
       status: result.status,
       templateId,
+      secrets: secretNames.length > 0 ? secretNames : undefined,
       jupyterUrl: result.jupyterUrl,
       vscodeUrl: result.vscodeUrl,
       workerUrl: result.workerUrl,
@@ -358,6 +569,7 @@ export const listInstances = httpAction(async (ctx, req) => {
   const providerFilter = url.searchParams.get("provider") as
     | SandboxProvider
     | null;
+  const includeStopped = url.searchParams.get("includeStopped") === "true";
 
   if (!teamSlugOrId) {
     return jsonResponse(
@@ -370,10 +582,14 @@ export const listInstances = httpAction(async (ctx, req) => {
     const rawInstances = (await ctx.runQuery(devboxApi.list, {
       teamSlugOrId,
       provider: providerFilter ?? undefined,
+      ...(includeStopped ? { includeStoppedAfter: 0 } : {}),
     })) as Array<{
       devboxId: string;
       status: string;
       name?: string;
+      metadata?: Record<string, string>;
+      expiresAt?: number;
+      tags?: Record<string, string>;
       createdAt: number;
       updatedAt: number;
     }>;
@@ -389,6 +605,9 @@ export const listInstances = httpAction(async (ctx, req) => {
           status: inst.status,
           name: inst.name,
           provider: info?.provider,
+          gpu: inst.metadata?.gpu,
+          tags: inst.tags ?? {},
+          expiresAt: inst.expiresAt,
           createdAt: inst.createdAt,
           updatedAt: inst.updatedAt,
         };
@@ -417,7 +636,14 @@ async function handleGetInstance(
     const instance = (await ctx.runQuery(devboxApi.getById, {
       teamSlugOrId,
       id,
-    })) as { id: string; status: string; name?: string } | null;
+    })) as {
+      id: string;
+      status: string;
+      name?: string;
+      metadata?: Record<string, string>;
+      tags?: Record<string, string>;
+      exposedPorts?: Array<{ port: number; url: string; createdAt: number }>;
+    } | null;
 
     if (!instance) {
       return jsonResponse({ code: 404, message: "Instance not found" }, 404);
@@ -430,6 +656,8 @@ async function handleGetInstance(
         provider: "unknown",
         status: instance.status,
         name: instance.name,
+        tags: instance.tags ?? {},
+        exposedPorts: instance.exposedPorts ?? [],
       });
     }
 
@@ -471,10 +699,13 @@ async function handleGetInstance(
       provider,
       status,
       name: instance.name,
+      gpu: instance.metadata?.gpu,
+      tags: instance.tags ?? {},
       jupyterUrl: providerResult.jupyterUrl ?? undefined,
       vscodeUrl: providerResult.vscodeUrl ?? undefined,
       workerUrl: providerResult.workerUrl ?? undefined,
       vncUrl: providerResult.vncUrl ?? undefined,
+      exposedPorts: instance.exposedPorts ?? [],
     });
   } catch (error) {
     console.error("[devbox_v2.get] Error:", error);
@@ -810,6 +1041,12 @@ async function handleUpdateTtl(
         instanceId: providerInstanceId,
         timeoutMs: ttlSeconds * 1000,
       });
+      // E2B counts the new timeout from now
+      await ctx.runMutation(devboxApi.setExpiresAt, {
+        teamSlugOrId,
+        id,
+        expiresAt: Date.now() + ttlSeconds * 1000,
+      });
     }
     // Modal sandbox timeout is set at creation; TTL extension is a no-op
 
@@ -913,6 +1150,70 @@ export const getMe = httpAction(async (ctx) => {
   }
 });
 
+// ============================================================================
+// GET /api/v2/devbox/teams - List the user's teams
+// ============================================================================
+export const listTeams = httpAction(async (ctx) => {
+  const { identity, error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  try {
+    const teams = await ctx.runQuery(internal.teams.listForUserInternal, {
+      userId: identity!.subject,
+    });
+    return jsonResponse({ teams });
+  } catch (err) {
+    console.error("[devbox_v2.teams] Error:", err);
+    return jsonResponse({ code: 500, message: "Failed to list teams" }, 500);
+  }
+});
+
+// ============================================================================
+// POST /api/v2/devbox/teams/select - Make a team the user's selected team
+// ============================================================================
+export const selectTeam = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { identity, error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  let body: { teamSlugOrId?: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+
+  try {
+    const userId = identity!.subject;
+    const teams = await ctx.runQuery(internal.teams.listForUserInternal, {
+      userId,
+    });
+    const team = teams.find(
+      (t) => t.id === body.teamSlugOrId || t.slug === body.teamSlugOrId
+    );
+    if (!team) {
+      return jsonResponse({ code: 404, message: "Team not found" }, 404);
+    }
+
+    await ctx.runAction(internal.stack_webhook_actions.setSelectedTeam, {
+      userId,
+      teamId: team.id,
+    });
+    return jsonResponse({ ...team, selected: true });
+  } catch (err) {
+    console.error("[devbox_v2.teams.select] Error:", err);
+    return jsonResponse({ code: 500, message: "Failed to select team" }, 500);
+  }
+});
+
 // ============================================================================
 // POST /api/v2/devbox/instances/{id}/token - Get auth token
 // ============================================================================
@@ -966,6 +1267,231 @@ async function handleGetAuthToken(
   }
 }
 
+// ============================================================================
+// POST /api/v2/devbox/instances/{id}/expose - Share a port at a public URL
+// ============================================================================
+async function handleExposePort(
+  ctx: ActionCtx,
+  id: string,
+  teamSlugOrId: string,
+  port: number
+): Promise<Response> {
+  try {
+    const instance = await ctx.runQuery(devboxApi.getById, {
+      teamSlugOrId,
+      id,
+    });
+
+    if (!instance) {
+      return jsonResponse({ code: 404, message: "Instance not found" }, 404);
+    }
+
+    const providerInfo = await getProviderInfo(ctx, id);
+    if (!providerInfo) {
+      return jsonResponse(
+        { code: 404, message: "Provider mapping not found" },
+        404
+      );
+    }
+
+    const { provider, providerInstanceId } = providerInfo;
+    const actionsApi =
+      provider === "modal" ? modalActionsApi : e2bActionsApi;
+
+    const result = (await ctx.runAction(actionsApi.getPortUrl, {
+      instanceId: providerInstanceId,
+      port,
+    })) as { url: string | null };
+
+    if (!result.url) {
+      return jsonResponse(
+        {
+          code: 400,
+          message: `Port ${port} can't be exposed: ${provider} only serves ports configured when the sandbox was created`,
+        },
+        400
+      );
+    }
+
+    await ctx.runMutation(devboxApi.setExposedPort, {
+      teamSlugOrId,
+      id,
+      port,
+      url: result.url,
+    });
+
+    return jsonResponse({ port, url: result.url });
+  } catch (error) {
+    console.error("[devbox_v2.expose] Error:", error);
+    return jsonResponse({ code: 500, message: "Failed to expose port" }, 500);
+  }
+}
+
+// ============================================================================
+// POST /api/v2/devbox/instances/{id}/unexpose - Stop sharing a port
+// ============================================================================
+async function handleUnexposePort(
+  ctx: ActionCtx,
+  id: string,
+  teamSlugOrId: string,
+  port: number
+): Promise<Response> {
+  try {
+    const removed = (await ctx.runMutation(devboxApi.removeExposedPort, {
+      teamSlugOrId,
+      id,
+      port,
+    })) as boolean;
+
+    if (!removed) {
+      return jsonResponse(
+        { code: 404, message: `Port ${port} is not exposed` },
+        404
+      );
+    }
+
+    return jsonResponse({ port, removed: true });
+  } catch (error) {
+    console.error("[devbox_v2.unexpose] Error:", error);
+    return jsonResponse(
+      { code: 500, message: "Failed to remove exposed port" },
+      500
+    );
+  }
+}
+
+// ============================================================================
+// POST /api/v2/devbox/instances/{id}/rename - Rename an instance
+// ============================================================================
+async function handleRenameInstance(
+  ctx: ActionCtx,
+  id: string,
+  teamSlugOrId: string,
+  name: string
+): Promise<Response> {
+  try {
+    const instance = await ctx.runQuery(devboxApi.getById, {
+      teamSlugOrId,
+      id,
+    });
+
+    if (!instance) {
+      return jsonResponse({ code: 404, message: "Instance not found" }, 404);
+    }
+
+    await ctx.runMutation(devboxApi.rename, { teamSlugOrId, id, name });
+    return jsonResponse({ id, name });
+  } catch (error) {
+    console.error("[devbox_v2.rename] Error:", error);
+    return jsonResponse(
+      { code: 500, message: "Failed to rename instance" },
+      500
+    );
+  }
+}
+
+// Tag keys are used as `key=value` filters on the CLI, so keep them simple.
+const TAG_KEY_PATTERN = /^[A-Za-z0-9][A-Za-z0-9._-]{0,62}$/;
+
+// ============================================================================
+// POST /api/v2/devbox/instances/{id}/tags - Set or remove instance tags
+// ============================================================================
+async function handleUpdateTags(
+  ctx: ActionCtx,
+  id: string,
+  teamSlugOrId: string,
+  set: Record<string, string>,
+  remove: string[]
+): Promise<Response> {
+  try {
+    const instance = await ctx.runQuery(devboxApi.getById, {
+      teamSlugOrId,
+      id,
+    });
+
+    if (!instance) {
+      return jsonResponse({ code: 404, message: "Instance not found" }, 404);
+    }
+
+    const tags = (await ctx.runMutation(devboxApi.updateTags, {
+      teamSlugOrId,
+      id,
+      set,
+      remove,
+    })) as Record<string, string>;
+    return jsonResponse({ id, tags });
+  } catch (error) {
+    console.error("[devbox_v2.tags] Error:", error);
+    return jsonResponse({ code: 500, message: "Failed to update tags" }, 500);
+  }
+}
+
+// ============================================================================
+// POST /api/v2/devbox/instances/{id}/snapshot - Capture a team snapshot
+// ============================================================================
+async function handleSnapshotInstance(
+  ctx: ActionCtx,
+  id: string,
+  teamSlugOrId: string,
+  name: string
+): Promise<Response> {
+  try {
+    const instance = (await ctx.runQuery(devboxApi.getById, {
+      teamSlugOrId,
+      id,
+    })) as { metadata?: Record<string, string> } | null;
+
+    if (!instance) {
+      return jsonResponse({ code: 404, message: "Instance not found" }, 404);
+    }
+
+    const providerInfo = await getProviderInfo(ctx, id);
+    if (!providerInfo) {
+      return jsonResponse(
+        { code: 404, message: "Provider mapping not found" },
+        404
+      );
+    }
+
+    const { provider, providerInstanceId } = providerInfo;
+    if (provider !== "modal") {
+      return jsonResponse(
+        {
+          code: 400,
+          message: `Snapshots are not supported for ${provider} sandboxes; start the sandbox with --provider modal`,
+        },
+        400
+      );
+    }
+
+    const result = (await ctx.runAction(modalActionsApi.snapshotInstance, {
+      instanceId: providerInstanceId,
+    })) as { imageId: string };
+
+    const snapshot = (await ctx.runMutation(devboxSnapshotsApi.create, {
+      teamSlugOrId,
+      name,
+      providerSnapshotId: result.imageId,
+      sourceDevboxId: id,
+      gpu: instance.metadata?.gpu,
+    })) as { id: string; createdAt: number };
+
+    return jsonResponse({
+      id: snapshot.id,
+      name,
+      provider,
+      sourceId: id,
+      createdAt: snapshot.createdAt,
+    });
+  } catch (error) {
+    console.error("[devbox_v2.snapshot] Error:", error);
+    return jsonResponse(
+      { code: 500, message: "Failed to create snapshot" },
+      500
+    );
+  }
+}
+
 // ============================================================================
 // Route handler for instance-specific POST actions
 // ============================================================================
@@ -988,6 +1514,10 @@ export const instanceActionRouter = httpAction(async (ctx, req) => {
     command?: string | string[];
     timeout?: number;
     ttlSeconds?: number;
+    port?: number;
+    name?: string;
+    set?: Record<string, string>;
+    remove?: string[];
   };
 
   try {
@@ -1034,6 +1564,64 @@ export const instanceActionRouter = httpAction(async (ctx, req) => {
     case "delete":
       return handleDeleteInstance(ctx, id, body.teamSlugOrId);
 
+    case "expose":
+    case "unexpose": {
+      const port = body.port;
+      if (
+        typeof port !== "number" ||
+        !Number.isInteger(port) ||
+        port < 1 ||
+        port > 65535
+      ) {
+        return jsonResponse(
+          { code: 400, message: "port must be an integer from 1 to 65535" },
+          400
+        );
+      }
+      return action === "expose"
+        ? handleExposePort(ctx, id, body.teamSlugOrId, port)
+        : handleUnexposePort(ctx, id, body.teamSlugOrId, port);
+    }
+
+    case "rename": {
+      const name = body.name?.trim();
+      if (!name) {
+        return jsonResponse({ code: 400, message: "name is required" }, 400);
+      }
+      return handleRenameInstance(ctx, id, body.teamSlugOrId, name);
+    }
+
+    case "tags": {
+      const set = body.set ?? {};
+      const remove = body.remove ?? [];
+      const keys = [...Object.keys(set), ...remove];
+      if (keys.length === 0) {
+        return jsonResponse(
+          { code: 400, message: "set or remove is required" },
+          400
+        );
+      }
+      if (keys.some((key) => !TAG_KEY_PATTERN.test(key))) {
+        return jsonResponse(
+          {
+            code: 400,
+            message:
+              "tag keys must start with a letter or digit and contain only letters, digits, '.', '_' or '-'",
+          },
+          400
+        );
+      }
+      return handleUpdateTags(ctx, id, body.teamSlugOrId, set, remove);
+    }
+
+    case "snapshot": {
+      const name = body.name?.trim();
+      if (!name) {
+        return jsonResponse({ code: 400, message: "name is required" }, 400);
+      }
+      return handleSnapshotInstance(ctx, id, body.teamSlugOrId, name);
+    }
+
     case "extend":
       return handleUpdateTtl(
         ctx,
@@ -1071,6 +1659,54 @@ export const instanceGetRouter = httpAction(async (ctx, req) => {
   return handleGetInstance(ctx, id, teamSlugOrId);
 });
 
+// ============================================================================
+// GET /api/v2/devbox/snapshots - List snapshots shared with the team
+// ============================================================================
+export const listSnapshots = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  try {
+    const rawSnapshots = (await ctx.runQuery(devboxSnapshotsApi.list, {
+      teamSlugOrId,
+    })) as Array<{
+      snapshotId: string;
+      name: string;
+      provider: string;
+      sourceDevboxId: string;
+      gpu?: string;
+      createdAt: number;
+    }>;
+
+    const snapshots = rawSnapshots.map((snap) => ({
+      id: snap.snapshotId,
+      name: snap.name,
+      provider: snap.provider,
+      sourceId: snap.sourceDevboxId,
+      gpu: snap.gpu,
+      createdAt: snap.createdAt,
+    }));
+
+    return jsonResponse({ snapshots });
+  } catch (error) {
+    console.error("[devbox_v2.listSnapshots] Error:", error);
+    return jsonResponse(
+      { code: 500, message: "Failed to list snapshots" },
+      500
+    );
+  }
+});
+
 // ============================================================================
 // GET /api/v2/devbox/templates - List available templates (all providers)
 // ============================================================================
@@ -1082,6 +1718,7 @@ export const listTemplates = httpAction(async (ctx, req) => {
   const providerFilter = url.searchParams.get("provider") as
     | SandboxProvider
     | null;
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
 
   const e2bTemplates =
     !providerFilter || providerFilter === "e2b"
@@ -1114,7 +1751,227 @@ export const listTemplates = httpAction(async (ctx, req) => {
         }))
       : [];
 
+  // Team templates built with `cloudrouter template build`
+  const customTemplates =
+    teamSlugOrId && (!providerFilter || providerFilter === "modal")
+      ? (
+          (await ctx.runQuery(devboxTemplatesApi.list, {
+            teamSlugOrId,
+          })) as Array<{
+            templateId: string;
+            name: string;
+            status: string;
+            createdAt: number;
+          }>
+        ).map((template) => ({
+          provider: "modal" as const,
+          templateId: template.templateId,
+          name: template.name,
+          custom: true,
+          status: template.status,
+          createdAt: template.createdAt,
+        }))
+      : [];
+
   return jsonResponse({
-    templates: [...e2bTemplates, ...modalTemplates],
+    templates: [...e2bTemplates, ...modalTemplates, ...customTemplates],
   });
 });
+
+// Dockerfiles are sent inline; keep them to a reasonable size.
+const MAX_DOCKERFILE_BYTES = 64 * 1024;
+
+// ============================================================================
+// POST /api/v2/devbox/templates - Build a custom template from a Dockerfile
+// ============================================================================
+export const buildTemplate = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  let body: { teamSlugOrId: string; name?: string; dockerfile?: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+  const name = body.name?.trim();
+  if (!name) {
+    return jsonResponse({ code: 400, message: "name is required" }, 400);
+  }
+  if (!body.dockerfile) {
+    return jsonResponse({ code: 400, message: "dockerfile is required" }, 400);
+  }
+  if (body.dockerfile.length > MAX_DOCKERFILE_BYTES) {
+    return jsonResponse(
+      { code: 400, message: "dockerfile must be 64 KiB or smaller" },
+      400
+    );
+  }
+
+  // Reject unsupported Dockerfiles up front instead of after queuing a build
+  let warnings: string[];
+  try {
+    warnings = parseDockerfile(body.dockerfile).warnings;
+  } catch (parseError) {
+    return jsonResponse(
+      {
+        code: 400,
+        message:
+          parseError instanceof Error ? parseError.message : "Invalid Dockerfile",
+      },
+      400
+    );
+  }
+
+  try {
+    const template = (await ctx.runMutation(devboxTemplatesApi.create, {
+      teamSlugOrId: body.teamSlugOrId,
+      name,
+    })) as { id: string; createdAt: number };
+
+    await ctx.scheduler.runAfter(0, modalActionsApi.buildTemplate, {
+      templateId: template.id,
+      dockerfile: body.dockerfile,
+    });
+
+    return jsonResponse({
+      id: template.id,
+      name,
+      status: "building",
+      warnings,
+      createdAt: template.createdAt,
+    });
+  } catch (buildError) {
+    console.error("[devbox_v2.buildTemplate] Error:", buildError);
+    return jsonResponse(
+      { code: 500, message: "Failed to start template build" },
+      500
+    );
+  }
+});
+
+// ============================================================================
+// GET /api/v2/devbox/templates/{id} - Template status and build logs
+// ============================================================================
+export const templateGetRouter = httpAction(async (ctx, req) => {
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  const id = pathParts[4]; // templates/{id}
+  const teamSlugOrId = url.searchParams.get("teamSlugOrId");
+  const after = Number(url.searchParams.get("after") ?? "0") || 0;
+
+  if (!id || pathParts.length > 5) {
+    return jsonResponse({ code: 404, message: "Not found" }, 404);
+  }
+  if (!teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId query parameter is required" },
+      400
+    );
+  }
+
+  try {
+    const template = (await ctx.runQuery(devboxTemplatesApi.getById, {
+      teamSlugOrId,
+      id,
+    })) as {
+      templateId: string;
+      name: string;
+      status: string;
+      error?: string;
+      createdAt: number;
+    } | null;
+
+    if (!template) {
+      return jsonResponse({ code: 404, message: "Template not found" }, 404);
+    }
+
+    const logs = (await ctx.runQuery(devboxTemplatesApi.getLogs, {
+      teamSlugOrId,
+      id,
+      after,
+    })) as Array<{ seq: number; text: string }>;
+
+    return jsonResponse({
+      id: template.templateId,
+      name: template.name,
+      provider: "modal",
+      status: template.status,
+      error: template.error,
+      createdAt: template.createdAt,
+      logs,
+    });
+  } catch (getError) {
+    console.error("[devbox_v2.getTemplate] Error:", getError);
+    return jsonResponse({ code: 500, message: "Failed to get template" }, 500);
+  }
+});
+
+// ============================================================================
+// POST /api/v2/devbox/templates/{id}/delete - Delete a custom template
+// ============================================================================
+export const templateActionRouter = httpAction(async (ctx, req) => {
+  const contentTypeError = verifyContentType(req);
+  if (contentTypeError) return contentTypeError;
+
+  const { error } = await getAuthenticatedUser(ctx);
+  if (error) return error;
+
+  const url = new URL(req.url);
+  const pathParts = url.pathname.split("/").filter(Boolean);
+  const id = pathParts[4]; // templates/{id}
+  const action = pathParts[5]; // {action}
+
+  let body: { teamSlugOrId: string };
+  try {
+    body = await req.json();
+  } catch {
+    return jsonResponse({ code: 400, message: "Invalid JSON body" }, 400);
+  }
+
+  if (!body.teamSlugOrId) {
+    return jsonResponse(
+      { code: 400, message: "teamSlugOrId is required" },
+      400
+    );
+  }
+
+  if (action !== "delete") {
+    return jsonResponse({ code: 404, message: "Not found" }, 404);
+  }
+
+  try {
+    const template = await ctx.runQuery(devboxTemplatesApi.getById, {
+      teamSlugOrId: body.teamSlugOrId,
+      id,
+    });
+    if (!template) {
+      return jsonResponse({ code: 404, message: "Template not found" }, 404);
+    }
+
+    await ctx.runMutation(devboxTemplatesApi.remove, {
+      teamSlugOrId: body.teamSlugOrId,
+      id,
+    });
+    return jsonResponse({ deleted: true });
+  } catch (deleteError) {
+    console.error("[devbox_v2.deleteTemplate] Error:", deleteError);
+    return jsonResponse(
+      { code: 500, message: "Failed to delete template" },
+      500
+    );
+  }
+});
@@ -153,6 +153,22 @@ export const extendTimeout = internalAction({
   },
 });
 
+/**
+ * Get the public URL for a port in an E2B sandbox.
+ * E2B routes every port through the sandbox host, so this always succeeds.
+ */
+export const getPortUrl = internalAction({
+  args: {
+    instanceId: v.string(),
+    port: v.number(),
+  },
+  handler: async (_ctx, args) => {
+    const client = getE2BClient();
+    const instance = await client.instances.get({ instanceId: args.instanceId });
+    return { url: `https://${instance.getHost(args.port)}` };
+  },
+});
+
 /**
  * Stop (kill) an E2B sandbox.
  */
@@ -122,6 +122,7 @@ export const update = authMutation({
     description: v.optional(v.string()),
     maintenanceScript: v.optional(v.string()),
     devScript: v.optional(v.string()),
+    selectedRepos: v.optional(v.array(v.string())),
   },
   handler: async (ctx, args) => {
     const teamId = await resolveTeamIdLoose(ctx, args.teamSlugOrId);
@@ -136,6 +137,7 @@ export const update = authMutation({
       description?: string;
       maintenanceScript?: string;
       devScript?: string;
+      selectedRepos?: string[];
       updatedAt: number;
     } = {
       updatedAt: Date.now(),
@@ -161,6 +163,11 @@ export const update = authMutation({
         trimmedDevScript.length > 0 ? trimmedDevScript : undefined;
     }
 
+    if (args.selectedRepos !== undefined) {
+      updates.selectedRepos =
+        args.selectedRepos.length > 0 ? args.selectedRepos : undefined;
+    }
+
     await ctx.db.patch(args.id, updates);
 
     return args.id;
@@ -47,16 +47,32 @@ import {
   getSnapshot as cmuxGetSnapshot,
   getConfig as cmuxGetConfig,
   getMe as cmuxGetMe,
+  exchangeApiKey as cmuxExchangeApiKey,
   instanceActionRouter as cmuxInstanceActionRouter,
   instanceGetRouter as cmuxInstanceGetRouter,
   instanceDeleteRouter as cmuxInstanceDeleteRouter,
+  getTaskRun as cmuxGetTaskRun,
+  listTasks as cmuxListTasks,
+  taskGetRouter as cmuxTaskGetRouter,
+  taskActionRouter as cmuxTaskActionRouter,
+  listSecrets as cmuxListSecrets,
+  setSecret as cmuxSetSecret,
+  deleteSecret as cmuxDeleteSecret,
+  createUploadUrl as cmuxCreateUploadUrl,
+  completeUpload as cmuxCompleteUpload,
 } from "./cmux_http";
 import {
   createInstance as devboxV2CreateInstance,
   listInstances as devboxV2ListInstances,
   listTemplates as devboxV2ListTemplates,
+  listSnapshots as devboxV2ListSnapshots,
+  buildTemplate as devboxV2BuildTemplate,
+  templateGetRouter as devboxV2TemplateGetRouter,
+  templateActionRouter as devboxV2TemplateActionRouter,
   getConfig as devboxV2GetConfig,
   getMe as devboxV2GetMe,
+  listTeams as devboxV2ListTeams,
+  selectTeam as devboxV2SelectTeam,
   instanceActionRouter as devboxV2InstanceActionRouter,
   instanceGetRouter as devboxV2InstanceGetRouter,
 } from "./devbox_v2_http";
@@ -313,6 +329,12 @@ http.route({
   handler: d(cmuxGetMe),
 });
 
+http.route({
+  path: "/api/v1/cmux/auth/api-key",
+  method: "POST",
+  handler: d(cmuxExchangeApiKey),
+});
+
 // Instance-specific routes use pathPrefix to capture the instance ID
 http.route({
   pathPrefix: "/api/v1/cmux/instances/",
@@ -332,6 +354,60 @@ http.route({
   handler: d(cmuxInstanceDeleteRouter),
 });
 
+http.route({
+  pathPrefix: "/api/v1/cmux/task-runs/",
+  method: "GET",
+  handler: d(cmuxGetTaskRun),
+});
+
+http.route({
+  path: "/api/v1/cmux/tasks",
+  method: "GET",
+  handler: d(cmuxListTasks),
+});
+
+http.route({
+  pathPrefix: "/api/v1/cmux/tasks/",
+  method: "GET",
+  handler: d(cmuxTaskGetRouter),
+});
+
+http.route({
+  pathPrefix: "/api/v1/cmux/tasks/",
+  method: "POST",
+  handler: d(cmuxTaskActionRouter),
+});
+
+http.route({
+  path: "/api/v1/cmux/secrets",
+  method: "GET",
+  handler: d(cmuxListSecrets),
+});
+
+http.route({
+  path: "/api/v1/cmux/secrets",
+  method: "PUT",
+  handler: d(cmuxSetSecret),
+});
+
+http.route({
+  pathPrefix: "/api/v1/cmux/secrets/",
+  method: "DELETE",
+  handler: d(cmuxDeleteSecret),
+});
+
+http.route({
+  path: "/api/v1/cmux/uploads",
+  method: "POST",
+  handler: d(cmuxCreateUploadUrl),
+});
+
+http.route({
+  path: "/api/v1/cmux/uploads/complete",
+  method: "POST",
+  handler: d(cmuxCompleteUpload),
+});
+
 // =============================================================================
 // v2/devbox API - Unified devbox management with provider selection (Morph/E2B)
 // =============================================================================
@@ -360,12 +436,48 @@ http.route({
   handler: d(devboxV2ListTemplates),
 });
 
+http.route({
+  path: "/api/v2/devbox/templates",
+  method: "POST",
+  handler: d(devboxV2BuildTemplate),
+});
+
+http.route({
+  pathPrefix: "/api/v2/devbox/templates/",
+  method: "GET",
+  handler: d(devboxV2TemplateGetRouter),
+});
+
+http.route({
+  pathPrefix: "/api/v2/devbox/templates/",
+  method: "POST",
+  handler: d(devboxV2TemplateActionRouter),
+});
+
+http.route({
+  path: "/api/v2/devbox/snapshots",
+  method: "GET",
+  handler: d(devboxV2ListSnapshots),
+});
+
 http.route({
   path: "/api/v2/devbox/me",
   method: "GET",
   handler: d(devboxV2GetMe),
 });
 
+http.route({
+  path: "/api/v2/devbox/teams",
+  method: "GET",
+  handler: d(devboxV2ListTeams),
+});
+
+http.route({
+  path: "/api/v2/devbox/teams/select",
+  method: "POST",
+  handler: d(devboxV2SelectTeam),
+});
+
 // Instance-specific routes use pathPrefix to capture the instance ID
 http.route({
   pathPrefix: "/api/v2/devbox/instances/",
@@ -1,8 +1,14 @@
 "use node";
 
 import { internalAction } from "./_generated/server";
+import { internal } from "./_generated/api";
 import { v } from "convex/values";
 import { env } from "../_shared/convex-env";
+import {
+  envExportLine,
+  parseDockerfile,
+  shellQuote,
+} from "../_shared/dockerfile";
 import {
   DEFAULT_MODAL_TEMPLATE_ID,
   getModalTemplateByPresetId,
@@ -178,6 +184,7 @@ export const startInstance = internalAction({
     metadata: v.optional(v.record(v.string(), v.string())),
     envs: v.optional(v.record(v.string(), v.string())),
     image: v.optional(v.string()),
+    snapshotImageId: v.optional(v.string()), // Team snapshot to boot from
   },
   handler: async (_ctx, args) => {
     const client = createClient();
@@ -188,7 +195,7 @@ export const startInstance = internalAction({
     const gpu = args.gpu ?? preset?.gpu;
     const cpu = args.cpu ?? parsePresetCpu(preset?.cpu);
     const memoryMiB = args.memoryMiB ?? parsePresetMemoryMiB(preset?.memory);
-    const snapshotImageId = DEFAULT_MODAL_SNAPSHOT_ID;
+    const snapshotImageId = args.snapshotImageId ?? DEFAULT_MODAL_SNAPSHOT_ID;
 
     try {
       console.log(`[modal_actions] Starting from snapshot ${snapshotImageId} (cpu=${cpu}, memoryMiB=${memoryMiB})`);
@@ -332,6 +339,185 @@ export const stopInstance = internalAction({
   },
 });
 
+/**
+ * Get the public tunnel URL for a port in a Modal sandbox.
+ * Modal only tunnels ports declared when the sandbox was created, so other
+ * ports return a null URL.
+ */
+export const getPortUrl = internalAction({
+  args: {
+    instanceId: v.string(),
+    port: v.number(),
+  },
+  handler: async (_ctx, args) => {
+    const client = createClient();
+    try {
+      const sandbox = await client.sandboxes.fromId(args.instanceId);
+      const tunnels = await sandbox.tunnels();
+      return { url: tunnels[args.port]?.url ?? null };
+    } finally {
+      client.close();
+    }
+  },
+});
+
+/**
+ * Snapshot the filesystem of a Modal sandbox into a reusable image.
+ * Running processes are not captured; the startup script restarts services
+ * when a new sandbox boots from the image.
+ */
+export const snapshotInstance = internalAction({
+  args: {
+    instanceId: v.string(),
+  },
+  handler: async (_ctx, args) => {
+    const client = createClient();
+    try {
+      const sandbox = await client.sandboxes.fromId(args.instanceId);
+      const image = await sandbox.snapshotFilesystem(5 * 60 * 1000);
+      return { imageId: image.imageId };
+    } finally {
+      client.close();
+    }
+  },
+});
+
+// Log lines written per mutation while streaming template build output.
+const TEMPLATE_LOG_BATCH_SIZE = 500;
+
+/**
+ * Build a team template by replaying Dockerfile steps in a sandbox booted from
+ * the base snapshot, then snapshotting its filesystem. Progress and command
+ * output are appended to the template's build log as the build runs.
+ * Convex actions time out after 10 minutes, so the build must finish by then.
+ */
+export const buildTemplate = internalAction({
+  args: {
+    templateId: v.string(),
+    dockerfile: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const log = async (lines: string[]) => {
+      for (let i = 0; i < lines.length; i += TEMPLATE_LOG_BATCH_SIZE) {
+        await ctx.runMutation(internal.devboxTemplates.appendLogsInternal, {
+          templateId: args.templateId,
+          lines: lines.slice(i, i + TEMPLATE_LOG_BATCH_SIZE),
+        });
+      }
+    };
+    const fail = async (error: unknown) => {
+      const message = error instanceof Error ? error.message : String(error);
+      await log([`Error: ${message}`]);
+      await ctx.runMutation(internal.devboxTemplates.finishBuildInternal, {
+        templateId: args.templateId,
+        error: message,
+      });
+    };
+
+    let parsed;
+    try {
+      parsed = parseDockerfile(args.dockerfile);
+    } catch (error) {
+      await fail(error);
+      return;
+    }
+    await log(parsed.warnings.map((warning) => `Warning: ${warning}`));
+
+    const client = createClient();
+    let sandbox: Sandbox | undefined;
+    try {
+      await log(["Starting build sandbox..."]);
+      const app = await client.apps.fromName("cmux-devbox", {
+        createIfMissing: true,
+      });
+      const image = await client.images.fromId(DEFAULT_MODAL_SNAPSHOT_ID);
+      sandbox = await client.sandboxes.create(app, image, {
+        timeoutMs: 15 * 60 * 1000,
+      });
+
+      const envLines: string[] = [];
+      const persistedEnvLines: string[] = [];
+      let workdir = "/";
+      const total = parsed.steps.length;
+
+      for (const [index, step] of parsed.steps.entries()) {
+        const label = `Step ${index + 1}/${total}`;
+        switch (step.kind) {
+          case "env": {
+            const lines = Object.entries(step.vars).map(([key, value]) =>
+              envExportLine(key, value),
+            );
+            envLines.push(...lines);
+            if (step.persist) {
+              persistedEnvLines.push(...lines);
+            }
+            await log([
+              `${label}: ${step.persist ? "ENV" : "ARG"} ${Object.keys(step.vars).join(" ")}`,
+            ]);
+            break;
+          }
+          case "workdir":
+            workdir = step.path.startsWith("/")
+              ? step.path
+              : `${workdir.replace(/\/$/, "")}/${step.path}`;
+            await log([`${label}: WORKDIR ${workdir}`]);
+            break;
+          case "run": {
+            await log([`${label}: RUN ${step.command}`]);
+            const script = [
+              ...envLines,
+              `mkdir -p ${shellQuote(workdir)} && cd ${shellQuote(workdir)}`,
+              step.command,
+            ].join("\n");
+            const result = await execBash(sandbox, script);
+            const output = `${result.stdout}${result.stderr}`.replace(
+              /\n+$/,
+              "",
+            );
+            if (output) {
+              await log(output.split("\n"));
+            }
+            if (result.exit_code !== 0) {
+              throw new Error(
+                `${label} failed with exit code ${result.exit_code}`,
+              );
+            }
+            break;
+          }
+        }
+      }
+
+      if (persistedEnvLines.length > 0) {
+        // ENV applies to login shells of sandboxes started from the template
+        const profile = persistedEnvLines.join("\n") + "\n";
+        const result = await execBash(
+          sandbox,
+          `printf '%s' ${shellQuote(profile)} > /etc/profile.d/cloudrouter-template.sh`,
+        );
+        if (result.exit_code !== 0) {
+          throw new Error(`Failed to persist ENV: ${result.stderr}`);
+        }
+      }
+
+      await log(["Snapshotting filesystem..."]);
+      const snapshot = await sandbox.snapshotFilesystem(5 * 60 * 1000);
+      await log([`Built image ${snapshot.imageId}`]);
+      await ctx.runMutation(internal.devboxTemplates.finishBuildInternal, {
+        templateId: args.templateId,
+        providerImageId: snapshot.imageId,
+      });
+    } catch (error) {
+      console.error("[modal_actions.buildTemplate] Error:", error);
+      await fail(error);
+    } finally {
+      if (sandbox) {
+        await sandbox.terminate().catch(() => {});
+      }
+      client.close();
+    }
+  },
+});
+
 /**
  * List all running Modal sandboxes.
  */
@@ -601,6 +601,18 @@ const convexSchema = defineSchema({
   })
     .index("by_envVar", ["envVar"])
     .index("by_team_user", ["teamId", "userId"]),
+  // Team secrets injected into sandboxes as environment variables. Shared by
+  // every member of the team, unlike the per-user apiKeys above.
+  teamSecrets: defineTable({
+    teamId: v.string(), // Team scope - secrets are shared with the whole team
+    name: v.string(), // Environment variable name, e.g. "NPM_TOKEN"
+    value: v.string(),
+    description: v.optional(v.string()),
+    createdByUserId: v.string(), // User who created the secret
+    updatedByUserId: v.string(), // User who last set the value
+    createdAt: v.number(),
+    updatedAt: v.number(),
+  }).index("by_team_name", ["teamId", "name"]),
   workspaceSettings: defineTable({
     worktreePath: v.optional(v.string()), // Custom path for git worktrees
     autoPrEnabled: v.optional(v.boolean()), // Auto-create PR for crown winner (default: false)
@@ -1287,6 +1299,17 @@ const convexSchema = defineSchema({
     updatedAt: v.number(),
     lastAccessedAt: v.optional(v.number()), // When user last accessed the instance
     stoppedAt: v.optional(v.number()), // When instance was stopped
+    expiresAt: v.optional(v.number()), // When the provider stops the instance (TTL)
+    exposedPorts: v.optional(
+      v.array(
+        v.object({
+          port: v.number(),
+          url: v.string(), // Public HTTPS URL for the port
+          createdAt: v.number(),
+        })
+      )
+    ), // Ports shared with `cloudrouter expose`
+    tags: v.optional(v.record(v.string(), v.string())), // User labels set with `cloudrouter tag`
   })
     .index("by_devboxId", ["devboxId"])
     .index("by_team_user", ["teamId", "userId", "createdAt"])
@@ -1294,6 +1317,48 @@ const convexSchema = defineSchema({
     .index("by_user", ["userId", "createdAt"])
     .index("by_status", ["status", "updatedAt"]),
 
+  // Team-shared filesystem snapshots of devbox instances (cloudrouter snapshot)
+  devboxSnapshots: defineTable({
+    snapshotId: v.string(), // Friendly ID (snap_xxxxxxxx) for CLI users
+    teamId: v.string(), // Team scope - snapshots are shared with the whole team
+    userId: v.string(), // User who captured the snapshot
+    name: v.string(), // User-provided name
+    provider: v.literal("modal"), // Only Modal supports filesystem snapshots
+    providerSnapshotId: v.string(), // Provider image ID (e.g., im-xxx)
+    sourceDevboxId: v.string(), // Instance the snapshot was captured from
+    gpu: v.optional(v.string()), // GPU of the source instance
+    createdAt: v.number(),
+  })
+    .index("by_snapshotId", ["snapshotId"])
+    .index("by_team", ["teamId", "createdAt"]),
+
+  // Team custom templates built from a Dockerfile (cloudrouter template build)
+  devboxTemplates: defineTable({
+    templateId: v.string(), // Friendly ID (tpl_xxxxxxxx) for CLI users
+    teamId: v.string(), // Team scope - templates are shared with the whole team
+    userId: v.string(), // User who started the build
+    name: v.string(), // User-provided name
+    provider: v.literal("modal"), // Templates are built as Modal images
+    status: v.union(
+      v.literal("building"),
+      v.literal("ready"),
+      v.literal("failed")
+    ),
+    providerImageId: v.optional(v.string()), // Modal image ID once built
+    error: v.optional(v.string()), // Build failure message
+    createdAt: v.number(),
+    updatedAt: v.number(),
+  })
+    .index("by_templateId", ["templateId"])
+    .index("by_team", ["teamId", "createdAt"]),
+
+  // Build log lines for devboxTemplates, in order
+  devboxTemplateLogs: defineTable({
+    templateId: v.string(), // Friendly template ID (tpl_xxxxxxxx)
+    seq: v.number(), // 1-based position in the log
+    text: v.string(),
+  }).index("by_template_seq", ["templateId", "seq"]),
+
   // Provider-specific info for devbox instances (maps our ID to provider details)
   devboxInfo: defineTable({
     devboxId: v.string(), // Our friendly ID (cr_xxxxxxxx)
@@ -452,3 +452,21 @@ export const deletePermissionPublic = authMutation({
   handler: async (ctx, { teamId, userId, permissionId }) =>
     deletePermissionCore(ctx as unknown as MutationCtx, teamId, userId, permissionId),
 });
+
+// Mirror a team selection made through Stack, so reads don't have to wait for
+// the user.updated webhook
+export const setSelectedTeamInternal = internalMutation({
+  args: { userId: v.string(), teamId: v.string() },
+  handler: async (ctx, { userId, teamId }) => {
+    const existing = await ctx.db
+      .query("users")
+      .withIndex("by_userId", (q) => q.eq("userId", userId))
+      .first();
+    if (existing) {
+      await ctx.db.patch(existing._id, {
+        selectedTeamId: teamId,
+        updatedAt: Date.now(),
+      });
+    }
+  },
+});
@@ -120,10 +120,16 @@ export const stackWebhook = httpAction(async (ctx, req) => {
     }
     case "team_membership.created": {
       const m = event.data;
-      await ctx.runMutation(internal.stack.ensureMembership, {
-        teamId: m.team_id,
-        userId: m.user_id,
-      });
+      await Promise.all([
+        ctx.runMutation(internal.stack.ensureMembership, {
+          teamId: m.team_id,
+          userId: m.user_id,
+        }),
+        ctx.runAction(internal.stack_webhook_actions.applyPendingInviteRole, {
+          teamId: m.team_id,
+          userId: m.user_id,
+        }),
+      ]);
       break;
     }
     case "team_membership.deleted": {
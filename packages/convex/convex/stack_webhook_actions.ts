@@ -39,3 +39,67 @@ export const syncTeamMembershipsFromStack = internalAction({
     }
   },
 });
+
+// Stack invitations can't carry a role, so the cmux teams API records the
+// role for the invited email in the team's server metadata until they join
+export const applyPendingInviteRole = internalAction({
+  args: { teamId: v.string(), userId: v.string() },
+  handler: async (_ctx, { teamId, userId }) => {
+    try {
+      const team = await stackServerAppJs.getTeam(teamId);
+      const pendingRoles = team?.serverMetadata?.pendingInviteRoles as
+        | Record<string, string>
+        | undefined;
+      if (!team || !pendingRoles) {
+        return;
+      }
+
+      // Only a verified address proves the user is the person invited;
+      // otherwise anyone could claim the role by signing up with the email
+      const user = await stackServerAppJs.getUser(userId);
+      const email = user?.primaryEmailVerified
+        ? user.primaryEmail?.toLowerCase()
+        : undefined;
+      if (!user || !email || !pendingRoles[email]) {
+        return;
+      }
+
+      if (pendingRoles[email] === "admin") {
+        await user.grantPermission(team, "team_admin");
+      }
+      const { [email]: _applied, ...remaining } = pendingRoles;
+      await team.update({
+        serverMetadata: {
+          ...team.serverMetadata,
+          pendingInviteRoles: remaining,
+        },
+      });
+    } catch (error) {
+      console.error("[stack_webhook] Failed to apply pending invite role", {
+        teamId,
+        userId,
+        error,
+      });
+    }
+  },
+});
+
+// Make a team the user's selected team in Stack, like the web app's team
+// switcher, so the web app and every CLI pick it up
+export const setSelectedTeam = internalAction({
+  args: { userId: v.string(), teamId: v.string() },
+  handler: async (ctx, { userId, teamId }) => {
+    const [user, team] = await Promise.all([
+      stackServerAppJs.getUser(userId),
+      stackServerAppJs.getTeam(teamId),
+    ]);
+    if (!user || !team) {
+      throw new Error("User or team not found in Stack");
+    }
+    await user.setSelectedTeam(team);
+    await ctx.runMutation(internal.stack.setSelectedTeamInternal, {
+      userId,
+      teamId,
+    });
+  },
+});
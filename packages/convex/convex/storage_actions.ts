@@ -0,0 +1,33 @@
+"use node";
+
+import { v } from "convex/values";
+import type { Id } from "./_generated/dataModel";
+import { internalAction } from "./_generated/server";
+
+/**
+ * Concatenate chunks uploaded separately (so each could be retried on its
+ * own) into a single stored file, then delete the chunks.
+ */
+export const composeChunks = internalAction({
+  args: {
+    chunks: v.array(v.id("_storage")),
+    contentType: v.optional(v.string()),
+  },
+  handler: async (ctx, args): Promise<Id<"_storage">> => {
+    const parts: Blob[] = [];
+    for (const chunk of args.chunks) {
+      const blob = await ctx.storage.get(chunk);
+      if (!blob) {
+        throw new Error(`Upload chunk not found: ${chunk}`);
+      }
+      parts.push(blob);
+    }
+
+    const storageId = await ctx.storage.store(
+      new Blob(parts, { type: args.contentType ?? "application/octet-stream" })
+    );
+
+    await Promise.all(args.chunks.map((chunk) => ctx.storage.delete(chunk)));
+    return storageId;
+  },
+});
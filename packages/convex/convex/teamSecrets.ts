@@ -0,0 +1,183 @@
+import { v } from "convex/values";
+import type { QueryCtx } from "./_generated/server";
+import { authMutation, authQuery } from "./users/utils";
+import { getTeamId } from "../_shared/team";
+
+const TEAM_ADMIN_PERMISSION = "team_admin";
+
+async function isTeamAdmin(
+  ctx: QueryCtx,
+  teamId: string,
+  userId: string
+): Promise<boolean> {
+  const permission = await ctx.db
+    .query("teamPermissions")
+    .withIndex("by_team_user_perm", (q) =>
+      q
+        .eq("teamId", teamId)
+        .eq("userId", userId)
+        .eq("permissionId", TEAM_ADMIN_PERMISSION)
+    )
+    .first();
+  return permission !== null;
+}
+
+/**
+ * List a team's secrets without their values. Any member of the team can
+ * list them.
+ */
+export const list = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const secrets = await ctx.db
+      .query("teamSecrets")
+      .withIndex("by_team_name", (q) => q.eq("teamId", teamId))
+      .collect();
+
+    return secrets.map((secret) => ({
+      name: secret.name,
+      description: secret.description,
+      createdByUserId: secret.createdByUserId,
+      createdAt: secret.createdAt,
+      updatedAt: secret.updatedAt,
+    }));
+  },
+});
+
+/**
+ * Look up secret values by name for injection into a sandbox. Returns the
+ * names that don't exist so the caller can fail before provisioning
+ * anything. Omit names to resolve every secret of the team.
+ */
+export const resolve = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+    names: v.optional(v.array(v.string())),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const values: Record<string, string> = {};
+    const missing: string[] = [];
+
+    if (args.names === undefined) {
+      const secrets = await ctx.db
+        .query("teamSecrets")
+        .withIndex("by_team_name", (q) => q.eq("teamId", teamId))
+        .collect();
+      for (const secret of secrets) {
+        values[secret.name] = secret.value;
+      }
+      return { values, missing };
+    }
+
+    for (const name of args.names) {
+      const secret = await ctx.db
+        .query("teamSecrets")
+        .withIndex("by_team_name", (q) =>
+          q.eq("teamId", teamId).eq("name", name)
+        )
+        .first();
+      if (secret) {
+        values[name] = secret.value;
+      } else {
+        missing.push(name);
+      }
+    }
+    return { values, missing };
+  },
+});
+
+/**
+ * Create or update a team secret. Any member can create a secret; only its
+ * creator or a team admin can overwrite it.
+ */
+export const upsert = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    name: v.string(),
+    value: v.string(),
+    description: v.optional(v.string()),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const existing = await ctx.db
+      .query("teamSecrets")
+      .withIndex("by_team_name", (q) =>
+        q.eq("teamId", teamId).eq("name", args.name)
+      )
+      .first();
+
+    const now = Date.now();
+    if (existing) {
+      if (
+        existing.createdByUserId !== userId &&
+        !(await isTeamAdmin(ctx, teamId, userId))
+      ) {
+        throw new Error(
+          "Forbidden: Only the secret's creator or a team admin can change it"
+        );
+      }
+      await ctx.db.patch(existing._id, {
+        value: args.value,
+        description: args.description ?? existing.description,
+        updatedByUserId: userId,
+        updatedAt: now,
+      });
+      return existing._id;
+    }
+
+    return await ctx.db.insert("teamSecrets", {
+      teamId,
+      name: args.name,
+      value: args.value,
+      description: args.description,
+      createdByUserId: userId,
+      updatedByUserId: userId,
+      createdAt: now,
+      updatedAt: now,
+    });
+  },
+});
+
+/**
+ * Delete a team secret. Only its creator or a team admin can delete it.
+ * Returns false if there is no secret with that name.
+ */
+export const remove = authMutation({
+  args: {
+    teamSlugOrId: v.string(),
+    name: v.string(),
+  },
+  handler: async (ctx, args) => {
+    const userId = ctx.identity.subject;
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+
+    const existing = await ctx.db
+      .query("teamSecrets")
+      .withIndex("by_team_name", (q) =>
+        q.eq("teamId", teamId).eq("name", args.name)
+      )
+      .first();
+    if (!existing) {
+      return false;
+    }
+
+    if (
+      existing.createdByUserId !== userId &&
+      !(await isTeamAdmin(ctx, teamId, userId))
+    ) {
+      throw new Error(
+        "Forbidden: Only the secret's creator or a team admin can delete it"
+      );
+    }
+    await ctx.db.delete(existing._id);
+    return true;
+  },
+});
@@ -155,3 +155,44 @@ export const getMembershipsByUserIdInternal = internalQuery({
     }));
   },
 });
+
+// Internal helper to list a user's teams with their role in each and which
+// one is selected (used by the devbox HTTP API)
+export const listForUserInternal = internalQuery({
+  args: { userId: v.string() },
+  handler: async (ctx, { userId }) => {
+    const user = await ctx.db
+      .query("users")
+      .withIndex("by_userId", (q) => q.eq("userId", userId))
+      .first();
+    const memberships = await ctx.db
+      .query("teamMemberships")
+      .withIndex("by_user", (q) => q.eq("userId", userId))
+      .collect();
+
+    return await Promise.all(
+      memberships.map(async (m) => {
+        const team = await ctx.db
+          .query("teams")
+          .withIndex("by_teamId", (q) => q.eq("teamId", m.teamId))
+          .first();
+        const admin = await ctx.db
+          .query("teamPermissions")
+          .withIndex("by_team_user_perm", (q) =>
+            q
+              .eq("teamId", m.teamId)
+              .eq("userId", userId)
+              .eq("permissionId", "team_admin")
+          )
+          .first();
+        return {
+          id: m.teamId,
+          slug: team?.slug ?? null,
+          displayName: team?.displayName ?? team?.name ?? null,
+          role: admin ? ("admin" as const) : ("member" as const),
+          selected: user?.selectedTeamId === m.teamId,
+        };
+      })
+    );
+  },
+});
@@ -0,0 +1,163 @@
+import { v } from "convex/values";
+import type { Doc } from "./_generated/dataModel";
+import { getTeamId } from "../_shared/team";
+import { authQuery } from "./users/utils";
+
+const HOUR_MS = 60 * 60 * 1000;
+
+/**
+ * Milliseconds of [start, end] that fall inside [from, to].
+ */
+function overlapMs(start: number, end: number, from: number, to: number) {
+  return Math.max(0, Math.min(end, to) - Math.max(start, from));
+}
+
+function toHours(ms: number) {
+  return Math.round((ms / HOUR_MS) * 100) / 100;
+}
+
+/**
+ * When an instance stopped accruing time: now if running, the last status
+ * change if paused, and the stop time if stopped.
+ */
+function instanceEnd(instance: Doc<"devboxInstances">, now: number) {
+  switch (instance.status) {
+    case "running":
+      return now;
+    case "stopped":
+      return instance.stoppedAt ?? instance.updatedAt;
+    default:
+      return instance.updatedAt;
+  }
+}
+
+function taskRunEnd(run: Doc<"taskRuns">, now: number) {
+  if (run.completedAt) return run.completedAt;
+  return run.status === "pending" || run.status === "running"
+    ? now
+    : run.updatedAt;
+}
+
+type AgentUsage = {
+  agentName: string;
+  runs: number;
+  completed: number;
+  failed: number;
+  hours: number;
+};
+
+/**
+ * Team-wide usage over a time window: devbox instance hours by provider,
+ * task run counts, and per-agent run counts and runtime. Instance hours are
+ * wall-clock time from creation until paused or stopped.
+ */
+export const summary = authQuery({
+  args: {
+    teamSlugOrId: v.string(),
+    from: v.number(),
+    to: v.number(),
+  },
+  handler: async (ctx, args) => {
+    const teamId = await getTeamId(ctx, args.teamSlugOrId);
+    const now = Date.now();
+    const to = Math.min(args.to, now);
+
+    // Instances: anything created before the window ends may overlap it
+    const instances = await ctx.db
+      .query("devboxInstances")
+      .withIndex("by_team", (q) =>
+        q.eq("teamId", teamId).lt("createdAt", to)
+      )
+      .collect();
+
+    const providers = new Map<string, { count: number; ms: number }>();
+    let instanceCount = 0;
+    let instanceMs = 0;
+    for (const instance of instances) {
+      const ms = overlapMs(
+        instance.createdAt,
+        instanceEnd(instance, now),
+        args.from,
+        to
+      );
+      if (ms === 0) continue;
+
+      const info = await ctx.db
+        .query("devboxInfo")
+        .withIndex("by_devboxId", (q) => q.eq("devboxId", instance.devboxId))
+        .first();
+      const provider = info?.provider ?? "unknown";
+      const entry = providers.get(provider) ?? { count: 0, ms: 0 };
+      entry.count += 1;
+      entry.ms += ms;
+      providers.set(provider, entry);
+
+      instanceCount += 1;
+      instanceMs += ms;
+    }
+
+    // Task runs: counted by creation time, runtime clipped to the window
+    const runs = await ctx.db
+      .query("taskRuns")
+      .withIndex("by_team_user", (q) => q.eq("teamId", teamId))
+      .filter((q) => q.lt(q.field("createdAt"), to))
+      .collect();
+
+    const agents = new Map<string, AgentUsage & { ms: number }>();
+    const totals = { total: 0, completed: 0, failed: 0, running: 0, ms: 0 };
+    for (const run of runs) {
+      if (run.createdAt < args.from) continue;
+
+      const ms = overlapMs(run.createdAt, taskRunEnd(run, now), args.from, to);
+      const agentName = run.agentName ?? "unknown";
+      const agent = agents.get(agentName) ?? {
+        agentName,
+        runs: 0,
+        completed: 0,
+        failed: 0,
+        hours: 0,
+        ms: 0,
+      };
+      agent.runs += 1;
+      agent.ms += ms;
+      totals.total += 1;
+      totals.ms += ms;
+      if (run.status === "completed") {
+        agent.completed += 1;
+        totals.completed += 1;
+      } else if (run.status === "failed") {
+        agent.failed += 1;
+        totals.failed += 1;
+      } else if (run.status === "pending" || run.status === "running") {
+        totals.running += 1;
+      }
+      agents.set(agentName, agent);
+    }
+
+    return {
+      from: args.from,
+      to,
+      instances: {
+        count: instanceCount,
+        hours: toHours(instanceMs),
+        byProvider: [...providers.entries()]
+          .map(([provider, entry]) => ({
+            provider,
+            count: entry.count,
+            hours: toHours(entry.ms),
+          }))
+          .sort((a, b) => b.hours - a.hours),
+      },
+      taskRuns: {
+        total: totals.total,
+        completed: totals.completed,
+        failed: totals.failed,
+        running: totals.running,
+        hours: toHours(totals.ms),
+      },
+      agents: [...agents.values()]
+        .map(({ ms, ...agent }) => ({ ...agent, hours: toHours(ms) }))
+        .sort((a, b) => b.runs - a.runs),
+    };
+  },
+});
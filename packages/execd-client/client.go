@@ -0,0 +1,296 @@
+// Package execd is a client for the cmux exec daemon (cmux-execd) that runs
+// inside sandboxes. It wraps the /exec streaming endpoint and the job replay
+// endpoint so callers get typed events, context cancellation, and automatic
+// re-attachment when a stream drops mid-command.
+//
+// execd only runs commands; it has no interactive session or PTY endpoints,
+// so this package has no helpers for them. Interactive terminals are served
+// by the cloudrouter worker's /pty WebSocket.
+package execd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPort is the port cmux-execd listens on inside a sandbox.
+const DefaultPort = 39375
+
+// jobIDHeader carries the job ID on /exec and /jobs responses.
+const jobIDHeader = "X-Execd-Job-Id"
+
+// Client talks to a single cmux-execd instance.
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	header       http.Header
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient overrides the HTTP client used for requests. The client must
+// not set an overall Timeout, since exec streams can run indefinitely; use
+// contexts to bound individual calls instead.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader adds a header to every request, e.g. an Authorization header
+// when execd is reached through an authenticating proxy.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithRetries sets how many times a failed connection is retried and the
+// initial backoff between attempts. The backoff doubles after each attempt.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// New creates a client for the execd instance at baseURL
+// (e.g. "http://localhost:39375").
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		httpClient:   &http.Client{},
+		header:       make(http.Header),
+		maxRetries:   3,
+		retryBackoff: 250 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ExecRequest describes a command to run.
+type ExecRequest struct {
+	// Command is run with the selected Shell.
+	Command string
+	// Shell is "sh", "bash", "cmd", or "powershell". Empty selects the
+	// platform default (sh, or cmd on Windows).
+	Shell string
+	// Argv runs a program directly without a shell, instead of Command.
+	Argv []string
+	// Timeout kills the command (and its process group) after the duration
+	// elapses. Zero means no timeout. execd has millisecond resolution, so
+	// the timeout is rounded up to a whole millisecond.
+	Timeout time.Duration
+	// Detach keeps the command running however long the stream stays
+	// dropped, and when the context passed to Exec is canceled. Other
+	// commands are killed when the context is canceled, or if no client
+	// re-attaches within execd's reconnect grace period (one minute by
+	// default). Streams returned by Exec re-attach automatically either way.
+	Detach bool
+}
+
+type execRequestBody struct {
+	Command   string   `json:"command"`
+	Shell     string   `json:"shell,omitempty"`
+	Argv      []string `json:"argv,omitempty"`
+	TimeoutMs *int     `json:"timeout_ms,omitempty"`
+	Detach    bool     `json:"detach,omitempty"`
+}
+
+// APIError is returned when execd responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("execd error (%d): %s", e.StatusCode, e.Message)
+}
+
+// Health checks that execd is reachable.
+func (c *Client) Health(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodGet, "/healthz", nil, true)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Exec starts a command and returns a stream of its events. The caller must
+// Close the stream. Canceling ctx stops the stream and, unless the command is
+// detached, kills it via Cancel.
+func (c *Client) Exec(ctx context.Context, req ExecRequest) (*Stream, error) {
+	if strings.TrimSpace(req.Command) == "" && len(req.Argv) == 0 {
+		return nil, errors.New("command is required")
+	}
+	body := execRequestBody{Command: req.Command, Shell: req.Shell, Argv: req.Argv, Detach: req.Detach}
+	if req.Timeout > 0 {
+		timeoutMs := int((req.Timeout + time.Millisecond - 1) / time.Millisecond)
+		body.TimeoutMs = &timeoutMs
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only retry failures that happen before execd sees the request, so a
+	// command is never started twice.
+	resp, err := c.do(ctx, http.MethodPost, "/exec", data, false)
+	if err != nil {
+		return nil, err
+	}
+	s := newStream(ctx, c, resp)
+	if !req.Detach {
+		s.cancelJobOnDone()
+	}
+	return s, nil
+}
+
+// Cancel kills a job and everything it spawned, whether or not it is
+// detached. Its output, ending with the exit event, can still be replayed
+// with Attach.
+func (c *Client) Cancel(ctx context.Context, jobID string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/jobs/"+url.PathEscape(jobID), nil, true)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Attach re-attaches to a job and replays every event with a sequence number
+// greater than after, then keeps streaming until the job exits.
+func (c *Client) Attach(ctx context.Context, jobID string, after uint64) (*Stream, error) {
+	resp, err := c.attach(ctx, jobID, after)
+	if err != nil {
+		return nil, err
+	}
+	s := newStream(ctx, c, resp)
+	s.lastSeq = after
+	return s, nil
+}
+
+func (c *Client) attach(ctx context.Context, jobID string, after uint64) (*http.Response, error) {
+	path := fmt.Sprintf("/jobs/%s/output?from=%s", url.PathEscape(jobID), strconv.FormatUint(after, 10))
+	return c.do(ctx, http.MethodGet, path, nil, true)
+}
+
+// Result is the collected output of a finished command.
+type Result struct {
+	JobID    string
+	ExitCode int
+	Stdout   []string
+	Stderr   []string
+	Errors   []string
+}
+
+// Run executes a command and collects all of its output.
+func (c *Client) Run(ctx context.Context, req ExecRequest) (*Result, error) {
+	stream, err := c.Exec(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	result := &Result{JobID: stream.JobID()}
+	exited := false
+	for stream.Next() {
+		event := stream.Event()
+		switch event.Type {
+		case EventStdout:
+			result.Stdout = append(result.Stdout, event.Data)
+		case EventStderr:
+			result.Stderr = append(result.Stderr, event.Data)
+		case EventError:
+			result.Errors = append(result.Errors, event.Message)
+		case EventExit:
+			exited = true
+			if event.Code != nil {
+				result.ExitCode = *event.Code
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return result, err
+	}
+	if !exited {
+		return result, errors.New("stream ended without an exit event")
+	}
+	return result, nil
+}
+
+// do sends a request, retrying connection failures with exponential backoff.
+// When retryAll is set, 5xx responses and failures after the request was
+// written are retried as well.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, retryAll bool) (*http.Response, error) {
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doOnce(ctx, method, path, body)
+		retryable := false
+		switch {
+		case err != nil:
+			retryable = ctx.Err() == nil && (retryAll || isDialError(err))
+		case resp.StatusCode >= 500 && retryAll:
+			retryable = true
+		}
+		if err == nil && resp.StatusCode >= 400 {
+			message, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+			err = &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(message))}
+		}
+		if err == nil {
+			return resp, nil
+		}
+		if !retryable || attempt >= c.maxRetries {
+			return nil, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.httpClient.Do(req)
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
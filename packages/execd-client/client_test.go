@@ -0,0 +1,162 @@
+package execd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunReattachesAfterDroppedStream(t *testing.T) {
+	exitCode := 3
+	events := []Event{
+		{Seq: 1, Type: EventStdout, Data: "one"},
+		{Seq: 2, Type: EventStderr, Data: "two"},
+		{Seq: 3, Type: EventStdout, Data: "three"},
+		{Seq: 4, Type: EventExit, Code: &exitCode},
+	}
+	writeEvents := func(w http.ResponseWriter, events []Event) {
+		for _, event := range events {
+			payload, _ := json.Marshal(event)
+			fmt.Fprintf(w, "%s\n", payload)
+		}
+	}
+
+	var attachFrom string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /exec", func(w http.ResponseWriter, r *http.Request) {
+		var body execRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !body.Detach {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(jobIDHeader, "job1")
+		// Drop the stream after the first two events.
+		writeEvents(w, events[:2])
+	})
+	mux.HandleFunc("GET /jobs/{id}/output", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "job1" {
+			http.NotFound(w, r)
+			return
+		}
+		attachFrom = r.URL.Query().Get("from")
+		w.Header().Set(jobIDHeader, "job1")
+		// Replay one duplicate to make sure it is filtered out.
+		writeEvents(w, events[1:])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := New(server.URL, WithRetries(2, time.Millisecond))
+	result, err := client.Run(ctx, ExecRequest{Command: "true", Detach: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if attachFrom != "2" {
+		t.Fatalf("expected re-attach from seq 2, got %q", attachFrom)
+	}
+	if result.JobID != "job1" || result.ExitCode != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Stdout) != 2 || result.Stdout[1] != "three" || len(result.Stderr) != 1 {
+		t.Fatalf("unexpected output: stdout=%v stderr=%v", result.Stdout, result.Stderr)
+	}
+}
+
+func TestExecDoesNotRetryRejectedRequests(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(server.URL, WithRetries(3, time.Millisecond))
+	_, err := client.Exec(context.Background(), ExecRequest{Command: "true"})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected APIError 500, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestExecRoundsSubMillisecondTimeoutUp(t *testing.T) {
+	var got *int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body execRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		got = body.TimeoutMs
+		exitCode := 0
+		payload, _ := json.Marshal(Event{Seq: 1, Type: EventExit, Code: &exitCode})
+		fmt.Fprintf(w, "%s\n", payload)
+	}))
+	t.Cleanup(server.Close)
+
+	client := New(server.URL)
+	if _, err := client.Run(context.Background(), ExecRequest{Command: "true", Timeout: 500 * time.Microsecond}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got == nil || *got != 1 {
+		t.Fatalf("expected timeout_ms 1, got %v", got)
+	}
+}
+
+func TestExecCancelsJobWhenContextCanceled(t *testing.T) {
+	for _, detach := range []bool{false, true} {
+		canceled := make(chan string, 1)
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /exec", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(jobIDHeader, "job1")
+			payload, _ := json.Marshal(Event{Seq: 1, Type: EventStdout, Data: "running"})
+			fmt.Fprintf(w, "%s\n", payload)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		})
+		mux.HandleFunc("DELETE /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+			canceled <- r.PathValue("id")
+			w.WriteHeader(http.StatusNoContent)
+		})
+		server := httptest.NewServer(mux)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		client := New(server.URL, WithRetries(0, time.Millisecond))
+		stream, err := client.Exec(ctx, ExecRequest{Command: "sleep 60", Detach: detach})
+		if err != nil {
+			t.Fatalf("exec: %v", err)
+		}
+		if !stream.Next() {
+			t.Fatalf("expected an event, got err %v", stream.Err())
+		}
+		cancel()
+		if stream.Next() {
+			t.Fatalf("expected the stream to stop after cancel")
+		}
+		stream.Close()
+
+		select {
+		case id := <-canceled:
+			if detach {
+				t.Fatalf("detached job %s was canceled", id)
+			}
+			if id != "job1" {
+				t.Fatalf("canceled job %q, want job1", id)
+			}
+		default:
+			if !detach {
+				t.Fatalf("job not canceled after the context was canceled")
+			}
+		}
+		server.Close()
+	}
+}
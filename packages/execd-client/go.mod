@@ -0,0 +1,3 @@
+module github.com/manaflow-ai/execd-client
+
+go 1.24.0
@@ -0,0 +1,207 @@
+package execd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// EventType identifies the kind of a streamed event.
+type EventType string
+
+const (
+	EventStdout EventType = "stdout"
+	EventStderr EventType = "stderr"
+	EventError  EventType = "error"
+	EventExit   EventType = "exit"
+)
+
+// Event is a single line of the execd JSON-lines stream.
+type Event struct {
+	// Seq is the job-scoped sequence number. A truncation notice during
+	// replay carries the seq of the last event it stands in for.
+	Seq uint64 `json:"seq,omitempty"`
+	// Time is when execd observed the event.
+	Time    time.Time `json:"ts"`
+	Type    EventType `json:"type"`
+	Data    string    `json:"data,omitempty"`
+	Code    *int      `json:"code,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Stream iterates over the events of a running job. It is not safe for
+// concurrent use.
+//
+//	for stream.Next() {
+//		event := stream.Event()
+//		...
+//	}
+//	if err := stream.Err(); err != nil { ... }
+type Stream struct {
+	ctx       context.Context
+	client    *Client
+	jobID     string
+	body      io.ReadCloser
+	scanner   *bufio.Scanner
+	lastSeq   uint64
+	event     Event
+	exited    bool
+	err       error
+	reattempt int
+	// stopCancel disarms the hook that cancels the job when ctx is done;
+	// cancelDone is closed once that hook has run.
+	stopCancel func() bool
+	cancelDone chan struct{}
+}
+
+// cancelTimeout bounds the request that kills a job after ctx is canceled.
+const cancelTimeout = 5 * time.Second
+
+func newStream(ctx context.Context, client *Client, resp *http.Response) *Stream {
+	s := &Stream{
+		ctx:    ctx,
+		client: client,
+		jobID:  resp.Header.Get(jobIDHeader),
+	}
+	s.reset(resp.Body)
+	return s
+}
+
+func (s *Stream) reset(body io.ReadCloser) {
+	if s.body != nil {
+		s.body.Close()
+	}
+	s.body = body
+	s.scanner = bufio.NewScanner(body)
+	s.scanner.Buffer(make([]byte, 0, 64*1024), 2*1024*1024)
+}
+
+// cancelJobOnDone kills the job when ctx is canceled before it exits.
+func (s *Stream) cancelJobOnDone() {
+	if s.jobID == "" {
+		return
+	}
+	client, jobID := s.client, s.jobID
+	done := make(chan struct{})
+	s.cancelDone = done
+	s.stopCancel = context.AfterFunc(s.ctx, func() {
+		defer close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		defer cancel()
+		_ = client.Cancel(ctx, jobID)
+	})
+}
+
+// stopJobCancel disarms cancelJobOnDone, waiting for the cancel request if
+// it is already in flight.
+func (s *Stream) stopJobCancel() {
+	if s.stopCancel == nil {
+		return
+	}
+	if !s.stopCancel() {
+		<-s.cancelDone
+	}
+	s.stopCancel = nil
+}
+
+// JobID returns the execd job ID, which can be passed to Client.Attach.
+func (s *Stream) JobID() string {
+	return s.jobID
+}
+
+// LastSeq returns the sequence number of the last event received.
+func (s *Stream) LastSeq() uint64 {
+	return s.lastSeq
+}
+
+// Next advances to the next event. It returns false when the job has exited,
+// the context is canceled, or an unrecoverable error occurs.
+func (s *Stream) Next() bool {
+	if s.exited || s.err != nil {
+		return false
+	}
+	for {
+		if s.scanner.Scan() {
+			var event Event
+			if err := json.Unmarshal(s.scanner.Bytes(), &event); err != nil {
+				s.err = fmt.Errorf("decode event: %w", err)
+				return false
+			}
+			if event.Seq != 0 {
+				if event.Seq <= s.lastSeq {
+					// Already delivered before a re-attach.
+					continue
+				}
+				s.lastSeq = event.Seq
+			}
+			s.event = event
+			s.reattempt = 0
+			if event.Type == EventExit {
+				s.exited = true
+				s.stopJobCancel()
+			}
+			return true
+		}
+
+		readErr := s.scanner.Err()
+		if readErr == nil {
+			readErr = io.ErrUnexpectedEOF
+		}
+		if err := s.ctx.Err(); err != nil {
+			s.err = err
+			return false
+		}
+		if s.jobID == "" || s.reattempt >= s.client.maxRetries {
+			s.err = fmt.Errorf("stream interrupted: %w", readErr)
+			return false
+		}
+		s.reattempt++
+		resp, err := s.client.attach(s.ctx, s.jobID, s.lastSeq)
+		if err != nil {
+			s.err = errors.Join(fmt.Errorf("stream interrupted: %w", readErr), err)
+			return false
+		}
+		s.reset(resp.Body)
+	}
+}
+
+// Event returns the event read by the last call to Next.
+func (s *Stream) Event() Event {
+	return s.event
+}
+
+// Err returns the error that stopped iteration, if any.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close releases the underlying connection. If ctx was canceled, it first
+// waits for the request that kills the job.
+func (s *Stream) Close() error {
+	s.stopJobCancel()
+	if s.body == nil {
+		return nil
+	}
+	return s.body.Close()
+}
+
+// All returns an iterator over the remaining events. Iteration stops at the
+// first error, which is yielded along with a zero Event.
+func (s *Stream) All() iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		for s.Next() {
+			if !yield(s.Event(), nil) {
+				return
+			}
+		}
+		if err := s.Err(); err != nil {
+			yield(Event{}, err)
+		}
+	}
+}
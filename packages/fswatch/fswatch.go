@@ -0,0 +1,338 @@
+// Package fswatch reports changes under a directory tree for the CLIs' watch
+// commands. It uses filesystem events (inotify, kqueue,
+// ReadDirectoryChangesW), watching every directory that isn't skipped, and
+// falls back to polling stat snapshots when events are unavailable, e.g.
+// once the tree needs more watches than the OS allows one process.
+package fswatch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// All is sent on Changes when changes may have been missed, e.g. after the
+// kernel's event queue overflowed. Treat the whole tree as changed.
+const All = "."
+
+// DefaultPollInterval is how often the polling fallback scans the tree
+const DefaultPollInterval = 500 * time.Millisecond
+
+var errPollForced = errors.New("polling forced")
+
+// Options configures a Watcher
+type Options struct {
+	// Skip reports whether an entry with this base name is excluded.
+	// Skipped directories are neither watched nor scanned.
+	Skip func(name string, isDir bool) bool
+	// PollInterval is how often the polling fallback scans the tree.
+	// Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+
+	// forcePoll skips filesystem events, for tests
+	forcePoll bool
+}
+
+// Watcher reports changes under a root directory
+type Watcher struct {
+	root    string
+	opts    Options
+	changes chan string
+	errors  chan error
+	done    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+	polling atomic.Bool
+}
+
+// New starts watching root. Changes made after New returns are reported on
+// Changes; the caller must Close the watcher.
+func New(root string, opts Options) (*Watcher, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Skip == nil {
+		opts.Skip = func(string, bool) bool { return false }
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	w := &Watcher{
+		root:    root,
+		opts:    opts,
+		changes: make(chan string, 256),
+		errors:  make(chan error, 16),
+		done:    make(chan struct{}),
+	}
+
+	notifyErr := errPollForced
+	if !opts.forcePoll {
+		notifyErr = w.startNotify()
+	}
+	if notifyErr != nil {
+		snapshot, err := w.scan()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+		}
+		if !opts.forcePoll {
+			w.sendErr(w.pollingNotice(notifyErr))
+		}
+		w.polling.Store(true)
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.poll(snapshot)
+		}()
+	}
+	return w, nil
+}
+
+// Changes delivers the path of each changed entry, relative to the root and
+// slash-separated, or All. A path may be reported more than once per change.
+func (w *Watcher) Changes() <-chan string {
+	return w.changes
+}
+
+// Errors delivers problems that don't stop the watcher, including a switch
+// to polling
+func (w *Watcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Polling reports whether the watcher has fallen back to polling
+func (w *Watcher) Polling() bool {
+	return w.polling.Load()
+}
+
+// Close stops the watcher
+func (w *Watcher) Close() error {
+	w.once.Do(func() { close(w.done) })
+	w.wg.Wait()
+	return nil
+}
+
+func (w *Watcher) pollingNotice(err error) error {
+	return fmt.Errorf("file events unavailable (%v); checking for changes every %s instead", err, w.opts.PollInterval)
+}
+
+// send reports a change unless the watcher is closed
+func (w *Watcher) send(rel string) {
+	select {
+	case w.changes <- rel:
+	case <-w.done:
+	}
+}
+
+// sendErr reports an error, dropping it if nobody is reading errors
+func (w *Watcher) sendErr(err error) {
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+func (w *Watcher) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func (w *Watcher) startNotify() error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.addTree(fsw, w.root, false); err != nil {
+		fsw.Close()
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.notifyLoop(fsw)
+	}()
+	return nil
+}
+
+// addTree watches dir and every directory under it that isn't skipped. For
+// a directory created while watching, report entries already in it too:
+// they may have been written before its watch was added.
+func (w *Watcher) addTree(fsw *fsnotify.Watcher, dir string, report bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Entries can disappear mid-walk; their events say so
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if path != dir && w.opts.Skip(d.Name(), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if report && path != dir {
+			if rel, ok := w.rel(path); ok {
+				w.send(rel)
+			}
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := fsw.Add(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) notifyLoop(fsw *fsnotify.Watcher) {
+	defer fsw.Close()
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			if err := w.handleEvent(fsw, event); err != nil {
+				w.fallBackToPolling(fsw, err)
+				return
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				w.send(All)
+				continue
+			}
+			w.sendErr(err)
+		}
+	}
+}
+
+// handleEvent reports the entry an event names and starts watching new
+// directories. It returns an error only when a new directory can't be
+// watched.
+func (w *Watcher) handleEvent(fsw *fsnotify.Watcher, event fsnotify.Event) error {
+	rel, ok := w.rel(event.Name)
+	if !ok || rel == "." {
+		return nil
+	}
+	info, err := os.Lstat(event.Name)
+	isDir := err == nil && info.IsDir()
+	if w.opts.Skip(filepath.Base(event.Name), isDir) {
+		return nil
+	}
+	w.send(rel)
+	if isDir && event.Has(fsnotify.Create) {
+		return w.addTree(fsw, event.Name, true)
+	}
+	return nil
+}
+
+// fallBackToPolling replaces filesystem events with polling, e.g. after a
+// new directory pushed the tree past the OS watch limit
+func (w *Watcher) fallBackToPolling(fsw *fsnotify.Watcher, cause error) {
+	fsw.Close()
+	w.polling.Store(true)
+	w.sendErr(w.pollingNotice(cause))
+	snapshot, err := w.scan()
+	if err != nil {
+		w.sendErr(fmt.Errorf("scan failed: %w", err))
+	}
+	w.send(All)
+	w.poll(snapshot)
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// scan records size, mtime, and mode for every entry under the root that
+// isn't skipped, keyed by slash-separated relative path
+func (w *Watcher) scan() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear mid-scan; the next scan will catch up
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if path == w.root {
+			return nil
+		}
+		if w.opts.Skip(d.Name(), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if rel, ok := w.rel(path); ok {
+			stamps[rel] = fileStamp{size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		}
+		return nil
+	})
+	return stamps, err
+}
+
+func (w *Watcher) poll(snapshot map[string]fileStamp) {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+		current, err := w.scan()
+		if err != nil {
+			w.sendErr(fmt.Errorf("scan failed: %w", err))
+			continue
+		}
+		for _, rel := range changedPaths(snapshot, current) {
+			w.send(rel)
+		}
+		snapshot = current
+	}
+}
+
+// changedPaths returns the sorted paths that were added, removed, or
+// modified between two scans
+func changedPaths(before, after map[string]fileStamp) []string {
+	var changed []string
+	for rel, stamp := range after {
+		if prev, ok := before[rel]; !ok || prev != stamp {
+			changed = append(changed, rel)
+		}
+	}
+	for rel := range before {
+		if _, ok := after[rel]; !ok {
+			changed = append(changed, rel)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
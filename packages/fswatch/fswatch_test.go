@@ -0,0 +1,121 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitFor reads changes until want has been reported
+func waitFor(t *testing.T, w *Watcher, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case rel := <-w.Changes():
+			if rel == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no change reported for %s", want)
+		}
+	}
+}
+
+// expectNone fails if anything other than a path in allowed is reported
+// within a short window
+func expectNone(t *testing.T, w *Watcher, allowed ...string) {
+	t.Helper()
+	timeout := time.After(300 * time.Millisecond)
+	for {
+		select {
+		case rel := <-w.Changes():
+			ok := false
+			for _, a := range allowed {
+				ok = ok || rel == a
+			}
+			if !ok {
+				t.Fatalf("unexpected change reported for %s", rel)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testWatcher(t *testing.T, forcePoll bool) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main")
+	writeFile(t, filepath.Join(root, "node_modules", "dep", "index.js"), "")
+
+	w, err := New(root, Options{
+		Skip:         func(name string, isDir bool) bool { return isDir && name == "node_modules" },
+		PollInterval: 20 * time.Millisecond,
+		forcePoll:    forcePoll,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer w.Close()
+	if w.Polling() != forcePoll {
+		t.Fatalf("Polling() = %v, want %v", w.Polling(), forcePoll)
+	}
+
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main // edited")
+	waitFor(t, w, "src/main.go")
+
+	// Files in a directory created after the watch started
+	writeFile(t, filepath.Join(root, "pkg", "util", "util.go"), "package util")
+	waitFor(t, w, "pkg/util/util.go")
+
+	if err := os.Remove(filepath.Join(root, "src", "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, "src/main.go")
+
+	writeFile(t, filepath.Join(root, "node_modules", "dep", "index.js"), "changed")
+	expectNone(t, w, "src", "pkg", "pkg/util", "pkg/util/util.go")
+}
+
+func TestWatcherReportsChanges(t *testing.T) {
+	testWatcher(t, false)
+}
+
+func TestWatcherPollingReportsChanges(t *testing.T) {
+	testWatcher(t, true)
+}
+
+func TestChangedPaths(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	before := map[string]fileStamp{
+		"a":     {modTime: t0, size: 1},
+		"b":     {modTime: t0, size: 1},
+		"dir/c": {modTime: t0, size: 1},
+	}
+	after := map[string]fileStamp{
+		"a":     {modTime: t0, size: 1},
+		"dir/c": {modTime: t0.Add(time.Second), size: 1},
+		"d":     {modTime: t0, size: 1},
+	}
+	got := changedPaths(before, after)
+	want := []string{"b", "d", "dir/c"}
+	if len(got) != len(want) {
+		t.Fatalf("changedPaths = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("changedPaths = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/manaflow-ai/fswatch
+
+go 1.24.0
+
+require github.com/fsnotify/fsnotify v1.7.0
+
+require golang.org/x/sys v0.40.0 // indirect
@@ -520,6 +520,8 @@ export type UpdateEnvironmentBody = {
     description?: string;
     maintenanceScript?: string;
     devScript?: string;
+    selectedRepos?: Array<string>;
+    envVarsContent?: string;
 };
 
 export type ExposedService = {
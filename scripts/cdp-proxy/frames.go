@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2).
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xA
+)
+
+// maxFramePayload bounds a single frame when no smaller message limit is
+// configured. Screenshots and heap snapshots can be large, but anything
+// beyond this is treated as a protocol error rather than buffered.
+const maxFramePayload = 512 << 20
+
+var errFrameTooLarge = errors.New("websocket frame exceeds size limit")
+
+// wsFrame is a single WebSocket frame. raw holds the exact bytes read from
+// the wire so that frames can be forwarded unchanged; payload is unmasked.
+type wsFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+	raw     []byte
+}
+
+// frameHeader is a parsed frame header. raw holds its exact wire bytes and
+// mask is nil for unmasked frames.
+type frameHeader struct {
+	fin    bool
+	opcode byte
+	length uint64
+	mask   []byte
+	raw    []byte
+}
+
+// readFrameHeader reads the header of the next frame, leaving its payload
+// unread.
+func readFrameHeader(r io.Reader) (*frameHeader, error) {
+	header := make([]byte, 2, 14)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	h := &frameHeader{
+		fin:    header[0]&0x80 != 0,
+		opcode: header[0] & 0x0f,
+	}
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	extra := 0
+	switch length {
+	case 126:
+		extra = 2
+	case 127:
+		extra = 8
+	}
+	if masked {
+		extra += 4
+	}
+	if extra > 0 {
+		header = header[:2+extra]
+		if _, err := io.ReadFull(r, header[2:]); err != nil {
+			return nil, err
+		}
+	}
+
+	offset := 2
+	switch length {
+	case 126:
+		length = uint64(binary.BigEndian.Uint16(header[2:4]))
+		offset = 4
+	case 127:
+		length = binary.BigEndian.Uint64(header[2:10])
+		offset = 10
+	}
+	h.length = length
+	if masked {
+		h.mask = header[offset : offset+4]
+	}
+	h.raw = header
+	return h, nil
+}
+
+// readFrame reads one frame, rejecting payloads larger than limit before
+// buffering them.
+func readFrame(r io.Reader, limit int) (*wsFrame, error) {
+	h, err := readFrameHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if h.length > uint64(limit) {
+		return nil, errFrameTooLarge
+	}
+
+	frame := &wsFrame{fin: h.fin, opcode: h.opcode}
+	frame.raw = make([]byte, len(h.raw)+int(h.length))
+	copy(frame.raw, h.raw)
+	if _, err := io.ReadFull(r, frame.raw[len(h.raw):]); err != nil {
+		return nil, err
+	}
+
+	frame.payload = frame.raw[len(h.raw):]
+	if h.mask != nil {
+		payload := make([]byte, h.length)
+		for i := range payload {
+			payload[i] = frame.payload[i] ^ h.mask[i%4]
+		}
+		frame.payload = payload
+	}
+	return frame, nil
+}
+
+// encodeFrame builds a single final frame. Frames sent by a client must be
+// masked; frames sent by a server must not be.
+func encodeFrame(opcode byte, payload []byte, mask bool) ([]byte, error) {
+	header := []byte{0x80 | opcode, 0}
+	length := len(payload)
+	switch {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+	if !mask {
+		return append(header, payload...), nil
+	}
+
+	header[1] |= 0x80
+	var key [4]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, fmt.Errorf("generate mask: %w", err)
+	}
+	header = append(header, key[:]...)
+	out := make([]byte, len(header)+length)
+	copy(out, header)
+	for i, b := range payload {
+		out[len(header)+i] = b ^ key[i%4]
+	}
+	return out, nil
+}
+
+// encodeCloseFrame builds an unmasked close frame, truncating the reason to
+// fit a control frame.
+func encodeCloseFrame(code uint16, reason string) ([]byte, error) {
+	if len(reason) > maxCloseReasonBytes {
+		reason = reason[:maxCloseReasonBytes]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, code)
+	payload = append(payload, reason...)
+	return encodeFrame(opClose, payload, false)
+}
+
+// messageAssembler joins fragmented data frames back into whole messages.
+// limit bounds the whole message, since readFrame only bounds each fragment.
+type messageAssembler struct {
+	limit  int
+	opcode byte
+	buf    []byte
+	active bool
+}
+
+// add feeds a frame and returns the completed message, if any. Control frames
+// never complete a message. It fails with errFrameTooLarge as soon as the
+// fragments received so far exceed the limit, before buffering any more.
+func (a *messageAssembler) add(frame *wsFrame) (byte, []byte, bool, error) {
+	switch frame.opcode {
+	case opText, opBinary:
+		if len(frame.payload) > a.limit {
+			return 0, nil, false, errFrameTooLarge
+		}
+		if frame.fin {
+			a.active = false
+			a.buf = nil
+			return frame.opcode, frame.payload, true, nil
+		}
+		a.opcode = frame.opcode
+		a.buf = append(a.buf[:0], frame.payload...)
+		a.active = true
+	case opContinuation:
+		if !a.active {
+			return 0, nil, false, nil
+		}
+		if len(a.buf)+len(frame.payload) > a.limit {
+			a.active = false
+			a.buf = nil
+			return 0, nil, false, errFrameTooLarge
+		}
+		a.buf = append(a.buf, frame.payload...)
+		if frame.fin {
+			a.active = false
+			message := a.buf
+			a.buf = nil
+			return a.opcode, message, true, nil
+		}
+	}
+	return 0, nil, false, nil
+}
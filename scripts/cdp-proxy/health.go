@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const healthProbeTimeout = 3 * time.Second
+
+type healthResponse struct {
+	Status          string `json:"status"`
+	Browser         string `json:"browser,omitempty"`
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	LatencyMs       int64  `json:"latencyMs"`
+	Error           string `json:"error,omitempty"`
+}
+
+// serveHealth probes Chrome's /json/version endpoint so that "browser ready"
+// checks reflect the browser itself rather than whether the proxy's port is
+// open.
+func (p *cdpProxy) serveHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	version, err := p.probeUpstream(ctx)
+	result := healthResponse{LatencyMs: time.Since(start).Milliseconds()}
+	status := http.StatusOK
+	if err != nil {
+		result.Status = "unavailable"
+		result.Error = err.Error()
+		status = http.StatusServiceUnavailable
+	} else {
+		result.Status = "ok"
+		result.Browser = version.Browser
+		result.ProtocolVersion = version.ProtocolVersion
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+type browserVersion struct {
+	Browser         string `json:"Browser"`
+	ProtocolVersion string `json:"Protocol-Version"`
+
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+func (p *cdpProxy) probeUpstream(ctx context.Context) (*browserVersion, error) {
+	var version browserVersion
+	if err := p.fetchUpstreamJSON(ctx, "/json/version", &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// fetchUpstreamJSON decodes a DevTools HTTP endpoint straight from Chrome.
+func (p *cdpProxy) fetchUpstreamJSON(ctx context.Context, path string, v any) error {
+	target := p.target()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+target.addr+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Host = target.hostHeader
+	resp, err := p.reverse.Transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upstream returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// track registers an open WebSocket session so drain can close it.
+func (p *cdpProxy) track(id uint64, closeSession func(reason string)) {
+	p.sessionsMu.Lock()
+	if p.draining.Load() {
+		p.sessionsMu.Unlock()
+		closeSession(drainReason)
+		return
+	}
+	if p.sessions == nil {
+		p.sessions = make(map[uint64]func(string))
+	}
+	p.sessions[id] = closeSession
+	p.sessionsMu.Unlock()
+}
+
+func (p *cdpProxy) untrack(id uint64) {
+	p.sessionsMu.Lock()
+	delete(p.sessions, id)
+	p.sessionsMu.Unlock()
+}
+
+// drainReason is the close reason clients see when the proxy shuts down.
+const drainReason = "cdp proxy is shutting down"
+
+// closeGrace is how long a client gets to answer the proxy's close frame
+// before its connection is dropped.
+const closeGrace = 2 * time.Second
+
+// drain refuses new WebSocket sessions and closes the open ones, sending each
+// client a going-away close frame and waiting up to closeGrace for them to
+// answer. It returns the number of sessions closed.
+func (p *cdpProxy) drain() int {
+	p.sessionsMu.Lock()
+	p.draining.Store(true)
+	sessions := p.sessions
+	p.sessions = nil
+	p.sessionsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, closeSession := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			closeSession(drainReason)
+		}()
+	}
+	wg.Wait()
+	return len(sessions)
+}
+
+// shutdown stops accepting connections, drains WebSocket sessions, and waits
+// up to timeout for in-flight HTTP requests. Hijacked WebSocket connections
+// are invisible to http.Server.Shutdown, hence the separate drain.
+func shutdown(server *http.Server, proxy *cdpProxy, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(ctx)
+	}()
+	if closed := proxy.drain(); closed > 0 {
+		log.Printf("closed %d websocket sessions", closed)
+	}
+	if err := <-shutdownErr; err != nil {
+		log.Printf("shutdown did not complete cleanly: %v", err)
+	}
+}
+
+// reloadTarget re-reads the target file and points new connections at the
+// target it describes. Open sessions keep their current upstream.
+func reloadTarget(proxy *cdpProxy, cfg proxyConfig) {
+	if cfg.targetFile == "" {
+		log.Print("SIGHUP ignored: CMUX_CDP_TARGET_FILE is not set")
+		return
+	}
+	next, err := applyTargetFile(cfg)
+	if err != nil {
+		log.Printf("reload failed, keeping current target: %v", err)
+		return
+	}
+	previous := proxy.target()
+	proxy.setTarget(next.targetHost, next.targetPort, next.hostHeader)
+	current := proxy.target()
+	if *current == *previous {
+		log.Print("reload: target unchanged")
+		return
+	}
+	// Pooled HTTP connections still point at the old target.
+	proxy.transport.CloseIdleConnections()
+	log.Printf("reload: forwarding to %s (Host header: %s)", current.addr, current.hostHeader)
+}
+
+// applyTargetFile overlays the target settings from cfg.targetFile onto cfg.
+// The file uses the systemd EnvironmentFile format and may set
+// CMUX_CDP_TARGET_HOST, CMUX_CDP_TARGET_PORT, and
+// CMUX_CDP_TARGET_HOST_HEADER; other keys are ignored. Moving the port without
+// a Host header resets the header to localhost:<port>. A missing file leaves
+// cfg unchanged so the unit can point at it before anyone creates it.
+func applyTargetFile(cfg proxyConfig) (proxyConfig, error) {
+	file, err := os.Open(cfg.targetFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return cfg, fmt.Errorf("%s: malformed line %q", cfg.targetFile, line)
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	if err := scanner.Err(); err != nil {
+		return cfg, err
+	}
+
+	if host := values["CMUX_CDP_TARGET_HOST"]; host != "" {
+		cfg.targetHost = host
+	}
+	if raw := values["CMUX_CDP_TARGET_PORT"]; raw != "" {
+		port, err := strconv.Atoi(raw)
+		if err != nil || port <= 0 || port > 65535 {
+			return cfg, fmt.Errorf("%s: invalid CMUX_CDP_TARGET_PORT %q", cfg.targetFile, raw)
+		}
+		if port != cfg.targetPort && values["CMUX_CDP_TARGET_HOST_HEADER"] == "" {
+			cfg.hostHeader = fmt.Sprintf("localhost:%d", port)
+		}
+		cfg.targetPort = port
+	}
+	if header := values["CMUX_CDP_TARGET_HOST_HEADER"]; header != "" {
+		cfg.hostHeader = header
+	}
+	return cfg, nil
+}
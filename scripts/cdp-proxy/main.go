@@ -1,15 +1,15 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -18,6 +18,43 @@ type proxyConfig struct {
 	targetPort int
 	targetHost string
 	hostHeader string
+
+	// targetFile optionally overrides the target settings above and is
+	// re-read on SIGHUP; see applyTargetFile.
+	targetFile string
+
+	// drainTimeout bounds how long shutdown waits for in-flight requests.
+	drainTimeout time.Duration
+
+	// TLS is enabled when both paths are set.
+	tlsCertPath       string
+	tlsKeyPath        string
+	tlsReloadInterval time.Duration
+
+	// Recording is enabled when recordPath is set.
+	recordPath       string
+	recordMaxBytes   int64
+	recordMaxMessage int
+
+	// policyPath points at a JSON method filtering policy; see cdpPolicy.
+	policyPath string
+
+	// WebSocket session limits; zero disables each limit.
+	idleTimeout     time.Duration
+	maxSessions     int
+	maxMessageBytes int
+
+	// Reconnecting across browser restarts is enabled when reconnectGrace
+	// is set. reconnectReplay lists the method patterns re-sent to the new
+	// browser; see rememberForReplay.
+	reconnectGrace       time.Duration
+	reconnectReplay      []string
+	reconnectBufferBytes int
+
+	// Test-only traffic shaping for WebSocket sessions: added one-way
+	// latency and a bandwidth cap in bytes per second, per direction.
+	shapeLatency   time.Duration
+	shapeBandwidth int64
 }
 
 func getenv(key string, fallback string) string {
@@ -39,71 +76,111 @@ func parsePort(raw string, fallback int) int {
 	return value
 }
 
-func loadConfig() proxyConfig {
-	targetPort := parsePort(getenv("CMUX_CDP_TARGET_PORT", "39382"), 39382)
-	return proxyConfig{
-		listenPort: parsePort(getenv("CMUX_CDP_PROXY_PORT", "39381"), 39381),
-		targetPort: targetPort,
-		targetHost: getenv("CMUX_CDP_TARGET_HOST", "127.0.0.1"),
-		hostHeader: getenv("CMUX_CDP_TARGET_HOST_HEADER", fmt.Sprintf("localhost:%d", targetPort)),
+func parseDuration(raw string, fallback time.Duration) time.Duration {
+	if raw == "" {
+		return fallback
 	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		log.Fatalf("invalid duration value %q", raw)
+	}
+	return value
 }
 
-func main() {
-	log.SetFlags(log.LstdFlags | log.LUTC)
-	cfg := loadConfig()
-
-	targetURL := &url.URL{
-		Scheme: "http",
-		Host:   net.JoinHostPort(cfg.targetHost, strconv.Itoa(cfg.targetPort)),
+func parseSize(raw string, fallback int64) int64 {
+	if raw == "" {
+		return fallback
 	}
-
-	// Custom dialer with TCP_NODELAY for low-latency proxying
-	dialer := &net.Dialer{
-		Timeout:   30 * time.Second,
-		KeepAlive: 30 * time.Second,
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value <= 0 {
+		log.Fatalf("invalid size value %q", raw)
 	}
+	return value
+}
 
-	// Custom transport with TCP_NODELAY enabled
-	transport := &http.Transport{
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			conn, err := dialer.DialContext(ctx, network, addr)
-			if err != nil {
-				return nil, err
-			}
-			if tcpConn, ok := conn.(*net.TCPConn); ok {
-				if err := tcpConn.SetNoDelay(true); err != nil {
-					log.Printf("warning: failed to set TCP_NODELAY: %v", err)
-				}
-			}
-			return conn, nil
-		},
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
+func parseList(raw string, fallback []string) []string {
+	if raw == "" {
+		return fallback
+	}
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
+	return values
+}
 
-	proxy := httputil.NewSingleHostReverseProxy(targetURL)
-	proxy.Transport = transport
-	originalDirector := proxy.Director
-	proxy.Director = func(req *http.Request) {
-		originalDirector(req)
-		req.Host = cfg.hostHeader
-		req.Header.Set("Host", cfg.hostHeader)
-		req.Header.Del("Proxy-Connection")
+func loadConfig() proxyConfig {
+	targetPort := parsePort(getenv("CMUX_CDP_TARGET_PORT", "39382"), 39382)
+	cfg := proxyConfig{
+		listenPort:           parsePort(getenv("CMUX_CDP_PROXY_PORT", "39381"), 39381),
+		targetPort:           targetPort,
+		targetHost:           getenv("CMUX_CDP_TARGET_HOST", "127.0.0.1"),
+		hostHeader:           getenv("CMUX_CDP_TARGET_HOST_HEADER", fmt.Sprintf("localhost:%d", targetPort)),
+		targetFile:           os.Getenv("CMUX_CDP_TARGET_FILE"),
+		drainTimeout:         parseDuration(os.Getenv("CMUX_CDP_DRAIN_TIMEOUT"), 10*time.Second),
+		tlsCertPath:          os.Getenv("CMUX_CDP_TLS_CERT"),
+		tlsKeyPath:           os.Getenv("CMUX_CDP_TLS_KEY"),
+		tlsReloadInterval:    parseDuration(os.Getenv("CMUX_CDP_TLS_RELOAD_INTERVAL"), 30*time.Second),
+		recordPath:           os.Getenv("CMUX_CDP_RECORD_PATH"),
+		recordMaxBytes:       parseSize(os.Getenv("CMUX_CDP_RECORD_MAX_BYTES"), 256<<20),
+		recordMaxMessage:     int(parseSize(os.Getenv("CMUX_CDP_RECORD_MAX_MESSAGE_BYTES"), 64<<10)),
+		policyPath:           os.Getenv("CMUX_CDP_POLICY_FILE"),
+		idleTimeout:          parseDuration(os.Getenv("CMUX_CDP_IDLE_TIMEOUT"), 0),
+		maxSessions:          int(parseSize(os.Getenv("CMUX_CDP_MAX_SESSIONS"), 0)),
+		maxMessageBytes:      int(parseSize(os.Getenv("CMUX_CDP_MAX_MESSAGE_BYTES"), 0)),
+		reconnectGrace:       parseDuration(os.Getenv("CMUX_CDP_RECONNECT_GRACE"), 0),
+		reconnectReplay:      parseList(os.Getenv("CMUX_CDP_RECONNECT_REPLAY"), defaultReconnectReplay),
+		reconnectBufferBytes: int(parseSize(os.Getenv("CMUX_CDP_RECONNECT_BUFFER_BYTES"), 4<<20)),
+		shapeLatency:         parseDuration(os.Getenv("CMUX_CDP_SHAPE_LATENCY"), 0),
+		shapeBandwidth:       parseSize(os.Getenv("CMUX_CDP_SHAPE_BANDWIDTH"), 0),
 	}
+	if (cfg.tlsCertPath == "") != (cfg.tlsKeyPath == "") {
+		log.Fatal("CMUX_CDP_TLS_CERT and CMUX_CDP_TLS_KEY must be set together")
+	}
+	return cfg
+}
 
-	proxy.ErrorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
-		log.Printf("proxy error: %v", err)
-		rw.Header().Set("Content-Type", "text/plain")
-		rw.WriteHeader(http.StatusBadGateway)
-		_, _ = rw.Write([]byte("Bad Gateway"))
+func main() {
+	log.SetFlags(log.LstdFlags | log.LUTC)
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		if err := runReplay(os.Args[2:]); err != nil {
+			log.Fatalf("replay failed: %v", err)
+		}
+		return
 	}
 
-	proxy.FlushInterval = 100 * time.Millisecond
+	baseCfg := loadConfig()
+	cfg := baseCfg
+	if cfg.targetFile != "" {
+		var err error
+		if cfg, err = applyTargetFile(baseCfg); err != nil {
+			log.Fatalf("failed to read target file: %v", err)
+		}
+	}
+	proxy := newCDPProxy(cfg)
+	if cfg.recordPath != "" {
+		rec, err := newRecorder(cfg.recordPath, cfg.recordMaxBytes, cfg.recordMaxMessage)
+		if err != nil {
+			log.Fatalf("failed to start recording: %v", err)
+		}
+		proxy.recorder = rec
+		log.Printf("recording CDP traffic to %s", cfg.recordPath)
+	}
+	if cfg.policyPath != "" {
+		policy, err := loadPolicy(cfg.policyPath)
+		if err != nil {
+			log.Fatalf("failed to load CDP policy: %v", err)
+		}
+		proxy.policy = policy
+		log.Printf("enforcing CDP policy from %s", cfg.policyPath)
+	}
 
 	log.Print("TCP_NODELAY enabled for low-latency proxying")
+	if cfg.shapeLatency > 0 || cfg.shapeBandwidth > 0 {
+		log.Printf("shaping websocket traffic for testing: +%s latency, %d bytes/s per direction (0 = unlimited)", cfg.shapeLatency, cfg.shapeBandwidth)
+	}
 
 	server := &http.Server{
 		Addr:              net.JoinHostPort("0.0.0.0", strconv.Itoa(cfg.listenPort)),
@@ -111,14 +188,53 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	if cfg.tlsCertPath != "" {
+		reloader, err := newCertReloader(cfg.tlsCertPath, cfg.tlsKeyPath)
+		if err != nil {
+			log.Fatalf("failed to load TLS certificate: %v", err)
+		}
+		go reloader.watch(cfg.tlsReloadInterval, make(chan struct{}))
+		server.TLSConfig = reloader.tlsConfig()
+	}
+
+	scheme := "http"
+	if server.TLSConfig != nil {
+		scheme = "https"
+	}
 	log.Printf(
-		"cmux CDP proxy listening on %d, forwarding to %s (Host header: %s)",
+		"cmux CDP proxy listening on %s port %d, forwarding to %s (Host header: %s)",
+		scheme,
 		cfg.listenPort,
-		targetURL.Host,
-		cfg.hostHeader,
+		proxy.target().addr,
+		proxy.target().hostHeader,
 	)
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	stopped := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	go func() {
+		defer close(stopped)
+		for sig := range signals {
+			if sig == syscall.SIGHUP {
+				reloadTarget(proxy, baseCfg)
+				continue
+			}
+			log.Printf("received %s, draining", sig)
+			shutdown(server, proxy, cfg.drainTimeout)
+			return
+		}
+	}()
+
+	var err error
+	if server.TLSConfig != nil {
+		// Certificates come from TLSConfig.GetCertificate so they can be
+		// reloaded without a restart.
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		log.Fatalf("server exited: %v", err)
 	}
+	<-stopped
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cdpPolicy decides which CDP commands clients may send to the browser. It is
+// loaded from a JSON file, for example:
+//
+//	{
+//	  "blockedMethods": ["Target.exposeDevToolsProtocol", "SystemInfo.*"],
+//	  "downloadDirs": ["/root/Downloads"]
+//	}
+//
+// blockedMethods accepts exact method names or "Domain.*" wildcards. When
+// downloadDirs is set, Browser.setDownloadBehavior and
+// Page.setDownloadBehavior may only enable downloads into those directories.
+// Commands wrapped in Target.sendMessageToTarget are checked the same way.
+type cdpPolicy struct {
+	BlockedMethods []string `json:"blockedMethods"`
+	DownloadDirs   []string `json:"downloadDirs"`
+
+	blocked        map[string]bool
+	blockedDomains map[string]bool
+}
+
+func loadPolicy(path string) (*cdpPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	var policy cdpPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	policy.compile()
+	return &policy, nil
+}
+
+func (p *cdpPolicy) compile() {
+	p.blocked = make(map[string]bool)
+	p.blockedDomains = make(map[string]bool)
+	for _, method := range p.BlockedMethods {
+		if domain, ok := strings.CutSuffix(method, ".*"); ok {
+			p.blockedDomains[domain] = true
+		} else {
+			p.blocked[method] = true
+		}
+	}
+	for i, dir := range p.DownloadDirs {
+		p.DownloadDirs[i] = filepath.Clean(dir)
+	}
+}
+
+type cdpCommand struct {
+	ID        json.RawMessage `json:"id"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params,omitempty"`
+}
+
+type cdpErrorResponse struct {
+	ID        json.RawMessage `json:"id"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Error     cdpError        `json:"error"`
+}
+
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// cdpServerError is the JSON-RPC code Chrome itself uses for rejected
+// commands.
+const cdpServerError = -32000
+
+// check inspects a client message. For a blocked command it returns the
+// error response to send back to the client in place of forwarding it.
+func (p *cdpPolicy) check(message []byte) ([]byte, bool) {
+	var cmd cdpCommand
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Method == "" {
+		return nil, false
+	}
+	reason := p.blockReason(cmd)
+	if reason == "" {
+		return nil, false
+	}
+	response, err := json.Marshal(cdpErrorResponse{
+		ID:        cmd.ID,
+		SessionID: cmd.SessionID,
+		Error:     cdpError{Code: cdpServerError, Message: reason},
+	})
+	if err != nil {
+		return nil, false
+	}
+	return response, true
+}
+
+func (p *cdpPolicy) blockReason(cmd cdpCommand) string {
+	domain, _, _ := strings.Cut(cmd.Method, ".")
+	if p.blocked[cmd.Method] || p.blockedDomains[domain] {
+		return fmt.Sprintf("'%s' is blocked by the cmux CDP proxy policy", cmd.Method)
+	}
+	switch cmd.Method {
+	case "Target.sendMessageToTarget":
+		// Sessions attached without flatten take their commands as a JSON
+		// string, so check the wrapped command like a top-level one
+		var params struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(cmd.Params, &params)
+		var nested cdpCommand
+		if err := json.Unmarshal([]byte(params.Message), &nested); err != nil || nested.Method == "" {
+			return ""
+		}
+		return p.blockReason(nested)
+	case "Browser.setDownloadBehavior", "Page.setDownloadBehavior":
+		if len(p.DownloadDirs) == 0 {
+			return ""
+		}
+		var params struct {
+			Behavior     string `json:"behavior"`
+			DownloadPath string `json:"downloadPath"`
+		}
+		_ = json.Unmarshal(cmd.Params, &params)
+		if params.Behavior == "deny" || params.Behavior == "default" {
+			return ""
+		}
+		if !p.downloadPathAllowed(params.DownloadPath) {
+			return fmt.Sprintf("'%s' may only download into %s", cmd.Method, strings.Join(p.DownloadDirs, ", "))
+		}
+	}
+	return ""
+}
+
+func (p *cdpPolicy) downloadPathAllowed(path string) bool {
+	if path == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	path = filepath.Clean(path)
+	for _, dir := range p.DownloadDirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
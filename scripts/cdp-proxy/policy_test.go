@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPolicyBlocksConfiguredMethods(t *testing.T) {
+	policy := &cdpPolicy{
+		BlockedMethods: []string{"Target.exposeDevToolsProtocol", "SystemInfo.*"},
+		DownloadDirs:   []string{"/root/Downloads/"},
+	}
+	policy.compile()
+
+	cases := []struct {
+		message string
+		blocked bool
+	}{
+		{`{"id":1,"method":"Runtime.evaluate","params":{"expression":"1"}}`, false},
+		{`{"id":2,"method":"Target.exposeDevToolsProtocol","params":{"targetId":"x"}}`, true},
+		{`{"id":3,"method":"SystemInfo.getInfo"}`, true},
+		{`{"id":4,"method":"Browser.setDownloadBehavior","params":{"behavior":"allow","downloadPath":"/root/Downloads/run1"}}`, false},
+		{`{"id":5,"method":"Browser.setDownloadBehavior","params":{"behavior":"allow","downloadPath":"/root/Downloads/../.ssh"}}`, true},
+		{`{"id":6,"method":"Page.setDownloadBehavior","params":{"behavior":"allow"}}`, true},
+		{`{"id":7,"method":"Page.setDownloadBehavior","params":{"behavior":"deny"}}`, false},
+		{`{"id":8,"method":"Target.sendMessageToTarget","params":{"sessionId":"s1","message":"{\"id\":1,\"method\":\"Runtime.evaluate\"}"}}`, false},
+		{`{"id":9,"method":"Target.sendMessageToTarget","params":{"sessionId":"s1","message":"{\"id\":1,\"method\":\"Page.setDownloadBehavior\",\"params\":{\"behavior\":\"allow\",\"downloadPath\":\"/tmp\"}}"}}`, true},
+		{`{"id":10,"method":"Target.sendMessageToTarget","params":{"sessionId":"s1","message":"{\"id\":1,\"method\":\"SystemInfo.getInfo\"}"}}`, true},
+		{`{"method":"Page.loadEventFired"}`, false},
+		{`not json`, false},
+	}
+	for _, tc := range cases {
+		response, blocked := policy.check([]byte(tc.message))
+		if blocked != tc.blocked {
+			t.Fatalf("%s: expected blocked=%v", tc.message, tc.blocked)
+		}
+		if !blocked {
+			continue
+		}
+		var decoded cdpErrorResponse
+		if err := json.Unmarshal(response, &decoded); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		var sent cdpCommand
+		_ = json.Unmarshal([]byte(tc.message), &sent)
+		if string(decoded.ID) != string(sent.ID) || decoded.Error.Code != cdpServerError || decoded.Error.Message == "" {
+			t.Fatalf("unexpected error response %s", response)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type publicOriginKey struct{}
+
+// cdpProxy forwards DevTools HTTP endpoints and WebSocket sessions to Chrome.
+// Responses from the /json discovery endpoints are rewritten so that the
+// debugger URLs they advertise point back at the proxy instead of at Chrome's
+// loopback address.
+type cdpProxy struct {
+	cfg       proxyConfig
+	upstream  atomic.Pointer[upstreamTarget]
+	dialer    *net.Dialer
+	transport *http.Transport
+	reverse   *httputil.ReverseProxy
+
+	// recorder, when set, captures all WebSocket messages.
+	recorder       *recorder
+	policy         *cdpPolicy
+	nextConnID     atomic.Uint64
+	activeSessions atomic.Int64
+
+	// Open WebSocket sessions, closed by drain on shutdown.
+	sessionsMu sync.Mutex
+	sessions   map[uint64]func(reason string)
+	draining   atomic.Bool
+}
+
+// upstreamTarget is where Chrome is reached. It is swapped atomically on
+// reload; open sessions keep the connection they already have.
+type upstreamTarget struct {
+	addr       string
+	hostHeader string
+}
+
+func newCDPProxy(cfg proxyConfig) *cdpProxy {
+	p := &cdpProxy{
+		cfg: cfg,
+		dialer: &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+	}
+	p.setTarget(cfg.targetHost, cfg.targetPort, cfg.hostHeader)
+
+	// Custom transport with TCP_NODELAY enabled
+	p.transport = &http.Transport{
+		DialContext:           p.dialUpstream,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	p.reverse = &httputil.ReverseProxy{
+		Transport:     p.transport,
+		FlushInterval: 100 * time.Millisecond,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			target := p.target()
+			pr.SetURL(&url.URL{Scheme: "http", Host: target.addr})
+			pr.Out.Host = target.hostHeader
+			pr.Out.Header.Del("Proxy-Connection")
+			pr.Out = pr.Out.WithContext(context.WithValue(pr.Out.Context(), publicOriginKey{}, publicOrigin(pr.In)))
+		},
+		ModifyResponse: p.rewriteDiscoveryResponse,
+		ErrorHandler: func(rw http.ResponseWriter, req *http.Request, err error) {
+			log.Printf("proxy error: %v", err)
+			rw.Header().Set("Content-Type", "text/plain")
+			rw.WriteHeader(http.StatusBadGateway)
+			_, _ = rw.Write([]byte("Bad Gateway"))
+		},
+	}
+	return p
+}
+
+func (p *cdpProxy) target() *upstreamTarget {
+	return p.upstream.Load()
+}
+
+func (p *cdpProxy) setTarget(host string, port int, hostHeader string) {
+	p.upstream.Store(&upstreamTarget{
+		addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		hostHeader: hostHeader,
+	})
+}
+
+func (p *cdpProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		p.serveHealth(w, r)
+		return
+	}
+	if isWebSocketUpgrade(r) {
+		p.serveWebSocket(w, r)
+		return
+	}
+	p.reverse.ServeHTTP(w, r)
+}
+
+// dialUpstream connects to Chrome with TCP_NODELAY for low-latency proxying.
+func (p *cdpProxy) dialUpstream(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := p.dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if err := tcpConn.SetNoDelay(true); err != nil {
+			log.Printf("warning: failed to set TCP_NODELAY: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// origin is the WebSocket scheme and host clients use to reach the proxy.
+type origin struct {
+	wsScheme string
+	host     string
+}
+
+// publicOrigin derives the origin of an incoming request, honoring the
+// X-Forwarded-* headers set by the sandbox ingress.
+func publicOrigin(r *http.Request) origin {
+	o := origin{wsScheme: "ws", host: r.Host}
+	if r.TLS != nil || strings.EqualFold(firstHeaderValue(r.Header.Get("X-Forwarded-Proto")), "https") {
+		o.wsScheme = "wss"
+	}
+	if forwardedHost := firstHeaderValue(r.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+		o.host = forwardedHost
+	}
+	return o
+}
+
+func firstHeaderValue(value string) string {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// rewriteDiscoveryResponse rewrites webSocketDebuggerUrl and
+// devtoolsFrontendUrl in /json, /json/list, /json/version, and /json/new
+// responses.
+func (p *cdpProxy) rewriteDiscoveryResponse(resp *http.Response) error {
+	if !strings.HasPrefix(resp.Request.URL.Path, "/json") || resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	o, ok := resp.Request.Context().Value(publicOriginKey{}).(origin)
+	if !ok || o.host == "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// Not JSON (e.g. /json/protocol errors); pass through untouched.
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return nil
+	}
+	rewriteDebuggerURLs(payload, o)
+	rewritten, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(rewritten))
+	resp.ContentLength = int64(len(rewritten))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(rewritten)))
+	return nil
+}
+
+func rewriteDebuggerURLs(value any, o origin) {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			rewriteDebuggerURLs(item, o)
+		}
+	case map[string]any:
+		if raw, ok := v["webSocketDebuggerUrl"].(string); ok {
+			if u, err := url.Parse(raw); err == nil {
+				u.Scheme = o.wsScheme
+				u.Host = o.host
+				v["webSocketDebuggerUrl"] = u.String()
+			}
+		}
+		if raw, ok := v["devtoolsFrontendUrl"].(string); ok {
+			v["devtoolsFrontendUrl"] = rewriteFrontendURL(raw, o)
+		}
+	}
+}
+
+// rewriteFrontendURL points the ws=/wss= query parameter of a DevTools
+// frontend URL at the proxy.
+func rewriteFrontendURL(raw string, o origin) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	query := u.Query()
+	target := query.Get("ws")
+	if target == "" {
+		target = query.Get("wss")
+	}
+	if target == "" {
+		return raw
+	}
+	if i := strings.IndexByte(target, '/'); i >= 0 {
+		target = o.host + target[i:]
+	} else {
+		target = o.host
+	}
+	query.Del("ws")
+	query.Del("wss")
+	query.Set(o.wsScheme, target)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
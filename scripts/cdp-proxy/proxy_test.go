@@ -0,0 +1,580 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFakeChrome serves a minimal DevTools discovery endpoint and echoes raw
+// bytes on upgraded WebSocket connections.
+func newFakeChrome(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Browser":              "Chrome/126.0",
+			"Protocol-Version":     "1.3",
+			"webSocketDebuggerUrl": "ws://" + r.Host + "/devtools/browser/abc",
+		})
+	})
+	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]string{{
+			"id":                   "page1",
+			"webSocketDebuggerUrl": "ws://" + r.Host + "/devtools/page/page1",
+			"devtoolsFrontendUrl":  "/devtools/inspector.html?ws=" + r.Host + "/devtools/page/page1",
+		}})
+	})
+	mux.HandleFunc("/devtools/", func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nX-Seen-Host: %s\r\n\r\n", r.Host)
+		_ = buf.Flush()
+		_, _ = io.Copy(conn, buf)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestProxy(t *testing.T, upstream *httptest.Server) *httptest.Server {
+	t.Helper()
+	host, portStr, err := net.SplitHostPort(strings.TrimPrefix(upstream.URL, "http://"))
+	if err != nil {
+		t.Fatalf("split upstream addr: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	cfg := proxyConfig{
+		targetHost: host,
+		targetPort: port,
+		hostHeader: fmt.Sprintf("localhost:%d", port),
+	}
+	server := httptest.NewServer(newCDPProxy(cfg))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// dialThroughProxy opens a WebSocket connection to the fake Chrome page
+// target via the proxy and returns it after the handshake.
+func dialThroughProxy(t *testing.T, proxyURL string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", strings.TrimPrefix(proxyURL, "http://"), time.Second)
+	if err != nil {
+		t.Fatalf("dial proxy: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "GET /devtools/page/page1 HTTP/1.1\r\nHost: proxy\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	return conn, reader
+}
+
+func newConfiguredProxy(t *testing.T, upstream *httptest.Server, configure func(*proxyConfig)) (*cdpProxy, *httptest.Server) {
+	t.Helper()
+	host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(upstream.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+	cfg := proxyConfig{targetHost: host, targetPort: port, hostHeader: "localhost"}
+	if configure != nil {
+		configure(&cfg)
+	}
+	proxy := newCDPProxy(cfg)
+	server := httptest.NewServer(proxy)
+	t.Cleanup(server.Close)
+	return proxy, server
+}
+
+func expectCloseFrame(t *testing.T, reader *bufio.Reader, code uint16) {
+	t.Helper()
+	frame, err := readFrame(reader, maxFramePayload)
+	if err != nil {
+		t.Fatalf("read close frame: %v", err)
+	}
+	if frame.opcode != opClose || len(frame.payload) < 2 {
+		t.Fatalf("expected close frame, got opcode %d", frame.opcode)
+	}
+	if got := binary.BigEndian.Uint16(frame.payload); got != code {
+		t.Fatalf("expected close code %d, got %d (%s)", code, got, frame.payload[2:])
+	}
+}
+
+func TestDiscoveryURLsAreRewritten(t *testing.T) {
+	proxy := newTestProxy(t, newFakeChrome(t))
+
+	req, _ := http.NewRequest(http.MethodGet, proxy.URL+"/json/list", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "port-39381-abc.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var targets []map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(targets) != 1 {
+		t.Fatalf("expected one target, got %d", len(targets))
+	}
+	if got := targets[0]["webSocketDebuggerUrl"]; got != "wss://port-39381-abc.example.com/devtools/page/page1" {
+		t.Fatalf("unexpected webSocketDebuggerUrl %q", got)
+	}
+	if got := targets[0]["devtoolsFrontendUrl"]; got != "/devtools/inspector.html?wss=port-39381-abc.example.com%2Fdevtools%2Fpage%2Fpage1" {
+		t.Fatalf("unexpected devtoolsFrontendUrl %q", got)
+	}
+
+	resp, err = http.Get(proxy.URL + "/json/version")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	var version map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := "ws://" + strings.TrimPrefix(proxy.URL, "http://") + "/devtools/browser/abc"
+	if got := version["webSocketDebuggerUrl"]; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWebSocketUpgradeIsTunneled(t *testing.T) {
+	proxy := newTestProxy(t, newFakeChrome(t))
+
+	conn, err := net.DialTimeout("tcp", strings.TrimPrefix(proxy.URL, "http://"), time.Second)
+	if err != nil {
+		t.Fatalf("dial proxy: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprintf(conn, "GET /devtools/page/page1 HTTP/1.1\r\nHost: proxy\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Seen-Host"); !strings.HasPrefix(got, "localhost:") {
+		t.Fatalf("upstream saw Host %q, expected the configured host header", got)
+	}
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("unexpected echo %q", string(buf))
+	}
+}
+
+func TestRecordingCapturesRedactedMessages(t *testing.T) {
+	recordPath := filepath.Join(t.TempDir(), "cdp.ndjson")
+	rec, err := newRecorder(recordPath, 1<<20, 1024)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	proxy, server := newConfiguredProxy(t, newFakeChrome(t), nil)
+	proxy.recorder = rec
+	conn, reader := dialThroughProxy(t, server.URL)
+
+	message := `{"id":1,"method":"Network.setCookie","params":{"name":"session","value":"hunter2","url":"https://example.com"}}`
+	frame, err := encodeFrame(opText, []byte(message), true)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	echoed, err := readFrame(reader, maxFramePayload)
+	if err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+	if string(echoed.payload) != message {
+		t.Fatalf("unexpected echo %q", echoed.payload)
+	}
+	conn.Close()
+
+	var entries []recordEntry
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(recordPath)
+		entries = entries[:0]
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry recordEntry
+			if json.Unmarshal([]byte(line), &entry) == nil {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 4 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("expected open, send, recv, close entries, got %+v", entries)
+	}
+	if entries[0].Event != "open" || entries[0].Path != "/devtools/page/page1" || entries[3].Event != "close" {
+		t.Fatalf("unexpected connection entries: %+v", entries)
+	}
+	for _, entry := range entries[1:3] {
+		if strings.Contains(string(entry.Message), "hunter2") || !strings.Contains(string(entry.Message), redacted) {
+			t.Fatalf("cookie value was not redacted: %s", entry.Message)
+		}
+	}
+	if entries[1].Dir != dirSend || entries[2].Dir != dirRecv {
+		t.Fatalf("unexpected directions: %s, %s", entries[1].Dir, entries[2].Dir)
+	}
+}
+
+func TestIdleSessionsAreClosed(t *testing.T) {
+	_, server := newConfiguredProxy(t, newFakeChrome(t), func(cfg *proxyConfig) {
+		cfg.idleTimeout = 100 * time.Millisecond
+	})
+	_, reader := dialThroughProxy(t, server.URL)
+	expectCloseFrame(t, reader, closeGoingAway)
+}
+
+func TestOversizedMessagesAreRejected(t *testing.T) {
+	_, server := newConfiguredProxy(t, newFakeChrome(t), func(cfg *proxyConfig) {
+		cfg.maxMessageBytes = 16
+	})
+	conn, reader := dialThroughProxy(t, server.URL)
+	frame, err := encodeFrame(opText, []byte(`{"id":1,"method":"Runtime.evaluate"}`), true)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	expectCloseFrame(t, reader, closeMessageTooBig)
+}
+
+func TestOversizedFragmentedMessagesAreRejected(t *testing.T) {
+	_, server := newConfiguredProxy(t, newFakeChrome(t), func(cfg *proxyConfig) {
+		cfg.maxMessageBytes = 16
+	})
+	conn, reader := dialThroughProxy(t, server.URL)
+
+	// Every fragment fits the limit on its own; the message as a whole doesn't.
+	for i := 0; i < 4; i++ {
+		opcode := opContinuation
+		if i == 0 {
+			opcode = opText
+		}
+		frame, err := encodeFrame(opcode, []byte("0123456789"), true)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		frame[0] &^= 0x80 // clear FIN
+		if _, err := conn.Write(frame); err != nil {
+			break // the proxy may close before every fragment is written
+		}
+	}
+	expectCloseFrame(t, reader, closeMessageTooBig)
+}
+
+func TestSessionCapRejectsExtraConnections(t *testing.T) {
+	_, server := newConfiguredProxy(t, newFakeChrome(t), func(cfg *proxyConfig) {
+		cfg.maxSessions = 1
+	})
+	dialThroughProxy(t, server.URL)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/devtools/page/page1", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", resp.StatusCode)
+	}
+}
+
+func TestHealthReportsUpstreamStatus(t *testing.T) {
+	chrome := newFakeChrome(t)
+	proxy := newTestProxy(t, chrome)
+
+	resp, err := http.Get(proxy.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	var health healthResponse
+	_ = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || health.Status != "ok" || health.Browser != "Chrome/126.0" || health.ProtocolVersion != "1.3" {
+		t.Fatalf("unexpected healthy response: %d %+v", resp.StatusCode, health)
+	}
+
+	chrome.Close()
+	resp, err = http.Get(proxy.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	health = healthResponse{}
+	_ = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || health.Status != "unavailable" || health.Error == "" {
+		t.Fatalf("unexpected unhealthy response: %d %+v", resp.StatusCode, health)
+	}
+}
+
+func TestBlockedCommandsGetSynthesizedErrors(t *testing.T) {
+	proxy, server := newConfiguredProxy(t, newFakeChrome(t), nil)
+	proxy.policy = &cdpPolicy{BlockedMethods: []string{"Browser.crash"}}
+	proxy.policy.compile()
+	conn, reader := dialThroughProxy(t, server.URL)
+
+	for _, message := range []string{
+		`{"id":1,"sessionId":"S1","method":"Browser.crash"}`,
+		`{"id":2,"method":"Runtime.enable"}`,
+	} {
+		frame, err := encodeFrame(opText, []byte(message), true)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if _, err := conn.Write(frame); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	// The blocked command is answered by the proxy; only the second one
+	// reaches (and is echoed by) the fake browser.
+	first, err := readFrame(reader, maxFramePayload)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var response cdpErrorResponse
+	if err := json.Unmarshal(first.payload, &response); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if string(response.ID) != "1" || response.SessionID != "S1" || response.Error.Code != cdpServerError {
+		t.Fatalf("unexpected synthesized response %s", first.payload)
+	}
+	second, err := readFrame(reader, maxFramePayload)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(second.payload) != `{"id":2,"method":"Runtime.enable"}` {
+		t.Fatalf("unexpected forwarded message %s", second.payload)
+	}
+}
+
+func TestSessionsSurviveBrowserRestart(t *testing.T) {
+	var up atomic.Bool
+	up.Store(true)
+	restarted := make(chan []string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]string{{
+			"id":                   "page2",
+			"type":                 "page",
+			"webSocketDebuggerUrl": "ws://" + r.Host + "/devtools/page/page2",
+		}})
+	})
+	mux.HandleFunc("/devtools/", func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		fmt.Fprintf(buf, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+		_ = buf.Flush()
+
+		if r.URL.Path == "/devtools/page/page1" {
+			// Echo one message, then crash.
+			frame, err := readFrame(buf, maxFramePayload)
+			if err == nil {
+				_, _ = conn.Write(frame.raw)
+			}
+			up.Store(false)
+			return
+		}
+		// The restarted browser answers every command.
+		var methods []string
+		for {
+			frame, err := readFrame(buf, maxFramePayload)
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int64  `json:"id"`
+				Method string `json:"method"`
+			}
+			_ = json.Unmarshal(frame.payload, &cmd)
+			methods = append(methods, cmd.Method)
+			if cmd.ID == 2 {
+				restarted <- methods
+			}
+			reply, _ := encodeFrame(opText, fmt.Appendf(nil, `{"id":%d,"result":{}}`, cmd.ID), false)
+			_, _ = conn.Write(reply)
+		}
+	})
+	chrome := httptest.NewServer(mux)
+	t.Cleanup(chrome.Close)
+
+	_, server := newConfiguredProxy(t, chrome, func(cfg *proxyConfig) {
+		cfg.reconnectGrace = 5 * time.Second
+		cfg.reconnectReplay = defaultReconnectReplay
+		cfg.reconnectBufferBytes = 1 << 20
+	})
+	conn, reader := dialThroughProxy(t, server.URL)
+
+	send := func(message string) {
+		frame, err := encodeFrame(opText, []byte(message), true)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if _, err := conn.Write(frame); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	send(`{"id":1,"method":"Page.enable"}`)
+	if _, err := readFrame(reader, maxFramePayload); err != nil {
+		t.Fatalf("read echo: %v", err)
+	}
+
+	// Sent while the browser is down: buffered until the restart.
+	time.Sleep(100 * time.Millisecond)
+	send(`{"id":2,"method":"Runtime.evaluate"}`)
+	up.Store(true)
+
+	select {
+	case methods := <-restarted:
+		if strings.Join(methods, ",") != "Page.enable,Runtime.evaluate" {
+			t.Fatalf("unexpected commands after restart: %v", methods)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("session did not reconnect")
+	}
+	// The reply to the replayed Page.enable is swallowed by the proxy.
+	frame, err := readFrame(reader, maxFramePayload)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(frame.payload) != `{"id":2,"result":{}}` {
+		t.Fatalf("unexpected message after restart %s", frame.payload)
+	}
+}
+
+func TestDrainClosesSessionsAndRefusesNewOnes(t *testing.T) {
+	for name, configure := range map[string]func(*proxyConfig){
+		"spliced": nil,
+		"framed": func(cfg *proxyConfig) {
+			cfg.idleTimeout = time.Minute
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			proxy, server := newConfiguredProxy(t, newFakeChrome(t), configure)
+			conn, reader := dialThroughProxy(t, server.URL)
+
+			// The session registers itself after the handshake is relayed.
+			deadline := time.Now().Add(time.Second)
+			for {
+				proxy.sessionsMu.Lock()
+				tracked := len(proxy.sessions)
+				proxy.sessionsMu.Unlock()
+				if tracked > 0 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatal("session was never tracked")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			drained := make(chan int, 1)
+			start := time.Now()
+			go func() { drained <- proxy.drain() }()
+			expectCloseFrame(t, reader, closeGoingAway)
+
+			// Answering the close frame ends the session without waiting
+			// out the grace period.
+			reply, err := encodeFrame(opClose, binary.BigEndian.AppendUint16(nil, closeGoingAway), true)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if _, err := conn.Write(reply); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if closed := <-drained; closed != 1 {
+				t.Fatalf("expected 1 session drained, got %d", closed)
+			}
+			if elapsed := time.Since(start); elapsed >= closeGrace {
+				t.Fatalf("drain waited %v despite the client answering", elapsed)
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/devtools/page/page1", nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("expected 503 while draining, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestTargetFileOverridesTarget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cdp-proxy.env")
+	content := "# moved by snapshot resume\nCMUX_CDP_TARGET_HOST=10.0.0.2\nCMUX_CDP_TARGET_PORT=\"9222\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, err := applyTargetFile(proxyConfig{targetHost: "127.0.0.1", targetPort: 39382, hostHeader: "localhost:39382", targetFile: path})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if cfg.targetHost != "10.0.0.2" || cfg.targetPort != 9222 || cfg.hostHeader != "localhost:9222" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte("CMUX_CDP_TARGET_PORT=nope\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := applyTargetFile(cfg); err == nil {
+		t.Fatal("expected invalid port to be rejected")
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// closeTryAgainLater tells clients the proxy gave up on a restarting browser
+// (RFC 6455 section 7.4.1, registered code 1013).
+const closeTryAgainLater uint16 = 1013
+
+// reconnectRetryInterval is how often a session retries the upstream while
+// the browser is restarting.
+const reconnectRetryInterval = 250 * time.Millisecond
+
+// replayIDBase is the first CDP command id used for handshake messages the
+// proxy sends on its own. Responses to them are swallowed so clients never
+// see ids they did not allocate.
+const replayIDBase int64 = 1 << 40
+
+// defaultReconnectReplay lists the commands that set up state Chrome forgets
+// when it restarts: domain subscriptions and target discovery.
+var defaultReconnectReplay = []string{"*.enable", "Target.setDiscoverTargets", "Target.setAutoAttach"}
+
+// methodMatches reports whether method matches a replay pattern. Patterns are
+// exact method names, "Domain.*", or "*.command".
+func methodMatches(pattern string, method string) bool {
+	if domain, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(method, domain+".")
+	}
+	if command, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(method, "."+command)
+	}
+	return pattern == method
+}
+
+// rememberForReplay keeps the latest browser-level command for each replayed
+// method so it can be re-sent after the browser restarts. Commands addressed
+// to a flat session are skipped: their session ids die with the browser.
+func (s *wsSession) rememberForReplay(message []byte) {
+	var cmd cdpCommand
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Method == "" || cmd.SessionID != "" {
+		return
+	}
+	for _, pattern := range s.proxy.cfg.reconnectReplay {
+		if !methodMatches(pattern, cmd.Method) {
+			continue
+		}
+		s.replayMu.Lock()
+		if s.replay == nil {
+			s.replay = make(map[string][]byte)
+		}
+		if _, seen := s.replay[cmd.Method]; !seen {
+			s.replayOrder = append(s.replayOrder, cmd.Method)
+		}
+		s.replay[cmd.Method] = append([]byte(nil), message...)
+		s.replayMu.Unlock()
+		return
+	}
+}
+
+// replayMessages renumbers the remembered commands with proxy-owned ids, in
+// the order the client first sent them.
+func (s *wsSession) replayMessages() [][]byte {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	var messages [][]byte
+	for _, method := range s.replayOrder {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(s.replay[method], &fields); err != nil {
+			continue
+		}
+		id := replayIDBase + s.nextReplayID
+		s.nextReplayID++
+		fields["id"] = json.RawMessage(strconv.FormatInt(id, 10))
+		message, err := json.Marshal(fields)
+		if err != nil {
+			continue
+		}
+		if s.pendingReplay == nil {
+			s.pendingReplay = make(map[int64]bool)
+		}
+		s.pendingReplay[id] = true
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// isReplayResponse reports whether an upstream message answers a handshake
+// command the proxy sent itself.
+func (s *wsSession) isReplayResponse(message []byte) bool {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	if len(s.pendingReplay) == 0 {
+		return false
+	}
+	var response struct {
+		ID *int64 `json:"id"`
+	}
+	if err := json.Unmarshal(message, &response); err != nil || response.ID == nil {
+		return false
+	}
+	if !s.pendingReplay[*response.ID] {
+		return false
+	}
+	delete(s.pendingReplay, *response.ID)
+	return true
+}
+
+// shouldReconnect reports whether a failed upstream read looks like the
+// browser going away rather than the session ending.
+func (s *wsSession) shouldReconnect(err error) bool {
+	if s.proxy.cfg.reconnectGrace <= 0 || s.closing.Load() || errors.Is(err, errFrameTooLarge) {
+		return false
+	}
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// reconnect waits up to the grace period for Chrome to come back, then swaps
+// the new connection in and returns a reader for it. Client messages sent in
+// the meantime are buffered by sendUpstream and flushed after the handshake.
+func (s *wsSession) reconnect() (io.Reader, error) {
+	grace := s.proxy.cfg.reconnectGrace
+	s.upstreamMu.Lock()
+	s.reconnecting = true
+	s.upstream.Close()
+	s.upstreamMu.Unlock()
+	log.Printf("websocket session %d: upstream lost, waiting up to %s for the browser", s.id, grace)
+
+	deadline := time.Now().Add(grace)
+	for {
+		s.touch()
+		conn, reader, err := s.dialReplacement()
+		if err == nil {
+			if err := s.resume(conn); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			log.Printf("websocket session %d: reconnected to the browser", s.id)
+			return reader, nil
+		}
+		if time.Now().After(deadline) {
+			log.Printf("websocket session %d: browser did not come back: %v", s.id, err)
+			return nil, &closeError{code: closeTryAgainLater, reason: "browser restarted and did not come back"}
+		}
+		select {
+		case <-s.done:
+			return nil, net.ErrClosed
+		case <-time.After(reconnectRetryInterval):
+		}
+	}
+}
+
+// dialReplacement opens a WebSocket to whatever target now corresponds to the
+// session's original path.
+func (s *wsSession) dialReplacement() (net.Conn, io.Reader, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	path, err := s.proxy.resolveTargetPath(ctx, s.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	target := s.proxy.target()
+	conn, err := s.proxy.dialUpstream(ctx, "tcp", target.addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	reader, err := websocketHandshake(conn, target.hostHeader, path)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return s.proxy.shape(conn), reader, nil
+}
+
+// resume replays the handshake and the buffered client messages on conn and
+// makes it the session's upstream.
+func (s *wsSession) resume(conn net.Conn) error {
+	s.upstreamMu.Lock()
+	defer s.upstreamMu.Unlock()
+	select {
+	case <-s.done:
+		return net.ErrClosed
+	default:
+	}
+	for _, message := range s.replayMessages() {
+		frame, err := encodeFrame(opText, message, true)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(frame); err != nil {
+			return err
+		}
+	}
+	for _, raw := range s.buffered {
+		if _, err := conn.Write(raw); err != nil {
+			return err
+		}
+	}
+	s.buffered = nil
+	s.bufferedBytes = 0
+	s.upstream = conn
+	s.reconnecting = false
+	return nil
+}
+
+// sendUpstream writes client frames to Chrome, or buffers them while the
+// session is reconnecting.
+func (s *wsSession) sendUpstream(frames ...[]byte) error {
+	s.upstreamMu.Lock()
+	defer s.upstreamMu.Unlock()
+	if s.reconnecting {
+		for _, raw := range frames {
+			s.bufferedBytes += len(raw)
+			if s.bufferedBytes > s.proxy.cfg.reconnectBufferBytes {
+				return &closeError{code: closeTryAgainLater, reason: "too many messages buffered while the browser restarts"}
+			}
+			s.buffered = append(s.buffered, append([]byte(nil), raw...))
+		}
+		return nil
+	}
+	for _, raw := range frames {
+		if _, err := s.upstream.Write(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveTargetPath maps a DevTools WebSocket path onto the browser as it is
+// now. Browser and page target ids change when Chrome restarts, so browser
+// sessions follow /json/version and page sessions fall back to the first
+// page listed by /json/list.
+func (p *cdpProxy) resolveTargetPath(ctx context.Context, path string) (string, error) {
+	switch {
+	case strings.HasPrefix(path, "/devtools/browser/"):
+		var version browserVersion
+		if err := p.fetchUpstreamJSON(ctx, "/json/version", &version); err != nil {
+			return "", err
+		}
+		return debuggerPath(version.WebSocketDebuggerURL)
+	case strings.HasPrefix(path, "/devtools/page/"):
+		var targets []struct {
+			ID                   string `json:"id"`
+			Type                 string `json:"type"`
+			WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+		}
+		if err := p.fetchUpstreamJSON(ctx, "/json/list", &targets); err != nil {
+			return "", err
+		}
+		id := strings.TrimPrefix(path, "/devtools/page/")
+		fallback := ""
+		for _, target := range targets {
+			if target.ID == id {
+				return debuggerPath(target.WebSocketDebuggerURL)
+			}
+			if fallback == "" && target.Type == "page" && target.WebSocketDebuggerURL != "" {
+				fallback = target.WebSocketDebuggerURL
+			}
+		}
+		if fallback == "" {
+			return "", errors.New("no page targets available")
+		}
+		return debuggerPath(fallback)
+	default:
+		return path, nil
+	}
+}
+
+func debuggerPath(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", errors.New("target has no webSocketDebuggerUrl")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return u.RequestURI(), nil
+}
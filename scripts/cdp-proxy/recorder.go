@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorded message directions.
+const (
+	dirSend = "send" // client to browser
+	dirRecv = "recv" // browser to client
+)
+
+// recordEntry is one line of a CDP recording.
+type recordEntry struct {
+	Ts        string          `json:"ts"`
+	Event     string          `json:"event"` // open, message, or close
+	Conn      uint64          `json:"conn"`
+	Path      string          `json:"path,omitempty"`
+	Dir       string          `json:"dir,omitempty"`
+	Size      int             `json:"size,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+	Message   json.RawMessage `json:"message,omitempty"`
+}
+
+// recorder appends CDP traffic to an NDJSON file for offline debugging.
+// Messages larger than maxMessage are recorded without their body, and
+// recording stops once the file reaches maxBytes.
+type recorder struct {
+	maxBytes   int64
+	maxMessage int
+
+	mu      sync.Mutex
+	file    *os.File
+	written int64
+	full    bool
+}
+
+func newRecorder(path string, maxBytes int64, maxMessage int) (*recorder, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open recording: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat recording: %w", err)
+	}
+	return &recorder{
+		maxBytes:   maxBytes,
+		maxMessage: maxMessage,
+		file:       file,
+		written:    info.Size(),
+	}, nil
+}
+
+func (r *recorder) open(conn uint64, path string) {
+	r.write(recordEntry{Event: "open", Conn: conn, Path: path})
+}
+
+func (r *recorder) close(conn uint64) {
+	r.write(recordEntry{Event: "close", Conn: conn})
+}
+
+func (r *recorder) message(conn uint64, dir string, opcode byte, payload []byte) {
+	entry := recordEntry{Event: "message", Conn: conn, Dir: dir, Size: len(payload)}
+	switch {
+	case len(payload) > r.maxMessage:
+		entry.Truncated = true
+	case opcode != opText:
+		entry.Message, _ = json.Marshal(fmt.Sprintf("<binary %d bytes>", len(payload)))
+	default:
+		entry.Message = redactMessage(payload)
+	}
+	r.write(entry)
+}
+
+func (r *recorder) write(entry recordEntry) {
+	entry.Ts = time.Now().UTC().Format(time.RFC3339Nano)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("recording: failed to encode entry: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return
+	}
+	if r.written+int64(len(line)) > r.maxBytes {
+		r.full = true
+		log.Printf("recording: size limit of %d bytes reached, recording stopped", r.maxBytes)
+		return
+	}
+	n, err := r.file.Write(line)
+	r.written += int64(n)
+	if err != nil {
+		log.Printf("recording: write failed: %v", err)
+	}
+}
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are object keys whose values are never recorded, wherever they
+// appear (params, results, and header maps alike).
+var sensitiveKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"password":            true,
+	"token":               true,
+	"secret":              true,
+	"apikey":              true,
+	"api-key":             true,
+	"x-api-key":           true,
+}
+
+// cookieMethods carry cookie values in a plain "value" field.
+var cookieMethods = map[string]bool{
+	"Network.setCookie":  true,
+	"Network.setCookies": true,
+	"Storage.setCookies": true,
+}
+
+// redactMessage returns the message as JSON with sensitive values replaced.
+// Non-JSON payloads are recorded as a JSON string.
+func redactMessage(payload []byte) json.RawMessage {
+	var message map[string]any
+	if err := json.Unmarshal(payload, &message); err != nil {
+		raw, _ := json.Marshal(string(payload))
+		return raw
+	}
+	method, _ := message["method"].(string)
+	redactValue(message, cookieMethods[method] || hasCookiesResult(message))
+	out, err := json.Marshal(message)
+	if err != nil {
+		raw, _ := json.Marshal(string(payload))
+		return raw
+	}
+	return out
+}
+
+// hasCookiesResult reports whether a response carries cookies, since
+// responses do not include the method that produced them.
+func hasCookiesResult(message map[string]any) bool {
+	result, ok := message["result"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = result["cookies"]
+	return ok
+}
+
+func redactValue(value any, cookies bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			lower := strings.ToLower(key)
+			if sensitiveKeys[lower] || (cookies && lower == "value") {
+				v[key] = redacted
+				continue
+			}
+			redactValue(child, cookies)
+		}
+	case []any:
+		for _, child := range v {
+			redactValue(child, cookies)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// runReplay implements "cdp-proxy replay": it re-sends the client messages of
+// one recorded connection to a live browser and prints everything the browser
+// sends back as NDJSON on stdout.
+func runReplay(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	target := fs.String("target", "", "DevTools WebSocket URL to replay against (required)")
+	connFlag := fs.Uint64("conn", 0, "recorded connection to replay (default: first connection in the file)")
+	keepTiming := fs.Bool("timing", false, "preserve the recorded delays between messages")
+	wait := fs.Duration("wait", 5*time.Second, "how long to wait for responses after the last message")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: cdp-proxy replay -target ws://host/devtools/... [flags] recording.ndjson\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target == "" || fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("a -target URL and a recording file are required")
+	}
+
+	entries, err := loadReplayEntries(fs.Arg(0), *connFlag)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no replayable client messages found in recording")
+	}
+
+	conn, reader, err := dialWebSocket(*target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var out sync.Mutex
+	encoder := json.NewEncoder(os.Stdout)
+	emit := func(entry recordEntry) {
+		entry.Ts = time.Now().UTC().Format(time.RFC3339Nano)
+		out.Lock()
+		defer out.Unlock()
+		_ = encoder.Encode(entry)
+	}
+
+	readDone := make(chan error, 1)
+	go func() {
+		assembler := messageAssembler{limit: maxFramePayload}
+		for {
+			frame, err := readFrame(reader, maxFramePayload)
+			if err != nil {
+				readDone <- err
+				return
+			}
+			if frame.opcode == opClose {
+				readDone <- nil
+				return
+			}
+			opcode, message, ok, err := assembler.add(frame)
+			if err != nil {
+				readDone <- err
+				return
+			}
+			if ok && opcode == opText {
+				emit(recordEntry{Event: "message", Dir: dirRecv, Size: len(message), Message: json.RawMessage(message)})
+			}
+		}
+	}()
+
+	var previous time.Time
+	for _, entry := range entries {
+		if *keepTiming {
+			if ts, err := time.Parse(time.RFC3339Nano, entry.Ts); err == nil {
+				if !previous.IsZero() && ts.After(previous) {
+					time.Sleep(ts.Sub(previous))
+				}
+				previous = ts
+			}
+		}
+		frame, err := encodeFrame(opText, entry.Message, true)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(frame); err != nil {
+			return fmt.Errorf("send message: %w", err)
+		}
+		emit(recordEntry{Event: "message", Dir: dirSend, Size: len(entry.Message), Message: entry.Message})
+	}
+
+	select {
+	case err := <-readDone:
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+	case <-time.After(*wait):
+	}
+	return nil
+}
+
+// loadReplayEntries returns the client-to-browser messages of one connection.
+// Truncated messages cannot be replayed and are skipped.
+func loadReplayEntries(path string, conn uint64) ([]recordEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []recordEntry
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFramePayload)
+	for scanner.Scan() {
+		var entry recordEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("parse recording: %w", err)
+		}
+		if conn == 0 && entry.Event == "open" {
+			conn = entry.Conn
+		}
+		if entry.Conn != conn || entry.Event != "message" || entry.Dir != dirSend {
+			continue
+		}
+		if entry.Truncated || len(entry.Message) == 0 || entry.Message[0] != '{' {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "skipping %d truncated or non-JSON messages\n", skipped)
+	}
+	return entries, nil
+}
+
+// dialWebSocket performs a client WebSocket handshake against rawURL.
+func dialWebSocket(rawURL string) (net.Conn, *bufio.Reader, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	switch u.Scheme {
+	case "ws":
+		conn, err = dialer.Dial("tcp", host)
+	case "wss":
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	default:
+		return nil, nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := websocketHandshake(conn, u.Host, u.RequestURI())
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, reader, nil
+}
+
+// websocketHandshake performs the client side of the opening handshake on
+// conn and returns a reader positioned at the first frame.
+func websocketHandshake(conn net.Conn, host string, requestURI string) (*bufio.Reader, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req, err := http.NewRequest(http.MethodGet, "http://"+host+requestURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("websocket handshake failed: %s", resp.Status)
+	}
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return nil, errors.New("websocket handshake failed: bad Sec-WebSocket-Accept")
+	}
+	return reader, nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebSocket close codes sent to clients (RFC 6455 section 7.4.1).
+const (
+	closeGoingAway      uint16 = 1001
+	closeMessageTooBig  uint16 = 1009
+	maxCloseReasonBytes        = 123
+)
+
+// closeError ends a session with a close frame so that CDP clients report a
+// meaningful reason instead of an abrupt disconnect.
+type closeError struct {
+	code   uint16
+	reason string
+}
+
+func (e *closeError) Error() string {
+	return e.reason
+}
+
+// wsSession relays one client WebSocket connection to Chrome frame by frame.
+// It is used instead of a raw byte splice whenever a feature needs to see
+// message boundaries.
+type wsSession struct {
+	id    uint64
+	path  string
+	proxy *cdpProxy
+
+	client         net.Conn
+	clientReader   io.Reader
+	upstream       net.Conn
+	upstreamReader io.Reader
+
+	clientMu     sync.Mutex // serializes frames written to the client
+	lastActivity atomic.Int64
+	closeOnce    sync.Once
+	done         chan struct{}
+
+	// closing is set once either side sends a close frame, so that the
+	// upstream going away afterwards is not mistaken for a browser restart.
+	closing atomic.Bool
+
+	// closeSent is set once the proxy has sent the client a close frame;
+	// clientClosed is closed when the client sends one.
+	closeSent       atomic.Bool
+	clientClosed    chan struct{}
+	clientCloseOnce sync.Once
+
+	// upstreamMu guards upstream and the buffer of client frames held while
+	// reconnecting to a restarted browser.
+	upstreamMu    sync.Mutex
+	reconnecting  bool
+	buffered      [][]byte
+	bufferedBytes int
+
+	// Handshake commands replayed after a reconnect; see rememberForReplay.
+	replayMu      sync.Mutex
+	replay        map[string][]byte
+	replayOrder   []string
+	nextReplayID  int64
+	pendingReplay map[int64]bool
+}
+
+func (s *wsSession) run() {
+	if rec := s.proxy.recorder; rec != nil {
+		rec.open(s.id, s.path)
+		defer rec.close(s.id)
+	}
+
+	s.touch()
+	if idle := s.proxy.cfg.idleTimeout; idle > 0 {
+		go s.watchIdle(idle)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.finish(s.pumpClient())
+	}()
+	go func() {
+		defer wg.Done()
+		s.finish(s.pumpUpstream())
+	}()
+	wg.Wait()
+}
+
+// messageLimit is the largest message accepted in either direction.
+func (s *wsSession) messageLimit() int {
+	if limit := s.proxy.cfg.maxMessageBytes; limit > 0 {
+		return limit
+	}
+	return maxFramePayload
+}
+
+func (s *wsSession) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// pumpClient forwards client frames to Chrome. Data frames are held until
+// their message is complete so that a policy can drop the whole message.
+func (s *wsSession) pumpClient() error {
+	limit := s.messageLimit()
+	assembler := messageAssembler{limit: limit}
+	var pending [][]byte
+	for {
+		frame, err := readFrame(s.clientReader, limit)
+		if err != nil {
+			return s.frameError(err)
+		}
+		s.touch()
+		if frame.opcode >= opClose {
+			if frame.opcode == opClose {
+				s.closing.Store(true)
+				s.clientCloseOnce.Do(func() { close(s.clientClosed) })
+			}
+			if err := s.sendUpstream(frame.raw); err != nil {
+				return err
+			}
+			continue
+		}
+
+		opcode, message, ok, err := assembler.add(frame)
+		if err != nil {
+			return s.frameError(err)
+		}
+		pending = append(pending, frame.raw)
+		if !ok && assembler.active {
+			continue
+		}
+		if ok {
+			if rec := s.proxy.recorder; rec != nil {
+				rec.message(s.id, dirSend, opcode, message)
+			}
+			if policy := s.proxy.policy; policy != nil && opcode == opText {
+				if response, blocked := policy.check(message); blocked {
+					pending = pending[:0]
+					if err := s.replyToClient(response); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if s.proxy.cfg.reconnectGrace > 0 && opcode == opText {
+				s.rememberForReplay(message)
+			}
+		}
+		if err := s.sendUpstream(pending...); err != nil {
+			return err
+		}
+		pending = pending[:0]
+	}
+}
+
+// replyToClient sends a message synthesized by the proxy to the client.
+func (s *wsSession) replyToClient(message []byte) error {
+	frame, err := encodeFrame(opText, message, false)
+	if err != nil {
+		return err
+	}
+	if rec := s.proxy.recorder; rec != nil {
+		rec.message(s.id, dirRecv, opText, message)
+	}
+	return s.writeClient(frame)
+}
+
+// pumpUpstream forwards Chrome frames to the client. When the browser goes
+// away and reconnecting is enabled, it switches to the replacement connection.
+func (s *wsSession) pumpUpstream() error {
+	limit := s.messageLimit()
+	assembler := messageAssembler{limit: limit}
+	reader := s.upstreamReader
+	for {
+		frame, err := readFrame(reader, limit)
+		if err != nil {
+			if !s.shouldReconnect(err) {
+				return s.frameError(err)
+			}
+			if reader, err = s.reconnect(); err != nil {
+				return err
+			}
+			assembler = messageAssembler{limit: limit}
+			continue
+		}
+		s.touch()
+		if frame.opcode == opClose {
+			s.closing.Store(true)
+		}
+		opcode, message, ok, err := assembler.add(frame)
+		if err != nil {
+			return s.frameError(err)
+		}
+		if ok {
+			if frame.opcode == opText && s.isReplayResponse(message) {
+				continue
+			}
+			if rec := s.proxy.recorder; rec != nil {
+				rec.message(s.id, dirRecv, opcode, message)
+			}
+		}
+		// Nothing may follow the proxy's own close frame
+		if s.closeSent.Load() {
+			continue
+		}
+		if err := s.writeClient(frame.raw); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *wsSession) frameError(err error) error {
+	if errors.Is(err, errFrameTooLarge) {
+		return &closeError{code: closeMessageTooBig, reason: "message exceeds proxy size limit"}
+	}
+	return err
+}
+
+func (s *wsSession) writeClient(frame []byte) error {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	_, err := s.client.Write(frame)
+	return err
+}
+
+// watchIdle closes the session once no frame has crossed it in either
+// direction for the idle timeout.
+func (s *wsSession) watchIdle(timeout time.Duration) {
+	ticker := time.NewTicker(min(timeout/4, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			last := time.Unix(0, s.lastActivity.Load())
+			if time.Since(last) >= timeout {
+				s.finish(&closeError{code: closeGoingAway, reason: "idle timeout"})
+				return
+			}
+		}
+	}
+}
+
+// goAway sends the client a going-away close frame and gives it up to
+// closeGrace to answer with its own before tearing the session down.
+func (s *wsSession) goAway(reason string) {
+	log.Printf("websocket session %d closing: %s", s.id, reason)
+	s.closing.Store(true)
+	s.sendClose(closeGoingAway, reason)
+	select {
+	case <-s.clientClosed:
+	case <-s.done:
+	case <-time.After(closeGrace):
+	}
+	s.finish(nil)
+}
+
+// finish tears the session down exactly once, sending a close frame to the
+// client first when the session ends because of a proxy policy.
+func (s *wsSession) finish(err error) {
+	s.closeOnce.Do(func() {
+		var ce *closeError
+		if errors.As(err, &ce) {
+			log.Printf("websocket session %d closed: %s", s.id, ce.reason)
+			s.sendClose(ce.code, ce.reason)
+		}
+		s.client.Close()
+		s.upstreamMu.Lock()
+		s.upstream.Close()
+		close(s.done)
+		s.upstreamMu.Unlock()
+	})
+}
+
+// sendClose sends a close frame to the client, at most once per session.
+func (s *wsSession) sendClose(code uint16, reason string) {
+	if !s.closeSent.CompareAndSwap(false, true) {
+		return
+	}
+	frame, err := encodeCloseFrame(code, reason)
+	if err != nil {
+		return
+	}
+	_ = s.client.SetWriteDeadline(time.Now().Add(time.Second))
+	_ = s.writeClient(frame)
+}
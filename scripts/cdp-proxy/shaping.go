@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// shapeFlushTimeout bounds how long a closed shapedConn keeps delivering
+// data that was queued before Close.
+const shapeFlushTimeout = 2 * time.Second
+
+// shape wraps a WebSocket connection with the configured artificial latency
+// and bandwidth limit, or returns it unchanged when shaping is off. Each
+// wrapped connection shapes the direction written to it, so wrapping both
+// sides of a session shapes both directions independently.
+func (p *cdpProxy) shape(conn net.Conn) net.Conn {
+	if p.cfg.shapeLatency <= 0 && p.cfg.shapeBandwidth <= 0 {
+		return conn
+	}
+	return newShapedConn(conn, p.cfg.shapeLatency, p.cfg.shapeBandwidth)
+}
+
+type shapedChunk struct {
+	data []byte
+	due  time.Time
+}
+
+// shapedConn delays every write by a fixed latency and paces delivery to a
+// fixed number of bytes per second. Writes are queued and return immediately,
+// so latency does not also cap throughput; delivery is deterministic for a
+// given sequence of writes.
+type shapedConn struct {
+	net.Conn
+	latency   time.Duration
+	bandwidth int64 // bytes per second; zero means unlimited
+
+	queue     chan shapedChunk
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error // set by run before done is closed
+}
+
+func newShapedConn(conn net.Conn, latency time.Duration, bandwidth int64) *shapedConn {
+	c := &shapedConn{
+		Conn:      conn,
+		latency:   latency,
+		bandwidth: bandwidth,
+		queue:     make(chan shapedChunk, 64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+func (c *shapedConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.stop:
+		return 0, net.ErrClosed
+	default:
+	}
+	chunk := shapedChunk{data: append([]byte(nil), b...), due: time.Now().Add(c.latency)}
+	select {
+	case c.queue <- chunk:
+		return len(b), nil
+	case <-c.stop:
+		return 0, net.ErrClosed
+	case <-c.done:
+		return 0, c.err
+	}
+}
+
+// Close stops accepting writes. Queued data is still delivered, for at most
+// shapeFlushTimeout, before the underlying connection is closed.
+func (c *shapedConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+func (c *shapedConn) run() {
+	defer close(c.done)
+	defer c.Conn.Close()
+
+	var linkFree time.Time
+	for {
+		select {
+		case chunk := <-c.queue:
+			if c.err = c.deliver(chunk, &linkFree); c.err != nil {
+				return
+			}
+		case <-c.stop:
+			c.flush(&linkFree)
+			return
+		}
+	}
+}
+
+func (c *shapedConn) flush(linkFree *time.Time) {
+	deadline := time.Now().Add(shapeFlushTimeout)
+	_ = c.Conn.SetWriteDeadline(deadline)
+	for {
+		select {
+		case chunk := <-c.queue:
+			if chunk.due.After(deadline) || c.deliver(chunk, linkFree) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// deliver writes a chunk once it has spent the latency in flight and, with a
+// bandwidth limit, the time needed to serialize it behind earlier chunks.
+func (c *shapedConn) deliver(chunk shapedChunk, linkFree *time.Time) error {
+	start := chunk.due
+	if linkFree.After(start) {
+		start = *linkFree
+	}
+	*linkFree = start
+	if c.bandwidth > 0 {
+		*linkFree = start.Add(time.Duration(int64(len(chunk.data)) * int64(time.Second) / c.bandwidth))
+	}
+	time.Sleep(time.Until(*linkFree))
+	_, err := c.Conn.Write(chunk.data)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestShapedConnAddsLatencyAndPacesBandwidth(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	shaped := newShapedConn(server, 50*time.Millisecond, 10_000)
+	defer shaped.Close()
+
+	start := time.Now()
+	for range 2 {
+		if _, err := shaped.Write(make([]byte, 500)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Fatalf("writes should not block on shaping, took %s", elapsed)
+	}
+
+	if _, err := io.ReadFull(client, make([]byte, 1000)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	// 50ms latency plus 1000 bytes at 10kB/s.
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("expected delivery after ~150ms, got %s", elapsed)
+	}
+}
+
+func TestShapedConnFlushesOnClose(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	shaped := newShapedConn(server, 20*time.Millisecond, 0)
+	if _, err := shaped.Write([]byte("bye")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	shaped.Close()
+
+	data, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(data) != "bye" {
+		t.Fatalf("expected queued data before close, got %q", data)
+	}
+	if _, err := shaped.Write([]byte("late")); err == nil {
+		t.Fatal("expected write after close to fail")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader serves a certificate loaded from disk and reloads it when the
+// cert or key file changes, e.g. after a certbot renewal inside the VM.
+type certReloader struct {
+	certPath string
+	keyPath  string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certStat fileStamp
+	keyStat  fileStamp
+}
+
+// fileStamp identifies a version of a file. os.Stat follows symlinks, so
+// certbot's live/ symlink swaps are picked up as well.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func newCertReloader(certPath, keyPath string) (*certReloader, error) {
+	r := &certReloader{certPath: certPath, keyPath: keyPath}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the key pair if either file changed since the last load and
+// reports whether a new certificate was installed.
+func (r *certReloader) reload() (bool, error) {
+	certStat, err := statFile(r.certPath)
+	if err != nil {
+		return false, fmt.Errorf("stat certificate: %w", err)
+	}
+	keyStat, err := statFile(r.keyPath)
+	if err != nil {
+		return false, fmt.Errorf("stat key: %w", err)
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && certStat == r.certStat && keyStat == r.keyStat
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return false, fmt.Errorf("load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certStat = certStat
+	r.keyStat = keyStat
+	r.mu.Unlock()
+	return true, nil
+}
+
+// watch polls for changes until stop is closed. A failed reload keeps the
+// previous certificate in place, since renewals may write the cert and key
+// non-atomically.
+func (r *certReloader) watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				log.Printf("tls reload failed, keeping current certificate: %v", err)
+				continue
+			}
+			if reloaded {
+				log.Printf("tls certificate reloaded from %s", r.certPath)
+			}
+		}
+	}
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+}
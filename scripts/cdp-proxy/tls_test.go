@@ -0,0 +1,100 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSelfSignedCert(t *testing.T, certPath, keyPath, commonName string, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+}
+
+func currentCommonName(t *testing.T, r *certReloader) string {
+	t.Helper()
+	cert, err := r.getCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("get certificate: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloaderPicksUpRenewedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	start := time.Now().Add(-time.Minute)
+	writeSelfSignedCert(t, certPath, keyPath, "first", start)
+
+	reloader, err := newCertReloader(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+	if got := currentCommonName(t, reloader); got != "first" {
+		t.Fatalf("expected first certificate, got %q", got)
+	}
+
+	if reloaded, err := reloader.reload(); err != nil || reloaded {
+		t.Fatalf("unchanged files should not reload: reloaded=%v err=%v", reloaded, err)
+	}
+
+	writeSelfSignedCert(t, certPath, keyPath, "second", start.Add(time.Second))
+	if reloaded, err := reloader.reload(); err != nil || !reloaded {
+		t.Fatalf("expected reload: reloaded=%v err=%v", reloaded, err)
+	}
+	if got := currentCommonName(t, reloader); got != "second" {
+		t.Fatalf("expected second certificate, got %q", got)
+	}
+
+	// A half-written renewal keeps the previous certificate.
+	if err := os.WriteFile(keyPath, []byte("garbage"), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if _, err := reloader.reload(); err == nil {
+		t.Fatalf("expected reload error for invalid key")
+	}
+	if got := currentCommonName(t, reloader); got != "second" {
+		t.Fatalf("expected second certificate to remain, got %q", got)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+func headerContainsToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serveWebSocket forwards the upgrade handshake to Chrome and, once Chrome
+// switches protocols, splices the client and upstream connections together.
+// Frames are relayed byte-for-byte so there is no per-message overhead.
+func (p *cdpProxy) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	if p.draining.Load() {
+		http.Error(w, "CDP proxy is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if limit := p.cfg.maxSessions; limit > 0 {
+		if p.activeSessions.Add(1) > int64(limit) {
+			p.activeSessions.Add(-1)
+			log.Printf("rejecting websocket session: %d sessions already open", limit)
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "Too many DevTools sessions", http.StatusServiceUnavailable)
+			return
+		}
+		defer p.activeSessions.Add(-1)
+	}
+
+	target := p.target()
+	upstream, err := p.dialUpstream(r.Context(), "tcp", target.addr)
+	if err != nil {
+		log.Printf("websocket dial failed: %v", err)
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		return
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.Host = target.hostHeader
+	outReq.RequestURI = ""
+	outReq.Header.Del("Proxy-Connection")
+	if p.inspectsFrames() {
+		// Compressed frames cannot be inspected; CDP clients fall back to
+		// uncompressed messages when no extension is negotiated.
+		outReq.Header.Del("Sec-WebSocket-Extensions")
+	}
+	if err := outReq.Write(upstream); err != nil {
+		upstream.Close()
+		log.Printf("websocket handshake write failed: %v", err)
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		return
+	}
+
+	upstreamReader := bufio.NewReader(upstream)
+	resp, err := http.ReadResponse(upstreamReader, outReq)
+	if err != nil {
+		upstream.Close()
+		log.Printf("websocket handshake read failed: %v", err)
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+		return
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		// Chrome rejected the upgrade (e.g. unknown target); relay its answer.
+		defer upstream.Close()
+		defer resp.Body.Close()
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "WebSocket upgrade not supported", http.StatusInternalServerError)
+		return
+	}
+	client, clientBuf, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		log.Printf("websocket hijack failed: %v", err)
+		return
+	}
+
+	client = p.shape(client)
+	upstream = p.shape(upstream)
+	if err := writeSwitchingProtocols(client, resp); err != nil {
+		client.Close()
+		upstream.Close()
+		log.Printf("websocket handshake relay failed: %v", err)
+		return
+	}
+
+	id := p.nextConnID.Add(1)
+	if !p.inspectsFrames() {
+		splice := &splicedSession{
+			id:           id,
+			client:       client,
+			upstream:     upstream,
+			clientClosed: make(chan struct{}),
+			done:         make(chan struct{}),
+		}
+		defer p.untrack(id)
+		p.track(id, splice.goAway)
+		splice.run(clientBuf.Reader, upstreamReader)
+		return
+	}
+
+	session := &wsSession{
+		id:             id,
+		path:           r.URL.RequestURI(),
+		proxy:          p,
+		client:         client,
+		clientReader:   clientBuf.Reader,
+		upstream:       upstream,
+		upstreamReader: upstreamReader,
+		clientClosed:   make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	defer p.untrack(id)
+	p.track(id, session.goAway)
+	session.run()
+}
+
+// inspectsFrames reports whether WebSocket traffic must be relayed frame by
+// frame instead of spliced as raw bytes.
+func (p *cdpProxy) inspectsFrames() bool {
+	return p.recorder != nil || p.policy != nil || p.cfg.idleTimeout > 0 || p.cfg.maxMessageBytes > 0 ||
+		p.cfg.reconnectGrace > 0
+}
+
+func writeSwitchingProtocols(w io.Writer, resp *http.Response) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "HTTP/1.1 %s\r\n", resp.Status)
+	if err := resp.Header.Write(buf); err != nil {
+		return err
+	}
+	if _, err := buf.WriteString("\r\n"); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// splicedSession relays a WebSocket connection without buffering messages.
+// Payloads are streamed through unchanged, but frame headers are parsed so
+// that the proxy knows where frames end and can still send the client a
+// close frame when it drains.
+type splicedSession struct {
+	id       uint64
+	client   net.Conn
+	upstream net.Conn
+
+	// clientMu is held while a frame is written to the client. goingAway
+	// is set once the proxy's close frame is sent; broken once a frame to
+	// the client was cut short, so no close frame can follow it.
+	clientMu  sync.Mutex
+	goingAway bool
+	broken    bool
+
+	clientClosed    chan struct{} // closed when the client sends a close frame
+	clientCloseOnce sync.Once
+	done            chan struct{}
+}
+
+// run copies frames in both directions until either side closes. The
+// readers hold any bytes already buffered during the handshake.
+func (s *splicedSession) run(clientReader io.Reader, upstreamReader io.Reader) {
+	defer close(s.done)
+	pipeConns(s.client, s.upstream,
+		func() error {
+			for {
+				h, err := readFrameHeader(clientReader)
+				if err != nil {
+					return err
+				}
+				if err := copyFrame(s.upstream, clientReader, h); err != nil {
+					return err
+				}
+				if h.opcode == opClose {
+					s.clientCloseOnce.Do(func() { close(s.clientClosed) })
+				}
+			}
+		},
+		func() error {
+			for {
+				h, err := readFrameHeader(upstreamReader)
+				if err != nil {
+					return err
+				}
+				if err := s.copyToClient(upstreamReader, h); err != nil {
+					return err
+				}
+			}
+		},
+	)
+}
+
+func (s *splicedSession) copyToClient(r io.Reader, h *frameHeader) error {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	if s.goingAway {
+		// Nothing may follow the proxy's own close frame
+		_, err := io.CopyN(io.Discard, r, int64(h.length))
+		return err
+	}
+	if err := copyFrame(s.client, r, h); err != nil {
+		s.broken = true
+		return err
+	}
+	return nil
+}
+
+// copyFrame writes a frame whose header has been read, streaming its payload
+// from r.
+func copyFrame(w io.Writer, r io.Reader, h *frameHeader) error {
+	if _, err := w.Write(h.raw); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w, r, int64(h.length))
+	return err
+}
+
+// goAway sends the client a going-away close frame once the frame in flight
+// has been relayed, and gives it up to closeGrace to answer before closing
+// both connections.
+func (s *splicedSession) goAway(reason string) {
+	log.Printf("websocket session %d closing: %s", s.id, reason)
+	// Bounds the wait for a frame Chrome is still streaming to the client
+	_ = s.upstream.SetReadDeadline(time.Now().Add(closeGrace))
+
+	s.clientMu.Lock()
+	if !s.goingAway && !s.broken {
+		s.goingAway = true
+		if frame, err := encodeCloseFrame(closeGoingAway, reason); err == nil {
+			_ = s.client.SetWriteDeadline(time.Now().Add(time.Second))
+			_, _ = s.client.Write(frame)
+		}
+	}
+	s.clientMu.Unlock()
+
+	select {
+	case <-s.clientClosed:
+	case <-s.done:
+	case <-time.After(closeGrace):
+	}
+	s.client.Close()
+	s.upstream.Close()
+}
+
+// pipeConns runs both copy directions and closes both connections as soon as
+// either direction finishes.
+func pipeConns(client net.Conn, upstream net.Conn, toUpstream func() error, toClient func() error) {
+	var once sync.Once
+	closeBoth := func() {
+		once.Do(func() {
+			client.Close()
+			upstream.Close()
+		})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer closeBoth()
+		_ = toUpstream()
+	}()
+	go func() {
+		defer wg.Done()
+		defer closeBoth()
+		_ = toClient()
+	}()
+	wg.Wait()
+}
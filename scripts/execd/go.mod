@@ -1,3 +1,5 @@
 module cmux/execd
 
 go 1.25
+
+require golang.org/x/sys v0.40.0
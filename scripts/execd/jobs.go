@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// job tracks a single command execution and retains its most recent output
+// in a fixed-size ring buffer so that clients can re-attach after a dropped
+// connection and replay everything they missed.
+type job struct {
+	id     string
+	cancel context.CancelFunc
+	// detached jobs keep running with no client attached. Other jobs are
+	// canceled once no client has streamed their output for orphanGrace.
+	detached    bool
+	orphanGrace time.Duration
+
+	mu          sync.Mutex
+	ring        []execEvent
+	head        int // index of the oldest retained event
+	size        int // number of retained events
+	nextSeq     uint64
+	done        bool
+	changed     chan struct{}
+	followers   int
+	orphanTimer *time.Timer
+	abandoned   bool
+	stopped     bool
+}
+
+// eventTimestamp formats the current time for an event. Fractional seconds
+// are kept so consumers can profile sub-second gaps between lines.
+func eventTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func newJob(id string, capacity int, cancel context.CancelFunc) *job {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &job{
+		id:      id,
+		cancel:  cancel,
+		ring:    make([]execEvent, capacity),
+		nextSeq: 1,
+		changed: make(chan struct{}),
+	}
+}
+
+// append assigns the next sequence number and a timestamp to event, stores
+// it, and wakes any followers. The oldest event is dropped once the buffer is
+// full. Both are assigned under the lock so that seq order matches ts order.
+func (j *job) append(event execEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	event.Ts = eventTimestamp()
+	event.Seq = j.nextSeq
+	j.nextSeq++
+	if j.size < len(j.ring) {
+		j.ring[(j.head+j.size)%len(j.ring)] = event
+		j.size++
+	} else {
+		j.ring[j.head] = event
+		j.head = (j.head + 1) % len(j.ring)
+	}
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// finish marks the job as complete; followers drain the buffer and return.
+func (j *job) finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done = true
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// eventsAfter returns the retained events with a sequence number greater than
+// after, the number of requested events that have already been discarded, and
+// a channel that is closed on the next change.
+func (j *job) eventsAfter(after uint64) ([]execEvent, uint64, bool, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	oldest := j.nextSeq - uint64(j.size)
+	var skipped uint64
+	if after+1 < oldest {
+		skipped = oldest - after - 1
+		after = oldest - 1
+	}
+	start := int(after + 1 - oldest)
+	events := make([]execEvent, 0, j.size-min(start, j.size))
+	for i := start; i < j.size; i++ {
+		events = append(events, j.ring[(j.head+i)%len(j.ring)])
+	}
+	return events, skipped, j.done, j.changed
+}
+
+// addFollower registers a client streaming the job's output and stops any
+// pending cancellation.
+func (j *job) addFollower() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.followers++
+	if j.orphanTimer != nil {
+		j.orphanTimer.Stop()
+		j.orphanTimer = nil
+	}
+}
+
+// removeFollower unregisters a client. When the last one leaves a job that
+// isn't detached, the job is canceled unless a client re-attaches within the
+// grace period.
+func (j *job) removeFollower() {
+	j.mu.Lock()
+	j.followers--
+	if j.followers > 0 || j.done || j.detached {
+		j.mu.Unlock()
+		return
+	}
+	if j.orphanGrace > 0 {
+		if j.orphanTimer == nil {
+			j.orphanTimer = time.AfterFunc(j.orphanGrace, j.abandon)
+		}
+		j.mu.Unlock()
+		return
+	}
+	j.mu.Unlock()
+	j.abandon()
+}
+
+// abandon cancels the job unless a client re-attached in the meantime.
+func (j *job) abandon() {
+	j.mu.Lock()
+	if j.followers > 0 || j.done {
+		j.mu.Unlock()
+		return
+	}
+	j.abandoned = true
+	j.orphanTimer = nil
+	j.mu.Unlock()
+	j.cancel()
+}
+
+// wasAbandoned reports whether the job was canceled because no client
+// re-attached after a disconnect.
+func (j *job) wasAbandoned() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.abandoned
+}
+
+// stop cancels the job at a client's request, whether or not it is
+// detached.
+func (j *job) stop() {
+	j.mu.Lock()
+	if !j.done {
+		j.stopped = true
+	}
+	if j.orphanTimer != nil {
+		j.orphanTimer.Stop()
+		j.orphanTimer = nil
+	}
+	j.mu.Unlock()
+	j.cancel()
+}
+
+// wasStopped reports whether the job was canceled via stop.
+func (j *job) wasStopped() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stopped
+}
+
+// follow streams events with a sequence number greater than after until the
+// job finishes or ctx is canceled.
+func (j *job) follow(ctx context.Context, after uint64, w http.ResponseWriter, flusher http.Flusher) error {
+	for {
+		events, skipped, done, changed := j.eventsAfter(after)
+		if skipped > 0 {
+			// The notice takes the seq of the last discarded event, so a
+			// client resuming from it picks up at the oldest retained one.
+			after += skipped
+			err := writeJSONLine(w, flusher, execEvent{
+				Seq:     after,
+				Ts:      eventTimestamp(),
+				Type:    "error",
+				Message: fmt.Sprintf("output truncated: %d events were discarded", skipped),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, event := range events {
+			if err := writeJSONLine(w, flusher, event); err != nil {
+				return err
+			}
+			after = event.Seq
+		}
+		if done && len(events) == 0 {
+			return nil
+		}
+		if len(events) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// jobStore holds running jobs and keeps finished ones around for a while so
+// their output can still be replayed.
+type jobStore struct {
+	bufferSize     int
+	retention      time.Duration
+	reconnectGrace time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func newJobStore(bufferSize int, retention, reconnectGrace time.Duration) *jobStore {
+	return &jobStore{
+		bufferSize:     bufferSize,
+		retention:      retention,
+		reconnectGrace: reconnectGrace,
+		jobs:           make(map[string]*job),
+	}
+}
+
+func newJobID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (s *jobStore) create(cancel context.CancelFunc, detached bool) (*job, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, fmt.Errorf("generate job id: %w", err)
+	}
+	j := newJob(id, s.bufferSize, cancel)
+	j.detached = detached
+	j.orphanGrace = s.reconnectGrace
+	s.mu.Lock()
+	s.jobs[id] = j
+	s.mu.Unlock()
+	return j, nil
+}
+
+func (s *jobStore) get(id string) (*job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// release schedules removal of a finished job once the retention period
+// elapses.
+func (s *jobStore) release(j *job) {
+	time.AfterFunc(s.retention, func() {
+		s.mu.Lock()
+		delete(s.jobs, j.id)
+		s.mu.Unlock()
+	})
+}
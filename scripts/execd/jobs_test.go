@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobRingBufferDiscardsOldest(t *testing.T) {
+	j := newJob("test", 3, func() {})
+	for _, line := range []string{"a", "b", "c", "d", "e"} {
+		j.append(execEvent{Type: "stdout", Data: line})
+	}
+
+	events, skipped, done, _ := j.eventsAfter(0)
+	if done {
+		t.Fatalf("job should not be done")
+	}
+	if skipped != 2 {
+		t.Fatalf("expected 2 skipped events, got %d", skipped)
+	}
+	if len(events) != 3 || events[0].Data != "c" || events[0].Seq != 3 || events[2].Seq != 5 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	events, skipped, _, _ = j.eventsAfter(4)
+	if skipped != 0 || len(events) != 1 || events[0].Data != "e" {
+		t.Fatalf("unexpected replay after seq 4: skipped=%d events=%+v", skipped, events)
+	}
+
+	events, _, _, _ = j.eventsAfter(5)
+	if len(events) != 0 {
+		t.Fatalf("expected no events after latest seq, got %+v", events)
+	}
+}
+
+func TestJobFollowStreamsUntilFinished(t *testing.T) {
+	j := newJob("test", 16, func() {})
+	j.append(execEvent{Type: "stdout", Data: "first"})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		j.append(execEvent{Type: "stdout", Data: "second"})
+		exitCode := 0
+		j.append(execEvent{Type: "exit", Code: &exitCode})
+		j.finish()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recorder := httptest.NewRecorder()
+	if err := j.follow(ctx, 1, recorder, recorder); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	var got []execEvent
+	scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+	for scanner.Scan() {
+		var event execEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		got = append(got, event)
+	}
+	if len(got) != 2 || got[0].Data != "second" || got[1].Type != "exit" {
+		t.Fatalf("unexpected followed events: %+v", got)
+	}
+}
+
+func TestJobFollowNumbersTruncationNotice(t *testing.T) {
+	j := newJob("test", 2, func() {})
+	for _, line := range []string{"a", "b", "c", "d"} {
+		j.append(execEvent{Type: "stdout", Data: line})
+	}
+	j.finish()
+
+	recorder := httptest.NewRecorder()
+	if err := j.follow(context.Background(), 0, recorder, recorder); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	var got []execEvent
+	scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+	for scanner.Scan() {
+		var event execEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		got = append(got, event)
+	}
+	if len(got) != 3 || got[0].Type != "error" || got[0].Seq != 2 || got[1].Seq != 3 || got[2].Seq != 4 {
+		t.Fatalf("unexpected followed events: %+v", got)
+	}
+}
+
+func TestJobAppendAssignsOrderedTimestamps(t *testing.T) {
+	j := newJob("test", 8, func() {})
+	j.append(execEvent{Type: "stdout", Data: "a"})
+	j.append(execEvent{Type: "stderr", Data: "b"})
+
+	events, _, _, _ := j.eventsAfter(0)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	var previous time.Time
+	for i, event := range events {
+		if event.Seq != uint64(i+1) {
+			t.Fatalf("event %d has seq %d", i, event.Seq)
+		}
+		ts, err := time.Parse(time.RFC3339Nano, event.Ts)
+		if err != nil {
+			t.Fatalf("event %d has invalid ts %q: %v", i, event.Ts, err)
+		}
+		if ts.Before(previous) {
+			t.Fatalf("event %d ts %s is before previous %s", i, ts, previous)
+		}
+		previous = ts
+	}
+}
+
+func TestJobCanceledOnlyAfterReconnectGrace(t *testing.T) {
+	canceled := make(chan struct{})
+	j := newJob("test", 8, func() { close(canceled) })
+	j.orphanGrace = 50 * time.Millisecond
+
+	j.addFollower()
+	j.removeFollower()
+	time.Sleep(20 * time.Millisecond)
+	j.addFollower()
+	time.Sleep(60 * time.Millisecond)
+	select {
+	case <-canceled:
+		t.Fatalf("job canceled although a client re-attached within the grace period")
+	default:
+	}
+
+	j.removeFollower()
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job not canceled after the grace period")
+	}
+	if !j.wasAbandoned() {
+		t.Fatalf("expected job to be marked abandoned")
+	}
+}
+
+func TestDetachedJobSurvivesDisconnect(t *testing.T) {
+	canceled := make(chan struct{})
+	j := newJob("test", 8, func() { close(canceled) })
+	j.detached = true
+
+	j.addFollower()
+	j.removeFollower()
+	select {
+	case <-canceled:
+		t.Fatalf("detached job canceled after client disconnect")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestCancelEndpointKillsJob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	server := httptest.NewServer(newHandler(newJobStore(64, time.Minute, time.Minute)))
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/exec", "application/json", strings.NewReader(`{"command":"sleep 30","detach":true}`))
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	defer resp.Body.Close()
+	jobID := resp.Header.Get("X-Execd-Job-Id")
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/jobs/"+jobID, nil)
+	cancelResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	cancelResp.Body.Close()
+	if cancelResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("cancel status = %d, want %d", cancelResp.StatusCode, http.StatusNoContent)
+	}
+
+	done := make(chan []execEvent)
+	go func() {
+		var events []execEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var event execEvent
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				events = append(events, event)
+			}
+		}
+		done <- events
+	}()
+	var events []execEvent
+	select {
+	case events = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job still running after cancel")
+	}
+	if len(events) < 2 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	last, notice := events[len(events)-1], events[len(events)-2]
+	if last.Type != "exit" || last.Code == nil || *last.Code != 1 {
+		t.Fatalf("unexpected exit event: %+v", last)
+	}
+	if notice.Type != "error" || !strings.Contains(notice.Message, "canceled") {
+		t.Fatalf("unexpected cancel notice: %+v", notice)
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, server.URL+"/jobs/unknown", nil)
+	missing, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("cancel unknown job: %v", err)
+	}
+	missing.Body.Close()
+	if missing.StatusCode != http.StatusNotFound {
+		t.Fatalf("cancel unknown job status = %d, want %d", missing.StatusCode, http.StatusNotFound)
+	}
+}
@@ -19,11 +19,28 @@ import (
 )
 
 type execRequest struct {
-	Command   string `json:"command"`
-	TimeoutMs *int   `json:"timeout_ms"`
+	Command string `json:"command"`
+	// Shell selects the interpreter for Command: "sh", "bash", "cmd", or
+	// "powershell". Defaults to sh, or cmd on Windows.
+	Shell string `json:"shell"`
+	// Argv runs a program directly without a shell. Command and Shell are
+	// ignored when it is set.
+	Argv      []string `json:"argv"`
+	TimeoutMs *int     `json:"timeout_ms"`
+	// Detach keeps the command running however long the client stays
+	// disconnected. Other commands keep running for the reconnect grace
+	// period, so a client can re-attach via /jobs/{id}/output after a
+	// dropped connection, and are killed if none does. DELETE /jobs/{id}
+	// kills any command right away.
+	Detach bool `json:"detach"`
 }
 
+// execEvent is one line of the JSON-lines stream. Seq increases
+// monotonically per job across stdout, stderr, and status events, so it gives
+// the true interleaving order; Ts records when execd observed the event.
 type execEvent struct {
+	Seq     uint64 `json:"seq,omitempty"`
+	Ts      string `json:"ts"`
 	Type    string `json:"type"`
 	Data    string `json:"data,omitempty"`
 	Code    *int   `json:"code,omitempty"`
@@ -43,155 +60,105 @@ func writeJSONLine(w io.Writer, flusher http.Flusher, event execEvent) error {
 	return nil
 }
 
-func readPipe(ctx context.Context, reader io.Reader, eventType string, wg *sync.WaitGroup, w io.Writer, flusher http.Flusher) {
+func readPipe(reader io.Reader, eventType string, wg *sync.WaitGroup, j *job) {
 	defer wg.Done()
 	scanner := bufio.NewScanner(reader)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
 		line := strings.TrimRight(scanner.Text(), "\r")
 		if line == "" {
 			continue
 		}
-		if err := writeJSONLine(w, flusher, execEvent{Type: eventType, Data: line}); err != nil {
-			return
-		}
+		j.append(execEvent{Type: eventType, Data: line})
 	}
 	if err := scanner.Err(); err != nil {
-		_ = writeJSONLine(w, flusher, execEvent{
+		j.append(execEvent{
 			Type:    "error",
 			Message: fmt.Sprintf("%s read failed: %v", eventType, err),
 		})
 	}
 }
 
-func execHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-		return
-	}
-	contentType := r.Header.Get("Content-Type")
-	if !strings.Contains(strings.ToLower(contentType), "application/json") {
-		http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
-		return
-	}
-
-	var payload execRequest
-	decoder := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
-	if err := decoder.Decode(&payload); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid JSON body: %v", err), http.StatusBadRequest)
-		return
-	}
-
-	command := strings.TrimSpace(payload.Command)
-	if command == "" {
-		http.Error(w, "Command is required", http.StatusBadRequest)
-		return
-	}
+// runJob executes command and records its output on j. It returns once the
+// command has exited and the final exit event has been recorded.
+func runJob(ctx context.Context, j *job, payload execRequest, timeoutMs int) {
+	defer j.finish()
 
-	timeoutMs := 0
-	var timeout time.Duration
-	if payload.TimeoutMs != nil {
-		if *payload.TimeoutMs < 0 {
-			http.Error(w, "timeout_ms must be non-negative", http.StatusBadRequest)
-			return
-		}
-		timeoutMs = *payload.TimeoutMs
-		timeout = time.Duration(*payload.TimeoutMs) * time.Millisecond
-	}
-
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+	cmd, err := buildCommand(ctx, payload)
+	if err != nil {
+		j.append(execEvent{Type: "error", Message: err.Error()})
+		exitCode := 127
+		j.append(execEvent{Type: "exit", Code: &exitCode})
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/jsonlines")
-	w.Header().Set("Cache-Control", "no-store")
-	w.WriteHeader(http.StatusOK)
-
-	baseCtx := context.Background()
-	clientCtx := r.Context()
-	var cancel context.CancelFunc
-	if timeout > 0 {
-		baseCtx, cancel = context.WithTimeout(baseCtx, timeout)
-	} else {
-		baseCtx, cancel = context.WithCancel(baseCtx)
-	}
-	defer cancel()
-
-	go func() {
-		select {
-		case <-clientCtx.Done():
-			cancel()
-		case <-baseCtx.Done():
-		}
-	}()
-
-	cmd := exec.CommandContext(baseCtx, "/bin/sh", "-c", command)
+	var tree processTree
+	tree.configure(cmd)
+	defer tree.release()
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		_ = writeJSONLine(w, flusher, execEvent{
+		j.append(execEvent{
 			Type:    "error",
 			Message: fmt.Sprintf("stdout pipe failed: %v", err),
 		})
 		exitCode := 127
-		_ = writeJSONLine(w, flusher, execEvent{Type: "exit", Code: &exitCode})
+		j.append(execEvent{Type: "exit", Code: &exitCode})
 		return
 	}
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		_ = writeJSONLine(w, flusher, execEvent{
+		j.append(execEvent{
 			Type:    "error",
 			Message: fmt.Sprintf("stderr pipe failed: %v", err),
 		})
 		exitCode := 127
-		_ = writeJSONLine(w, flusher, execEvent{Type: "exit", Code: &exitCode})
+		j.append(execEvent{Type: "exit", Code: &exitCode})
 		return
 	}
 
 	if err := cmd.Start(); err != nil {
-		_ = writeJSONLine(w, flusher, execEvent{
+		j.append(execEvent{
 			Type:    "error",
 			Message: fmt.Sprintf("spawn failed: %v", err),
 		})
 		exitCode := 127
-		_ = writeJSONLine(w, flusher, execEvent{Type: "exit", Code: &exitCode})
+		j.append(execEvent{Type: "exit", Code: &exitCode})
 		return
 	}
+	if err := tree.attach(cmd); err != nil {
+		log.Printf("failed to track process tree: %v", err)
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(2)
-	go readPipe(clientCtx, stdout, "stdout", &wg, w, flusher)
-	go readPipe(clientCtx, stderr, "stderr", &wg, w, flusher)
+	go readPipe(stdout, "stdout", &wg, j)
+	go readPipe(stderr, "stderr", &wg, j)
 
 	waitErr := cmd.Wait()
 	wg.Wait()
 
 	exitCode := 0
-	ctxErr := baseCtx.Err()
+	ctxErr := ctx.Err()
 	if waitErr != nil {
 		var exitErr *exec.ExitError
 		switch {
 		case errors.Is(ctxErr, context.DeadlineExceeded):
 			message := fmt.Sprintf("timeout after %dms", timeoutMs)
-			_ = writeJSONLine(w, flusher, execEvent{Type: "error", Message: message})
+			j.append(execEvent{Type: "error", Message: message})
 			exitCode = 124
-		case errors.Is(ctxErr, context.Canceled) && clientCtx.Err() != nil:
-			_ = writeJSONLine(w, flusher, execEvent{
+		case errors.Is(ctxErr, context.Canceled) && j.wasStopped():
+			j.append(execEvent{Type: "error", Message: "request canceled: job was canceled by a client"})
+			exitCode = 1
+		case errors.Is(ctxErr, context.Canceled) && j.wasAbandoned():
+			j.append(execEvent{
 				Type:    "error",
-				Message: "request canceled by client",
+				Message: "request canceled: client disconnected and did not re-attach",
 			})
 			exitCode = 1
 		case errors.As(waitErr, &exitErr):
 			exitCode = exitErr.ExitCode()
 		default:
-			_ = writeJSONLine(w, flusher, execEvent{
+			j.append(execEvent{
 				Type:    "error",
 				Message: fmt.Sprintf("wait failed: %v", waitErr),
 			})
@@ -199,7 +166,147 @@ func execHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	_ = writeJSONLine(w, flusher, execEvent{Type: "exit", Code: &exitCode})
+	j.append(execEvent{Type: "exit", Code: &exitCode})
+}
+
+func execHandler(jobs *jobStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		contentType := r.Header.Get("Content-Type")
+		if !strings.Contains(strings.ToLower(contentType), "application/json") {
+			http.Error(w, "Unsupported Content-Type", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		var payload execRequest
+		decoder := json.NewDecoder(io.LimitReader(r.Body, 1<<20))
+		if err := decoder.Decode(&payload); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		payload.Command = strings.TrimSpace(payload.Command)
+		if payload.Command == "" && len(payload.Argv) == 0 {
+			http.Error(w, "Command is required", http.StatusBadRequest)
+			return
+		}
+		if len(payload.Argv) > 0 && payload.Argv[0] == "" {
+			http.Error(w, "argv[0] must not be empty", http.StatusBadRequest)
+			return
+		}
+		if len(payload.Argv) == 0 {
+			if _, err := shellArgv(payload.Shell, payload.Command); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		timeoutMs := 0
+		var timeout time.Duration
+		if payload.TimeoutMs != nil {
+			if *payload.TimeoutMs < 0 {
+				http.Error(w, "timeout_ms must be non-negative", http.StatusBadRequest)
+				return
+			}
+			timeoutMs = *payload.TimeoutMs
+			timeout = time.Duration(*payload.TimeoutMs) * time.Millisecond
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		baseCtx := context.Background()
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			baseCtx, cancel = context.WithTimeout(baseCtx, timeout)
+		} else {
+			baseCtx, cancel = context.WithCancel(baseCtx)
+		}
+
+		j, err := jobs.create(cancel, payload.Detach)
+		if err != nil {
+			cancel()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jsonlines")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Execd-Job-Id", j.id)
+		w.WriteHeader(http.StatusOK)
+		// Send the job ID now, so a client can cancel or re-attach before
+		// the command prints anything
+		flusher.Flush()
+
+		j.addFollower()
+		defer j.removeFollower()
+
+		go func() {
+			defer cancel()
+			defer jobs.release(j)
+			runJob(baseCtx, j, payload, timeoutMs)
+		}()
+
+		_ = j.follow(r.Context(), 0, w, flusher)
+	}
+}
+
+// jobOutputHandler replays the retained output of a job. Events with a
+// sequence number greater than the "from" query parameter are written, and
+// the response keeps streaming until the job exits.
+func jobOutputHandler(jobs *jobStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		j, ok := jobs.get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+
+		var after uint64
+		if raw := r.URL.Query().Get("from"); raw != "" {
+			value, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				http.Error(w, "from must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			after = value
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jsonlines")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Execd-Job-Id", j.id)
+		w.WriteHeader(http.StatusOK)
+
+		j.addFollower()
+		defer j.removeFollower()
+		_ = j.follow(r.Context(), after, w, flusher)
+	}
+}
+
+// jobCancelHandler kills a job and its process tree. The job's output stays
+// available for replay, ending with the cancellation error and exit event.
+func jobCancelHandler(jobs *jobStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		j, ok := jobs.get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "Job not found", http.StatusNotFound)
+			return
+		}
+		j.stop()
+		w.WriteHeader(http.StatusNoContent)
+	}
 }
 
 func healthHandler(w http.ResponseWriter, _ *http.Request) {
@@ -219,18 +326,27 @@ func determinePort(flagPort int) int {
 	return 39375
 }
 
+func newHandler(jobs *jobStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", healthHandler)
+	mux.HandleFunc("/exec", execHandler(jobs))
+	mux.HandleFunc("GET /jobs/{id}/output", jobOutputHandler(jobs))
+	mux.HandleFunc("DELETE /jobs/{id}", jobCancelHandler(jobs))
+	return mux
+}
+
 func main() {
 	portFlag := flag.Int("port", 39375, "port to listen on")
+	bufferFlag := flag.Int("job-buffer", 10000, "number of output events retained per job for replay")
+	retentionFlag := flag.Duration("job-retention", 10*time.Minute, "how long finished jobs stay available for replay")
+	graceFlag := flag.Duration("reconnect-grace", time.Minute, "how long a command that isn't detached keeps running after its client disconnects, waiting for it to re-attach")
 	flag.Parse()
 
 	port := determinePort(*portFlag)
-	mux := http.NewServeMux()
-	mux.HandleFunc("/healthz", healthHandler)
-	mux.HandleFunc("/exec", execHandler)
-
+	jobs := newJobStore(*bufferFlag, *retentionFlag, *graceFlag)
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", port),
-		Handler:           mux,
+		Handler:           newHandler(jobs),
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       0,
 		WriteTimeout:      0,
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// processTree kills everything a command spawned when it is canceled. On
+// Unix the command runs in its own process group and the whole group is
+// signaled, so grandchildren spawned by the shell (dev servers, watchers) do
+// not outlive a timeout or client cancel.
+type processTree struct{}
+
+func (t *processTree) configure(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+		return err
+	}
+}
+
+func (t *processTree) attach(_ *exec.Cmd) error { return nil }
+
+func (t *processTree) release() {}
@@ -0,0 +1,120 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// processTree kills everything a command spawned when it is canceled. On
+// Windows the command starts suspended, is assigned to a job object, and only
+// then resumes, so nothing it spawns can escape the job. Cancellation
+// terminates the whole job.
+type processTree struct {
+	mu  sync.Mutex
+	job windows.Handle
+}
+
+func (t *processTree) configure(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.CREATE_SUSPENDED,
+	}
+	cmd.Cancel = func() error {
+		t.mu.Lock()
+		job := t.job
+		t.mu.Unlock()
+		if job == 0 {
+			return cmd.Process.Kill()
+		}
+		return windows.TerminateJobObject(job, 1)
+	}
+}
+
+// attach assigns the suspended command to a job object and resumes it. The
+// command is resumed even if the job object can't be set up, and killed if
+// it can't be resumed.
+func (t *processTree) attach(cmd *exec.Cmd) error {
+	pid := uint32(cmd.Process.Pid)
+	err := t.assign(pid)
+	if resumeErr := resumeProcess(pid); resumeErr != nil {
+		_ = cmd.Process.Kill()
+		return errors.Join(err, fmt.Errorf("resume process: %w", resumeErr))
+	}
+	return err
+}
+
+func (t *processTree) assign(pid uint32) error {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return fmt.Errorf("create job object: %w", err)
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		_ = windows.CloseHandle(job)
+		return fmt.Errorf("open process: %w", err)
+	}
+	defer windows.CloseHandle(process)
+	if err := windows.AssignProcessToJobObject(job, process); err != nil {
+		_ = windows.CloseHandle(job)
+		return fmt.Errorf("assign job object: %w", err)
+	}
+
+	t.mu.Lock()
+	t.job = job
+	t.mu.Unlock()
+	return nil
+}
+
+// resumeProcess resumes the threads of a process created with
+// CREATE_SUSPENDED. os/exec doesn't expose the main thread handle, so the
+// threads are found through a snapshot; a suspended process has only its main
+// thread.
+func resumeProcess(pid uint32) error {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPTHREAD, 0)
+	if err != nil {
+		return fmt.Errorf("snapshot threads: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var entry windows.ThreadEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	resumed := 0
+	for err = windows.Thread32First(snapshot, &entry); err == nil; err = windows.Thread32Next(snapshot, &entry) {
+		if entry.OwnerProcessID != pid {
+			continue
+		}
+		thread, err := windows.OpenThread(windows.THREAD_SUSPEND_RESUME, false, entry.ThreadID)
+		if err != nil {
+			return fmt.Errorf("open thread %d: %w", entry.ThreadID, err)
+		}
+		_, err = windows.ResumeThread(thread)
+		_ = windows.CloseHandle(thread)
+		if err != nil {
+			return fmt.Errorf("resume thread %d: %w", entry.ThreadID, err)
+		}
+		resumed++
+	}
+	if !errors.Is(err, windows.ERROR_NO_MORE_FILES) {
+		return fmt.Errorf("enumerate threads: %w", err)
+	}
+	if resumed == 0 {
+		return fmt.Errorf("no threads found for process %d", pid)
+	}
+	return nil
+}
+
+func (t *processTree) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.job != 0 {
+		_ = windows.CloseHandle(t.job)
+		t.job = 0
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// defaultShell is used when a request does not name a shell.
+func defaultShell() string {
+	if runtime.GOOS == "windows" {
+		return "cmd"
+	}
+	return "sh"
+}
+
+// shellArgv returns the argv that runs command with the selected shell.
+func shellArgv(shell string, command string) ([]string, error) {
+	if shell == "" {
+		shell = defaultShell()
+	}
+	switch shell {
+	case "sh":
+		return []string{"/bin/sh", "-c", command}, nil
+	case "bash":
+		return []string{"bash", "-c", command}, nil
+	case "cmd":
+		return []string{"cmd.exe", "/D", "/S", "/C", command}, nil
+	case "powershell":
+		name := "powershell.exe"
+		if runtime.GOOS != "windows" {
+			name = "pwsh"
+		}
+		return []string{name, "-NoProfile", "-NonInteractive", "-Command", command}, nil
+	default:
+		return nil, fmt.Errorf("unsupported shell %q (expected sh, bash, cmd, or powershell)", shell)
+	}
+}
+
+// buildCommand returns the command for an exec request. An explicit argv is
+// run directly without a shell; otherwise command is passed to the selected
+// shell.
+func buildCommand(ctx context.Context, payload execRequest) (*exec.Cmd, error) {
+	argv := payload.Argv
+	if len(argv) == 0 {
+		var err error
+		if argv, err = shellArgv(payload.Shell, payload.Command); err != nil {
+			return nil, err
+		}
+	}
+	return exec.CommandContext(ctx, argv[0], argv[1:]...), nil
+}